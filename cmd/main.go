@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"os"
 
 	"github.com/mnaufalhilmym/bookshelf/internal/config"
 )
@@ -15,23 +17,8 @@ func main() {
 		conf.GetUint("log.max_pc"),
 	)
 
-	db := config.NewDatabase(
-		conf.GetString("db.name"),
-		conf.GetInt("db.pool.idle"),
-		conf.GetInt("db.pool.max"),
-		conf.GetInt("db.pool.lifetime"),
-	)
-
-	router := config.NewGin(conf.GetString("app.mode"))
-
-	config.Bootstrap(
-		router,
-		db,
-		conf.GetString("jwt.key"),
-		conf.GetDuration("jwt.duration"),
-	)
-
-	if err := router.Run(conf.GetString("web.address")); err != nil {
-		panic(fmt.Errorf("failed to start server: %w", err))
+	if err := config.RunCommand(context.Background(), conf, os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 }
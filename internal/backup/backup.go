@@ -0,0 +1,266 @@
+// Package backup keeps gzip compressed snapshots of the SQLite database in a
+// directory, named after the time they were taken.
+package backup
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	prefix     = "bookshelf-"
+	extension  = ".db.gz"
+	timeFormat = "20060102T150405.000Z"
+)
+
+var (
+	ErrNotFound = errors.New("backup not found")
+	ErrInUse    = errors.New("database is in use")
+)
+
+type Snapshot struct {
+	Name      string
+	Size      int64
+	CreatedAt time.Time
+}
+
+type Store struct {
+	dir string
+}
+
+func NewStore(dir string) *Store {
+	return &Store{dir}
+}
+
+// TempPath returns a path in the directory of the store for an uncompressed
+// database, which is on the same file system as the snapshots.
+func (s *Store) TempPath() (string, error) {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return "", err
+	}
+	file, err := os.CreateTemp(s.dir, ".snapshot-*.db")
+	if err != nil {
+		return "", err
+	}
+	path := file.Name()
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+	// VACUUM INTO refuses to write to an existing file.
+	return path, os.Remove(path)
+}
+
+// Save compresses the database at source into a snapshot taken at createdAt.
+// The snapshot appears only once it is complete.
+func (s *Store) Save(source string, createdAt time.Time) (*Snapshot, error) {
+	name := prefix + createdAt.UTC().Format(timeFormat) + extension
+
+	in, err := os.Open(source)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+
+	out, err := os.CreateTemp(s.dir, ".snapshot-*.gz")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+
+	compressor := gzip.NewWriter(out)
+	compressor.Name = strings.TrimSuffix(name, ".gz")
+	compressor.ModTime = createdAt
+	if _, err := io.Copy(compressor, in); err != nil {
+		return nil, err
+	}
+	if err := compressor.Close(); err != nil {
+		return nil, err
+	}
+	if err := out.Sync(); err != nil {
+		return nil, err
+	}
+	if err := out.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(out.Name(), filepath.Join(s.dir, name)); err != nil {
+		return nil, err
+	}
+
+	return s.stat(name)
+}
+
+// List returns the snapshots, the newest first.
+func (s *Store) List() ([]Snapshot, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var snapshots []Snapshot
+	for _, entry := range entries {
+		if _, ok := parseName(entry.Name()); !ok || entry.IsDir() {
+			continue
+		}
+		snapshot, err := s.stat(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, *snapshot)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].CreatedAt.After(snapshots[j].CreatedAt)
+	})
+	return snapshots, nil
+}
+
+// Extract decompresses the snapshot to target, which must not exist.
+func (s *Store) Extract(name string, target string) error {
+	if _, ok := parseName(name); !ok {
+		return ErrNotFound
+	}
+
+	in, err := os.Open(filepath.Join(s.dir, name))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ErrNotFound
+		}
+		return err
+	}
+	defer in.Close()
+
+	decompressor, err := gzip.NewReader(in)
+	if err != nil {
+		return err
+	}
+	defer decompressor.Close()
+
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, decompressor); err != nil {
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+func (s *Store) Delete(name string) error {
+	if _, ok := parseName(name); !ok {
+		return ErrNotFound
+	}
+	return os.Remove(filepath.Join(s.dir, name))
+}
+
+func (s *Store) stat(name string) (*Snapshot, error) {
+	createdAt, _ := parseName(name)
+	info, err := os.Stat(filepath.Join(s.dir, name))
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{Name: name, Size: info.Size(), CreatedAt: createdAt}, nil
+}
+
+func parseName(name string) (time.Time, bool) {
+	if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, extension) {
+		return time.Time{}, false
+	}
+	createdAt, err := time.Parse(timeFormat, strings.TrimSuffix(strings.TrimPrefix(name, prefix), extension))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return createdAt, true
+}
+
+// IntegrityCheck opens the database at path read-only and runs the integrity
+// check of SQLite on it.
+func IntegrityCheck(path string) error {
+	db, err := gorm.Open(sqlite.Open("file:"+path+"?mode=ro"), &gorm.Config{
+		Logger: logger.Discard,
+	})
+	if err != nil {
+		return err
+	}
+	connection, err := db.DB()
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+
+	var results []string
+	if err := db.Raw("PRAGMA integrity_check").Scan(&results).Error; err != nil {
+		return err
+	}
+	if len(results) != 1 || results[0] != "ok" {
+		return fmt.Errorf("integrity check failed: %s", strings.Join(results, "; "))
+	}
+	return nil
+}
+
+// CheckNotInUse fails with ErrInUse when another connection has the database
+// at path open in WAL mode, or holds a lock on it in the other journal modes.
+// Connections in those modes hold no lock while idle and go unnoticed.
+//
+// The database is left in rollback journal mode, which checkpoints the WAL
+// into the database file.
+func CheckNotInUse(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	db, err := gorm.Open(sqlite.Open("file:"+path+"?_busy_timeout=0"), &gorm.Config{
+		Logger: logger.Discard,
+	})
+	if err != nil {
+		return err
+	}
+	connection, err := db.DB()
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+	connection.SetMaxOpenConns(1)
+
+	// Leaving WAL mode requires the only connection to the database, and an
+	// exclusive transaction requires that no other holds a lock.
+	var journalMode string
+	if err := db.Raw("PRAGMA journal_mode = DELETE").Row().Scan(&journalMode); err != nil {
+		return inUse(err)
+	}
+	if err := db.Exec("BEGIN EXCLUSIVE").Error; err != nil {
+		return inUse(err)
+	}
+	return db.Exec("ROLLBACK").Error
+}
+
+func inUse(err error) error {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && (sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked) {
+		return ErrInUse
+	}
+	return err
+}
@@ -4,44 +4,227 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mnaufalhilmym/bookshelf/internal/backup"
 	"github.com/mnaufalhilmym/bookshelf/internal/delivery/http/handler"
 	"github.com/mnaufalhilmym/bookshelf/internal/delivery/http/middleware"
 	"github.com/mnaufalhilmym/bookshelf/internal/delivery/http/route"
+	"github.com/mnaufalhilmym/bookshelf/internal/migration"
 	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/bookshelf/internal/storage"
 	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
 	"gorm.io/gorm"
 )
 
-func Bootstrap(
-	router *gin.Engine,
+// App holds the usecases of bookshelf, which the HTTP server and the command
+// line share.
+type App struct {
+	jwtKey                 string
+	requireIfMatch         bool
+	cacheControl           string
+	cacheControlRoutes     map[string]string
+	migrationUsecase       *usecase.MigrationUsecase
+	userUsecase            *usecase.UserUsecase
+	authorUsecase          *usecase.AuthorUsecase
+	bookUsecase            *usecase.BookUsecase
+	reviewUsecase          *usecase.ReviewUsecase
+	readingProgressUsecase *usecase.ReadingProgressUsecase
+	readingGoalUsecase     *usecase.ReadingGoalUsecase
+	challengeUsecase       *usecase.ChallengeUsecase
+	annotationUsecase      *usecase.AnnotationUsecase
+	importUsecase          *usecase.ImportUsecase
+	exportUsecase          *usecase.ExportUsecase
+	shelfUsecase           *usecase.ShelfUsecase
+	bookFileUsecase        *usecase.BookFileUsecase
+	coverUsecase           *usecase.CoverUsecase
+	apiKeyUsecase          *usecase.APIKeyUsecase
+	opdsUsecase            *usecase.OPDSUsecase
+	oaiUsecase             *usecase.OAIUsecase
+	importJobUsecase       *usecase.ImportJobUsecase
+	backupUsecase          *usecase.BackupUsecase
+	trashUsecase           *usecase.TrashUsecase
+}
+
+func NewApp(
 	db *gorm.DB,
+	fileStorage storage.Storage,
 	jwtKey string,
 	jwtExpiration time.Duration,
-) {
+	oaiRepositoryName string,
+	oaiAdminEmail string,
+	oaiNamespace string,
+	databasePath string,
+	backupPath string,
+	backupKeep int,
+	backupMaxAge time.Duration,
+	trashRetention time.Duration,
+	requireIfMatch bool,
+	cacheControl string,
+	cacheControlRoutes map[string]string,
+) *App {
 	// Repository
-	userRepository := repository.NewUserRepository(db)
-	authorRepository := repository.NewAuthorRepository(db)
-	bookRepository := repository.NewBookRepository(db)
+	userRepository := repository.NewUserRepository()
+	authorRepository := repository.NewAuthorRepository()
+	bookRepository := repository.NewBookRepository()
+	reviewRepository := repository.NewReviewRepository()
+	readingProgressRepository := repository.NewReadingProgressRepository()
+	readingGoalRepository := repository.NewReadingGoalRepository()
+	challengeRepository := repository.NewChallengeRepository()
+	challengeParticipantRepository := repository.NewChallengeParticipantRepository()
+	annotationRepository := repository.NewAnnotationRepository()
+	shelfEntryRepository := repository.NewShelfEntryRepository()
+	importJobRepository := repository.NewImportJobRepository()
+	bookFileRepository := repository.NewBookFileRepository()
+	apiKeyRepository := repository.NewAPIKeyRepository()
+	schemaMigrationRepository := repository.NewSchemaMigrationRepository()
+	backupRepository := repository.NewBackupRepository()
+	trashRepository := repository.NewTrashRepository()
+	revisionRepository := repository.NewRevisionRepository()
 
 	// Usecase
+	migrationUsecase := usecase.NewMigrationUsecase(db, schemaMigrationRepository, migration.All())
 	userUsecase := usecase.NewUserUsecase(db, userRepository, jwtKey, jwtExpiration)
-	authorUsecase := usecase.NewAuthorUsecase(db, authorRepository)
-	bookUsecase := usecase.NewBookUsecase(db, bookRepository, authorRepository)
+	authorUsecase := usecase.NewAuthorUsecase(db, authorRepository, bookRepository, revisionRepository)
+	bookUsecase := usecase.NewBookUsecase(db, bookRepository, authorRepository, revisionRepository)
+	reviewUsecase := usecase.NewReviewUsecase(db, reviewRepository, bookRepository)
+	readingProgressUsecase := usecase.NewReadingProgressUsecase(db, readingProgressRepository, bookRepository)
+	readingGoalUsecase := usecase.NewReadingGoalUsecase(db, readingGoalRepository, readingProgressRepository)
+	challengeUsecase := usecase.NewChallengeUsecase(
+		db,
+		challengeRepository,
+		challengeParticipantRepository,
+		readingProgressRepository,
+	)
+	annotationUsecase := usecase.NewAnnotationUsecase(db, annotationRepository, bookRepository)
+	importUsecase := usecase.NewImportUsecase(db, authorRepository, bookRepository, annotationRepository)
+	exportUsecase := usecase.NewExportUsecase(db, bookRepository)
+	shelfUsecase := usecase.NewShelfUsecase(db, shelfEntryRepository)
+	bookFileUsecase := usecase.NewBookFileUsecase(
+		db,
+		fileStorage,
+		bookFileRepository,
+		bookRepository,
+		authorRepository,
+	)
+	coverUsecase := usecase.NewCoverUsecase(db, fileStorage, bookRepository)
+	apiKeyUsecase := usecase.NewAPIKeyUsecase(db, apiKeyRepository)
+	opdsUsecase := usecase.NewOPDSUsecase(db, bookRepository, authorRepository, bookFileRepository)
+	oaiUsecase := usecase.NewOAIUsecase(
+		db,
+		bookRepository,
+		authorRepository,
+		oaiRepositoryName,
+		oaiAdminEmail,
+		oaiNamespace,
+	)
+	importJobUsecase := usecase.NewImportJobUsecase(
+		db,
+		importJobRepository,
+		authorRepository,
+		bookRepository,
+		reviewRepository,
+		readingProgressRepository,
+		shelfEntryRepository,
+	)
+	backupUsecase := usecase.NewBackupUsecase(
+		db,
+		backupRepository,
+		backup.NewStore(backupPath),
+		databasePath,
+		backupKeep,
+		backupMaxAge,
+	)
+	trashUsecase := usecase.NewTrashUsecase(
+		db,
+		fileStorage,
+		trashRepository,
+		userRepository,
+		authorRepository,
+		bookRepository,
+		bookFileRepository,
+		trashRetention,
+	)
+
+	return &App{
+		jwtKey:                 jwtKey,
+		requireIfMatch:         requireIfMatch,
+		cacheControl:           cacheControl,
+		cacheControlRoutes:     cacheControlRoutes,
+		migrationUsecase:       migrationUsecase,
+		userUsecase:            userUsecase,
+		authorUsecase:          authorUsecase,
+		bookUsecase:            bookUsecase,
+		reviewUsecase:          reviewUsecase,
+		readingProgressUsecase: readingProgressUsecase,
+		readingGoalUsecase:     readingGoalUsecase,
+		challengeUsecase:       challengeUsecase,
+		annotationUsecase:      annotationUsecase,
+		importUsecase:          importUsecase,
+		exportUsecase:          exportUsecase,
+		shelfUsecase:           shelfUsecase,
+		bookFileUsecase:        bookFileUsecase,
+		coverUsecase:           coverUsecase,
+		apiKeyUsecase:          apiKeyUsecase,
+		opdsUsecase:            opdsUsecase,
+		oaiUsecase:             oaiUsecase,
+		importJobUsecase:       importJobUsecase,
+		backupUsecase:          backupUsecase,
+		trashUsecase:           trashUsecase,
+	}
+}
 
+func Bootstrap(router *gin.Engine, app *App) {
 	// Handler
-	userHandler := handler.NewUserHandler(userUsecase)
-	authorHandler := handler.NewAuthorHandler(authorUsecase)
-	bookHandler := handler.NewBookHandler(bookUsecase)
+	userHandler := handler.NewUserHandler(app.userUsecase)
+	authorHandler := handler.NewAuthorHandler(app.authorUsecase)
+	bookHandler := handler.NewBookHandler(app.bookUsecase)
+	reviewHandler := handler.NewReviewHandler(app.reviewUsecase)
+	readingProgressHandler := handler.NewReadingProgressHandler(app.readingProgressUsecase)
+	readingGoalHandler := handler.NewReadingGoalHandler(app.readingGoalUsecase)
+	challengeHandler := handler.NewChallengeHandler(app.challengeUsecase)
+	annotationHandler := handler.NewAnnotationHandler(app.annotationUsecase)
+	importHandler := handler.NewImportHandler(app.importUsecase)
+	exportHandler := handler.NewExportHandler(app.exportUsecase)
+	shelfHandler := handler.NewShelfHandler(app.shelfUsecase)
+	importJobHandler := handler.NewImportJobHandler(app.importJobUsecase)
+	bookFileHandler := handler.NewBookFileHandler(app.bookFileUsecase)
+	coverHandler := handler.NewCoverHandler(app.coverUsecase)
+	apiKeyHandler := handler.NewAPIKeyHandler(app.apiKeyUsecase)
+	opdsHandler := handler.NewOPDSHandler(app.opdsUsecase)
+	oaiHandler := handler.NewOAIHandler(app.oaiUsecase)
+	backupHandler := handler.NewBackupHandler(app.backupUsecase)
+	trashHandler := handler.NewTrashHandler(app.trashUsecase)
 
 	// Middleware
-	validateTokenMiddleware := middleware.NewValidateTokenMiddleware(jwtKey, userUsecase)
+	validateTokenMiddleware := middleware.NewValidateTokenMiddleware(app.jwtKey, app.userUsecase)
+	basicAuthMiddleware := middleware.NewBasicAuthMiddleware(app.userUsecase, app.apiKeyUsecase)
+	preconditionMiddleware := middleware.NewPreconditionMiddleware(app.requireIfMatch)
+	cacheControlMiddleware := middleware.NewCacheControlMiddleware(app.cacheControl, app.cacheControlRoutes)
 
 	routeConfig := route.New(
 		router,
 		userHandler,
 		authorHandler,
 		bookHandler,
+		reviewHandler,
+		readingProgressHandler,
+		readingGoalHandler,
+		challengeHandler,
+		annotationHandler,
+		importHandler,
+		exportHandler,
+		shelfHandler,
+		importJobHandler,
+		bookFileHandler,
+		coverHandler,
+		apiKeyHandler,
+		opdsHandler,
+		oaiHandler,
+		backupHandler,
+		trashHandler,
 		validateTokenMiddleware,
+		basicAuthMiddleware,
+		preconditionMiddleware,
+		cacheControlMiddleware,
 	)
 
 	routeConfig.ConfigureRoutes()
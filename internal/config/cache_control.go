@@ -0,0 +1,41 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// NewCacheControlRoutes reads the Cache-Control header of each route in
+// web.cache_control.routes. They are a list rather than a map, since viper
+// splits keys at dots and lowercases them.
+func NewCacheControlRoutes(conf *viper.Viper) map[string]string {
+	routes, err := cacheControlRoutes(conf)
+	if err != nil {
+		panic(err)
+	}
+	return routes
+}
+
+func cacheControlRoutes(conf *viper.Viper) (map[string]string, error) {
+	var entries []struct {
+		Path  string `mapstructure:"path"`
+		Value string `mapstructure:"value"`
+	}
+	if err := conf.UnmarshalKey("web.cache_control.routes", &entries); err != nil {
+		return nil, fmt.Errorf("web.cache_control.routes is not a list of paths and values: %w", err)
+	}
+
+	routes := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Path, "/") {
+			return nil, fmt.Errorf("web.cache_control.routes path %q does not start with /", entry.Path)
+		}
+		if _, ok := routes[entry.Path]; ok {
+			return nil, fmt.Errorf("web.cache_control.routes has path %q more than once", entry.Path)
+		}
+		routes[entry.Path] = entry.Value
+	}
+	return routes, nil
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"os"
+	"strings"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/delivery/cli"
+	"github.com/mnaufalhilmym/bookshelf/internal/migration"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+	"github.com/spf13/viper"
+)
+
+// minJWTKeyLength is the length of a key for HS256 that is as long as the
+// hash it signs with.
+const minJWTKeyLength = 32
+
+func configChecks(conf *viper.Viper) []cli.ConfigCheck {
+	return []cli.ConfigCheck{
+		{Name: "app", Check: func(context.Context) error {
+			switch mode := conf.GetString("app.mode"); mode {
+			case "debug", "release", "test":
+				return nil
+			default:
+				return fmt.Errorf("app.mode must be debug, release or test, not %q", mode)
+			}
+		}},
+		{Name: "web", Check: func(context.Context) error {
+			if _, _, err := net.SplitHostPort(conf.GetString("web.address")); err != nil {
+				return fmt.Errorf("web.address is not a host and port: %w", err)
+			}
+			_, err := cacheControlRoutes(conf)
+			return err
+		}},
+		{Name: "db", Check: func(ctx context.Context) error {
+			if conf.GetInt("db.pool.idle") < 0 || conf.GetInt("db.pool.max") < 1 || conf.GetInt("db.pool.lifetime") < 0 {
+				return errors.New("db.pool.max must be positive, db.pool.idle and db.pool.lifetime must not be negative")
+			}
+			db, err := openDatabase(
+				conf.GetString("db.driver"),
+				conf.GetString("db.dsn"),
+				conf.GetInt("db.pool.idle"),
+				conf.GetInt("db.pool.max"),
+				conf.GetInt("db.pool.lifetime"),
+				NewSQLiteOptions(conf),
+			)
+			if err != nil {
+				return err
+			}
+			if connection, err := db.DB(); err == nil {
+				defer connection.Close()
+			}
+			return usecase.NewMigrationUsecase(db, repository.NewSchemaMigrationRepository(), migration.All()).Check(ctx)
+		}},
+		{Name: "backup", Check: func(context.Context) error {
+			if conf.GetString("backup.path") == "" {
+				return errors.New("backup.path must not be empty")
+			}
+			if conf.GetDuration("backup.interval") < 0 || conf.GetInt("backup.keep") < 0 || conf.GetDuration("backup.max_age") < 0 {
+				return errors.New("backup.interval, backup.keep and backup.max_age must not be negative")
+			}
+			if err := os.MkdirAll(conf.GetString("backup.path"), 0o755); err != nil {
+				return fmt.Errorf("failed to create backup directory: %w", err)
+			}
+			return nil
+		}},
+		{Name: "trash", Check: func(context.Context) error {
+			if conf.GetDuration("trash.retention") < 0 || conf.GetDuration("trash.purge_interval") < 0 {
+				return errors.New("trash.retention and trash.purge_interval must not be negative")
+			}
+			return nil
+		}},
+		{Name: "storage", Check: func(ctx context.Context) error {
+			fileStorage, err := openStorage(conf)
+			if err != nil {
+				return err
+			}
+			// Writing and deleting an object proves that the storage is
+			// reachable and writable with the credentials.
+			key := ".config-check"
+			if err := fileStorage.Put(ctx, key, strings.NewReader("ok")); err != nil {
+				return fmt.Errorf("failed to write to storage: %w", err)
+			}
+			if err := fileStorage.Delete(ctx, key); err != nil {
+				return fmt.Errorf("failed to delete from storage: %w", err)
+			}
+			return nil
+		}},
+		{Name: "jwt", Check: func(context.Context) error {
+			if len(conf.GetString("jwt.key")) < minJWTKeyLength {
+				return fmt.Errorf("jwt.key must be at least %d characters long", minJWTKeyLength)
+			}
+			if conf.GetDuration("jwt.duration") <= 0 {
+				return errors.New("jwt.duration must be positive")
+			}
+			return nil
+		}},
+		{Name: "oai", Check: func(context.Context) error {
+			if email := conf.GetString("oai.admin_email"); email != "" {
+				if _, err := mail.ParseAddress(email); err != nil {
+					return fmt.Errorf("oai.admin_email is not an email address: %w", err)
+				}
+			}
+			return nil
+		}},
+	}
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/backup"
+	"github.com/mnaufalhilmym/bookshelf/internal/delivery/cli"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+	"github.com/spf13/viper"
+)
+
+// RunCommand runs the command named by args, and serves the HTTP API when
+// there are no args.
+func RunCommand(
+	ctx context.Context,
+	conf *viper.Viper,
+	args []string,
+	out io.Writer,
+) error {
+	if len(args) == 0 {
+		args = []string{"serve"}
+	}
+
+	configCommand := cli.NewConfigCommand(configChecks(conf))
+	// Setting up the application panics on the first problem with the
+	// configuration, so the configuration is checked before.
+	if len(args) >= 2 && args[0] == "config" && args[1] == "check" {
+		return configCommand.Check(ctx, args[2:], out)
+	}
+	// Restoring replaces the database file, so the database is not opened.
+	if args[0] == "restore" {
+		backupCommand := cli.NewBackupCommand(usecase.NewBackupUsecase(
+			nil,
+			repository.NewBackupRepository(),
+			backup.NewStore(conf.GetString("backup.path")),
+			databasePath(conf),
+			0,
+			0,
+		))
+		return backupCommand.Restore(ctx, args[1:], out)
+	}
+
+	app := NewApp(
+		NewDatabase(
+			conf.GetString("db.driver"),
+			conf.GetString("db.dsn"),
+			conf.GetInt("db.pool.idle"),
+			conf.GetInt("db.pool.max"),
+			conf.GetInt("db.pool.lifetime"),
+			NewSQLiteOptions(conf),
+		),
+		NewStorage(conf),
+		conf.GetString("jwt.key"),
+		conf.GetDuration("jwt.duration"),
+		conf.GetString("oai.repository_name"),
+		conf.GetString("oai.admin_email"),
+		conf.GetString("oai.namespace"),
+		databasePath(conf),
+		conf.GetString("backup.path"),
+		conf.GetInt("backup.keep"),
+		conf.GetDuration("backup.max_age"),
+		conf.GetDuration("trash.retention"),
+		conf.GetBool("web.require_if_match"),
+		conf.GetString("web.cache_control.default"),
+		NewCacheControlRoutes(conf),
+	)
+
+	// Command
+	serveCommand := cli.NewServeCommand(func() error {
+		if interval := conf.GetDuration("backup.interval"); interval > 0 && conf.GetString("db.driver") == "sqlite" {
+			go app.backupUsecase.Schedule(ctx, interval)
+		}
+		if interval := conf.GetDuration("trash.purge_interval"); interval > 0 {
+			go app.trashUsecase.Schedule(ctx, interval)
+		}
+		router := NewGin(conf.GetString("app.mode"))
+		Bootstrap(router, app)
+		if err := router.Run(conf.GetString("web.address")); err != nil {
+			return fmt.Errorf("failed to start server: %w", err)
+		}
+		return nil
+	})
+	migrateCommand := cli.NewMigrateCommand(app.migrationUsecase)
+	userCommand := cli.NewUserCommand(app.userUsecase)
+	importCommand := cli.NewImportCommand(app.importUsecase)
+	exportCommand := cli.NewExportCommand(app.exportUsecase)
+	backupCommand := cli.NewBackupCommand(app.backupUsecase)
+	reindexCommand := cli.NewReindexCommand(app.reviewUsecase)
+
+	return cli.New(
+		serveCommand,
+		migrateCommand,
+		userCommand,
+		importCommand,
+		exportCommand,
+		backupCommand,
+		reindexCommand,
+		configCommand,
+	).Run(ctx, args, out)
+}
+
+// databasePath returns the file of the database to back up, which only
+// SQLite databases have.
+func databasePath(conf *viper.Viper) string {
+	if conf.GetString("db.driver") != "sqlite" {
+		return ""
+	}
+	return sqlitePath(conf.GetString("db.dsn"))
+}
@@ -2,22 +2,49 @@ package config
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
+	mysqldriver "github.com/go-sql-driver/mysql"
 	"github.com/mnaufalhilmym/gotracing"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
 )
 
 func NewDatabase(
-	database string,
+	driver string,
+	dsn string,
 	poolIdle int,
 	poolMax int,
 	poolLifetime int,
+	sqliteOptions SQLiteOptions,
 ) *gorm.DB {
+	db, err := openDatabase(driver, dsn, poolIdle, poolMax, poolLifetime, sqliteOptions)
+	if err != nil {
+		panic(err)
+	}
+	return db
+}
+
+func openDatabase(
+	driver string,
+	dsn string,
+	poolIdle int,
+	poolMax int,
+	poolLifetime int,
+	sqliteOptions SQLiteOptions,
+) (*gorm.DB, error) {
+	dialector, err := newDialector(driver, dsn, sqliteOptions)
+	if err != nil {
+		return nil, err
+	}
+
 	db, err := gorm.Open(
-		sqlite.Open(database),
+		dialector,
 		&gorm.Config{
 			TranslateError: true,
 			Logger: logger.New(&gormTracingWriter{}, logger.Config{
@@ -28,23 +55,79 @@ func NewDatabase(
 		},
 	)
 	if err != nil {
-		panic(fmt.Errorf("failed to connect to database: %w", err))
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
 	connection, err := db.DB()
 	if err != nil {
-		panic(fmt.Errorf("failed to get *sql.DB: %w", err))
+		return nil, fmt.Errorf("failed to get *sql.DB: %w", err)
 	}
 
-	connection.SetMaxIdleConns(poolIdle)
-	connection.SetMaxOpenConns(poolMax)
-	connection.SetConnMaxLifetime(time.Duration(poolLifetime * int(time.Second)))
+	if pool, ok := db.ConnPool.(*sqlitePool); ok {
+		pool.setPool(poolIdle, poolMax, time.Duration(poolLifetime*int(time.Second)))
+	} else {
+		connection.SetMaxIdleConns(poolIdle)
+		connection.SetMaxOpenConns(poolMax)
+		connection.SetConnMaxLifetime(time.Duration(poolLifetime * int(time.Second)))
+	}
 
 	if err := connection.Ping(); err != nil {
-		panic(fmt.Errorf("failed to ping the database: %w", err))
+		return nil, fmt.Errorf("failed to ping the database: %w", err)
 	}
 
-	return db
+	return db, nil
+}
+
+func newDialector(driver string, dsn string, sqliteOptions SQLiteOptions) (gorm.Dialector, error) {
+	switch driver {
+	case "sqlite":
+		pool, err := newSQLitePool(dsn, sqliteOptions)
+		if err != nil {
+			return nil, err
+		}
+		return sqlite.New(sqlite.Config{DSN: dsn, Conn: pool}), nil
+	case "postgres":
+		return postgres.Open(dsn), nil
+	case "mysql":
+		config, err := mysqldriver.ParseDSN(dsn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse mysql dsn: %w", err)
+		}
+		// Timestamps are scanned into time.Time and compared in local time,
+		// as they are with the other drivers.
+		config.ParseTime = true
+		config.Loc = time.Local
+		return &mysqlDialector{mysql.New(mysql.Config{DSN: config.FormatDSN()}).(*mysql.Dialector)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q, use sqlite, postgres or mysql", driver)
+	}
+}
+
+// mysqlDialector gives strings in unique indexes a size. MySQL cannot index
+// the TEXT type that strings without a size default to, and gorm only sizes
+// strings tagged unique or index.
+type mysqlDialector struct {
+	*mysql.Dialector
+}
+
+func (d *mysqlDialector) Migrator(db *gorm.DB) gorm.Migrator {
+	m := d.Dialector.Migrator(db).(mysql.Migrator)
+	m.Migrator.Dialector = d
+	return m
+}
+
+func (d *mysqlDialector) DataTypeOf(field *schema.Field) string {
+	if field.DataType == schema.String && field.Size == 0 && field.TagSettings["UNIQUEINDEX"] != "" {
+		return "varchar(191)"
+	}
+	return d.Dialector.DataTypeOf(field)
+}
+
+// sqlitePath returns the file of a SQLite DSN, which may be a URI with
+// parameters.
+func sqlitePath(dsn string) string {
+	path, _, _ := strings.Cut(strings.TrimPrefix(dsn, "file:"), "?")
+	return path
 }
 
 type gormTracingWriter struct{}
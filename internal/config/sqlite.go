@@ -0,0 +1,168 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+// SQLiteOptions are the PRAGMAs set on every connection to a SQLite database.
+// Empty and zero values keep the defaults of SQLite.
+type SQLiteOptions struct {
+	JournalMode string
+	Synchronous string
+	BusyTimeout time.Duration
+	ForeignKeys bool
+	// CacheSize is in pages when positive, and in KiB when negative.
+	CacheSize int
+}
+
+func NewSQLiteOptions(conf *viper.Viper) SQLiteOptions {
+	return SQLiteOptions{
+		JournalMode: conf.GetString("db.sqlite.journal_mode"),
+		Synchronous: conf.GetString("db.sqlite.synchronous"),
+		BusyTimeout: conf.GetDuration("db.sqlite.busy_timeout"),
+		ForeignKeys: conf.GetBool("db.sqlite.foreign_keys"),
+		CacheSize:   conf.GetInt("db.sqlite.cache_size"),
+	}
+}
+
+func (o SQLiteOptions) pragmas() ([]string, error) {
+	var pragmas []string
+	if o.JournalMode != "" {
+		switch mode := strings.ToUpper(o.JournalMode); mode {
+		case "DELETE", "TRUNCATE", "PERSIST", "MEMORY", "WAL", "OFF":
+			pragmas = append(pragmas, "PRAGMA journal_mode = "+mode)
+		default:
+			return nil, fmt.Errorf("unsupported db.sqlite.journal_mode %q", o.JournalMode)
+		}
+	}
+	if o.Synchronous != "" {
+		switch synchronous := strings.ToUpper(o.Synchronous); synchronous {
+		case "OFF", "NORMAL", "FULL", "EXTRA":
+			pragmas = append(pragmas, "PRAGMA synchronous = "+synchronous)
+		default:
+			return nil, fmt.Errorf("unsupported db.sqlite.synchronous %q", o.Synchronous)
+		}
+	}
+	if o.BusyTimeout > 0 {
+		pragmas = append(pragmas, fmt.Sprintf("PRAGMA busy_timeout = %d", o.BusyTimeout.Milliseconds()))
+	}
+	if o.ForeignKeys {
+		pragmas = append(pragmas, "PRAGMA foreign_keys = ON")
+	}
+	if o.CacheSize != 0 {
+		pragmas = append(pragmas, fmt.Sprintf("PRAGMA cache_size = %d", o.CacheSize))
+	}
+	return pragmas, nil
+}
+
+// sqlitePool sends writes and transactions to a single connection, SQLite
+// allows one writer at a time anyway, and sends reads and read-only
+// transactions to a pool of connections which cannot write. Writing
+// transactions begin immediately, taking the write lock up front rather than
+// failing when a read turns into a write while another process writes.
+type sqlitePool struct {
+	writer *sql.DB
+	reader *sql.DB
+}
+
+func newSQLitePool(dsn string, options SQLiteOptions) (*sqlitePool, error) {
+	pragmas, err := options.pragmas()
+	if err != nil {
+		return nil, err
+	}
+
+	writer := sql.OpenDB(&sqliteConnector{withDSNParam(dsn, "_txlock=immediate"), pragmas})
+	writer.SetMaxOpenConns(1)
+	writer.SetMaxIdleConns(1)
+
+	// Every connection to a database in memory has a database of its own.
+	if dsn == ":memory:" || strings.Contains(dsn, "mode=memory") {
+		return &sqlitePool{writer, writer}, nil
+	}
+
+	// SQLite creates the database file but not its directory.
+	if err := os.MkdirAll(filepath.Dir(sqlitePath(dsn)), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create database directory: %w", err)
+	}
+
+	reader := sql.OpenDB(&sqliteConnector{dsn, append(pragmas, "PRAGMA query_only = ON")})
+	return &sqlitePool{writer, reader}, nil
+}
+
+func (p *sqlitePool) setPool(poolIdle int, poolMax int, poolLifetime time.Duration) {
+	if p.reader == p.writer {
+		return
+	}
+	p.reader.SetMaxIdleConns(poolIdle)
+	p.reader.SetMaxOpenConns(poolMax)
+	p.reader.SetConnMaxLifetime(poolLifetime)
+}
+
+func (p *sqlitePool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return p.writer.PrepareContext(ctx, query)
+}
+
+func (p *sqlitePool) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return p.writer.ExecContext(ctx, query, args...)
+}
+
+func (p *sqlitePool) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return p.reader.QueryContext(ctx, query, args...)
+}
+
+func (p *sqlitePool) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return p.reader.QueryRowContext(ctx, query, args...)
+}
+
+func (p *sqlitePool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	if opts != nil && opts.ReadOnly {
+		return p.reader.BeginTx(ctx, opts)
+	}
+	return p.writer.BeginTx(ctx, opts)
+}
+
+// GetDBConn returns the writer, which is the connection to ping and close.
+func (p *sqlitePool) GetDBConn() (*sql.DB, error) {
+	return p.writer, nil
+}
+
+type sqliteConnector struct {
+	dsn     string
+	pragmas []string
+}
+
+func (c *sqliteConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Driver().Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	for _, pragma := range c.pragmas {
+		if _, err := conn.(driver.ExecerContext).ExecContext(ctx, pragma, nil); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to set %s: %w", pragma, err)
+		}
+	}
+	return conn, nil
+}
+
+func (*sqliteConnector) Driver() driver.Driver {
+	return &sqlite3.SQLiteDriver{}
+}
+
+func withDSNParam(dsn string, param string) string {
+	if strings.Contains(dsn, "?") {
+		return dsn + "&" + param
+	}
+	return dsn + "?" + param
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/storage"
+	"github.com/spf13/viper"
+)
+
+func NewStorage(conf *viper.Viper) storage.Storage {
+	fileStorage, err := openStorage(conf)
+	if err != nil {
+		panic(err)
+	}
+	return fileStorage
+}
+
+func openStorage(conf *viper.Viper) (storage.Storage, error) {
+	switch driver := conf.GetString("storage.driver"); driver {
+	case "", "local":
+		return storage.NewLocal(conf.GetString("storage.path")), nil
+	case "s3":
+		s3, err := storage.NewS3(
+			conf.GetString("storage.s3.endpoint"),
+			conf.GetString("storage.s3.bucket"),
+			conf.GetString("storage.s3.region"),
+			conf.GetString("storage.s3.access_key"),
+			conf.GetString("storage.s3.secret_key"),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure storage: %w", err)
+		}
+		return s3, nil
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", driver)
+	}
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -17,5 +18,21 @@ func NewViper() *viper.Viper {
 		panic(fmt.Errorf("error reading config file: %w", err))
 	}
 
+	// db.name is the SQLite database of configurations written before
+	// db.driver and db.dsn.
+	v.SetDefault("db.driver", "sqlite")
+	v.SetDefault("db.dsn", v.GetString("db.name"))
+
+	v.SetDefault("db.sqlite.journal_mode", "WAL")
+	v.SetDefault("db.sqlite.synchronous", "NORMAL")
+	v.SetDefault("db.sqlite.busy_timeout", 5*time.Second)
+	v.SetDefault("db.sqlite.foreign_keys", true)
+	v.SetDefault("db.sqlite.cache_size", -20000)
+
+	v.SetDefault("web.cache_control.default", "private, no-cache")
+
+	v.SetDefault("trash.retention", 30*24*time.Hour)
+	v.SetDefault("trash.purge_interval", 24*time.Hour)
+
 	return v
 }
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+)
+
+type BackupCommand struct {
+	usecase *usecase.BackupUsecase
+}
+
+func NewBackupCommand(uc *usecase.BackupUsecase) *BackupCommand {
+	return &BackupCommand{uc}
+}
+
+func (c *BackupCommand) Create(ctx context.Context, args []string, out io.Writer) error {
+	if len(args) != 0 {
+		return fmt.Errorf("%w: backup", errUsage)
+	}
+
+	response, err := c.usecase.Create(ctx)
+	if err != nil {
+		return unwrapModelError(err)
+	}
+
+	return writeJSON(out, response)
+}
+
+func (c *BackupCommand) List(ctx context.Context, args []string, out io.Writer) error {
+	if len(args) != 0 {
+		return fmt.Errorf("%w: backup list", errUsage)
+	}
+
+	response, err := c.usecase.GetMany(ctx)
+	if err != nil {
+		return unwrapModelError(err)
+	}
+
+	return writeJSON(out, response)
+}
+
+func (c *BackupCommand) Restore(ctx context.Context, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("restore", flag.ContinueOnError)
+	flags.SetOutput(out)
+	at := flags.String("at", "", "restore the latest backup taken at or before this RFC 3339 time")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 1 || (flags.NArg() == 1 && *at != "") {
+		return fmt.Errorf("%w: restore [-at time | name]", errUsage)
+	}
+
+	request := &model.RestoreBackupRequest{Name: flags.Arg(0)}
+	if *at != "" {
+		t, err := time.Parse(time.RFC3339, *at)
+		if err != nil {
+			return fmt.Errorf("%w: -at must be an RFC 3339 time", errUsage)
+		}
+		request.At = &t
+	}
+
+	response, err := c.usecase.Restore(ctx, request)
+	if err != nil {
+		return unwrapModelError(err)
+	}
+
+	return writeJSON(out, response)
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+)
+
+var errUsage = errors.New("invalid usage")
+
+type command func(ctx context.Context, args []string, out io.Writer) error
+
+type CommandConfig struct {
+	commands map[string]command
+}
+
+func New(
+	serveCommand *ServeCommand,
+	migrateCommand *MigrateCommand,
+	userCommand *UserCommand,
+	importCommand *ImportCommand,
+	exportCommand *ExportCommand,
+	backupCommand *BackupCommand,
+	reindexCommand *ReindexCommand,
+	configCommand *ConfigCommand,
+) *CommandConfig {
+	return &CommandConfig{
+		commands: map[string]command{
+			"serve":               migrateCommand.Require(serveCommand.Serve),
+			"migrate up":          migrateCommand.Up,
+			"migrate down":        migrateCommand.Down,
+			"migrate status":      migrateCommand.Status,
+			"user create":         migrateCommand.Require(userCommand.Create),
+			"user list":           migrateCommand.Require(userCommand.List),
+			"user set-role":       migrateCommand.Require(userCommand.SetRole),
+			"user reset-password": migrateCommand.Require(userCommand.ResetPassword),
+			"user delete":         migrateCommand.Require(userCommand.Delete),
+			"import csv":          migrateCommand.Require(importCommand.CSV),
+			"import calibre":      migrateCommand.Require(importCommand.Calibre),
+			"export":              migrateCommand.Require(exportCommand.Books),
+			"backup":              backupCommand.Create,
+			"backup list":         backupCommand.List,
+			"restore":             backupCommand.Restore,
+			"reindex":             migrateCommand.Require(reindexCommand.Reindex),
+			"config check":        configCommand.Check,
+		},
+	}
+}
+
+// Run executes the subcommand named by the leading arguments, e.g.
+// "import csv catalog.csv", and passes the remaining arguments to it.
+func (c *CommandConfig) Run(ctx context.Context, args []string, out io.Writer) error {
+	for i := len(args); i > 0; i-- {
+		if cmd, ok := c.commands[strings.Join(args[:i], " ")]; ok {
+			return cmd(ctx, args[i:], out)
+		}
+	}
+	return fmt.Errorf("unknown command %q, available commands: %s", strings.Join(args, " "), strings.Join(c.names(), ", "))
+}
+
+func (c *CommandConfig) names() []string {
+	names := make([]string, 0, len(c.commands))
+	for name := range c.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func writeJSON(out io.Writer, data any) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
+
+// unwrapModelError drops the HTTP status code of usecase errors, which means
+// nothing on the command line.
+func unwrapModelError(err error) error {
+	var modelErr *model.Error
+	if errors.As(err, &modelErr) {
+		return modelErr.Err
+	}
+	return err
+}
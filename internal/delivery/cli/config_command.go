@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// ConfigCheck validates a part of the configuration, such as a setting or a
+// service that is configured.
+type ConfigCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+type ConfigCommand struct {
+	checks []ConfigCheck
+}
+
+func NewConfigCommand(checks []ConfigCheck) *ConfigCommand {
+	return &ConfigCommand{checks}
+}
+
+type configCheckResult struct {
+	Name  string `json:"name"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// Check runs every check, rather than stopping at the first problem, and
+// fails when any of them does.
+func (c *ConfigCommand) Check(ctx context.Context, args []string, out io.Writer) error {
+	if len(args) != 0 {
+		return fmt.Errorf("%w: config check", errUsage)
+	}
+
+	results := make([]configCheckResult, len(c.checks))
+	failed := 0
+	for i, check := range c.checks {
+		results[i] = configCheckResult{Name: check.Name, OK: true}
+		if err := check.Check(ctx); err != nil {
+			results[i] = configCheckResult{Name: check.Name, Error: unwrapModelError(err).Error()}
+			failed++
+		}
+	}
+
+	if err := writeJSON(out, results); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d configuration checks failed", failed, len(c.checks))
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+)
+
+type ExportCommand struct {
+	usecase *usecase.ExportUsecase
+}
+
+func NewExportCommand(uc *usecase.ExportUsecase) *ExportCommand {
+	return &ExportCommand{uc}
+}
+
+func (c *ExportCommand) Books(ctx context.Context, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	flags.SetOutput(out)
+	format := flags.String("format", model.ExportFormatCSV, "csv, ndjson, bibtex, marc or marcxml")
+	output := flags.String("o", "", "file to write to instead of the standard output")
+	title := flags.String("title", "", "only books whose title contains this")
+	isbn := flags.String("isbn", "", "only books whose isbn contains this")
+	authorID := flags.Int("author-id", 0, "only books by this author")
+	authorName := flags.String("author-name", "", "only books whose author name contains this")
+	sort := flags.String("sort", "", "id, -id, title, -title, rating or -rating")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("%w: export [-format csv|ndjson|bibtex|marc|marcxml] [-o file] [-title text] [-isbn text] [-author-id id] [-author-name text] [-sort order]", errUsage)
+	}
+
+	request := &model.ExportBooksRequest{Format: *format}
+	if *title != "" {
+		request.Title = title
+	}
+	if *isbn != "" {
+		request.ISBN = isbn
+	}
+	if *authorID != 0 {
+		request.AuthorID = authorID
+	}
+	if *authorName != "" {
+		request.AuthorName = authorName
+	}
+	if *sort != "" {
+		request.Sort = sort
+	}
+
+	w := out
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if err := c.usecase.ExportBooks(ctx, request, w); err != nil {
+		return unwrapModelError(err)
+	}
+
+	return nil
+}
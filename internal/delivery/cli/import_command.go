@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+)
+
+type ImportCommand struct {
+	usecase *usecase.ImportUsecase
+}
+
+func NewImportCommand(uc *usecase.ImportUsecase) *ImportCommand {
+	return &ImportCommand{uc}
+}
+
+func (c *ImportCommand) CSV(ctx context.Context, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("import csv", flag.ContinueOnError)
+	flags.SetOutput(out)
+	dryRun := flags.Bool("dry-run", false, "validate and report without saving")
+	mode := flags.String("mode", model.ImportModeTransaction, "transaction or per_row")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("%w: import csv [-dry-run] [-mode transaction|per_row] <file>", errUsage)
+	}
+	if *mode != model.ImportModeTransaction && *mode != model.ImportModePerRow {
+		return fmt.Errorf("%w: mode must be %s or %s", errUsage, model.ImportModeTransaction, model.ImportModePerRow)
+	}
+
+	content, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	response, err := c.usecase.ImportCSV(ctx, &model.ImportBooksRequest{
+		DryRun:  *dryRun,
+		Mode:    *mode,
+		Content: content,
+	})
+	if err != nil {
+		return unwrapModelError(err)
+	}
+
+	return writeJSON(out, response)
+}
+
+func (c *ImportCommand) Calibre(ctx context.Context, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("import calibre", flag.ContinueOnError)
+	flags.SetOutput(out)
+	dryRun := flags.Bool("dry-run", false, "validate and report without saving")
+	mode := flags.String("mode", model.ImportModeTransaction, "transaction or per_row")
+	overwrite := flags.Bool("overwrite", false, "update existing books instead of reporting conflicts")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("%w: import calibre [-dry-run] [-mode transaction|per_row] [-overwrite] <metadata.db>", errUsage)
+	}
+	if *mode != model.ImportModeTransaction && *mode != model.ImportModePerRow {
+		return fmt.Errorf("%w: mode must be %s or %s", errUsage, model.ImportModeTransaction, model.ImportModePerRow)
+	}
+
+	response, err := c.usecase.ImportCalibre(ctx, &model.ImportCalibreRequest{
+		DryRun:    *dryRun,
+		Mode:      *mode,
+		Overwrite: *overwrite,
+		Path:      flags.Arg(0),
+	})
+	if err != nil {
+		return unwrapModelError(err)
+	}
+
+	return writeJSON(out, response)
+}
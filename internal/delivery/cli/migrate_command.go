@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+)
+
+type MigrateCommand struct {
+	usecase *usecase.MigrationUsecase
+}
+
+func NewMigrateCommand(uc *usecase.MigrationUsecase) *MigrateCommand {
+	return &MigrateCommand{uc}
+}
+
+func (c *MigrateCommand) Up(ctx context.Context, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("migrate up", flag.ContinueOnError)
+	flags.SetOutput(out)
+	target := flags.Int("to", 0, "version to migrate to, the latest by default")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("%w: migrate up [-to version]", errUsage)
+	}
+
+	response, err := c.usecase.Up(ctx, &model.MigrateUpRequest{Target: *target})
+	if err != nil {
+		return unwrapModelError(err)
+	}
+
+	return writeJSON(out, response)
+}
+
+func (c *MigrateCommand) Down(ctx context.Context, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("migrate down", flag.ContinueOnError)
+	flags.SetOutput(out)
+	steps := flags.Int("steps", 1, "number of migrations to revert")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("%w: migrate down [-steps n]", errUsage)
+	}
+
+	response, err := c.usecase.Down(ctx, &model.MigrateDownRequest{Steps: *steps})
+	if err != nil {
+		return unwrapModelError(err)
+	}
+
+	return writeJSON(out, response)
+}
+
+func (c *MigrateCommand) Status(ctx context.Context, args []string, out io.Writer) error {
+	if len(args) != 0 {
+		return fmt.Errorf("%w: migrate status", errUsage)
+	}
+
+	response, err := c.usecase.Status(ctx)
+	if err != nil {
+		return unwrapModelError(err)
+	}
+
+	return writeJSON(out, response)
+}
+
+// Require wraps a command that needs every migration applied.
+func (c *MigrateCommand) Require(cmd command) command {
+	return func(ctx context.Context, args []string, out io.Writer) error {
+		if err := c.usecase.Check(ctx); err != nil {
+			return unwrapModelError(err)
+		}
+		return cmd(ctx, args, out)
+	}
+}
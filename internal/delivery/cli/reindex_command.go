@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+)
+
+type ReindexCommand struct {
+	reviewUsecase *usecase.ReviewUsecase
+}
+
+func NewReindexCommand(reviewUsecase *usecase.ReviewUsecase) *ReindexCommand {
+	return &ReindexCommand{reviewUsecase}
+}
+
+// Reindex recalculates the data derived from other records, which are the
+// ratings of books.
+func (c *ReindexCommand) Reindex(ctx context.Context, args []string, out io.Writer) error {
+	if len(args) != 0 {
+		return fmt.Errorf("%w: reindex", errUsage)
+	}
+
+	response, err := c.reviewUsecase.RecalculateRatings(ctx)
+	if err != nil {
+		return unwrapModelError(err)
+	}
+
+	return writeJSON(out, response)
+}
@@ -0,0 +1,24 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+type ServeCommand struct {
+	listen func() error
+}
+
+// NewServeCommand serves the HTTP API with listen, which returns when the
+// server stops.
+func NewServeCommand(listen func() error) *ServeCommand {
+	return &ServeCommand{listen}
+}
+
+func (c *ServeCommand) Serve(_ context.Context, args []string, _ io.Writer) error {
+	if len(args) != 0 {
+		return fmt.Errorf("%w: serve", errUsage)
+	}
+	return c.listen()
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+)
+
+type UserCommand struct {
+	usecase *usecase.UserUsecase
+}
+
+func NewUserCommand(uc *usecase.UserUsecase) *UserCommand {
+	return &UserCommand{uc}
+}
+
+// userWithPassword prints a generated password once, it cannot be shown
+// again since only its hash is stored.
+type userWithPassword struct {
+	*model.UserResponse
+	Password string `json:"password"`
+}
+
+func (c *UserCommand) Create(ctx context.Context, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("user create", flag.ContinueOnError)
+	flags.SetOutput(out)
+	role := flags.String("role", entity.UserRoleUser, "user, moderator or admin")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("%w: user create [-role user|moderator|admin] <username>", errUsage)
+	}
+
+	password, err := newPassword()
+	if err != nil {
+		return err
+	}
+
+	response, err := c.usecase.Create(ctx, &model.CreateUserRequest{
+		Username: flags.Arg(0),
+		Password: password,
+		Role:     *role,
+	})
+	if err != nil {
+		return unwrapModelError(err)
+	}
+
+	return writeJSON(out, userWithPassword{response, password})
+}
+
+func (c *UserCommand) List(ctx context.Context, args []string, out io.Writer) error {
+	if len(args) != 0 {
+		return fmt.Errorf("%w: user list", errUsage)
+	}
+
+	response, err := c.usecase.GetMany(ctx)
+	if err != nil {
+		return unwrapModelError(err)
+	}
+
+	return writeJSON(out, response)
+}
+
+func (c *UserCommand) SetRole(ctx context.Context, args []string, out io.Writer) error {
+	if len(args) != 2 {
+		return fmt.Errorf("%w: user set-role <username> user|moderator|admin", errUsage)
+	}
+
+	response, err := c.usecase.SetRole(ctx, &model.SetUserRoleRequest{
+		Username: args[0],
+		Role:     args[1],
+	})
+	if err != nil {
+		return unwrapModelError(err)
+	}
+
+	return writeJSON(out, response)
+}
+
+func (c *UserCommand) ResetPassword(ctx context.Context, args []string, out io.Writer) error {
+	if len(args) != 1 {
+		return fmt.Errorf("%w: user reset-password <username>", errUsage)
+	}
+
+	password, err := newPassword()
+	if err != nil {
+		return err
+	}
+
+	response, err := c.usecase.ResetPassword(ctx, &model.ResetUserPasswordRequest{
+		Username: args[0],
+		Password: password,
+	})
+	if err != nil {
+		return unwrapModelError(err)
+	}
+
+	return writeJSON(out, userWithPassword{response, password})
+}
+
+func (c *UserCommand) Delete(ctx context.Context, args []string, out io.Writer) error {
+	if len(args) != 1 {
+		return fmt.Errorf("%w: user delete <username>", errUsage)
+	}
+
+	response, err := c.usecase.Delete(ctx, &model.DeleteUserRequest{
+		Username: args[0],
+	})
+	if err != nil {
+		return unwrapModelError(err)
+	}
+
+	return writeJSON(out, response)
+}
+
+func newPassword() (string, error) {
+	secret := make([]byte, 18)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate password: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(secret), nil
+}
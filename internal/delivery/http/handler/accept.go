@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ByAccept dispatches on the media type the client prefers in the Accept
+// header, weighing q values and wildcards. fallback serves the JSON envelope
+// and handles requests that prefer none of the media types of handlers,
+// including those without an Accept header.
+func ByAccept(fallback gin.HandlerFunc, handlers map[string]gin.HandlerFunc) gin.HandlerFunc {
+	mediaTypes := make([]string, 0, len(handlers))
+	for mediaType := range handlers {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	sort.Strings(mediaTypes)
+
+	return func(ctx *gin.Context) {
+		ctx.Header("Vary", "Accept")
+
+		ranges := parseAccept(ctx.GetHeader("Accept"))
+		if len(ranges) == 0 {
+			fallback(ctx)
+			return
+		}
+
+		best := fallback
+		bestQuality, bestSpecificity := acceptQuality(ranges, "application/json")
+		for _, mediaType := range mediaTypes {
+			quality, specificity := acceptQuality(ranges, mediaType)
+			if quality > bestQuality || (quality == bestQuality && specificity > bestSpecificity) {
+				best, bestQuality, bestSpecificity = handlers[mediaType], quality, specificity
+			}
+		}
+		best(ctx)
+	}
+}
+
+type acceptRange struct {
+	mediaType string
+	quality   float64
+}
+
+func parseAccept(header string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		ranges = append(ranges, acceptRange{mediaType, quality})
+	}
+	return ranges
+}
+
+// acceptQuality returns the q value of the most specific range matching the
+// media type, and how specific that range is: 3 for the media type itself, 2
+// for type/* and 1 for */*.
+func acceptQuality(ranges []acceptRange, mediaType string) (float64, int) {
+	mainType, _, _ := strings.Cut(mediaType, "/")
+
+	quality, specificity := 0.0, 0
+	for _, r := range ranges {
+		s := 0
+		switch r.mediaType {
+		case mediaType:
+			s = 3
+		case mainType + "/*":
+			s = 2
+		case "*/*":
+			s = 1
+		}
+		if s > specificity {
+			quality, specificity = r.quality, s
+		}
+	}
+	return quality, specificity
+}
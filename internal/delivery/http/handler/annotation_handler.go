@@ -0,0 +1,274 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+	"github.com/mnaufalhilmym/gotracing"
+)
+
+type AnnotationHandler struct {
+	usecase *usecase.AnnotationUsecase
+}
+
+func NewAnnotationHandler(uc *usecase.AnnotationUsecase) *AnnotationHandler {
+	return &AnnotationHandler{uc}
+}
+
+func (h *AnnotationHandler) GetMany(ctx *gin.Context) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	request := new(model.GetManyAnnotationsRequest)
+	if err := ctx.ShouldBindQuery(request); err != nil {
+		gotracing.Error("Failed to parse request", err)
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			model.ResponseError(ctx, model.ErrorBadRequest(fmt.Errorf("validation error in field %s", errs[0].Field())))
+			return
+		}
+		model.ResponseError(ctx, model.ErrorBadRequest(errors.New("failed to parse request")))
+		return
+	}
+	request.ViewerID = user.ID
+
+	h.getMany(ctx, request)
+}
+
+func (h *AnnotationHandler) GetManyByBook(ctx *gin.Context) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	request := new(model.GetManyAnnotationsRequest)
+	if err := ctx.ShouldBindQuery(request); err != nil {
+		gotracing.Error("Failed to parse request", err)
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			model.ResponseError(ctx, model.ErrorBadRequest(fmt.Errorf("validation error in field %s", errs[0].Field())))
+			return
+		}
+		model.ResponseError(ctx, model.ErrorBadRequest(errors.New("failed to parse request")))
+		return
+	}
+	if err := ctx.ShouldBindUri(request); err != nil {
+		gotracing.Error("Failed to parse request", err)
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			model.ResponseError(ctx, model.ErrorBadRequest(fmt.Errorf("validation error in field %s", errs[0].Field())))
+			return
+		}
+		model.ResponseError(ctx, model.ErrorBadRequest(errors.New("failed to parse request")))
+		return
+	}
+	request.ViewerID = user.ID
+
+	h.getMany(ctx, request)
+}
+
+func (h *AnnotationHandler) GetOwn(ctx *gin.Context) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	request := new(model.GetManyAnnotationsRequest)
+	if err := ctx.ShouldBindQuery(request); err != nil {
+		gotracing.Error("Failed to parse request", err)
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			model.ResponseError(ctx, model.ErrorBadRequest(fmt.Errorf("validation error in field %s", errs[0].Field())))
+			return
+		}
+		model.ResponseError(ctx, model.ErrorBadRequest(errors.New("failed to parse request")))
+		return
+	}
+	request.ViewerID = user.ID
+	request.UserID = &user.ID
+
+	h.getMany(ctx, request)
+}
+
+func (h *AnnotationHandler) getMany(ctx *gin.Context, request *model.GetManyAnnotationsRequest) {
+	if request.Page <= 0 {
+		request.Page = 1
+	}
+	if request.Size <= 0 {
+		request.Size = 10
+	}
+
+	response, total, err := h.usecase.GetMany(ctx, request)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	model.ResponseOKPaginated(ctx, response, total, request.Page, request.Size)
+}
+
+func (h *AnnotationHandler) Get(ctx *gin.Context) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	request := new(model.GetAnnotationRequest)
+	if err := ctx.ShouldBindUri(request); err != nil {
+		gotracing.Error("Failed to parse request", err)
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			model.ResponseError(ctx, model.ErrorBadRequest(fmt.Errorf("validation error in field %s", errs[0].Field())))
+			return
+		}
+		model.ResponseError(ctx, model.ErrorBadRequest(errors.New("failed to parse request")))
+		return
+	}
+	request.ViewerID = user.ID
+
+	response, err := h.usecase.Get(ctx, request)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	model.LastModified(ctx, response.UpdatedAt)
+	model.ResponseOK(ctx, response)
+}
+
+func (h *AnnotationHandler) Create(ctx *gin.Context) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	request := new(model.CreateAnnotationRequest)
+	if err := ctx.ShouldBindJSON(request); err != nil {
+		gotracing.Error("Failed to parse request", err)
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			model.ResponseError(ctx, model.ErrorBadRequest(fmt.Errorf("validation error in field %s", errs[0].Field())))
+			return
+		}
+		model.ResponseError(ctx, model.ErrorBadRequest(errors.New("failed to parse request")))
+		return
+	}
+	if err := ctx.ShouldBindUri(request); err != nil {
+		gotracing.Error("Failed to parse request", err)
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			model.ResponseError(ctx, model.ErrorBadRequest(fmt.Errorf("validation error in field %s", errs[0].Field())))
+			return
+		}
+		model.ResponseError(ctx, model.ErrorBadRequest(errors.New("failed to parse request")))
+		return
+	}
+	request.UserID = user.ID
+
+	response, err := h.usecase.Create(ctx, request)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	model.ResponseCreated(ctx, response)
+}
+
+func (h *AnnotationHandler) Update(ctx *gin.Context) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	request := new(model.UpdateAnnotationRequest)
+	if err := ctx.ShouldBindUri(request); err != nil {
+		gotracing.Error("Failed to parse request", err)
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			model.ResponseError(ctx, model.ErrorBadRequest(fmt.Errorf("validation error in field %s", errs[0].Field())))
+			return
+		}
+		model.ResponseError(ctx, model.ErrorBadRequest(errors.New("failed to parse request")))
+		return
+	}
+	if err := ctx.ShouldBindJSON(request); err != nil {
+		gotracing.Error("Failed to parse request", err)
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			model.ResponseError(ctx, model.ErrorBadRequest(fmt.Errorf("validation error in field %s", errs[0].Field())))
+			return
+		}
+		model.ResponseError(ctx, model.ErrorBadRequest(errors.New("failed to parse request")))
+		return
+	}
+	request.UserID = user.ID
+
+	response, err := h.usecase.Update(ctx, request)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	model.ResponseOK(ctx, response)
+}
+
+func (h *AnnotationHandler) Delete(ctx *gin.Context) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	request := new(model.DeleteAnnotationRequest)
+	if err := ctx.ShouldBindUri(request); err != nil {
+		gotracing.Error("Failed to parse request", err)
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			model.ResponseError(ctx, model.ErrorBadRequest(fmt.Errorf("validation error in field %s", errs[0].Field())))
+			return
+		}
+		model.ResponseError(ctx, model.ErrorBadRequest(errors.New("failed to parse request")))
+		return
+	}
+	request.UserID = user.ID
+
+	annotationID, err := h.usecase.Delete(ctx, request)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	model.ResponseOK(ctx, *annotationID)
+}
+
+func (h *AnnotationHandler) Export(ctx *gin.Context) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	request := new(model.ExportAnnotationsRequest)
+	if err := ctx.ShouldBindQuery(request); err != nil {
+		gotracing.Error("Failed to parse request", err)
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			model.ResponseError(ctx, model.ErrorBadRequest(fmt.Errorf("validation error in field %s", errs[0].Field())))
+			return
+		}
+		model.ResponseError(ctx, model.ErrorBadRequest(errors.New("failed to parse request")))
+		return
+	}
+	request.UserID = user.ID
+
+	markdown, err := h.usecase.ExportMarkdown(ctx, request)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	ctx.Header("Content-Disposition", `attachment; filename="annotations.md"`)
+	ctx.Data(http.StatusOK, "text/markdown; charset=utf-8", markdown)
+}
@@ -0,0 +1,169 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnaufalhilmym/bookshelf/internal/delivery/http/handler"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+func newAnnotationRouter(t *testing.T) *gin.Engine {
+	db := newDatabase()
+	userRepo := repository.NewUserRepository()
+	authorRepo := repository.NewAuthorRepository()
+	bookRepo := repository.NewBookRepository()
+	annotationRepo := repository.NewAnnotationRepository()
+	userUc := usecase.NewUserUsecase(db, userRepo, "jwtKey", 10*time.Second)
+	authorHandler := handler.NewAuthorHandler(usecase.NewAuthorUsecase(db, authorRepo, bookRepo, repository.NewRevisionRepository()))
+	bookHandler := handler.NewBookHandler(usecase.NewBookUsecase(db, bookRepo, authorRepo, repository.NewRevisionRepository()))
+	annotationHandler := handler.NewAnnotationHandler(usecase.NewAnnotationUsecase(db, annotationRepo, bookRepo))
+
+	user, err := userUc.Register(context.Background(), &model.RegisterUserRequest{
+		Username: "reader",
+		Password: "password",
+	})
+	assert.NoError(t, err)
+
+	router := gin.Default()
+	router.Use(func(ctx *gin.Context) {
+		ctx.Set(model.UserContextKey, user)
+	})
+
+	router.POST("/authors", authorHandler.Create)
+	router.POST("/books", bookHandler.Create)
+	router.GET("/books/:id/annotations", annotationHandler.GetManyByBook)
+	router.POST("/books/:id/annotations", annotationHandler.Create)
+	router.GET("/annotations/:id", annotationHandler.Get)
+	router.GET("/me/annotations/export", annotationHandler.Export)
+
+	createAuthor(t, router, &model.CreateAuthorRequest{
+		Name:      "Author Name 1",
+		Birthdate: time.Date(2011, 11, 11, 11, 11, 11, 111, time.UTC),
+	})
+	createBook(t, router, &model.CreateBookRequest{
+		Title:    "Book Title 1",
+		ISBN:     "978-1451673319",
+		AuthorID: 1,
+	})
+
+	return router
+}
+
+func TestAnnotationHandler_Create(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := newAnnotationRouter(t)
+
+	t.Run("Positive Case - create annotation", func(t *testing.T) {
+		reqBody, err := json.Marshal(model.CreateAnnotationRequest{Page: 10, Text: "A quote", Visibility: "public"})
+		assert.NoError(t, err)
+
+		httpReq, err := http.NewRequest(http.MethodPost, "/books/1/annotations", bytes.NewReader(reqBody))
+		assert.NoError(t, err)
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusCreated, testRec.Code)
+
+		res := new(model.Response[model.AnnotationResponse])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+
+		assert.EqualValues(t, "A quote", res.Data.Text)
+		assert.EqualValues(t, "public", res.Data.Visibility)
+	})
+
+	t.Run("Negative Case - invalid visibility", func(t *testing.T) {
+		reqBody, err := json.Marshal(model.CreateAnnotationRequest{Text: "A quote", Visibility: "friends"})
+		assert.NoError(t, err)
+
+		httpReq, err := http.NewRequest(http.MethodPost, "/books/1/annotations", bytes.NewReader(reqBody))
+		assert.NoError(t, err)
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusBadRequest, testRec.Code)
+	})
+}
+
+func TestAnnotationHandler_Get(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := newAnnotationRouter(t)
+
+	reqBody, err := json.Marshal(model.CreateAnnotationRequest{Page: 10, Text: "A quote", Visibility: "public"})
+	assert.NoError(t, err)
+	httpReq, err := http.NewRequest(http.MethodPost, "/books/1/annotations", bytes.NewReader(reqBody))
+	assert.NoError(t, err)
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(httptest.NewRecorder(), httpReq)
+
+	get := func(ifModifiedSince string) *httptest.ResponseRecorder {
+		httpReq, err := http.NewRequest(http.MethodGet, "/annotations/1", nil)
+		assert.NoError(t, err)
+		if ifModifiedSince != "" {
+			httpReq.Header.Set("If-Modified-Since", ifModifiedSince)
+		}
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+		return testRec
+	}
+
+	testRec := get("")
+	assert.EqualValues(t, http.StatusOK, testRec.Code)
+	res := new(model.Response[model.AnnotationResponse])
+	assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+	lastModified := testRec.Header().Get("Last-Modified")
+	assert.EqualValues(t, res.Data.UpdatedAt.UTC().Format(http.TimeFormat), lastModified)
+
+	t.Run("Positive Case - not modified since", func(t *testing.T) {
+		testRec := get(lastModified)
+		assert.EqualValues(t, http.StatusNotModified, testRec.Code)
+		assert.Empty(t, testRec.Body.String())
+	})
+
+	t.Run("Negative Case - modified since", func(t *testing.T) {
+		since := res.Data.UpdatedAt.Add(-time.Hour).UTC().Format(http.TimeFormat)
+		assert.EqualValues(t, http.StatusOK, get(since).Code)
+		assert.EqualValues(t, http.StatusOK, get("yesterday").Code)
+	})
+}
+
+func TestAnnotationHandler_Export(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := newAnnotationRouter(t)
+
+	reqBody, err := json.Marshal(model.CreateAnnotationRequest{Text: "A quote"})
+	assert.NoError(t, err)
+	httpReq, err := http.NewRequest(http.MethodPost, "/books/1/annotations", bytes.NewReader(reqBody))
+	assert.NoError(t, err)
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(httptest.NewRecorder(), httpReq)
+
+	t.Run("Positive Case - export markdown", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodGet, "/me/annotations/export", nil)
+		assert.NoError(t, err)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+		assert.EqualValues(t, "text/markdown; charset=utf-8", testRec.Header().Get("Content-Type"))
+		assert.True(t, strings.Contains(testRec.Body.String(), "> A quote\n"))
+	})
+}
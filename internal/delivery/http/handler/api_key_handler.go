@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+	"github.com/mnaufalhilmym/gotracing"
+)
+
+type APIKeyHandler struct {
+	usecase *usecase.APIKeyUsecase
+}
+
+func NewAPIKeyHandler(uc *usecase.APIKeyUsecase) *APIKeyHandler {
+	return &APIKeyHandler{uc}
+}
+
+func (h *APIKeyHandler) GetMany(ctx *gin.Context) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	response, err := h.usecase.GetMany(ctx, &model.GetManyAPIKeysRequest{UserID: user.ID})
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	model.ResponseOK(ctx, response)
+}
+
+func (h *APIKeyHandler) Create(ctx *gin.Context) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	request := new(model.CreateAPIKeyRequest)
+	if err := ctx.ShouldBindJSON(request); err != nil {
+		gotracing.Error("Failed to parse request", err)
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			model.ResponseError(ctx, model.ErrorBadRequest(fmt.Errorf("validation error in field %s", errs[0].Field())))
+			return
+		}
+		model.ResponseError(ctx, model.ErrorBadRequest(errors.New("failed to parse request")))
+		return
+	}
+	request.UserID = user.ID
+
+	response, err := h.usecase.Create(ctx, request)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	model.ResponseCreated(ctx, response)
+}
+
+func (h *APIKeyHandler) Delete(ctx *gin.Context) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	request := new(model.DeleteAPIKeyRequest)
+	if err := ctx.ShouldBindUri(request); err != nil {
+		gotracing.Error("Failed to parse request", err)
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			model.ResponseError(ctx, model.ErrorBadRequest(fmt.Errorf("validation error in field %s", errs[0].Field())))
+			return
+		}
+		model.ResponseError(ctx, model.ErrorBadRequest(errors.New("failed to parse request")))
+		return
+	}
+	request.UserID = user.ID
+
+	apiKeyID, err := h.usecase.Delete(ctx, request)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	model.ResponseOK(ctx, *apiKeyID)
+}
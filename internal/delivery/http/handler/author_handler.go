@@ -68,6 +68,35 @@ func (h *AuthorHandler) Get(ctx *gin.Context) {
 	model.ResponseOK(ctx, response)
 }
 
+// GetAs returns a handler that represents the author as schema.org JSON-LD or
+// as Dublin Core, for clients asking for them in the Accept header.
+func (h *AuthorHandler) GetAs(representation string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		request := new(model.GetAuthorRequest)
+		if err := ctx.ShouldBindUri(request); err != nil {
+			gotracing.Error("Failed to parse request", err)
+			if errs, ok := err.(validator.ValidationErrors); ok {
+				model.ResponseError(ctx, model.ErrorBadRequest(fmt.Errorf("validation error in field %s", errs[0].Field())))
+				return
+			}
+			model.ResponseError(ctx, model.ErrorBadRequest(errors.New("failed to parse request")))
+			return
+		}
+
+		response, err := h.usecase.Get(ctx, request)
+		if err != nil {
+			model.ResponseError(ctx, err)
+			return
+		}
+
+		if representation == model.RepresentationJSONLD {
+			respondJSONLD(ctx, model.ToAuthorJSONLD(response, baseURL(ctx)))
+			return
+		}
+		respondDublinCore(ctx, model.ToAuthorDublinCore(response, baseURL(ctx)))
+	}
+}
+
 func (h *AuthorHandler) Create(ctx *gin.Context) {
 	request := new(model.CreateAuthorRequest)
 	if err := ctx.ShouldBindJSON(request); err != nil {
@@ -110,6 +139,11 @@ func (h *AuthorHandler) Update(ctx *gin.Context) {
 		return
 	}
 
+	if user, err := currentUser(ctx); err == nil {
+		request.UpdatedBy = &user.ID
+	}
+	request.IfMatch = ctx.GetHeader("If-Match")
+
 	response, err := h.usecase.Update(ctx, request)
 	if err != nil {
 		model.ResponseError(ctx, err)
@@ -130,6 +164,20 @@ func (h *AuthorHandler) Delete(ctx *gin.Context) {
 		model.ResponseError(ctx, model.ErrorBadRequest(errors.New("failed to parse request")))
 		return
 	}
+	if err := ctx.ShouldBindQuery(request); err != nil {
+		gotracing.Error("Failed to parse request", err)
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			model.ResponseError(ctx, model.ErrorBadRequest(fmt.Errorf("validation error in field %s", errs[0].Field())))
+			return
+		}
+		model.ResponseError(ctx, model.ErrorBadRequest(errors.New("failed to parse request")))
+		return
+	}
+
+	if user, err := currentUser(ctx); err == nil {
+		request.DeletedBy = &user.ID
+	}
+	request.IfMatch = ctx.GetHeader("If-Match")
 
 	authorID, err := h.usecase.Delete(ctx, request)
 	if err != nil {
@@ -139,3 +187,95 @@ func (h *AuthorHandler) Delete(ctx *gin.Context) {
 
 	model.ResponseOK(ctx, *authorID)
 }
+
+func (h *AuthorHandler) GetRevisions(ctx *gin.Context) {
+	request := new(model.GetManyRevisionsRequest)
+	if err := ctx.ShouldBindUri(request); err != nil {
+		gotracing.Error("Failed to parse request", err)
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			model.ResponseError(ctx, model.ErrorBadRequest(fmt.Errorf("validation error in field %s", errs[0].Field())))
+			return
+		}
+		model.ResponseError(ctx, model.ErrorBadRequest(errors.New("failed to parse request")))
+		return
+	}
+	if err := ctx.ShouldBindQuery(request); err != nil {
+		gotracing.Error("Failed to parse request", err)
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			model.ResponseError(ctx, model.ErrorBadRequest(fmt.Errorf("validation error in field %s", errs[0].Field())))
+			return
+		}
+		model.ResponseError(ctx, model.ErrorBadRequest(errors.New("failed to parse request")))
+		return
+	}
+
+	if request.Page <= 0 {
+		request.Page = 1
+	}
+	if request.Size <= 0 {
+		request.Size = 10
+	}
+
+	response, total, err := h.usecase.GetRevisions(ctx, request)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	model.ResponseOKPaginated(ctx, response, total, request.Page, request.Size)
+}
+
+func (h *AuthorHandler) DiffRevisions(ctx *gin.Context) {
+	request := new(model.DiffRevisionsRequest)
+	if err := ctx.ShouldBindUri(request); err != nil {
+		gotracing.Error("Failed to parse request", err)
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			model.ResponseError(ctx, model.ErrorBadRequest(fmt.Errorf("validation error in field %s", errs[0].Field())))
+			return
+		}
+		model.ResponseError(ctx, model.ErrorBadRequest(errors.New("failed to parse request")))
+		return
+	}
+	if err := ctx.ShouldBindQuery(request); err != nil {
+		gotracing.Error("Failed to parse request", err)
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			model.ResponseError(ctx, model.ErrorBadRequest(fmt.Errorf("validation error in field %s", errs[0].Field())))
+			return
+		}
+		model.ResponseError(ctx, model.ErrorBadRequest(errors.New("failed to parse request")))
+		return
+	}
+
+	response, err := h.usecase.DiffRevisions(ctx, request)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	model.ResponseOK(ctx, response)
+}
+
+func (h *AuthorHandler) RestoreRevision(ctx *gin.Context) {
+	request := new(model.RestoreRevisionRequest)
+	if err := ctx.ShouldBindUri(request); err != nil {
+		gotracing.Error("Failed to parse request", err)
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			model.ResponseError(ctx, model.ErrorBadRequest(fmt.Errorf("validation error in field %s", errs[0].Field())))
+			return
+		}
+		model.ResponseError(ctx, model.ErrorBadRequest(errors.New("failed to parse request")))
+		return
+	}
+
+	if user, err := currentUser(ctx); err == nil {
+		request.RestoredBy = &user.ID
+	}
+
+	response, err := h.usecase.RestoreRevision(ctx, request)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	model.ResponseOK(ctx, response)
+}
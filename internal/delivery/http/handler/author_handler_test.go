@@ -2,6 +2,7 @@ package handler_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -9,19 +10,30 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/mnaufalhilmym/bookshelf/internal/config"
 	"github.com/mnaufalhilmym/bookshelf/internal/delivery/http/handler"
+	"github.com/mnaufalhilmym/bookshelf/internal/migration"
 	"github.com/mnaufalhilmym/bookshelf/internal/model"
 	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/bookshelf/internal/testdb"
 	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
 	"github.com/mnaufalhilmym/bookshelf/internal/util"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
 )
 
+func newDatabase() *gorm.DB {
+	db := testdb.New()
+	uc := usecase.NewMigrationUsecase(db, repository.NewSchemaMigrationRepository(), migration.All())
+	if _, err := uc.Up(context.Background(), &model.MigrateUpRequest{}); err != nil {
+		panic(err)
+	}
+	return db
+}
+
 func newAuthorHandler() *handler.AuthorHandler {
-	db := config.NewDatabase(":memory:", 1, 1, 100)
-	repo := repository.NewAuthorRepository(db)
-	uc := usecase.NewAuthorUsecase(db, repo)
+	db := newDatabase()
+	repo := repository.NewAuthorRepository()
+	uc := usecase.NewAuthorUsecase(db, repo, repository.NewBookRepository(), repository.NewRevisionRepository())
 	return handler.NewAuthorHandler(uc)
 }
 
@@ -395,10 +407,12 @@ func TestAuthorHandler_Delete(t *testing.T) {
 
 	router := gin.Default()
 
-	handler := newAuthorHandler()
+	handler, bookHandler := newAuthorAndBookHandler()
 
 	router.POST("/authors", handler.Create)
 	router.DELETE("/authors/:id", handler.Delete)
+	router.POST("/books", bookHandler.Create)
+	router.GET("/books/:id", bookHandler.Get)
 
 	t.Run("Positive Case - delete author", func(t *testing.T) {
 		req1 := &model.CreateAuthorRequest{
@@ -472,4 +486,74 @@ func TestAuthorHandler_Delete(t *testing.T) {
 
 		assert.EqualValues(t, "failed to parse request", res.Error)
 	})
+
+	createAuthor(t, router, &model.CreateAuthorRequest{
+		Name:      "Author Name 2",
+		Birthdate: time.Date(2012, 12, 12, 12, 12, 12, 122, time.UTC),
+	})
+	createAuthor(t, router, &model.CreateAuthorRequest{
+		Name:      "Author Name 3",
+		Birthdate: time.Date(2013, 3, 13, 13, 13, 13, 133, time.UTC),
+	})
+	createBook(t, router, &model.CreateBookRequest{
+		Title:    "Book Title 1",
+		ISBN:     "978-0451524935",
+		AuthorID: 2,
+	})
+
+	t.Run("Negative Case 4 - author has books", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodDelete, "/authors/2", nil)
+		assert.NoError(t, err)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusConflict, testRec.Code)
+
+		res := new(model.Response[[]model.BookResponse])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+
+		assert.EqualValues(t, "author still has books", res.Error)
+		assert.EqualValues(t, 1, len(res.Data))
+		assert.EqualValues(t, "Book Title 1", res.Data[0].Title)
+	})
+
+	t.Run("Positive Case 2 - reassign books", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodDelete, "/authors/2?reassign_to=3", nil)
+		assert.NoError(t, err)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+
+		httpReq, err = http.NewRequest(http.MethodGet, "/books/1", nil)
+		assert.NoError(t, err)
+
+		testRec = httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		res := new(model.Response[model.BookResponse])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+
+		assert.EqualValues(t, 3, res.Data.AuthorID)
+	})
+
+	t.Run("Positive Case 3 - cascade", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodDelete, "/authors/3?cascade=true", nil)
+		assert.NoError(t, err)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+
+		httpReq, err = http.NewRequest(http.MethodGet, "/books/1", nil)
+		assert.NoError(t, err)
+
+		testRec = httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusNotFound, testRec.Code)
+	})
 }
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+)
+
+type BackupHandler struct {
+	usecase *usecase.BackupUsecase
+}
+
+func NewBackupHandler(uc *usecase.BackupUsecase) *BackupHandler {
+	return &BackupHandler{uc}
+}
+
+func (h *BackupHandler) GetMany(ctx *gin.Context) {
+	response, err := h.usecase.GetMany(ctx)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	model.ResponseOK(ctx, response)
+}
+
+func (h *BackupHandler) Create(ctx *gin.Context) {
+	response, err := h.usecase.Create(ctx)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	model.ResponseCreated(ctx, response)
+}
@@ -0,0 +1,65 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnaufalhilmym/bookshelf/internal/backup"
+	"github.com/mnaufalhilmym/bookshelf/internal/config"
+	"github.com/mnaufalhilmym/bookshelf/internal/delivery/http/handler"
+	"github.com/mnaufalhilmym/bookshelf/internal/migration"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackupHandler_Create(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	dir := t.TempDir()
+	databasePath := filepath.Join(dir, "sqlite.db")
+	db := config.NewDatabase("sqlite", databasePath, 1, 1, 100, config.SQLiteOptions{})
+	_, err := usecase.NewMigrationUsecase(db, repository.NewSchemaMigrationRepository(), migration.All()).
+		Up(context.Background(), &model.MigrateUpRequest{})
+	assert.NoError(t, err)
+
+	h := handler.NewBackupHandler(usecase.NewBackupUsecase(
+		db,
+		repository.NewBackupRepository(),
+		backup.NewStore(filepath.Join(dir, "backups")),
+		databasePath,
+		0,
+		0,
+	))
+
+	router := gin.Default()
+	router.GET("/admin/backups", h.GetMany)
+	router.POST("/admin/backups", h.Create)
+
+	httpReq, err := http.NewRequest(http.MethodPost, "/admin/backups", nil)
+	assert.NoError(t, err)
+	testRec := httptest.NewRecorder()
+	router.ServeHTTP(testRec, httpReq)
+	assert.EqualValues(t, http.StatusCreated, testRec.Code)
+
+	created := new(model.Response[model.BackupResponse])
+	assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), created))
+	assert.Greater(t, created.Data.Size, int64(0))
+
+	httpReq, err = http.NewRequest(http.MethodGet, "/admin/backups", nil)
+	assert.NoError(t, err)
+	testRec = httptest.NewRecorder()
+	router.ServeHTTP(testRec, httpReq)
+	assert.EqualValues(t, http.StatusOK, testRec.Code)
+
+	backups := new(model.Response[[]model.BackupResponse])
+	assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), backups))
+	assert.EqualValues(t, 1, len(backups.Data))
+	assert.EqualValues(t, created.Data.Name, backups.Data[0].Name)
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+	"github.com/mnaufalhilmym/gotracing"
+)
+
+type BookFileHandler struct {
+	usecase *usecase.BookFileUsecase
+}
+
+func NewBookFileHandler(uc *usecase.BookFileUsecase) *BookFileHandler {
+	return &BookFileHandler{uc}
+}
+
+func (h *BookFileHandler) GetMany(ctx *gin.Context) {
+	request := new(model.GetManyBookFilesRequest)
+	if err := ctx.ShouldBindUri(request); err != nil {
+		gotracing.Error("Failed to parse request", err)
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			model.ResponseError(ctx, model.ErrorBadRequest(fmt.Errorf("validation error in field %s", errs[0].Field())))
+			return
+		}
+		model.ResponseError(ctx, model.ErrorBadRequest(errors.New("failed to parse request")))
+		return
+	}
+
+	response, err := h.usecase.GetMany(ctx, request)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	model.ResponseOK(ctx, response)
+}
+
+func (h *BookFileHandler) Upload(ctx *gin.Context) {
+	request := new(model.UploadBookFileRequest)
+	if err := ctx.ShouldBindUri(request); err != nil {
+		gotracing.Error("Failed to parse request", err)
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			model.ResponseError(ctx, model.ErrorBadRequest(fmt.Errorf("validation error in field %s", errs[0].Field())))
+			return
+		}
+		model.ResponseError(ctx, model.ErrorBadRequest(errors.New("failed to parse request")))
+		return
+	}
+
+	filename, content, err := readUploadedNamedFile(ctx, "file")
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+	request.Filename = filename
+	request.Content = content
+
+	response, err := h.usecase.Upload(ctx, request)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	model.ResponseCreated(ctx, response)
+}
+
+func (h *BookFileHandler) UploadBook(ctx *gin.Context) {
+	request := new(model.UploadBookRequest)
+	if err := ctx.ShouldBind(request); err != nil {
+		gotracing.Error("Failed to parse request", err)
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			model.ResponseError(ctx, model.ErrorBadRequest(fmt.Errorf("validation error in field %s", errs[0].Field())))
+			return
+		}
+		model.ResponseError(ctx, model.ErrorBadRequest(errors.New("failed to parse request")))
+		return
+	}
+
+	filename, content, err := readUploadedNamedFile(ctx, "file")
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+	request.Filename = filename
+	request.Content = content
+
+	response, err := h.usecase.UploadBook(ctx, request)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	model.ResponseCreated(ctx, response)
+}
+
+// Download serves the file with http.ServeContent, which answers Range and
+// conditional requests.
+func (h *BookFileHandler) Download(ctx *gin.Context) {
+	request := new(model.GetBookFileRequest)
+	if err := ctx.ShouldBindUri(request); err != nil {
+		gotracing.Error("Failed to parse request", err)
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			model.ResponseError(ctx, model.ErrorBadRequest(fmt.Errorf("validation error in field %s", errs[0].Field())))
+			return
+		}
+		model.ResponseError(ctx, model.ErrorBadRequest(errors.New("failed to parse request")))
+		return
+	}
+
+	file, content, err := h.usecase.Open(ctx, request)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+	defer content.Close()
+
+	ctx.Header("Content-Type", file.ContentType)
+	ctx.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": file.Filename}))
+	ctx.Header("ETag", `"`+file.Checksum+`"`)
+	http.ServeContent(ctx.Writer, ctx.Request, file.Filename, file.CreatedAt, content)
+}
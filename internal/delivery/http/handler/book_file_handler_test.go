@@ -0,0 +1,193 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnaufalhilmym/bookshelf/internal/delivery/http/handler"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/bookshelf/internal/storage"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+const pdfDocument = "%PDF-1.4\n" +
+	"1 0 obj\n<< /Type /Catalog /Pages 2 0 R /Lang (en) >>\nendobj\n" +
+	"2 0 obj\n<< /Type /Pages /Kids [] /Count 0 >>\nendobj\n" +
+	"3 0 obj\n<< /Title (Dune) /Author (Frank Herbert) /Keywords (isbn:9780441172719, science fiction) >>\nendobj\n" +
+	"trailer\n<< /Size 4 /Root 1 0 R /Info 3 0 R >>\n%%EOF\n"
+
+func newBookFileRouter(t *testing.T) *gin.Engine {
+	db := newDatabase()
+	authorRepo := repository.NewAuthorRepository()
+	bookRepo := repository.NewBookRepository()
+	bookFileRepo := repository.NewBookFileRepository()
+	authorHandler := handler.NewAuthorHandler(usecase.NewAuthorUsecase(db, authorRepo, bookRepo, repository.NewRevisionRepository()))
+	bookHandler := handler.NewBookHandler(usecase.NewBookUsecase(db, bookRepo, authorRepo, repository.NewRevisionRepository()))
+	fileStorage := storage.NewLocal(t.TempDir())
+	bookFileHandler := handler.NewBookFileHandler(usecase.NewBookFileUsecase(
+		db,
+		fileStorage,
+		bookFileRepo,
+		bookRepo,
+		authorRepo,
+	))
+	coverHandler := handler.NewCoverHandler(usecase.NewCoverUsecase(db, fileStorage, bookRepo))
+
+	router := gin.Default()
+
+	router.POST("/authors", authorHandler.Create)
+	router.POST("/books", bookHandler.Create)
+	router.POST("/books/upload", bookFileHandler.UploadBook)
+	router.GET("/books/:id/files", bookFileHandler.GetMany)
+	router.POST("/books/:id/files", bookFileHandler.Upload)
+	router.GET("/books/:id/files/:file_id", bookFileHandler.Download)
+	router.GET("/books/:id/cover", coverHandler.Get)
+	router.GET("/books/:id/cover/:size", coverHandler.Get)
+	router.PUT("/books/:id/cover", coverHandler.Upload)
+	router.DELETE("/books/:id/cover", coverHandler.Delete)
+
+	createAuthor(t, router, &model.CreateAuthorRequest{
+		Name:      "Author Name 1",
+		Birthdate: time.Date(2011, 11, 11, 11, 11, 11, 111, time.UTC),
+	})
+	createBook(t, router, &model.CreateBookRequest{
+		Title:    "Book Title 1",
+		ISBN:     "978-1451673319",
+		AuthorID: 1,
+	})
+
+	return router
+}
+
+func TestBookFileHandler_UploadBook(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := newBookFileRouter(t)
+
+	var uploaded model.UploadBookFileResponse
+
+	t.Run("Positive Case - create book from pdf", func(t *testing.T) {
+		httpReq := newUploadRequest(t, "/books/upload", "file", "dune.pdf", pdfDocument)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusCreated, testRec.Code)
+
+		res := new(model.Response[model.UploadBookFileResponse])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+
+		assert.EqualValues(t, "Dune", res.Data.Book.Title)
+		assert.EqualValues(t, "9780441172719", res.Data.Book.ISBN)
+		assert.EqualValues(t, "Frank Herbert", res.Data.Book.AuthorName)
+		assert.EqualValues(t, "en", res.Data.Book.Language)
+		assert.EqualValues(t, "dune.pdf", res.Data.File.Filename)
+		assert.EqualValues(t, "application/pdf", res.Data.File.ContentType)
+
+		uploaded = res.Data
+	})
+
+	t.Run("Positive Case - download whole file", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/books/%d/files/%d", uploaded.Book.ID, uploaded.File.ID), nil)
+		assert.NoError(t, err)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+		assert.EqualValues(t, "application/pdf", testRec.Header().Get("Content-Type"))
+		assert.EqualValues(t, "attachment; filename=dune.pdf", testRec.Header().Get("Content-Disposition"))
+		assert.EqualValues(t, "bytes", testRec.Header().Get("Accept-Ranges"))
+		assert.EqualValues(t, pdfDocument, testRec.Body.String())
+	})
+
+	t.Run("Positive Case - download range", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/books/%d/files/%d", uploaded.Book.ID, uploaded.File.ID), nil)
+		assert.NoError(t, err)
+		httpReq.Header.Set("Range", "bytes=0-7")
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusPartialContent, testRec.Code)
+		assert.EqualValues(t, fmt.Sprintf("bytes 0-7/%d", len(pdfDocument)), testRec.Header().Get("Content-Range"))
+		assert.EqualValues(t, "%PDF-1.4", testRec.Body.String())
+	})
+
+	t.Run("Negative Case - missing file", func(t *testing.T) {
+		httpReq := newUploadRequest(t, "/books/upload", "", "", "")
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusBadRequest, testRec.Code)
+	})
+}
+
+func TestBookFileHandler_Upload(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := newBookFileRouter(t)
+
+	t.Run("Positive Case - attach pdf", func(t *testing.T) {
+		httpReq := newUploadRequest(t, "/books/1/files", "file", "book.pdf", pdfDocument)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusCreated, testRec.Code)
+
+		res := new(model.Response[model.UploadBookFileResponse])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+
+		assert.EqualValues(t, 1, res.Data.Book.ID)
+		assert.EqualValues(t, "Dune", res.Data.Metadata.Title)
+	})
+
+	t.Run("Positive Case - list files", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodGet, "/books/1/files", nil)
+		assert.NoError(t, err)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+
+		res := new(model.Response[[]model.BookFileResponse])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+
+		assert.EqualValues(t, 1, len(res.Data))
+		assert.EqualValues(t, len(pdfDocument), res.Data[0].Size)
+	})
+
+	t.Run("Negative Case - unsupported file", func(t *testing.T) {
+		httpReq := newUploadRequest(t, "/books/1/files", "file", "notes.txt", "just text")
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusBadRequest, testRec.Code)
+
+		res := new(model.Response[model.UploadBookFileResponse])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+
+		assert.EqualValues(t, "unsupported file format, expected epub or pdf", res.Error)
+	})
+
+	t.Run("Negative Case - book without cover", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodGet, "/books/1/cover", nil)
+		assert.NoError(t, err)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusNotFound, testRec.Code)
+	})
+}
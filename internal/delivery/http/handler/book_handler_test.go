@@ -9,8 +9,8 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/mnaufalhilmym/bookshelf/internal/config"
 	"github.com/mnaufalhilmym/bookshelf/internal/delivery/http/handler"
+	"github.com/mnaufalhilmym/bookshelf/internal/delivery/http/middleware"
 	"github.com/mnaufalhilmym/bookshelf/internal/model"
 	"github.com/mnaufalhilmym/bookshelf/internal/repository"
 	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
@@ -19,11 +19,11 @@ import (
 )
 
 func newAuthorAndBookHandler() (*handler.AuthorHandler, *handler.BookHandler) {
-	db := config.NewDatabase(":memory:", 1, 1, 100)
-	authorRepo := repository.NewAuthorRepository(db)
-	bookRepo := repository.NewBookRepository(db)
-	authorUc := usecase.NewAuthorUsecase(db, authorRepo)
-	bookUc := usecase.NewBookUsecase(db, bookRepo, authorRepo)
+	db := newDatabase()
+	authorRepo := repository.NewAuthorRepository()
+	bookRepo := repository.NewBookRepository()
+	authorUc := usecase.NewAuthorUsecase(db, authorRepo, bookRepo, repository.NewRevisionRepository())
+	bookUc := usecase.NewBookUsecase(db, bookRepo, authorRepo, repository.NewRevisionRepository())
 	authorHandler := handler.NewAuthorHandler(authorUc)
 	bookHandler := handler.NewBookHandler(bookUc)
 	return authorHandler, bookHandler
@@ -80,6 +80,7 @@ func TestBookHandler_GetMany(t *testing.T) {
 			ISBN:       reqBook1.ISBN,
 			AuthorID:   reqBook1.AuthorID,
 			AuthorName: reqAuthor1.Name,
+			Version:    1,
 		}}
 
 		httpReq, err := http.NewRequest(http.MethodGet, "/books?title=1", nil)
@@ -103,6 +104,7 @@ func TestBookHandler_GetMany(t *testing.T) {
 			ISBN:       reqBook2.ISBN,
 			AuthorID:   reqBook2.AuthorID,
 			AuthorName: reqAuthor1.Name,
+			Version:    1,
 		}}
 
 		httpReq, err := http.NewRequest(http.MethodGet, "/books?isbn=0563", nil)
@@ -181,6 +183,7 @@ func TestBookHandler_Get(t *testing.T) {
 			ISBN:       reqBook1.ISBN,
 			AuthorID:   reqBook1.AuthorID,
 			AuthorName: reqAuthor1.Name,
+			Version:    1,
 		}
 
 		httpReq, err := http.NewRequest(http.MethodGet, "/books/1", nil)
@@ -274,6 +277,7 @@ func TestBookHandler_Create(t *testing.T) {
 			ISBN:       payload.ISBN,
 			AuthorID:   payload.AuthorID,
 			AuthorName: reqAuthor1.Name,
+			Version:    1,
 		}
 
 		httpReq, err := http.NewRequest(http.MethodPost, "/books", bytes.NewReader(reqBody))
@@ -369,6 +373,7 @@ func TestBookHandler_Update(t *testing.T) {
 			ISBN:       *payload.ISBN,
 			AuthorID:   reqBook1.AuthorID,
 			AuthorName: reqAuthor1.Name,
+			Version:    2,
 		}
 
 		httpReq, err := http.NewRequest(http.MethodPut, "/books/1", bytes.NewReader(reqBody))
@@ -537,3 +542,221 @@ func TestBookHandler_Delete(t *testing.T) {
 		assert.EqualValues(t, "failed to parse request", res.Error)
 	})
 }
+
+func TestBookHandler_Revisions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.Default()
+	router.Use(func(ctx *gin.Context) {
+		ctx.Set(model.UserContextKey, &model.UserResponse{ID: 7, Username: "librarian"})
+	})
+
+	authorHandler, bookHandler := newAuthorAndBookHandler()
+
+	router.POST("/authors", authorHandler.Create)
+	router.POST("/books", bookHandler.Create)
+	router.PUT("/books/:id", bookHandler.Update)
+	router.GET("/books/:id/revisions", bookHandler.GetRevisions)
+	router.GET("/books/:id/revisions/diff", bookHandler.DiffRevisions)
+	router.POST("/books/:id/revisions/:rev/restore", bookHandler.RestoreRevision)
+
+	createAuthor(t, router, &model.CreateAuthorRequest{
+		Name:      "Author Name 1",
+		Birthdate: time.Date(2011, 11, 11, 11, 11, 11, 111, time.UTC),
+	})
+	createBook(t, router, &model.CreateBookRequest{
+		Title:    "Book Title 1",
+		ISBN:     "978-1451673319",
+		AuthorID: 1,
+	})
+
+	serve := func(method string, url string, body []byte) *httptest.ResponseRecorder {
+		httpReq, err := http.NewRequest(method, url, bytes.NewReader(body))
+		assert.NoError(t, err)
+		httpReq.Header.Set("Content-Type", "application/json")
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+		return testRec
+	}
+
+	reqBody, err := json.Marshal(model.UpdateBookRequest{Title: util.ToPointer("Book Title Changed")})
+	assert.NoError(t, err)
+	assert.EqualValues(t, http.StatusOK, serve(http.MethodPut, "/books/1", reqBody).Code)
+
+	t.Run("Positive Case - list revisions", func(t *testing.T) {
+		testRec := serve(http.MethodGet, "/books/1/revisions", nil)
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+
+		res := new(model.Response[[]model.RevisionResponse])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+		assert.EqualValues(t, 2, res.Pagination.TotalItem)
+		assert.EqualValues(t, 2, res.Data[0].Revision)
+		assert.EqualValues(t, util.ToPointer(7), res.Data[0].CreatedBy)
+	})
+
+	t.Run("Positive Case - diff revisions", func(t *testing.T) {
+		testRec := serve(http.MethodGet, "/books/1/revisions/diff", nil)
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+
+		res := new(model.Response[model.RevisionDiffResponse])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+		assert.EqualValues(t, []model.RevisionChangeResponse{
+			{Field: "title", From: "Book Title 1", To: "Book Title Changed"},
+		}, res.Data.Changes)
+	})
+
+	t.Run("Negative Case 1 - invalid revision", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusBadRequest, serve(http.MethodGet, "/books/1/revisions/diff?from=-1", nil).Code)
+		assert.EqualValues(t, http.StatusNotFound, serve(http.MethodGet, "/books/1/revisions/diff?to=1", nil).Code)
+	})
+
+	t.Run("Negative Case 2 - book not found", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusNotFound, serve(http.MethodGet, "/books/2/revisions", nil).Code)
+		assert.EqualValues(t, http.StatusNotFound, serve(http.MethodPost, "/books/1/revisions/3/restore", nil).Code)
+	})
+
+	t.Run("Positive Case - restore revision", func(t *testing.T) {
+		testRec := serve(http.MethodPost, "/books/1/revisions/1/restore", nil)
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+
+		res := new(model.Response[model.BookResponse])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+		assert.EqualValues(t, "Book Title 1", res.Data.Title)
+	})
+}
+
+func TestBookHandler_IfMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.Default()
+
+	authorHandler, bookHandler := newAuthorAndBookHandler()
+	requireIfMatch := middleware.NewPreconditionMiddleware(true).RequireIfMatch()
+
+	router.POST("/authors", authorHandler.Create)
+	router.POST("/books", bookHandler.Create)
+	router.GET("/books/:id", bookHandler.Get)
+	router.PUT("/books/:id", requireIfMatch, bookHandler.Update)
+	router.DELETE("/books/:id", requireIfMatch, bookHandler.Delete)
+
+	createAuthor(t, router, &model.CreateAuthorRequest{
+		Name:      "Author Name 1",
+		Birthdate: time.Date(2011, 11, 11, 11, 11, 11, 111, time.UTC),
+	})
+	createBook(t, router, &model.CreateBookRequest{
+		Title:    "Book Title 1",
+		ISBN:     "978-1451673319",
+		AuthorID: 1,
+	})
+
+	serve := func(method string, url string, body []byte, ifMatch string) *httptest.ResponseRecorder {
+		httpReq, err := http.NewRequest(method, url, bytes.NewReader(body))
+		assert.NoError(t, err)
+		httpReq.Header.Set("Content-Type", "application/json")
+		if ifMatch != "" {
+			httpReq.Header.Set("If-Match", ifMatch)
+		}
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+		return testRec
+	}
+
+	testRec := serve(http.MethodGet, "/books/1", nil, "")
+	assert.EqualValues(t, http.StatusOK, testRec.Code)
+	res := new(model.Response[model.BookResponse])
+	assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+	etag := testRec.Header().Get("ETag")
+	assert.EqualValues(t, `"1-1-`+res.DataHash+`"`, etag)
+
+	reqBody, err := json.Marshal(model.UpdateBookRequest{Title: util.ToPointer("Book Title Changed")})
+	assert.NoError(t, err)
+
+	t.Run("Negative Case 1 - if-match is required", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusPreconditionRequired, serve(http.MethodPut, "/books/1", reqBody, "").Code)
+		assert.EqualValues(t, http.StatusPreconditionRequired, serve(http.MethodDelete, "/books/1", nil, "").Code)
+	})
+
+	t.Run("Positive Case - update with current etag", func(t *testing.T) {
+		testRec := serve(http.MethodPut, "/books/1", reqBody, etag)
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+		assert.NotEqualValues(t, etag, testRec.Header().Get("ETag"))
+		assert.EqualValues(t, testRec.Header().Get("ETag"), serve(http.MethodGet, "/books/1", nil, "").Header().Get("ETag"))
+	})
+
+	t.Run("Negative Case 2 - stale etag", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusPreconditionFailed, serve(http.MethodPut, "/books/1", reqBody, etag).Code)
+		assert.EqualValues(t, http.StatusPreconditionFailed, serve(http.MethodDelete, "/books/1", nil, etag).Code)
+		assert.EqualValues(t, http.StatusPreconditionFailed, serve(http.MethodDelete, "/books/1", nil, "W/"+etag).Code)
+	})
+}
+
+func TestBookHandler_IfNoneMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.Default()
+	router.Use(middleware.NewCacheControlMiddleware("private, no-cache", map[string]string{
+		"/books": "private, max-age=60",
+	}).SetCacheControl())
+
+	authorHandler, bookHandler := newAuthorAndBookHandler()
+
+	router.POST("/authors", authorHandler.Create)
+	router.GET("/books", bookHandler.GetMany)
+	router.POST("/books", bookHandler.Create)
+	router.GET("/books/:id", bookHandler.Get)
+
+	createAuthor(t, router, &model.CreateAuthorRequest{
+		Name:      "Author Name 1",
+		Birthdate: time.Date(2011, 11, 11, 11, 11, 11, 111, time.UTC),
+	})
+	createBook(t, router, &model.CreateBookRequest{
+		Title:    "Book Title 1",
+		ISBN:     "978-1451673319",
+		AuthorID: 1,
+	})
+
+	get := func(url string, ifNoneMatch string) *httptest.ResponseRecorder {
+		httpReq, err := http.NewRequest(http.MethodGet, url, nil)
+		assert.NoError(t, err)
+		if ifNoneMatch != "" {
+			httpReq.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+		return testRec
+	}
+
+	testRec := get("/books/1", "")
+	assert.EqualValues(t, http.StatusOK, testRec.Code)
+	assert.EqualValues(t, "private, no-cache", testRec.Header().Get("Cache-Control"))
+	etag := testRec.Header().Get("ETag")
+
+	t.Run("Positive Case 1 - not modified", func(t *testing.T) {
+		testRec := get("/books/1", etag)
+		assert.EqualValues(t, http.StatusNotModified, testRec.Code)
+		assert.Empty(t, testRec.Body.String())
+		assert.EqualValues(t, etag, testRec.Header().Get("ETag"))
+
+		assert.EqualValues(t, http.StatusNotModified, get("/books/1", `"other", W/`+etag).Code)
+	})
+
+	t.Run("Negative Case 1 - modified", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusOK, get("/books/1", `"other"`).Code)
+	})
+
+	t.Run("Positive Case 2 - page not modified until a book is added", func(t *testing.T) {
+		testRec := get("/books", "")
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+		assert.EqualValues(t, "private, max-age=60", testRec.Header().Get("Cache-Control"))
+		pageETag := testRec.Header().Get("ETag")
+
+		assert.EqualValues(t, http.StatusNotModified, get("/books", pageETag).Code)
+
+		createBook(t, router, &model.CreateBookRequest{
+			Title:    "Book Title 2",
+			ISBN:     "978-1503290563",
+			AuthorID: 1,
+		})
+		assert.EqualValues(t, http.StatusOK, get("/books", pageETag).Code)
+	})
+}
@@ -0,0 +1,104 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnaufalhilmym/bookshelf/internal/delivery/http/handler"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+func newReadingGoalAndChallengeRouter(t *testing.T) *gin.Engine {
+	db := newDatabase()
+	userRepo := repository.NewUserRepository()
+	repository.NewAuthorRepository()
+	repository.NewBookRepository()
+	progressRepo := repository.NewReadingProgressRepository()
+	goalRepo := repository.NewReadingGoalRepository()
+	challengeRepo := repository.NewChallengeRepository()
+	participantRepo := repository.NewChallengeParticipantRepository()
+	userUc := usecase.NewUserUsecase(db, userRepo, "jwtKey", 10*time.Second)
+	goalHandler := handler.NewReadingGoalHandler(usecase.NewReadingGoalUsecase(db, goalRepo, progressRepo))
+	challengeHandler := handler.NewChallengeHandler(usecase.NewChallengeUsecase(db, challengeRepo, participantRepo, progressRepo))
+
+	user, err := userUc.Register(context.Background(), &model.RegisterUserRequest{
+		Username: "reader",
+		Password: "password",
+	})
+	assert.NoError(t, err)
+
+	router := gin.Default()
+	router.Use(func(ctx *gin.Context) {
+		ctx.Set(model.UserContextKey, user)
+	})
+
+	router.PUT("/me/goals/:year", goalHandler.Upsert)
+	router.GET("/me/goals/:year", goalHandler.Get)
+	router.POST("/challenges", challengeHandler.Create)
+	router.POST("/challenges/:id/join", challengeHandler.Join)
+	router.GET("/challenges/:id/leaderboard", challengeHandler.GetLeaderboard)
+
+	return router
+}
+
+func TestChallengeHandler_GetLeaderboard(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := newReadingGoalAndChallengeRouter(t)
+
+	reqBody, err := json.Marshal(model.CreateChallengeRequest{
+		Name:        "Read 5 books",
+		StartAt:     time.Now().UTC().AddDate(0, -1, 0),
+		EndAt:       time.Now().UTC().AddDate(0, 1, 0),
+		TargetBooks: 5,
+	})
+	assert.NoError(t, err)
+	httpReq, err := http.NewRequest(http.MethodPost, "/challenges", bytes.NewReader(reqBody))
+	assert.NoError(t, err)
+	httpReq.Header.Set("Content-Type", "application/json")
+	testRec := httptest.NewRecorder()
+	router.ServeHTTP(testRec, httpReq)
+	assert.EqualValues(t, http.StatusCreated, testRec.Code)
+
+	httpReq, err = http.NewRequest(http.MethodPost, "/challenges/1/join", nil)
+	assert.NoError(t, err)
+	testRec = httptest.NewRecorder()
+	router.ServeHTTP(testRec, httpReq)
+	assert.EqualValues(t, http.StatusCreated, testRec.Code)
+
+	t.Run("Positive Case - get leaderboard", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodGet, "/challenges/1/leaderboard", nil)
+		assert.NoError(t, err)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+
+		res := new(model.Response[[]model.ChallengeLeaderboardEntryResponse])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+
+		assert.EqualValues(t, []model.ChallengeLeaderboardEntryResponse{
+			{Rank: 1, UserID: 1, Username: "reader"},
+		}, res.Data)
+	})
+
+	t.Run("Negative Case - challenge not found", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodGet, "/challenges/9/leaderboard", nil)
+		assert.NoError(t, err)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusNotFound, testRec.Code)
+	})
+}
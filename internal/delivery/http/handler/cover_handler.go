@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+	"github.com/mnaufalhilmym/gotracing"
+)
+
+// maxCoverRequestSize leaves room for the multipart framing around the image.
+const maxCoverRequestSize = usecase.MaxCoverSize + 64<<10
+
+type CoverHandler struct {
+	usecase *usecase.CoverUsecase
+}
+
+func NewCoverHandler(uc *usecase.CoverUsecase) *CoverHandler {
+	return &CoverHandler{uc}
+}
+
+// Get serves the cover with http.ServeContent, which answers conditional
+// requests against the ETag. Versioned URLs never change content, so they
+// are cached for a year; the rest are revalidated on every use.
+func (h *CoverHandler) Get(ctx *gin.Context) {
+	request := new(model.GetBookCoverRequest)
+	if err := ctx.ShouldBindUri(request); err != nil {
+		gotracing.Error("Failed to parse request", err)
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			model.ResponseError(ctx, model.ErrorBadRequest(fmt.Errorf("validation error in field %s", errs[0].Field())))
+			return
+		}
+		model.ResponseError(ctx, model.ErrorBadRequest(errors.New("failed to parse request")))
+		return
+	}
+	if err := ctx.ShouldBindQuery(request); err != nil {
+		gotracing.Error("Failed to parse request", err)
+		model.ResponseError(ctx, model.ErrorBadRequest(errors.New("failed to parse request")))
+		return
+	}
+
+	cover, content, err := h.usecase.Open(ctx, request)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+	defer content.Close()
+
+	ctx.Header("Content-Type", cover.ContentType)
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	if cover.ETag != "" {
+		ctx.Header("ETag", cover.ETag)
+	}
+	if cover.Immutable {
+		ctx.Header("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		ctx.Header("Cache-Control", "public, no-cache")
+	}
+	http.ServeContent(ctx.Writer, ctx.Request, "", time.Time{}, content)
+}
+
+func (h *CoverHandler) Upload(ctx *gin.Context) {
+	request := new(model.UploadBookCoverRequest)
+	if err := ctx.ShouldBindUri(request); err != nil {
+		gotracing.Error("Failed to parse request", err)
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			model.ResponseError(ctx, model.ErrorBadRequest(fmt.Errorf("validation error in field %s", errs[0].Field())))
+			return
+		}
+		model.ResponseError(ctx, model.ErrorBadRequest(errors.New("failed to parse request")))
+		return
+	}
+
+	if ctx.Request.ContentLength > maxCoverRequestSize {
+		model.ResponseError(ctx, model.ErrorRequestEntityTooLarge(
+			fmt.Errorf("cover must not be larger than %d MiB", usecase.MaxCoverSize>>20),
+		))
+		return
+	}
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxCoverRequestSize)
+
+	content, err := readUploadedFile(ctx, "file")
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+	request.Content = content
+
+	response, err := h.usecase.Upload(ctx, request)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	model.ResponseOK(ctx, response)
+}
+
+func (h *CoverHandler) Delete(ctx *gin.Context) {
+	request := new(model.DeleteBookCoverRequest)
+	if err := ctx.ShouldBindUri(request); err != nil {
+		gotracing.Error("Failed to parse request", err)
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			model.ResponseError(ctx, model.ErrorBadRequest(fmt.Errorf("validation error in field %s", errs[0].Field())))
+			return
+		}
+		model.ResponseError(ctx, model.ErrorBadRequest(errors.New("failed to parse request")))
+		return
+	}
+
+	response, err := h.usecase.Delete(ctx, request)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	model.ResponseOK(ctx, response)
+}
@@ -0,0 +1,151 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func newPNG(t *testing.T, width int, height int) string {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.RGBA{R: 40, G: 40, B: 200, A: 255}), image.Point{}, draw.Src)
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, png.Encode(buf, img))
+	return buf.String()
+}
+
+func newCoverUploadRequest(t *testing.T, url string, filename string, content string) *http.Request {
+	httpReq := newUploadRequest(t, url, "file", filename, content)
+	httpReq.Method = http.MethodPut
+	return httpReq
+}
+
+func TestCoverHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := newBookFileRouter(t)
+	cover := newPNG(t, 200, 300)
+
+	var book model.BookResponse
+
+	t.Run("Positive Case - upload cover", func(t *testing.T) {
+		// The type is sniffed from the content, not taken from the name.
+		httpReq := newCoverUploadRequest(t, "/books/1/cover", "cover.jpg", cover)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+
+		res := new(model.Response[model.BookResponse])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+
+		assert.NotNil(t, res.Data.Cover)
+		assert.True(t, strings.HasPrefix(res.Data.Cover.Small, "/books/1/cover/small?v="))
+
+		book = res.Data
+	})
+
+	t.Run("Positive Case - versioned url is cached indefinitely", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodGet, book.Cover.Original, nil)
+		assert.NoError(t, err)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+		assert.EqualValues(t, "image/png", testRec.Header().Get("Content-Type"))
+		assert.EqualValues(t, "public, max-age=31536000, immutable", testRec.Header().Get("Cache-Control"))
+		assert.NotEmpty(t, testRec.Header().Get("ETag"))
+		assert.EqualValues(t, cover, testRec.Body.String())
+	})
+
+	t.Run("Positive Case - unversioned thumbnail is revalidated", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodGet, "/books/1/cover/medium", nil)
+		assert.NoError(t, err)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+		assert.EqualValues(t, "image/jpeg", testRec.Header().Get("Content-Type"))
+		assert.EqualValues(t, "public, no-cache", testRec.Header().Get("Cache-Control"))
+
+		httpReq.Header.Set("If-None-Match", testRec.Header().Get("ETag"))
+
+		testRec = httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusNotModified, testRec.Code)
+	})
+
+	t.Run("Positive Case - book includes cover urls", func(t *testing.T) {
+		httpReq := newUploadRequest(t, "/books/1/files", "file", "book.pdf", pdfDocument)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		res := new(model.Response[model.UploadBookFileResponse])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+
+		assert.EqualValues(t, book.Cover, res.Data.Book.Cover)
+	})
+
+	t.Run("Negative Case - unknown size", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodGet, "/books/1/cover/huge", nil)
+		assert.NoError(t, err)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusBadRequest, testRec.Code)
+	})
+
+	t.Run("Negative Case - not an image", func(t *testing.T) {
+		httpReq := newCoverUploadRequest(t, "/books/1/cover", "cover.png", pdfDocument)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusUnsupportedMediaType, testRec.Code)
+	})
+
+	t.Run("Negative Case - too large", func(t *testing.T) {
+		httpReq := newCoverUploadRequest(t, "/books/1/cover", "cover.png", cover+strings.Repeat("\x00", 6<<20))
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusRequestEntityTooLarge, testRec.Code)
+	})
+
+	t.Run("Positive Case - delete cover", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodDelete, "/books/1/cover", nil)
+		assert.NoError(t, err)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+
+		httpReq, err = http.NewRequest(http.MethodGet, "/books/1/cover", nil)
+		assert.NoError(t, err)
+
+		testRec = httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusNotFound, testRec.Code)
+	})
+}
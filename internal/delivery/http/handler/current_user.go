@@ -0,0 +1,16 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+)
+
+func currentUser(ctx *gin.Context) (*model.UserResponse, error) {
+	user, ok := ctx.Value(model.UserContextKey).(*model.UserResponse)
+	if !ok {
+		return nil, model.ErrorUnauthorized(errors.New("user is not authenticated"))
+	}
+	return user, nil
+}
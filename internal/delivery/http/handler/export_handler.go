@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+	"github.com/mnaufalhilmym/gotracing"
+)
+
+var exportContentTypes = map[string]struct {
+	contentType string
+	extension   string
+}{
+	model.ExportFormatCSV:     {"text/csv; charset=utf-8", ".csv"},
+	model.ExportFormatNDJSON:  {"application/x-ndjson", ".ndjson"},
+	model.ExportFormatBibTeX:  {"application/x-bibtex; charset=utf-8", ".bib"},
+	model.ExportFormatMARC:    {"application/marc", ".mrc"},
+	model.ExportFormatMARCXML: {"application/marcxml+xml", ".xml"},
+}
+
+type ExportHandler struct {
+	usecase *usecase.ExportUsecase
+}
+
+func NewExportHandler(uc *usecase.ExportUsecase) *ExportHandler {
+	return &ExportHandler{uc}
+}
+
+func (h *ExportHandler) ExportBooks(ctx *gin.Context) {
+	request := new(model.ExportBooksRequest)
+	if err := ctx.ShouldBindQuery(request); err != nil {
+		gotracing.Error("Failed to parse request", err)
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			model.ResponseError(ctx, model.ErrorBadRequest(fmt.Errorf("validation error in field %s", errs[0].Field())))
+			return
+		}
+		model.ResponseError(ctx, model.ErrorBadRequest(errors.New("failed to parse request")))
+		return
+	}
+
+	export := exportContentTypes[request.Format]
+	ctx.Header("Content-Type", export.contentType)
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="books%s"`, export.extension))
+	ctx.Status(http.StatusOK)
+
+	if err := h.usecase.ExportBooks(ctx, request, ctx.Writer); err != nil {
+		// Once the first batch is sent the status can no longer change, so
+		// the stream is cut short and the client sees a truncated body.
+		if ctx.Writer.Written() {
+			ctx.Abort()
+			return
+		}
+		ctx.Writer.Header().Del("Content-Disposition")
+		model.ResponseError(ctx, err)
+	}
+}
+
+// ExportBook returns a handler that exports a single book in the given
+// format, for routes such as GET /books/{id}.mrc.
+func (h *ExportHandler) ExportBook(format string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		request := &model.ExportBookRequest{Format: format}
+		if err := ctx.ShouldBindUri(request); err != nil {
+			gotracing.Error("Failed to parse request", err)
+			if errs, ok := err.(validator.ValidationErrors); ok {
+				model.ResponseError(ctx, model.ErrorBadRequest(fmt.Errorf("validation error in field %s", errs[0].Field())))
+				return
+			}
+			model.ResponseError(ctx, model.ErrorBadRequest(errors.New("failed to parse request")))
+			return
+		}
+
+		buf := new(bytes.Buffer)
+		if err := h.usecase.ExportBook(ctx, request, buf); err != nil {
+			model.ResponseError(ctx, err)
+			return
+		}
+
+		export := exportContentTypes[format]
+		ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="book-%d%s"`, request.ID, export.extension))
+		ctx.Data(http.StatusOK, export.contentType, buf.Bytes())
+	}
+}
@@ -0,0 +1,189 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnaufalhilmym/bookshelf/internal/delivery/http/handler"
+	"github.com/mnaufalhilmym/bookshelf/internal/format/marc"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+func newExportRouter(t *testing.T) *gin.Engine {
+	db := newDatabase()
+	authorRepo := repository.NewAuthorRepository()
+	bookRepo := repository.NewBookRepository()
+	authorHandler := handler.NewAuthorHandler(usecase.NewAuthorUsecase(db, authorRepo, bookRepo, repository.NewRevisionRepository()))
+	bookHandler := handler.NewBookHandler(usecase.NewBookUsecase(db, bookRepo, authorRepo, repository.NewRevisionRepository()))
+	exportHandler := handler.NewExportHandler(usecase.NewExportUsecase(db, bookRepo))
+
+	router := gin.Default()
+
+	router.POST("/authors", authorHandler.Create)
+	router.POST("/books", bookHandler.Create)
+	router.GET("/export/books", exportHandler.ExportBooks)
+
+	createAuthor(t, router, &model.CreateAuthorRequest{
+		Name:      "Author Name 1",
+		Birthdate: time.Date(2011, 11, 11, 11, 11, 11, 111, time.UTC),
+	})
+	createBook(t, router, &model.CreateBookRequest{
+		Title:    "Book Title 1",
+		ISBN:     "978-1451673319",
+		AuthorID: 1,
+	})
+	createBook(t, router, &model.CreateBookRequest{
+		Title:    "Book Title 2",
+		ISBN:     "978-0743273565",
+		AuthorID: 1,
+	})
+
+	return router
+}
+
+func TestExportHandler_ExportBooks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := newExportRouter(t)
+
+	t.Run("Positive Case - export csv", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodGet, "/export/books?format=csv&title=title+2", nil)
+		assert.NoError(t, err)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+		assert.EqualValues(t, "text/csv; charset=utf-8", testRec.Header().Get("Content-Type"))
+		assert.EqualValues(t, `attachment; filename="books.csv"`, testRec.Header().Get("Content-Disposition"))
+
+		lines := strings.Split(strings.TrimSpace(testRec.Body.String()), "\n")
+		assert.Len(t, lines, 2)
+		assert.EqualValues(t, "2,Book Title 2,978-0743273565,Author Name 1,2011-11-11,0,1,0,0", lines[1])
+	})
+
+	t.Run("Positive Case - export ndjson", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodGet, "/export/books?format=ndjson", nil)
+		assert.NoError(t, err)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+		assert.EqualValues(t, "application/x-ndjson", testRec.Header().Get("Content-Type"))
+
+		lines := strings.Split(strings.TrimSpace(testRec.Body.String()), "\n")
+		assert.Len(t, lines, 2)
+
+		book := new(model.ExportBookResponse)
+		assert.NoError(t, json.Unmarshal([]byte(lines[0]), book))
+		assert.EqualValues(t, "Book Title 1", book.Title)
+		assert.EqualValues(t, "Author Name 1", book.Author.Name)
+	})
+
+	t.Run("Negative Case - invalid format", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodGet, "/export/books?format=xml", nil)
+		assert.NoError(t, err)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusBadRequest, testRec.Code)
+
+		res := new(model.Response[any])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+
+		assert.EqualValues(t, "validation error in field Format", res.Error)
+	})
+}
+
+func TestExportHandler_ExportBook(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db := newDatabase()
+	authorRepo := repository.NewAuthorRepository()
+	bookRepo := repository.NewBookRepository()
+	authorHandler := handler.NewAuthorHandler(usecase.NewAuthorUsecase(db, authorRepo, bookRepo, repository.NewRevisionRepository()))
+	bookHandler := handler.NewBookHandler(usecase.NewBookUsecase(db, bookRepo, authorRepo, repository.NewRevisionRepository()))
+	exportHandler := handler.NewExportHandler(usecase.NewExportUsecase(db, bookRepo))
+
+	router := gin.Default()
+
+	router.POST("/authors", authorHandler.Create)
+	router.POST("/books", bookHandler.Create)
+	router.GET("/books/:id", handler.ByExtension("id", bookHandler.Get, map[string]gin.HandlerFunc{
+		".mrc": exportHandler.ExportBook(model.ExportFormatMARC),
+		".xml": exportHandler.ExportBook(model.ExportFormatMARCXML),
+	}))
+
+	createAuthor(t, router, &model.CreateAuthorRequest{
+		Name:      "Author Name 1",
+		Birthdate: time.Date(2011, 11, 11, 11, 11, 11, 111, time.UTC),
+	})
+	createBook(t, router, &model.CreateBookRequest{
+		Title:    "Book Title 1",
+		ISBN:     "978-1451673319",
+		AuthorID: 1,
+	})
+
+	t.Run("Positive Case - export marc", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodGet, "/books/1.mrc", nil)
+		assert.NoError(t, err)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+		assert.EqualValues(t, "application/marc", testRec.Header().Get("Content-Type"))
+		assert.EqualValues(t, `attachment; filename="book-1.mrc"`, testRec.Header().Get("Content-Disposition"))
+
+		record, err := marc.NewReader(testRec.Body).Read()
+		assert.NoError(t, err)
+		assert.EqualValues(t, "Book Title 1 /", record.Subfield("245", 'a'))
+	})
+
+	t.Run("Positive Case - export marcxml", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodGet, "/books/1.xml", nil)
+		assert.NoError(t, err)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+		assert.EqualValues(t, "application/marcxml+xml", testRec.Header().Get("Content-Type"))
+		assert.Contains(t, testRec.Body.String(), `<subfield code="a">978-1451673319</subfield>`)
+	})
+
+	t.Run("Positive Case - plain get still works", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodGet, "/books/1", nil)
+		assert.NoError(t, err)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+
+		res := new(model.Response[model.BookResponse])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+
+		assert.EqualValues(t, "Book Title 1", res.Data.Title)
+	})
+
+	t.Run("Negative Case - book not found", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodGet, "/books/2.mrc", nil)
+		assert.NoError(t, err)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusNotFound, testRec.Code)
+	})
+}
@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ByExtension dispatches on a file extension at the end of a path parameter,
+// e.g. GET /books/1.mrc, because the router cannot register /books/:id.mrc
+// next to /books/:id. The extension is stripped from the parameter before the
+// matching handler runs; without a known extension fallback handles the
+// request.
+func ByExtension(param string, fallback gin.HandlerFunc, handlers map[string]gin.HandlerFunc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		value := ctx.Param(param)
+		for extension, handler := range handlers {
+			if id, ok := strings.CutSuffix(value, extension); ok && id != "" {
+				for i := range ctx.Params {
+					if ctx.Params[i].Key == param {
+						ctx.Params[i].Value = id
+					}
+				}
+				handler(ctx)
+				return
+			}
+		}
+		fallback(ctx)
+	}
+}
@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+	"github.com/mnaufalhilmym/gotracing"
+)
+
+type ImportHandler struct {
+	usecase *usecase.ImportUsecase
+}
+
+func NewImportHandler(uc *usecase.ImportUsecase) *ImportHandler {
+	return &ImportHandler{uc}
+}
+
+func (h *ImportHandler) ImportKindle(ctx *gin.Context) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	request := new(model.ImportKindleRequest)
+	if err := ctx.ShouldBindQuery(request); err != nil {
+		gotracing.Error("Failed to parse request", err)
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			model.ResponseError(ctx, model.ErrorBadRequest(fmt.Errorf("validation error in field %s", errs[0].Field())))
+			return
+		}
+		model.ResponseError(ctx, model.ErrorBadRequest(errors.New("failed to parse request")))
+		return
+	}
+	request.UserID = user.ID
+
+	content, err := readUploadedFile(ctx, "file")
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+	request.Content = content
+
+	response, err := h.usecase.ImportKindle(ctx, request)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	if request.DryRun {
+		model.ResponseOK(ctx, response)
+		return
+	}
+	model.ResponseCreated(ctx, response)
+}
+
+func (h *ImportHandler) ImportCSV(ctx *gin.Context) {
+	request := new(model.ImportBooksRequest)
+	if err := ctx.ShouldBindQuery(request); err != nil {
+		gotracing.Error("Failed to parse request", err)
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			model.ResponseError(ctx, model.ErrorBadRequest(fmt.Errorf("validation error in field %s", errs[0].Field())))
+			return
+		}
+		model.ResponseError(ctx, model.ErrorBadRequest(errors.New("failed to parse request")))
+		return
+	}
+
+	content, err := readUploadedFile(ctx, "file")
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+	request.Content = content
+
+	response, err := h.usecase.ImportCSV(ctx, request)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	if response.Committed {
+		model.ResponseCreated(ctx, response)
+		return
+	}
+	model.ResponseOK(ctx, response)
+}
+
+func (h *ImportHandler) ImportMARC(ctx *gin.Context) {
+	request := new(model.ImportBooksRequest)
+	if err := ctx.ShouldBindQuery(request); err != nil {
+		gotracing.Error("Failed to parse request", err)
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			model.ResponseError(ctx, model.ErrorBadRequest(fmt.Errorf("validation error in field %s", errs[0].Field())))
+			return
+		}
+		model.ResponseError(ctx, model.ErrorBadRequest(errors.New("failed to parse request")))
+		return
+	}
+
+	content, err := readUploadedFile(ctx, "file")
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+	request.Content = content
+
+	response, err := h.usecase.ImportMARC(ctx, request)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	if response.Committed {
+		model.ResponseCreated(ctx, response)
+		return
+	}
+	model.ResponseOK(ctx, response)
+}
+
+func (h *ImportHandler) ImportCalibre(ctx *gin.Context) {
+	request := new(model.ImportCalibreRequest)
+	if err := ctx.ShouldBindQuery(request); err != nil {
+		gotracing.Error("Failed to parse request", err)
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			model.ResponseError(ctx, model.ErrorBadRequest(fmt.Errorf("validation error in field %s", errs[0].Field())))
+			return
+		}
+		model.ResponseError(ctx, model.ErrorBadRequest(errors.New("failed to parse request")))
+		return
+	}
+
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		gotracing.Error("Failed to parse request", err)
+		model.ResponseError(ctx, model.ErrorBadRequest(errors.New("file is required")))
+		return
+	}
+
+	// SQLite can only open a database from a file, so the upload is stored
+	// in a temporary directory for the duration of the import.
+	dir, err := os.MkdirTemp("", "bookshelf-calibre-")
+	if err != nil {
+		gotracing.Error("Failed to create temporary directory", err)
+		model.ResponseError(ctx, model.ErrorInternalServerError(errors.New("failed to store uploaded file")))
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	request.Path = filepath.Join(dir, "metadata.db")
+	if err := ctx.SaveUploadedFile(header, request.Path); err != nil {
+		gotracing.Error("Failed to store uploaded file", err)
+		model.ResponseError(ctx, model.ErrorInternalServerError(errors.New("failed to store uploaded file")))
+		return
+	}
+
+	response, err := h.usecase.ImportCalibre(ctx, request)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	if response.Committed {
+		model.ResponseCreated(ctx, response)
+		return
+	}
+	model.ResponseOK(ctx, response)
+}
+
+func readUploadedFile(ctx *gin.Context, field string) ([]byte, error) {
+	_, content, err := readUploadedNamedFile(ctx, field)
+	return content, err
+}
+
+func readUploadedNamedFile(ctx *gin.Context, field string) (string, []byte, error) {
+	header, err := ctx.FormFile(field)
+	if err != nil {
+		gotracing.Error("Failed to parse request", err)
+		return "", nil, model.ErrorBadRequest(fmt.Errorf("%s is required", field))
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		gotracing.Error("Failed to open uploaded file", err)
+		return "", nil, model.ErrorBadRequest(errors.New("failed to read uploaded file"))
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		gotracing.Error("Failed to read uploaded file", err)
+		return "", nil, model.ErrorBadRequest(errors.New("failed to read uploaded file"))
+	}
+
+	return header.Filename, content, nil
+}
@@ -0,0 +1,300 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnaufalhilmym/bookshelf/internal/config"
+	"github.com/mnaufalhilmym/bookshelf/internal/delivery/http/handler"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+const kindleClippings = "Book Title 1 (Name 1, Author)\n" +
+	"- Your Highlight on page 5 | Location 70-72 | Added on Monday, 3 June 2024 21:04:11\n" +
+	"\n" +
+	"A highlighted sentence.\n" +
+	"==========\n" +
+	"Unknown Title (Someone Else)\n" +
+	"- Your Highlight on page 1 | Location 10-11 | Added on Monday, 3 June 2024 21:05:00\n" +
+	"\n" +
+	"Another highlighted sentence.\n" +
+	"==========\n"
+
+func newImportRouter(t *testing.T) *gin.Engine {
+	db := newDatabase()
+	userRepo := repository.NewUserRepository()
+	authorRepo := repository.NewAuthorRepository()
+	bookRepo := repository.NewBookRepository()
+	annotationRepo := repository.NewAnnotationRepository()
+	userUc := usecase.NewUserUsecase(db, userRepo, "jwtKey", 10*time.Second)
+	authorHandler := handler.NewAuthorHandler(usecase.NewAuthorUsecase(db, authorRepo, bookRepo, repository.NewRevisionRepository()))
+	bookHandler := handler.NewBookHandler(usecase.NewBookUsecase(db, bookRepo, authorRepo, repository.NewRevisionRepository()))
+	importHandler := handler.NewImportHandler(usecase.NewImportUsecase(db, authorRepo, bookRepo, annotationRepo))
+
+	user, err := userUc.Register(context.Background(), &model.RegisterUserRequest{
+		Username: "reader",
+		Password: "password",
+	})
+	assert.NoError(t, err)
+
+	router := gin.Default()
+	router.Use(func(ctx *gin.Context) {
+		ctx.Set(model.UserContextKey, user)
+	})
+
+	router.POST("/authors", authorHandler.Create)
+	router.POST("/books", bookHandler.Create)
+	router.POST("/import/kindle", importHandler.ImportKindle)
+	router.POST("/import/csv", importHandler.ImportCSV)
+	router.POST("/import/marc", importHandler.ImportMARC)
+	router.POST("/import/calibre", importHandler.ImportCalibre)
+
+	createAuthor(t, router, &model.CreateAuthorRequest{
+		Name:      "Author Name 1",
+		Birthdate: time.Date(2011, 11, 11, 11, 11, 11, 111, time.UTC),
+	})
+	createBook(t, router, &model.CreateBookRequest{
+		Title:    "Book Title 1",
+		ISBN:     "978-1451673319",
+		AuthorID: 1,
+	})
+
+	return router
+}
+
+func newUploadRequest(t *testing.T, url string, field string, filename string, content string) *http.Request {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	if field != "" {
+		part, err := writer.CreateFormFile(field, filename)
+		assert.NoError(t, err)
+		_, err = part.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, writer.Close())
+
+	httpReq, err := http.NewRequest(http.MethodPost, url, body)
+	assert.NoError(t, err)
+	httpReq.Header.Set("Content-Type", writer.FormDataContentType())
+	return httpReq
+}
+
+func TestImportHandler_ImportKindle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := newImportRouter(t)
+
+	t.Run("Positive Case - dry run", func(t *testing.T) {
+		httpReq := newUploadRequest(t, "/import/kindle?dry_run=true", "file", "My Clippings.txt", kindleClippings)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+
+		res := new(model.Response[model.ImportKindleResponse])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+
+		assert.True(t, res.Data.DryRun)
+		assert.EqualValues(t, 1, res.Data.Imported)
+		assert.EqualValues(t, 1, res.Data.Unmatched)
+	})
+
+	t.Run("Positive Case - import creating missing books", func(t *testing.T) {
+		httpReq := newUploadRequest(t, "/import/kindle?create_missing=true", "file", "My Clippings.txt", kindleClippings)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusCreated, testRec.Code)
+
+		res := new(model.Response[model.ImportKindleResponse])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+
+		assert.EqualValues(t, 2, res.Data.Imported)
+		assert.EqualValues(t, 1, res.Data.CreatedBooks)
+		assert.EqualValues(t, 1, res.Data.CreatedAuthors)
+		assert.NotNil(t, res.Data.Items[0].AnnotationID)
+	})
+
+	t.Run("Negative Case - missing file", func(t *testing.T) {
+		httpReq := newUploadRequest(t, "/import/kindle", "", "", "")
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusBadRequest, testRec.Code)
+
+		res := new(model.Response[model.ImportKindleResponse])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+
+		assert.EqualValues(t, "file is required", res.Error)
+	})
+}
+
+func TestImportHandler_ImportCSV(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := newImportRouter(t)
+
+	content := "title,isbn,author,page_count\n" +
+		"Book Title 1,978-1451673319,Author Name 1,320\n" +
+		"Book Title 2,978-0743273565,Author Name 2,\n" +
+		"Book Title 3,,Author Name 2,\n"
+
+	t.Run("Positive Case - transaction mode reports without committing", func(t *testing.T) {
+		httpReq := newUploadRequest(t, "/import/csv", "file", "books.csv", content)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+
+		res := new(model.Response[model.ImportBooksResponse])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+
+		assert.False(t, res.Data.Committed)
+		assert.EqualValues(t, 1, res.Data.Updated)
+		assert.EqualValues(t, 1, res.Data.Created)
+		assert.EqualValues(t, 1, res.Data.Failed)
+		assert.EqualValues(t, "isbn is required", res.Data.Rows[2].Reason)
+	})
+
+	t.Run("Positive Case - per row mode", func(t *testing.T) {
+		httpReq := newUploadRequest(t, "/import/csv?mode=per_row", "file", "books.csv", content)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusCreated, testRec.Code)
+
+		res := new(model.Response[model.ImportBooksResponse])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+
+		assert.True(t, res.Data.Committed)
+		assert.EqualValues(t, 1, res.Data.Created)
+		assert.EqualValues(t, 1, res.Data.CreatedAuthors)
+	})
+
+	t.Run("Negative Case - invalid mode", func(t *testing.T) {
+		httpReq := newUploadRequest(t, "/import/csv?mode=all", "file", "books.csv", "title,isbn,author\n")
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusBadRequest, testRec.Code)
+
+		res := new(model.Response[model.ImportBooksResponse])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+
+		assert.EqualValues(t, "validation error in field Mode", res.Error)
+	})
+}
+
+func TestImportHandler_ImportMARC(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := newImportRouter(t)
+
+	t.Run("Positive Case - import marcxml", func(t *testing.T) {
+		content := `<collection xmlns="http://www.loc.gov/MARC21/slim"><record>
+  <leader>00000nam a2200000 i 4500</leader>
+  <datafield tag="020" ind1=" " ind2=" "><subfield code="a">9780060850524 (paperback)</subfield></datafield>
+  <datafield tag="100" ind1="1" ind2=" "><subfield code="a">Huxley, Aldous,</subfield></datafield>
+  <datafield tag="245" ind1="1" ind2="0"><subfield code="a">Brave new world /</subfield></datafield>
+  <datafield tag="300" ind1=" " ind2=" "><subfield code="a">288 p. ;</subfield></datafield>
+</record></collection>`
+		httpReq := newUploadRequest(t, "/import/marc", "file", "books.xml", content)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusCreated, testRec.Code)
+
+		res := new(model.Response[model.ImportBooksResponse])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+
+		assert.EqualValues(t, 1, res.Data.Created)
+		assert.EqualValues(t, "9780060850524", res.Data.Rows[0].ISBN)
+		assert.EqualValues(t, "Brave new world", res.Data.Rows[0].Title)
+	})
+
+	t.Run("Negative Case - empty file", func(t *testing.T) {
+		httpReq := newUploadRequest(t, "/import/marc", "file", "books.mrc", "")
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusBadRequest, testRec.Code)
+
+		res := new(model.Response[model.ImportBooksResponse])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+
+		assert.EqualValues(t, "invalid marc file: no records found", res.Error)
+	})
+}
+
+func TestImportHandler_ImportCalibre(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := newImportRouter(t)
+
+	path := filepath.Join(t.TempDir(), "metadata.db")
+	library := config.NewDatabase("sqlite", path, 1, 1, 100, config.SQLiteOptions{})
+	for _, statement := range []string{
+		"CREATE TABLE books (id INTEGER PRIMARY KEY, title TEXT NOT NULL, isbn TEXT DEFAULT '', uuid TEXT, series_index REAL NOT NULL DEFAULT 1.0)",
+		"CREATE TABLE authors (id INTEGER PRIMARY KEY, name TEXT NOT NULL)",
+		"CREATE TABLE books_authors_link (id INTEGER PRIMARY KEY, book INTEGER NOT NULL, author INTEGER NOT NULL)",
+		"CREATE TABLE identifiers (id INTEGER PRIMARY KEY, book INTEGER NOT NULL, type TEXT NOT NULL, val TEXT NOT NULL)",
+		"INSERT INTO books (id, title) VALUES (1, 'Book Title 1 (Calibre)'), (2, 'Book Title 2')",
+		"INSERT INTO authors (id, name) VALUES (1, 'Author Name 1')",
+		"INSERT INTO books_authors_link (book, author) VALUES (1, 1), (2, 1)",
+		"INSERT INTO identifiers (book, type, val) VALUES (1, 'isbn', '9781451673319'), (2, 'isbn', '9780743273565')",
+	} {
+		assert.NoError(t, library.Exec(statement).Error)
+	}
+	connection, err := library.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, connection.Close())
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+
+	t.Run("Positive Case - dry run reports conflicts", func(t *testing.T) {
+		httpReq := newUploadRequest(t, "/import/calibre?dry_run=true", "file", "metadata.db", string(content))
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+
+		res := new(model.Response[model.ImportBooksResponse])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+
+		assert.EqualValues(t, 1, res.Data.Created)
+		assert.EqualValues(t, 1, res.Data.Conflicts)
+		assert.EqualValues(t, "title", res.Data.Rows[0].Conflicts[0].Field)
+		assert.EqualValues(t, "Book Title 1", res.Data.Rows[0].Conflicts[0].Current)
+	})
+
+	t.Run("Negative Case - not a database", func(t *testing.T) {
+		httpReq := newUploadRequest(t, "/import/calibre", "file", "metadata.db", "plain text")
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusBadRequest, testRec.Code)
+	})
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+	"github.com/mnaufalhilmym/gotracing"
+)
+
+type ImportJobHandler struct {
+	usecase *usecase.ImportJobUsecase
+}
+
+func NewImportJobHandler(uc *usecase.ImportJobUsecase) *ImportJobHandler {
+	return &ImportJobHandler{uc}
+}
+
+func (h *ImportJobHandler) ImportGoodreads(ctx *gin.Context) {
+	h.start(ctx, h.usecase.ImportGoodreads)
+}
+
+func (h *ImportJobHandler) ImportStoryGraph(ctx *gin.Context) {
+	h.start(ctx, h.usecase.ImportStoryGraph)
+}
+
+func (h *ImportJobHandler) Get(ctx *gin.Context) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	request := new(model.GetImportJobRequest)
+	if err := ctx.ShouldBindUri(request); err != nil {
+		gotracing.Error("Failed to parse request", err)
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			model.ResponseError(ctx, model.ErrorBadRequest(fmt.Errorf("validation error in field %s", errs[0].Field())))
+			return
+		}
+		model.ResponseError(ctx, model.ErrorBadRequest(errors.New("failed to parse request")))
+		return
+	}
+	request.UserID = user.ID
+
+	response, err := h.usecase.Get(ctx, request)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	model.LastModified(ctx, response.UpdatedAt)
+	model.ResponseOK(ctx, response)
+}
+
+func (h *ImportJobHandler) start(
+	ctx *gin.Context,
+	importFn func(context.Context, *model.ImportReadingHistoryRequest) (*model.ImportJobResponse, error),
+) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	content, err := readUploadedFile(ctx, "file")
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	response, err := importFn(ctx, &model.ImportReadingHistoryRequest{
+		UserID:  user.ID,
+		Content: content,
+	})
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	ctx.Header("Location", fmt.Sprintf("/import/jobs/%d", response.ID))
+	model.ResponseAccepted(ctx, response)
+}
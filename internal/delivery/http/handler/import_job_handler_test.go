@@ -0,0 +1,178 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnaufalhilmym/bookshelf/internal/delivery/http/handler"
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+const goodreadsExport = "Book Id,Title,Author,ISBN,ISBN13,My Rating,Number of Pages,Date Read,Bookshelves,Exclusive Shelf\n" +
+	`1,Book Title 1,Author Name 1,"=""1451673310""","=""9781451673319""",5,200,2024/03/15,,read` + "\n" +
+	`2,Dune,Frank Herbert,"=""0441172717""","=""9780441172719""",0,612,,,to-read` + "\n"
+
+func newImportJobRouter(t *testing.T) (*gin.Engine, *usecase.ImportJobUsecase) {
+	db := newDatabase()
+	userRepo := repository.NewUserRepository()
+	authorRepo := repository.NewAuthorRepository()
+	bookRepo := repository.NewBookRepository()
+	reviewRepo := repository.NewReviewRepository()
+	progressRepo := repository.NewReadingProgressRepository()
+	shelfEntryRepo := repository.NewShelfEntryRepository()
+	importJobRepo := repository.NewImportJobRepository()
+	userUc := usecase.NewUserUsecase(db, userRepo, "jwtKey", 10*time.Second)
+	importJobUc := usecase.NewImportJobUsecase(
+		db,
+		importJobRepo,
+		authorRepo,
+		bookRepo,
+		reviewRepo,
+		progressRepo,
+		shelfEntryRepo,
+	)
+	authorHandler := handler.NewAuthorHandler(usecase.NewAuthorUsecase(db, authorRepo, bookRepo, repository.NewRevisionRepository()))
+	bookHandler := handler.NewBookHandler(usecase.NewBookUsecase(db, bookRepo, authorRepo, repository.NewRevisionRepository()))
+	importJobHandler := handler.NewImportJobHandler(importJobUc)
+	shelfHandler := handler.NewShelfHandler(usecase.NewShelfUsecase(db, shelfEntryRepo))
+
+	user, err := userUc.Register(context.Background(), &model.RegisterUserRequest{
+		Username: "reader",
+		Password: "password",
+	})
+	assert.NoError(t, err)
+
+	router := gin.Default()
+	router.Use(func(ctx *gin.Context) {
+		ctx.Set(model.UserContextKey, user)
+	})
+
+	router.POST("/authors", authorHandler.Create)
+	router.POST("/books", bookHandler.Create)
+	router.POST("/import/goodreads", importJobHandler.ImportGoodreads)
+	router.POST("/import/storygraph", importJobHandler.ImportStoryGraph)
+	router.GET("/import/jobs/:id", importJobHandler.Get)
+	router.GET("/me/shelves", shelfHandler.GetMany)
+	router.GET("/me/shelves/:shelf", shelfHandler.Get)
+
+	createAuthor(t, router, &model.CreateAuthorRequest{
+		Name:      "Author Name 1",
+		Birthdate: time.Date(2011, 11, 11, 11, 11, 11, 111, time.UTC),
+	})
+	createBook(t, router, &model.CreateBookRequest{
+		Title:    "Book Title 1",
+		ISBN:     "978-1451673319",
+		AuthorID: 1,
+	})
+
+	return router, importJobUc
+}
+
+func TestImportJobHandler_ImportGoodreads(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router, importJobUc := newImportJobRouter(t)
+
+	t.Run("Positive Case - start job and poll it", func(t *testing.T) {
+		httpReq := newUploadRequest(t, "/import/goodreads", "file", "goodreads_library_export.csv", goodreadsExport)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusAccepted, testRec.Code)
+
+		res := new(model.Response[model.ImportJobResponse])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+
+		assert.EqualValues(t, model.ImportSourceGoodreads, res.Data.Source)
+		assert.EqualValues(t, 2, res.Data.Total)
+		assert.EqualValues(t, fmt.Sprintf("/import/jobs/%d", res.Data.ID), testRec.Header().Get("Location"))
+
+		importJobUc.Wait()
+
+		httpReq, err := http.NewRequest(http.MethodGet, testRec.Header().Get("Location"), nil)
+		assert.NoError(t, err)
+
+		testRec = httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+
+		res = new(model.Response[model.ImportJobResponse])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+
+		assert.EqualValues(t, entity.ImportJobStatusCompleted, res.Data.Status)
+		assert.EqualValues(t, 2, res.Data.Processed)
+		assert.EqualValues(t, 2, res.Data.Result.Imported)
+		assert.EqualValues(t, 1, res.Data.Result.CreatedBooks)
+	})
+
+	t.Run("Positive Case - list shelves", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodGet, "/me/shelves", nil)
+		assert.NoError(t, err)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+
+		res := new(model.Response[[]model.ShelfResponse])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+
+		assert.EqualValues(t, []model.ShelfResponse{
+			{Name: "read", BookCount: 1},
+			{Name: "to-read", BookCount: 1},
+		}, res.Data)
+	})
+
+	t.Run("Positive Case - list books on a shelf", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodGet, "/me/shelves/read", nil)
+		assert.NoError(t, err)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+
+		res := new(model.Response[[]model.ShelfEntryResponse])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+
+		assert.EqualValues(t, 1, res.Pagination.TotalItem)
+		assert.EqualValues(t, "Book Title 1", res.Data[0].Book.Title)
+		assert.EqualValues(t, 5, res.Data[0].Book.AverageRating)
+	})
+
+	t.Run("Negative Case - invalid export", func(t *testing.T) {
+		httpReq := newUploadRequest(t, "/import/goodreads", "file", "export.csv", "Title\nBook Title 1\n")
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusBadRequest, testRec.Code)
+
+		res := new(model.Response[model.ImportJobResponse])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+
+		assert.EqualValues(t, "invalid goodreads export: missing Author column", res.Error)
+	})
+
+	t.Run("Negative Case - job not found", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodGet, "/import/jobs/100", nil)
+		assert.NoError(t, err)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusNotFound, testRec.Code)
+	})
+}
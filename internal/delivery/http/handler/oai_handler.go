@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+	"github.com/mnaufalhilmym/gotracing"
+)
+
+type OAIHandler struct {
+	usecase *usecase.OAIUsecase
+}
+
+func NewOAIHandler(uc *usecase.OAIUsecase) *OAIHandler {
+	return &OAIHandler{uc}
+}
+
+// Handle answers OAI-PMH requests, which harvesters send as GET with a query
+// or as POST with a form.
+func (h *OAIHandler) Handle(ctx *gin.Context) {
+	if err := ctx.Request.ParseForm(); err != nil {
+		gotracing.Error("Failed to parse request", err)
+		model.ResponseError(ctx, model.ErrorBadRequest(errors.New("failed to parse request")))
+		return
+	}
+
+	response, err := h.usecase.Handle(ctx, &model.OAIRequest{
+		BaseURL:   baseURL(ctx) + ctx.Request.URL.Path,
+		Arguments: ctx.Request.Form,
+	})
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	ctx.Data(http.StatusOK, response.ContentType, response.Content)
+}
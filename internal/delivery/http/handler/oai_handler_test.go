@@ -0,0 +1,90 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnaufalhilmym/bookshelf/internal/delivery/http/handler"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+func newOAIRouter(t *testing.T) *gin.Engine {
+	db := newDatabase()
+	authorRepo := repository.NewAuthorRepository()
+	bookRepo := repository.NewBookRepository()
+	authorHandler := handler.NewAuthorHandler(usecase.NewAuthorUsecase(db, authorRepo, bookRepo, repository.NewRevisionRepository()))
+	bookHandler := handler.NewBookHandler(usecase.NewBookUsecase(db, bookRepo, authorRepo, repository.NewRevisionRepository()))
+	oaiHandler := handler.NewOAIHandler(usecase.NewOAIUsecase(db, bookRepo, authorRepo, "Bookshelf", "admin@example.com", ""))
+
+	router := gin.Default()
+
+	router.POST("/authors", authorHandler.Create)
+	router.POST("/books", bookHandler.Create)
+	router.GET("/oai", oaiHandler.Handle)
+	router.POST("/oai", oaiHandler.Handle)
+
+	createAuthor(t, router, &model.CreateAuthorRequest{
+		Name:      "Author Name 1",
+		Birthdate: time.Date(2011, 11, 11, 11, 11, 11, 111, time.UTC),
+	})
+	createBook(t, router, &model.CreateBookRequest{
+		Title:    "Book Title 1",
+		ISBN:     "978-1451673319",
+		AuthorID: 1,
+	})
+
+	return router
+}
+
+func TestOAIHandler_Handle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := newOAIRouter(t)
+
+	t.Run("Positive Case - get with query", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodGet, "/oai?verb=ListIdentifiers&metadataPrefix=oai_dc", nil)
+		assert.NoError(t, err)
+		httpReq.Host = "books.example.com:8080"
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+		assert.EqualValues(t, "text/xml; charset=utf-8", testRec.Header().Get("Content-Type"))
+		assert.True(t, strings.Contains(testRec.Body.String(), `>http://books.example.com:8080/oai</request>`))
+		assert.True(t, strings.Contains(testRec.Body.String(), `<identifier>oai:books.example.com:book/1</identifier>`))
+	})
+
+	t.Run("Positive Case - post with form", func(t *testing.T) {
+		form := url.Values{"verb": {"GetRecord"}, "metadataPrefix": {"oai_dc"}, "identifier": {"oai:example.com:book/1"}}
+		httpReq, err := http.NewRequest(http.MethodPost, "/oai", strings.NewReader(form.Encode()))
+		assert.NoError(t, err)
+		httpReq.Host = "example.com"
+		httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+		assert.True(t, strings.Contains(testRec.Body.String(), `<dc:title>Book Title 1</dc:title>`))
+	})
+
+	t.Run("Negative Case - bad verb", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodGet, "/oai?verb=Harvest", nil)
+		assert.NoError(t, err)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+		assert.True(t, strings.Contains(testRec.Body.String(), `<error code="badVerb">`))
+	})
+}
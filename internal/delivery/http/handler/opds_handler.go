@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+	"github.com/mnaufalhilmym/gotracing"
+)
+
+// OPDSHandler serves the catalog to e-reader apps. Each feed handler is
+// created for an OPDS version, so the same feeds can be routed for both.
+type OPDSHandler struct {
+	usecase *usecase.OPDSUsecase
+}
+
+func NewOPDSHandler(uc *usecase.OPDSUsecase) *OPDSHandler {
+	return &OPDSHandler{uc}
+}
+
+func (h *OPDSHandler) Root(version string) gin.HandlerFunc {
+	return h.feed(version, h.usecase.Root)
+}
+
+func (h *OPDSHandler) Newest(version string) gin.HandlerFunc {
+	return h.feed(version, h.usecase.Newest)
+}
+
+func (h *OPDSHandler) Authors(version string) gin.HandlerFunc {
+	return h.feed(version, h.usecase.Authors)
+}
+
+func (h *OPDSHandler) Series(version string) gin.HandlerFunc {
+	return h.feed(version, h.usecase.Series)
+}
+
+func (h *OPDSHandler) Author(version string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		request := &model.OPDSAuthorFeedRequest{Version: version}
+		if err := ctx.ShouldBindUri(request); err != nil {
+			opdsBindError(ctx, err)
+			return
+		}
+		if err := ctx.ShouldBindQuery(request); err != nil {
+			opdsBindError(ctx, err)
+			return
+		}
+
+		response, err := h.usecase.Author(ctx, request)
+		if err != nil {
+			model.ResponseError(ctx, err)
+			return
+		}
+
+		ctx.Data(http.StatusOK, response.ContentType, response.Content)
+	}
+}
+
+func (h *OPDSHandler) SeriesBooks(version string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		request := &model.OPDSSeriesFeedRequest{Version: version}
+		if err := ctx.ShouldBindQuery(request); err != nil {
+			opdsBindError(ctx, err)
+			return
+		}
+
+		response, err := h.usecase.SeriesBooks(ctx, request)
+		if err != nil {
+			model.ResponseError(ctx, err)
+			return
+		}
+
+		ctx.Data(http.StatusOK, response.ContentType, response.Content)
+	}
+}
+
+func (h *OPDSHandler) Search(version string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		request := &model.OPDSSearchFeedRequest{Version: version}
+		if err := ctx.ShouldBindQuery(request); err != nil {
+			opdsBindError(ctx, err)
+			return
+		}
+
+		response, err := h.usecase.Search(ctx, request)
+		if err != nil {
+			model.ResponseError(ctx, err)
+			return
+		}
+
+		ctx.Data(http.StatusOK, response.ContentType, response.Content)
+	}
+}
+
+func (h *OPDSHandler) OpenSearch(ctx *gin.Context) {
+	response, err := h.usecase.OpenSearch(ctx, &model.OPDSOpenSearchRequest{
+		BaseURL: baseURL(ctx),
+	})
+	if err != nil {
+		model.ResponseError(ctx, err)
+		return
+	}
+
+	ctx.Data(http.StatusOK, response.ContentType, response.Content)
+}
+
+func (h *OPDSHandler) feed(
+	version string,
+	get func(ctx context.Context, request *model.OPDSFeedRequest) (*model.OPDSResponse, error),
+) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		request := &model.OPDSFeedRequest{Version: version}
+		if err := ctx.ShouldBindQuery(request); err != nil {
+			opdsBindError(ctx, err)
+			return
+		}
+
+		response, err := get(ctx, request)
+		if err != nil {
+			model.ResponseError(ctx, err)
+			return
+		}
+
+		ctx.Data(http.StatusOK, response.ContentType, response.Content)
+	}
+}
+
+func opdsBindError(ctx *gin.Context, err error) {
+	gotracing.Error("Failed to parse request", err)
+	if errs, ok := err.(validator.ValidationErrors); ok {
+		model.ResponseError(ctx, model.ErrorBadRequest(fmt.Errorf("validation error in field %s", errs[0].Field())))
+		return
+	}
+	model.ResponseError(ctx, model.ErrorBadRequest(errors.New("failed to parse request")))
+}
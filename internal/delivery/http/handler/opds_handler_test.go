@@ -0,0 +1,192 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnaufalhilmym/bookshelf/internal/delivery/http/handler"
+	"github.com/mnaufalhilmym/bookshelf/internal/delivery/http/middleware"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/bookshelf/internal/storage"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+func newOPDSRouter(t *testing.T) (*gin.Engine, string) {
+	db := newDatabase()
+	userRepo := repository.NewUserRepository()
+	authorRepo := repository.NewAuthorRepository()
+	bookRepo := repository.NewBookRepository()
+	bookFileRepo := repository.NewBookFileRepository()
+	fileStorage := storage.NewLocal(t.TempDir())
+	userUc := usecase.NewUserUsecase(db, userRepo, "jwtKey", 10*time.Second)
+	apiKeyUc := usecase.NewAPIKeyUsecase(db, repository.NewAPIKeyRepository())
+	authorHandler := handler.NewAuthorHandler(usecase.NewAuthorUsecase(db, authorRepo, bookRepo, repository.NewRevisionRepository()))
+	bookHandler := handler.NewBookHandler(usecase.NewBookUsecase(db, bookRepo, authorRepo, repository.NewRevisionRepository()))
+	bookFileHandler := handler.NewBookFileHandler(usecase.NewBookFileUsecase(db, fileStorage, bookFileRepo, bookRepo, authorRepo))
+	opdsHandler := handler.NewOPDSHandler(usecase.NewOPDSUsecase(db, bookRepo, authorRepo, bookFileRepo))
+	basicAuth := middleware.NewBasicAuthMiddleware(userUc, apiKeyUc)
+
+	user, err := userUc.Register(context.Background(), &model.RegisterUserRequest{
+		Username: "reader",
+		Password: "password",
+	})
+	assert.NoError(t, err)
+	key, err := apiKeyUc.Create(context.Background(), &model.CreateAPIKeyRequest{UserID: user.ID, Name: "e-reader"})
+	assert.NoError(t, err)
+
+	router := gin.Default()
+
+	router.POST("/authors", authorHandler.Create)
+	router.POST("/books", bookHandler.Create)
+	router.POST("/books/:id/files", bookFileHandler.Upload)
+
+	opds := router.Group("/opds", basicAuth.Authenticate())
+	opds.GET("", opdsHandler.Root(model.OPDSVersion1))
+	opds.GET("/v2/newest", opdsHandler.Newest(model.OPDSVersion2))
+	opds.GET("/search", opdsHandler.Search(model.OPDSVersion1))
+	opds.GET("/opensearch.xml", opdsHandler.OpenSearch)
+	opds.GET("/books/:id/files/:file_id", bookFileHandler.Download)
+
+	createAuthor(t, router, &model.CreateAuthorRequest{
+		Name:      "Frank Herbert",
+		Birthdate: time.Date(1920, 10, 8, 0, 0, 0, 0, time.UTC),
+	})
+	createBook(t, router, &model.CreateBookRequest{
+		Title:       "Dune",
+		ISBN:        "978-0441172719",
+		AuthorID:    1,
+		Series:      "Dune",
+		SeriesIndex: 1,
+	})
+
+	testRec := httptest.NewRecorder()
+	router.ServeHTTP(testRec, newUploadRequest(t, "/books/1/files", "file", "dune.pdf", pdfDocument))
+	assert.EqualValues(t, http.StatusCreated, testRec.Code)
+
+	return router, key.Key
+}
+
+func TestOPDSHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router, key := newOPDSRouter(t)
+
+	t.Run("Negative Case - missing credentials", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodGet, "/opds", nil)
+		assert.NoError(t, err)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusUnauthorized, testRec.Code)
+		assert.EqualValues(t, `Basic realm="bookshelf", charset="UTF-8"`, testRec.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("Negative Case - wrong password", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodGet, "/opds", nil)
+		assert.NoError(t, err)
+		httpReq.SetBasicAuth("reader", "wrong")
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusUnauthorized, testRec.Code)
+	})
+
+	t.Run("Positive Case - basic auth with password", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodGet, "/opds", nil)
+		assert.NoError(t, err)
+		httpReq.SetBasicAuth("reader", "password")
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+		assert.EqualValues(t, "application/atom+xml;profile=opds-catalog;kind=navigation", testRec.Header().Get("Content-Type"))
+		assert.True(t, strings.Contains(testRec.Body.String(), `href="/opds/newest"`))
+	})
+
+	t.Run("Positive Case - basic auth with api key", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodGet, "/opds/search?q=herbert", nil)
+		assert.NoError(t, err)
+		httpReq.SetBasicAuth("reader", key)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+		assert.True(t, strings.Contains(testRec.Body.String(), `href="/opds/books/1/files/1"`))
+	})
+
+	t.Run("Negative Case - api key of another user", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodGet, "/opds", nil)
+		assert.NoError(t, err)
+		httpReq.SetBasicAuth("someone", key)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusUnauthorized, testRec.Code)
+	})
+
+	t.Run("Positive Case - api key header with opds 2.0", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodGet, "/opds/v2/newest", nil)
+		assert.NoError(t, err)
+		httpReq.Header.Set("X-API-Key", key)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+		assert.EqualValues(t, "application/opds+json", testRec.Header().Get("Content-Type"))
+
+		feed := map[string]any{}
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), &feed))
+		assert.EqualValues(t, 1, len(feed["publications"].([]any)))
+	})
+
+	t.Run("Negative Case - missing search query", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodGet, "/opds/search", nil)
+		assert.NoError(t, err)
+		httpReq.Header.Set("X-API-Key", key)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusBadRequest, testRec.Code)
+	})
+
+	t.Run("Positive Case - open search description", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodGet, "/opds/opensearch.xml", nil)
+		assert.NoError(t, err)
+		httpReq.Host = "books.example.com"
+		httpReq.Header.Set("X-Forwarded-Proto", "https")
+		httpReq.Header.Set("X-API-Key", key)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+		assert.True(t, strings.Contains(testRec.Body.String(), `template="https://books.example.com/opds/search?q={searchTerms}"`))
+	})
+
+	t.Run("Positive Case - download acquisition link", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodGet, "/opds/books/1/files/1", nil)
+		assert.NoError(t, err)
+		httpReq.SetBasicAuth("reader", "password")
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+		assert.EqualValues(t, pdfDocument, testRec.Body.String())
+	})
+}
@@ -0,0 +1,54 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadingGoalHandler_Upsert(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := newReadingGoalAndChallengeRouter(t)
+
+	t.Run("Positive Case - set goal", func(t *testing.T) {
+		reqBody, err := json.Marshal(model.UpsertReadingGoalRequest{Type: "books", Target: 24})
+		assert.NoError(t, err)
+
+		httpReq, err := http.NewRequest(http.MethodPut, "/me/goals/2020", bytes.NewReader(reqBody))
+		assert.NoError(t, err)
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+
+		res := new(model.Response[model.ReadingGoalResponse])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+
+		assert.EqualValues(t, 2020, res.Data.Year)
+		assert.EqualValues(t, 24, res.Data.Target)
+		assert.EqualValues(t, "24 books behind schedule", res.Data.Pace)
+	})
+
+	t.Run("Negative Case - invalid goal type", func(t *testing.T) {
+		reqBody, err := json.Marshal(model.UpsertReadingGoalRequest{Type: "chapters", Target: 24})
+		assert.NoError(t, err)
+
+		httpReq, err := http.NewRequest(http.MethodPut, "/me/goals/2020", bytes.NewReader(reqBody))
+		assert.NoError(t, err)
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusBadRequest, testRec.Code)
+	})
+}
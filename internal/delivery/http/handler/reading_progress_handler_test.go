@@ -0,0 +1,128 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnaufalhilmym/bookshelf/internal/delivery/http/handler"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+	"github.com/mnaufalhilmym/bookshelf/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func newReadingProgressRouter(t *testing.T) *gin.Engine {
+	db := newDatabase()
+	userRepo := repository.NewUserRepository()
+	authorRepo := repository.NewAuthorRepository()
+	bookRepo := repository.NewBookRepository()
+	progressRepo := repository.NewReadingProgressRepository()
+	userUc := usecase.NewUserUsecase(db, userRepo, "jwtKey", 10*time.Second)
+	authorHandler := handler.NewAuthorHandler(usecase.NewAuthorUsecase(db, authorRepo, bookRepo, repository.NewRevisionRepository()))
+	bookHandler := handler.NewBookHandler(usecase.NewBookUsecase(db, bookRepo, authorRepo, repository.NewRevisionRepository()))
+	progressHandler := handler.NewReadingProgressHandler(usecase.NewReadingProgressUsecase(db, progressRepo, bookRepo))
+
+	user, err := userUc.Register(context.Background(), &model.RegisterUserRequest{
+		Username: "reader",
+		Password: "password",
+	})
+	assert.NoError(t, err)
+
+	router := gin.Default()
+	router.Use(func(ctx *gin.Context) {
+		ctx.Set(model.UserContextKey, user)
+	})
+
+	router.POST("/authors", authorHandler.Create)
+	router.POST("/books", bookHandler.Create)
+	router.GET("/books/:id/progress", progressHandler.GetMany)
+	router.POST("/books/:id/progress", progressHandler.Create)
+	router.GET("/me/stats", progressHandler.GetStats)
+
+	createAuthor(t, router, &model.CreateAuthorRequest{
+		Name:      "Author Name 1",
+		Birthdate: time.Date(2011, 11, 11, 11, 11, 11, 111, time.UTC),
+	})
+	createBook(t, router, &model.CreateBookRequest{
+		Title:     "Book Title 1",
+		ISBN:      "978-1451673319",
+		AuthorID:  1,
+		PageCount: 120,
+	})
+
+	return router
+}
+
+func TestReadingProgressHandler_Create(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := newReadingProgressRouter(t)
+
+	t.Run("Positive Case - update progress", func(t *testing.T) {
+		reqBody, err := json.Marshal(model.CreateReadingProgressRequest{Page: util.ToPointer(30)})
+		assert.NoError(t, err)
+
+		httpReq, err := http.NewRequest(http.MethodPost, "/books/1/progress", bytes.NewReader(reqBody))
+		assert.NoError(t, err)
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusCreated, testRec.Code)
+
+		res := new(model.Response[model.ReadingProgressResponse])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+
+		assert.EqualValues(t, 30, res.Data.Page)
+		assert.EqualValues(t, 25, res.Data.Percentage)
+	})
+
+	t.Run("Negative Case - missing page and percentage", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodPost, "/books/1/progress", bytes.NewReader([]byte("{}")))
+		assert.NoError(t, err)
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusBadRequest, testRec.Code)
+	})
+}
+
+func TestReadingProgressHandler_GetStats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := newReadingProgressRouter(t)
+
+	reqBody, err := json.Marshal(model.CreateReadingProgressRequest{Percentage: util.ToPointer(100.0)})
+	assert.NoError(t, err)
+	httpReq, err := http.NewRequest(http.MethodPost, "/books/1/progress", bytes.NewReader(reqBody))
+	assert.NoError(t, err)
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(httptest.NewRecorder(), httpReq)
+
+	t.Run("Positive Case - get stats", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodGet, "/me/stats", nil)
+		assert.NoError(t, err)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+
+		res := new(model.Response[model.ReadingStatsResponse])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+
+		assert.EqualValues(t, 1, res.Data.BooksFinished)
+		assert.EqualValues(t, 120, res.Data.PagesRead)
+		assert.EqualValues(t, 1, res.Data.CurrentStreakDays)
+	})
+}
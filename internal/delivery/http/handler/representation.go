@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnaufalhilmym/bookshelf/internal/format/dublincore"
+	"github.com/mnaufalhilmym/bookshelf/internal/format/schemaorg"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/gotracing"
+)
+
+func respondJSONLD(ctx *gin.Context, document any) {
+	content, err := json.Marshal(document)
+	if err != nil {
+		gotracing.Error("Failed to encode JSON-LD", err)
+		model.ResponseError(ctx, model.ErrorInternalServerError(errors.New("failed to encode response")))
+		return
+	}
+
+	ctx.Data(http.StatusOK, schemaorg.ContentType, content)
+}
+
+func respondDublinCore(ctx *gin.Context, record *dublincore.Record) {
+	content, err := xml.MarshalIndent(record, "", "  ")
+	if err != nil {
+		gotracing.Error("Failed to encode Dublin Core", err)
+		model.ResponseError(ctx, model.ErrorInternalServerError(errors.New("failed to encode response")))
+		return
+	}
+
+	ctx.Data(http.StatusOK, "application/xml; charset=utf-8", append([]byte(xml.Header), content...))
+}
@@ -0,0 +1,173 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnaufalhilmym/bookshelf/internal/delivery/http/handler"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+type dublinCoreTestRecord struct {
+	XMLName     xml.Name `xml:"http://www.openarchives.org/OAI/2.0/oai_dc/ dc"`
+	Titles      []string `xml:"http://purl.org/dc/elements/1.1/ title"`
+	Creators    []string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Identifiers []string `xml:"http://purl.org/dc/elements/1.1/ identifier"`
+	Dates       []string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Relations   []string `xml:"http://purl.org/dc/elements/1.1/ relation"`
+}
+
+func newRepresentationRouter(t *testing.T) *gin.Engine {
+	authorHandler, bookHandler := newAuthorAndBookHandler()
+
+	router := gin.Default()
+
+	router.POST("/authors", authorHandler.Create)
+	router.POST("/books", bookHandler.Create)
+	router.GET("/authors/:id", handler.ByAccept(authorHandler.Get, map[string]gin.HandlerFunc{
+		"application/ld+json": authorHandler.GetAs(model.RepresentationJSONLD),
+		"application/xml":     authorHandler.GetAs(model.RepresentationDublinCore),
+	}))
+	router.GET("/books/:id", handler.ByAccept(bookHandler.Get, map[string]gin.HandlerFunc{
+		"application/ld+json": bookHandler.GetAs(model.RepresentationJSONLD),
+		"application/xml":     bookHandler.GetAs(model.RepresentationDublinCore),
+	}))
+
+	createAuthor(t, router, &model.CreateAuthorRequest{
+		Name:      "Frank Herbert",
+		Birthdate: time.Date(1920, 10, 8, 0, 0, 0, 0, time.UTC),
+	})
+	createBook(t, router, &model.CreateBookRequest{
+		Title:       "Dune",
+		ISBN:        "978-0441172719",
+		AuthorID:    1,
+		PageCount:   412,
+		Language:    "en",
+		Series:      "Dune",
+		SeriesIndex: 1,
+	})
+
+	return router
+}
+
+func getWithAccept(router *gin.Engine, url string, accept string) *httptest.ResponseRecorder {
+	httpReq, _ := http.NewRequest(http.MethodGet, url, nil)
+	httpReq.Host = "books.example.com"
+	if accept != "" {
+		httpReq.Header.Set("Accept", accept)
+	}
+
+	testRec := httptest.NewRecorder()
+	router.ServeHTTP(testRec, httpReq)
+	return testRec
+}
+
+func TestRepresentation_Book(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := newRepresentationRouter(t)
+
+	t.Run("Positive Case - json envelope by default", func(t *testing.T) {
+		for _, accept := range []string{"", "*/*", "application/json", "application/*", "text/html"} {
+			testRec := getWithAccept(router, "/books/1", accept)
+
+			assert.EqualValues(t, http.StatusOK, testRec.Code)
+			assert.EqualValues(t, "Accept", testRec.Header().Get("Vary"))
+
+			res := new(model.Response[model.BookResponse])
+			assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+			assert.EqualValues(t, "Dune", res.Data.Title)
+		}
+	})
+
+	t.Run("Positive Case - schema.org json-ld", func(t *testing.T) {
+		testRec := getWithAccept(router, "/books/1", "application/json;q=0.5, application/ld+json")
+
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+		assert.EqualValues(t, "application/ld+json", testRec.Header().Get("Content-Type"))
+
+		document := map[string]any{}
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), &document))
+		assert.EqualValues(t, map[string]any{
+			"@context":      "https://schema.org",
+			"@type":         "Book",
+			"@id":           "http://books.example.com/books/1",
+			"url":           "http://books.example.com/books/1",
+			"name":          "Dune",
+			"isbn":          "9780441172719",
+			"numberOfPages": 412.0,
+			"inLanguage":    "en",
+			"author": map[string]any{
+				"@type": "Person",
+				"@id":   "http://books.example.com/authors/1",
+				"name":  "Frank Herbert",
+			},
+			"isPartOf": map[string]any{"@type": "BookSeries", "name": "Dune"},
+			"position": 1.0,
+		}, document)
+	})
+
+	t.Run("Positive Case - dublin core", func(t *testing.T) {
+		testRec := getWithAccept(router, "/books/1", "text/html, application/xml;q=0.9, */*;q=0.8")
+
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+		assert.EqualValues(t, "application/xml; charset=utf-8", testRec.Header().Get("Content-Type"))
+
+		record := new(dublinCoreTestRecord)
+		assert.NoError(t, xml.Unmarshal(testRec.Body.Bytes(), record))
+		assert.EqualValues(t, []string{"Dune"}, record.Titles)
+		assert.EqualValues(t, []string{"Frank Herbert"}, record.Creators)
+		assert.EqualValues(t, []string{"http://books.example.com/books/1", "urn:isbn:9780441172719"}, record.Identifiers)
+		assert.EqualValues(t, []string{"Dune"}, record.Relations)
+	})
+
+	t.Run("Negative Case - not found keeps the envelope", func(t *testing.T) {
+		testRec := getWithAccept(router, "/books/2", "application/ld+json")
+
+		assert.EqualValues(t, http.StatusNotFound, testRec.Code)
+
+		res := new(model.Response[model.BookResponse])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+		assert.EqualValues(t, "book not found", res.Error)
+	})
+}
+
+func TestRepresentation_Author(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := newRepresentationRouter(t)
+
+	t.Run("Positive Case - schema.org json-ld", func(t *testing.T) {
+		testRec := getWithAccept(router, "/authors/1", "application/ld+json")
+
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+
+		document := map[string]any{}
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), &document))
+		assert.EqualValues(t, map[string]any{
+			"@context":  "https://schema.org",
+			"@type":     "Person",
+			"@id":       "http://books.example.com/authors/1",
+			"url":       "http://books.example.com/authors/1",
+			"name":      "Frank Herbert",
+			"birthDate": "1920-10-08",
+		}, document)
+	})
+
+	t.Run("Positive Case - dublin core", func(t *testing.T) {
+		testRec := getWithAccept(router, "/authors/1", "application/xml")
+
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+
+		record := new(dublinCoreTestRecord)
+		assert.NoError(t, xml.Unmarshal(testRec.Body.Bytes(), record))
+		assert.EqualValues(t, []string{"Frank Herbert"}, record.Titles)
+		assert.EqualValues(t, []string{"1920-10-08"}, record.Dates)
+	})
+}
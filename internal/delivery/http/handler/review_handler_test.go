@@ -0,0 +1,157 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnaufalhilmym/bookshelf/internal/delivery/http/handler"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+func newReviewRouter(t *testing.T) *gin.Engine {
+	db := newDatabase()
+	userRepo := repository.NewUserRepository()
+	authorRepo := repository.NewAuthorRepository()
+	bookRepo := repository.NewBookRepository()
+	reviewRepo := repository.NewReviewRepository()
+	userUc := usecase.NewUserUsecase(db, userRepo, "jwtKey", 10*time.Second)
+	authorHandler := handler.NewAuthorHandler(usecase.NewAuthorUsecase(db, authorRepo, bookRepo, repository.NewRevisionRepository()))
+	bookHandler := handler.NewBookHandler(usecase.NewBookUsecase(db, bookRepo, authorRepo, repository.NewRevisionRepository()))
+	reviewHandler := handler.NewReviewHandler(usecase.NewReviewUsecase(db, reviewRepo, bookRepo))
+
+	user, err := userUc.Register(context.Background(), &model.RegisterUserRequest{
+		Username: "reader",
+		Password: "password",
+	})
+	assert.NoError(t, err)
+
+	router := gin.Default()
+	router.Use(func(ctx *gin.Context) {
+		ctx.Set(model.UserContextKey, user)
+	})
+
+	router.POST("/authors", authorHandler.Create)
+	router.POST("/books", bookHandler.Create)
+	router.GET("/books/:id", bookHandler.Get)
+	router.GET("/books/:id/reviews", reviewHandler.GetMany)
+	router.PUT("/books/:id/review", reviewHandler.Upsert)
+	router.DELETE("/books/:id/review", reviewHandler.DeleteOwn)
+
+	createAuthor(t, router, &model.CreateAuthorRequest{
+		Name:      "Author Name 1",
+		Birthdate: time.Date(2011, 11, 11, 11, 11, 11, 111, time.UTC),
+	})
+	createBook(t, router, &model.CreateBookRequest{
+		Title:    "Book Title 1",
+		ISBN:     "978-1451673319",
+		AuthorID: 1,
+	})
+
+	return router
+}
+
+func TestReviewHandler_Upsert(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := newReviewRouter(t)
+
+	t.Run("Positive Case - rate book", func(t *testing.T) {
+		reqBody, err := json.Marshal(model.UpsertReviewRequest{Rating: 5, Text: "Great"})
+		assert.NoError(t, err)
+
+		httpReq, err := http.NewRequest(http.MethodPut, "/books/1/review", bytes.NewReader(reqBody))
+		assert.NoError(t, err)
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+
+		res := new(model.Response[model.ReviewResponse])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+
+		assert.EqualValues(t, 1, res.Data.BookID)
+		assert.EqualValues(t, "reader", res.Data.Username)
+		assert.EqualValues(t, 5, res.Data.Rating)
+
+		httpReq, err = http.NewRequest(http.MethodGet, "/books/1", nil)
+		assert.NoError(t, err)
+
+		testRec = httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		book := new(model.Response[model.BookResponse])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), book))
+
+		assert.EqualValues(t, 5, book.Data.AverageRating)
+		assert.EqualValues(t, 1, book.Data.RatingCount)
+	})
+
+	t.Run("Negative Case - rating out of range", func(t *testing.T) {
+		reqBody, err := json.Marshal(model.UpsertReviewRequest{Rating: 6})
+		assert.NoError(t, err)
+
+		httpReq, err := http.NewRequest(http.MethodPut, "/books/1/review", bytes.NewReader(reqBody))
+		assert.NoError(t, err)
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusBadRequest, testRec.Code)
+
+		res := new(model.Response[any])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+
+		assert.EqualValues(t, "validation error in field Rating", res.Error)
+	})
+}
+
+func TestReviewHandler_GetMany(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := newReviewRouter(t)
+
+	reqBody, err := json.Marshal(model.UpsertReviewRequest{Rating: 3})
+	assert.NoError(t, err)
+	httpReq, err := http.NewRequest(http.MethodPut, "/books/1/review", bytes.NewReader(reqBody))
+	assert.NoError(t, err)
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(httptest.NewRecorder(), httpReq)
+
+	t.Run("Positive Case - list reviews", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodGet, "/books/1/reviews?page=1&size=5", nil)
+		assert.NoError(t, err)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+
+		res := new(model.Response[[]model.ReviewResponse])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+
+		assert.Len(t, res.Data, 1)
+		assert.EqualValues(t, 1, res.Pagination.TotalItem)
+	})
+
+	t.Run("Negative Case - book not found", func(t *testing.T) {
+		httpReq, err := http.NewRequest(http.MethodGet, "/books/99/reviews", nil)
+		assert.NoError(t, err)
+
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+
+		assert.EqualValues(t, http.StatusNotFound, testRec.Code)
+	})
+}
@@ -0,0 +1,102 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnaufalhilmym/bookshelf/internal/delivery/http/handler"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/bookshelf/internal/storage"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrashHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db := newDatabase()
+	userRepo := repository.NewUserRepository()
+	authorRepo := repository.NewAuthorRepository()
+	bookRepo := repository.NewBookRepository()
+	authorHandler := handler.NewAuthorHandler(usecase.NewAuthorUsecase(db, authorRepo, bookRepo, repository.NewRevisionRepository()))
+	bookHandler := handler.NewBookHandler(usecase.NewBookUsecase(db, bookRepo, authorRepo, repository.NewRevisionRepository()))
+	trashHandler := handler.NewTrashHandler(usecase.NewTrashUsecase(
+		db,
+		storage.NewLocal(t.TempDir()),
+		repository.NewTrashRepository(),
+		userRepo,
+		authorRepo,
+		bookRepo,
+		repository.NewBookFileRepository(),
+		time.Hour,
+	))
+
+	router := gin.Default()
+	router.Use(func(ctx *gin.Context) {
+		ctx.Set(model.UserContextKey, &model.UserResponse{ID: 7, Username: "admin"})
+	})
+	router.POST("/authors", authorHandler.Create)
+	router.POST("/books", bookHandler.Create)
+	router.GET("/books/:id", bookHandler.Get)
+	router.DELETE("/books/:id", bookHandler.Delete)
+	router.GET("/trash", trashHandler.GetMany)
+	router.POST("/trash/:type/:id/restore", trashHandler.Restore)
+
+	createAuthor(t, router, &model.CreateAuthorRequest{
+		Name:      "Author Name 1",
+		Birthdate: time.Date(2011, 11, 11, 11, 11, 11, 111, time.UTC),
+	})
+	createBook(t, router, &model.CreateBookRequest{
+		Title:    "Book Title 1",
+		ISBN:     "978-1451673319",
+		AuthorID: 1,
+	})
+
+	serve := func(method string, url string) *httptest.ResponseRecorder {
+		httpReq, err := http.NewRequest(method, url, nil)
+		assert.NoError(t, err)
+		testRec := httptest.NewRecorder()
+		router.ServeHTTP(testRec, httpReq)
+		return testRec
+	}
+
+	assert.EqualValues(t, http.StatusOK, serve(http.MethodDelete, "/books/1").Code)
+	assert.EqualValues(t, http.StatusNotFound, serve(http.MethodGet, "/books/1").Code)
+
+	t.Run("Positive Case - list trash", func(t *testing.T) {
+		testRec := serve(http.MethodGet, "/trash?type=book")
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+
+		res := new(model.Response[[]model.TrashItemResponse])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+		assert.EqualValues(t, 1, res.Pagination.TotalItem)
+		assert.EqualValues(t, "book", res.Data[0].Type)
+		assert.EqualValues(t, 1, res.Data[0].ID)
+		assert.EqualValues(t, 7, *res.Data[0].DeletedBy)
+	})
+
+	t.Run("Negative Case 1 - unknown type", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusBadRequest, serve(http.MethodGet, "/trash?type=review").Code)
+		assert.EqualValues(t, http.StatusBadRequest, serve(http.MethodPost, "/trash/review/1/restore").Code)
+	})
+
+	t.Run("Negative Case 2 - not in trash", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusNotFound, serve(http.MethodPost, "/trash/author/1/restore").Code)
+	})
+
+	t.Run("Positive Case - restore book", func(t *testing.T) {
+		testRec := serve(http.MethodPost, "/trash/book/1/restore")
+		assert.EqualValues(t, http.StatusOK, testRec.Code)
+
+		res := new(model.Response[int])
+		assert.NoError(t, json.Unmarshal(testRec.Body.Bytes(), res))
+		assert.EqualValues(t, 1, res.Data)
+
+		assert.EqualValues(t, http.StatusOK, serve(http.MethodGet, "/books/1").Code)
+	})
+}
@@ -9,7 +9,6 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/mnaufalhilmym/bookshelf/internal/config"
 	"github.com/mnaufalhilmym/bookshelf/internal/delivery/http/handler"
 	"github.com/mnaufalhilmym/bookshelf/internal/model"
 	"github.com/mnaufalhilmym/bookshelf/internal/repository"
@@ -18,8 +17,8 @@ import (
 )
 
 func newUserHandler() *handler.UserHandler {
-	db := config.NewDatabase(":memory:", 1, 1, 100)
-	repo := repository.NewUserRepository(db)
+	db := newDatabase()
+	repo := repository.NewUserRepository()
 	uc := usecase.NewUserUsecase(db, repo, "jwtKey", 10*time.Second)
 	return handler.NewUserHandler(uc)
 }
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+)
+
+// BasicAuthMiddleware authenticates clients that cannot obtain a JWT, such as
+// e-reader apps. They send an API key in the X-API-Key header, or HTTP Basic
+// credentials whose password is either the account password or an API key.
+type BasicAuthMiddleware struct {
+	userUsecase   *usecase.UserUsecase
+	apiKeyUsecase *usecase.APIKeyUsecase
+}
+
+func NewBasicAuthMiddleware(
+	userUsecase *usecase.UserUsecase,
+	apiKeyUsecase *usecase.APIKeyUsecase,
+) *BasicAuthMiddleware {
+	return &BasicAuthMiddleware{
+		userUsecase:   userUsecase,
+		apiKeyUsecase: apiKeyUsecase,
+	}
+}
+
+func (m *BasicAuthMiddleware) Authenticate() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		user, err := m.authenticate(ctx)
+		if err != nil {
+			// The challenge makes e-reader apps prompt for credentials.
+			ctx.Header("WWW-Authenticate", `Basic realm="bookshelf", charset="UTF-8"`)
+			model.ResponseError(ctx, err)
+			ctx.Abort()
+			return
+		}
+
+		ctx.Set(model.UserContextKey, user)
+
+		ctx.Next()
+	}
+}
+
+func (m *BasicAuthMiddleware) authenticate(ctx *gin.Context) (*model.UserResponse, error) {
+	if key := ctx.Request.Header.Get("X-API-Key"); key != "" {
+		return m.apiKeyUsecase.Authenticate(ctx, key)
+	}
+
+	username, password, ok := ctx.Request.BasicAuth()
+	if !ok {
+		return nil, model.ErrorUnauthorized(errors.New("authorization header is missing"))
+	}
+
+	if !strings.HasPrefix(password, usecase.APIKeyPrefix) {
+		return m.userUsecase.Authenticate(ctx, username, password)
+	}
+
+	// A password may happen to look like an API key, so it is checked as a
+	// password when no such key exists.
+	user, err := m.apiKeyUsecase.Authenticate(ctx, password)
+	if err != nil {
+		var appError *model.Error
+		if errors.As(err, &appError) && appError.Code == http.StatusUnauthorized {
+			return m.userUsecase.Authenticate(ctx, username, password)
+		}
+		return nil, err
+	}
+	if username != "" && username != user.Username {
+		return nil, model.ErrorUnauthorized(errors.New("invalid api key"))
+	}
+	return user, nil
+}
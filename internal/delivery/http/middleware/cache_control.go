@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CacheControlMiddleware sends the Cache-Control header with the responses to
+// GET requests. Routes are looked up by the path they are registered with,
+// such as /books/:id, and routes without a value of their own get the
+// default.
+type CacheControlMiddleware struct {
+	defaultValue string
+	routes       map[string]string
+}
+
+func NewCacheControlMiddleware(defaultValue string, routes map[string]string) *CacheControlMiddleware {
+	return &CacheControlMiddleware{
+		defaultValue: defaultValue,
+		routes:       routes,
+	}
+}
+
+func (m *CacheControlMiddleware) SetCacheControl() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if ctx.Request.Method == http.MethodGet || ctx.Request.Method == http.MethodHead {
+			value, ok := m.routes[ctx.FullPath()]
+			if !ok {
+				value = m.defaultValue
+			}
+			if value != "" {
+				ctx.Header("Cache-Control", value)
+			}
+		}
+
+		ctx.Next()
+	}
+}
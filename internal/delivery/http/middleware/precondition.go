@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+)
+
+// PreconditionMiddleware makes clients send the ETag they read in the
+// If-Match header when they change a record, so that they cannot overwrite
+// changes they have not seen. Unless it is required, the header is optional.
+type PreconditionMiddleware struct {
+	requireIfMatch bool
+}
+
+func NewPreconditionMiddleware(requireIfMatch bool) *PreconditionMiddleware {
+	return &PreconditionMiddleware{
+		requireIfMatch: requireIfMatch,
+	}
+}
+
+func (m *PreconditionMiddleware) RequireIfMatch() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if m.requireIfMatch && ctx.GetHeader("If-Match") == "" {
+			model.ResponseError(ctx, model.ErrorPreconditionRequired(errors.New("If-Match header is required")))
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+)
+
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		user, ok := ctx.Value(model.UserContextKey).(*model.UserResponse)
+		if !ok {
+			model.ResponseError(ctx, model.ErrorUnauthorized(errors.New("user is not authenticated")))
+			ctx.Abort()
+			return
+		}
+
+		if !slices.Contains(roles, user.Role) {
+			model.ResponseError(ctx, model.ErrorForbidden(errors.New("insufficient role")))
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -53,14 +53,21 @@ func (m *ValidateTokenMiddleware) ValidateToken() gin.HandlerFunc {
 			return
 		}
 
-		if jwtClaims, ok := token.Claims.(model.JWTClaims); ok {
-			if _, err := m.userUsecase.GetByUsername(ctx, jwtClaims.Subject); err != nil {
-				model.ResponseError(ctx, err)
-				ctx.Abort()
-				return
-			}
-			ctx.Set("jwt_claims", jwtClaims)
+		jwtClaims, ok := token.Claims.(*model.JWTClaims)
+		if !ok {
+			model.ResponseError(ctx, model.ErrorUnauthorized(errors.New("invalid token claims")))
+			ctx.Abort()
+			return
+		}
+
+		user, err := m.userUsecase.GetByUsername(ctx, jwtClaims.Subject)
+		if err != nil {
+			model.ResponseError(ctx, err)
+			ctx.Abort()
+			return
 		}
+		ctx.Set(model.JWTClaimsContextKey, jwtClaims)
+		ctx.Set(model.UserContextKey, user)
 
 		ctx.Next()
 	}
@@ -4,16 +4,37 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/mnaufalhilmym/bookshelf/internal/delivery/http/handler"
 	"github.com/mnaufalhilmym/bookshelf/internal/delivery/http/middleware"
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
 )
 
 type RouteConfig struct {
 	router *gin.Engine
 
-	userHandler   *handler.UserHandler
-	authorHandler *handler.AuthorHandler
-	bookHandler   *handler.BookHandler
+	userHandler            *handler.UserHandler
+	authorHandler          *handler.AuthorHandler
+	bookHandler            *handler.BookHandler
+	reviewHandler          *handler.ReviewHandler
+	readingProgressHandler *handler.ReadingProgressHandler
+	readingGoalHandler     *handler.ReadingGoalHandler
+	challengeHandler       *handler.ChallengeHandler
+	annotationHandler      *handler.AnnotationHandler
+	importHandler          *handler.ImportHandler
+	exportHandler          *handler.ExportHandler
+	shelfHandler           *handler.ShelfHandler
+	importJobHandler       *handler.ImportJobHandler
+	bookFileHandler        *handler.BookFileHandler
+	coverHandler           *handler.CoverHandler
+	apiKeyHandler          *handler.APIKeyHandler
+	opdsHandler            *handler.OPDSHandler
+	oaiHandler             *handler.OAIHandler
+	backupHandler          *handler.BackupHandler
+	trashHandler           *handler.TrashHandler
 
 	validateTokenMiddleware *middleware.ValidateTokenMiddleware
+	basicAuthMiddleware     *middleware.BasicAuthMiddleware
+	preconditionMiddleware  *middleware.PreconditionMiddleware
+	cacheControlMiddleware  *middleware.CacheControlMiddleware
 }
 
 func New(
@@ -22,33 +43,190 @@ func New(
 	userHandler *handler.UserHandler,
 	authorHandler *handler.AuthorHandler,
 	bookHandler *handler.BookHandler,
+	reviewHandler *handler.ReviewHandler,
+	readingProgressHandler *handler.ReadingProgressHandler,
+	readingGoalHandler *handler.ReadingGoalHandler,
+	challengeHandler *handler.ChallengeHandler,
+	annotationHandler *handler.AnnotationHandler,
+	importHandler *handler.ImportHandler,
+	exportHandler *handler.ExportHandler,
+	shelfHandler *handler.ShelfHandler,
+	importJobHandler *handler.ImportJobHandler,
+	bookFileHandler *handler.BookFileHandler,
+	coverHandler *handler.CoverHandler,
+	apiKeyHandler *handler.APIKeyHandler,
+	opdsHandler *handler.OPDSHandler,
+	oaiHandler *handler.OAIHandler,
+	backupHandler *handler.BackupHandler,
+	trashHandler *handler.TrashHandler,
 
 	validateTokenMiddleware *middleware.ValidateTokenMiddleware,
+	basicAuthMiddleware *middleware.BasicAuthMiddleware,
+	preconditionMiddleware *middleware.PreconditionMiddleware,
+	cacheControlMiddleware *middleware.CacheControlMiddleware,
 ) *RouteConfig {
 	return &RouteConfig{
 		router,
 		userHandler,
 		authorHandler,
 		bookHandler,
+		reviewHandler,
+		readingProgressHandler,
+		readingGoalHandler,
+		challengeHandler,
+		annotationHandler,
+		importHandler,
+		exportHandler,
+		shelfHandler,
+		importJobHandler,
+		bookFileHandler,
+		coverHandler,
+		apiKeyHandler,
+		opdsHandler,
+		oaiHandler,
+		backupHandler,
+		trashHandler,
 		validateTokenMiddleware,
+		basicAuthMiddleware,
+		preconditionMiddleware,
+		cacheControlMiddleware,
 	}
 }
 
 func (r *RouteConfig) ConfigureRoutes() {
+	r.router.Use(r.cacheControlMiddleware.SetCacheControl())
+
 	r.router.POST("/auth/register", r.userHandler.Register)
 	r.router.POST("/auth/login", r.userHandler.Login)
 
+	// Union catalogs harvest the catalog without credentials.
+	r.router.GET("/oai", r.oaiHandler.Handle)
+	r.router.POST("/oai", r.oaiHandler.Handle)
+
+	// E-reader apps cannot log in for a JWT, so the OPDS catalog and the
+	// covers and files it links to accept HTTP Basic credentials and API keys.
+	opds := r.router.Group("/opds", r.basicAuthMiddleware.Authenticate())
+	opds.GET("/opensearch.xml", r.opdsHandler.OpenSearch)
+	opds.GET("/books/:id/cover", r.coverHandler.Get)
+	opds.GET("/books/:id/cover/:size", r.coverHandler.Get)
+	opds.GET("/books/:id/files/:file_id", r.bookFileHandler.Download)
+	for _, feed := range []struct {
+		prefix  string
+		version string
+	}{
+		{"", model.OPDSVersion1},
+		{"/v2", model.OPDSVersion2},
+	} {
+		opds.GET(feed.prefix, r.opdsHandler.Root(feed.version))
+		opds.GET(feed.prefix+"/newest", r.opdsHandler.Newest(feed.version))
+		opds.GET(feed.prefix+"/authors", r.opdsHandler.Authors(feed.version))
+		opds.GET(feed.prefix+"/authors/:id", r.opdsHandler.Author(feed.version))
+		opds.GET(feed.prefix+"/series", r.opdsHandler.Series(feed.version))
+		opds.GET(feed.prefix+"/series/books", r.opdsHandler.SeriesBooks(feed.version))
+		opds.GET(feed.prefix+"/search", r.opdsHandler.Search(feed.version))
+	}
+
 	r.router.Use(r.validateTokenMiddleware.ValidateToken())
 
+	requireIfMatch := r.preconditionMiddleware.RequireIfMatch()
+
 	r.router.GET("/authors", r.authorHandler.GetMany)
-	r.router.GET("/authors/:id", r.authorHandler.Get)
+	r.router.GET("/authors/:id", handler.ByAccept(r.authorHandler.Get, map[string]gin.HandlerFunc{
+		"application/ld+json": r.authorHandler.GetAs(model.RepresentationJSONLD),
+		"application/xml":     r.authorHandler.GetAs(model.RepresentationDublinCore),
+		"text/xml":            r.authorHandler.GetAs(model.RepresentationDublinCore),
+	}))
 	r.router.POST("/authors", r.authorHandler.Create)
-	r.router.PUT("/authors/:id", r.authorHandler.Update)
-	r.router.DELETE("/authors/:id", r.authorHandler.Delete)
+	r.router.PUT("/authors/:id", requireIfMatch, r.authorHandler.Update)
+	r.router.DELETE("/authors/:id", requireIfMatch, r.authorHandler.Delete)
+	r.router.GET("/authors/:id/revisions", r.authorHandler.GetRevisions)
+	r.router.GET("/authors/:id/revisions/diff", r.authorHandler.DiffRevisions)
+	r.router.POST("/authors/:id/revisions/:rev/restore", r.authorHandler.RestoreRevision)
 
 	r.router.GET("/books", r.bookHandler.GetMany)
-	r.router.GET("/books/:id", r.bookHandler.Get)
+	bookRepresentations := handler.ByAccept(r.bookHandler.Get, map[string]gin.HandlerFunc{
+		"application/ld+json": r.bookHandler.GetAs(model.RepresentationJSONLD),
+		"application/xml":     r.bookHandler.GetAs(model.RepresentationDublinCore),
+		"text/xml":            r.bookHandler.GetAs(model.RepresentationDublinCore),
+	})
+	r.router.GET("/books/:id", handler.ByExtension("id", bookRepresentations, map[string]gin.HandlerFunc{
+		".mrc": r.exportHandler.ExportBook(model.ExportFormatMARC),
+		".xml": r.exportHandler.ExportBook(model.ExportFormatMARCXML),
+	}))
 	r.router.POST("/books", r.bookHandler.Create)
-	r.router.PUT("/books/:id", r.bookHandler.Update)
-	r.router.DELETE("/books/:id", r.bookHandler.Delete)
+	r.router.PUT("/books/:id", requireIfMatch, r.bookHandler.Update)
+	r.router.DELETE("/books/:id", requireIfMatch, r.bookHandler.Delete)
+	r.router.GET("/books/:id/revisions", r.bookHandler.GetRevisions)
+	r.router.GET("/books/:id/revisions/diff", r.bookHandler.DiffRevisions)
+	r.router.POST("/books/:id/revisions/:rev/restore", r.bookHandler.RestoreRevision)
+
+	r.router.POST("/books/upload", r.bookFileHandler.UploadBook)
+	r.router.GET("/books/:id/files", r.bookFileHandler.GetMany)
+	r.router.POST("/books/:id/files", r.bookFileHandler.Upload)
+	r.router.GET("/books/:id/files/:file_id", r.bookFileHandler.Download)
+
+	r.router.GET("/books/:id/cover", r.coverHandler.Get)
+	r.router.GET("/books/:id/cover/:size", r.coverHandler.Get)
+	r.router.PUT("/books/:id/cover", r.coverHandler.Upload)
+	r.router.DELETE("/books/:id/cover", r.coverHandler.Delete)
+
+	r.router.GET("/books/:id/reviews", r.reviewHandler.GetMany)
+	r.router.PUT("/books/:id/review", r.reviewHandler.Upsert)
+	r.router.DELETE("/books/:id/review", r.reviewHandler.DeleteOwn)
+	r.router.DELETE(
+		"/books/:id/reviews/:review_id",
+		middleware.RequireRole(entity.UserRoleModerator, entity.UserRoleAdmin),
+		r.reviewHandler.Delete,
+	)
+
+	r.router.GET("/books/:id/progress", r.readingProgressHandler.GetMany)
+	r.router.POST("/books/:id/progress", r.readingProgressHandler.Create)
+	r.router.GET("/me/stats", r.readingProgressHandler.GetStats)
+
+	r.router.GET("/me/goals", r.readingGoalHandler.GetMany)
+	r.router.GET("/me/goals/:year", r.readingGoalHandler.Get)
+	r.router.PUT("/me/goals/:year", r.readingGoalHandler.Upsert)
+	r.router.DELETE("/me/goals/:year", r.readingGoalHandler.Delete)
+
+	requireAdmin := middleware.RequireRole(entity.UserRoleAdmin)
+
+	r.router.GET("/challenges", r.challengeHandler.GetMany)
+	r.router.GET("/challenges/:id", r.challengeHandler.Get)
+	r.router.POST("/challenges", requireAdmin, r.challengeHandler.Create)
+	r.router.PUT("/challenges/:id", requireAdmin, r.challengeHandler.Update)
+	r.router.DELETE("/challenges/:id", requireAdmin, r.challengeHandler.Delete)
+	r.router.POST("/challenges/:id/join", r.challengeHandler.Join)
+	r.router.GET("/challenges/:id/leaderboard", r.challengeHandler.GetLeaderboard)
+
+	r.router.GET("/annotations", r.annotationHandler.GetMany)
+	r.router.GET("/annotations/:id", r.annotationHandler.Get)
+	r.router.PUT("/annotations/:id", r.annotationHandler.Update)
+	r.router.DELETE("/annotations/:id", r.annotationHandler.Delete)
+	r.router.GET("/books/:id/annotations", r.annotationHandler.GetManyByBook)
+	r.router.POST("/books/:id/annotations", r.annotationHandler.Create)
+	r.router.GET("/me/annotations", r.annotationHandler.GetOwn)
+	r.router.GET("/me/annotations/export", r.annotationHandler.Export)
+
+	r.router.POST("/import/kindle", r.importHandler.ImportKindle)
+	r.router.POST("/import/csv", r.importHandler.ImportCSV)
+	r.router.POST("/import/marc", r.importHandler.ImportMARC)
+	r.router.POST("/import/calibre", r.importHandler.ImportCalibre)
+	r.router.POST("/import/goodreads", r.importJobHandler.ImportGoodreads)
+	r.router.POST("/import/storygraph", r.importJobHandler.ImportStoryGraph)
+	r.router.GET("/import/jobs/:id", r.importJobHandler.Get)
+
+	r.router.GET("/me/api-keys", r.apiKeyHandler.GetMany)
+	r.router.POST("/me/api-keys", r.apiKeyHandler.Create)
+	r.router.DELETE("/me/api-keys/:id", r.apiKeyHandler.Delete)
+
+	r.router.GET("/me/shelves", r.shelfHandler.GetMany)
+	r.router.GET("/me/shelves/:shelf", r.shelfHandler.Get)
+
+	r.router.GET("/export/books", r.exportHandler.ExportBooks)
+
+	r.router.GET("/admin/backups", requireAdmin, r.backupHandler.GetMany)
+	r.router.POST("/admin/backups", requireAdmin, r.backupHandler.Create)
+
+	r.router.GET("/trash", requireAdmin, r.trashHandler.GetMany)
+	r.router.POST("/trash/:type/:id/restore", requireAdmin, r.trashHandler.Restore)
 }
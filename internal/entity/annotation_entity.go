@@ -0,0 +1,28 @@
+package entity
+
+import "time"
+
+const (
+	AnnotationVisibilityPrivate = "private"
+	AnnotationVisibilityPublic  = "public"
+)
+
+type Annotation struct {
+	ID         int       `gorm:"column:id;primaryKey"`
+	UserID     int       `gorm:"column:user_id;not null;index"`
+	BookID     int       `gorm:"column:book_id;not null;index"`
+	Page       int       `gorm:"column:page"`
+	Location   string    `gorm:"column:location"`
+	Text       string    `gorm:"column:text;not null"`
+	Note       string    `gorm:"column:note"`
+	Visibility string    `gorm:"column:visibility;not null;default:private"`
+	CreatedAt  time.Time `gorm:"column:created_at"`
+	UpdatedAt  time.Time `gorm:"column:updated_at"`
+
+	User User `gorm:"foreignKey:user_id;references:id"`
+	Book Book `gorm:"foreignKey:book_id;references:id"`
+}
+
+func (*Annotation) TableName() string {
+	return "annotations"
+}
@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+// APIKey lets clients that cannot log in for a JWT, such as e-reader apps,
+// authenticate as the user. Only a hash of the key is stored.
+type APIKey struct {
+	ID         int        `gorm:"column:id;primaryKey"`
+	UserID     int        `gorm:"column:user_id;not null;index"`
+	Name       string     `gorm:"column:name;not null"`
+	Prefix     string     `gorm:"column:prefix;not null"`
+	KeyHash    string     `gorm:"column:key_hash;not null;unique"`
+	CreatedAt  time.Time  `gorm:"column:created_at"`
+	LastUsedAt *time.Time `gorm:"column:last_used_at"`
+
+	User User `gorm:"foreignKey:user_id;references:id"`
+}
+
+func (*APIKey) TableName() string {
+	return "api_keys"
+}
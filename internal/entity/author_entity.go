@@ -1,11 +1,18 @@
 package entity
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Author struct {
-	ID        int       `gorm:"column:id;primaryKey"`
-	Name      string    `gorm:"column:name"`
-	Birthdate time.Time `gorm:"column:birthdate"`
+	ID        int            `gorm:"column:id;primaryKey"`
+	Name      string         `gorm:"column:name"`
+	Birthdate time.Time      `gorm:"column:birthdate"`
+	Version   int            `gorm:"column:version;not null;default:1"`
+	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at;index"`
+	DeletedBy *int           `gorm:"column:deleted_by"`
 }
 
 func (*Author) TableName() string {
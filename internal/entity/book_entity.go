@@ -1,10 +1,29 @@
 package entity
 
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
 type Book struct {
-	ID       int    `gorm:"column:id;primaryKey"`
-	Title    string `gorm:"column:title"`
-	ISBN     string `gorm:"column:isbn;not null;unique"`
-	AuthorID int    `gorn:"column:author_id"`
+	ID               int            `gorm:"column:id;primaryKey"`
+	Title            string         `gorm:"column:title"`
+	ISBN             string         `gorm:"column:isbn;not null;uniqueIndex:idx_books_isbn,where:deleted_at IS NULL"`
+	AuthorID         int            `gorn:"column:author_id"`
+	PageCount        int            `gorm:"column:page_count;not null;default:0"`
+	RatingAverage    float64        `gorm:"column:rating_average;not null;default:0"`
+	RatingCount      int64          `gorm:"column:rating_count;not null;default:0"`
+	Language         string         `gorm:"column:language"`
+	Series           string         `gorm:"column:series;index"`
+	SeriesIndex      float64        `gorm:"column:series_index;not null;default:0"`
+	CoverKey         string         `gorm:"column:cover_key"`
+	CoverContentType string         `gorm:"column:cover_content_type"`
+	CoverChecksum    string         `gorm:"column:cover_checksum"`
+	Version          int            `gorm:"column:version;not null;default:1"`
+	UpdatedAt        time.Time      `gorm:"column:updated_at;index"`
+	DeletedAt        gorm.DeletedAt `gorm:"column:deleted_at;index"`
+	DeletedBy        *int           `gorm:"column:deleted_by"`
 
 	Author Author `gorm:"foreignKey:author_id;references:id"`
 }
@@ -12,3 +31,15 @@ type Book struct {
 func (*Book) TableName() string {
 	return "books"
 }
+
+// DeletedBook records a deleted book, so that harvesters of the catalog learn
+// about the deletion. Its ID is the ID the book had.
+type DeletedBook struct {
+	ID        int       `gorm:"column:id;primaryKey;autoIncrement:false"`
+	AuthorID  int       `gorm:"column:author_id;index"`
+	DeletedAt time.Time `gorm:"column:deleted_at;index"`
+}
+
+func (*DeletedBook) TableName() string {
+	return "deleted_books"
+}
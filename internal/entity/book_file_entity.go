@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+type BookFile struct {
+	ID          int       `gorm:"column:id;primaryKey"`
+	BookID      int       `gorm:"column:book_id;not null;uniqueIndex:idx_book_files_book_id_checksum"`
+	Format      string    `gorm:"column:format;not null"`
+	Filename    string    `gorm:"column:filename;not null"`
+	ContentType string    `gorm:"column:content_type;not null"`
+	Size        int64     `gorm:"column:size;not null"`
+	Checksum    string    `gorm:"column:checksum;not null;uniqueIndex:idx_book_files_book_id_checksum"`
+	StorageKey  string    `gorm:"column:storage_key;not null"`
+	CreatedAt   time.Time `gorm:"column:created_at"`
+}
+
+func (*BookFile) TableName() string {
+	return "book_files"
+}
@@ -0,0 +1,31 @@
+package entity
+
+import "time"
+
+type Challenge struct {
+	ID               int        `gorm:"column:id;primaryKey"`
+	Name             string     `gorm:"column:name;not null"`
+	Description      string     `gorm:"column:description"`
+	StartAt          time.Time  `gorm:"column:start_at;not null"`
+	EndAt            time.Time  `gorm:"column:end_at;not null"`
+	TargetBooks      int        `gorm:"column:target_books;not null"`
+	AuthorBornBefore *time.Time `gorm:"column:author_born_before"`
+	AuthorBornAfter  *time.Time `gorm:"column:author_born_after"`
+}
+
+func (*Challenge) TableName() string {
+	return "challenges"
+}
+
+type ChallengeParticipant struct {
+	ID          int       `gorm:"column:id;primaryKey"`
+	ChallengeID int       `gorm:"column:challenge_id;not null;uniqueIndex:idx_challenge_participants_challenge_id_user_id"`
+	UserID      int       `gorm:"column:user_id;not null;uniqueIndex:idx_challenge_participants_challenge_id_user_id"`
+	JoinedAt    time.Time `gorm:"column:joined_at;autoCreateTime"`
+
+	User User `gorm:"foreignKey:user_id;references:id"`
+}
+
+func (*ChallengeParticipant) TableName() string {
+	return "challenge_participants"
+}
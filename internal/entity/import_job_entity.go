@@ -0,0 +1,28 @@
+package entity
+
+import "time"
+
+const (
+	ImportJobStatusPending   = "pending"
+	ImportJobStatusRunning   = "running"
+	ImportJobStatusCompleted = "completed"
+	ImportJobStatusFailed    = "failed"
+)
+
+type ImportJob struct {
+	ID         int        `gorm:"column:id;primaryKey"`
+	UserID     int        `gorm:"column:user_id;not null;index"`
+	Source     string     `gorm:"column:source;not null"`
+	Status     string     `gorm:"column:status;not null;default:pending"`
+	Total      int        `gorm:"column:total;not null"`
+	Processed  int        `gorm:"column:processed;not null"`
+	Result     string     `gorm:"column:result"`
+	Error      string     `gorm:"column:error"`
+	CreatedAt  time.Time  `gorm:"column:created_at"`
+	UpdatedAt  time.Time  `gorm:"column:updated_at"`
+	FinishedAt *time.Time `gorm:"column:finished_at"`
+}
+
+func (*ImportJob) TableName() string {
+	return "import_jobs"
+}
@@ -0,0 +1,18 @@
+package entity
+
+const (
+	ReadingGoalTypeBooks = "books"
+	ReadingGoalTypePages = "pages"
+)
+
+type ReadingGoal struct {
+	ID     int    `gorm:"column:id;primaryKey"`
+	UserID int    `gorm:"column:user_id;not null;uniqueIndex:idx_reading_goals_user_id_year"`
+	Year   int    `gorm:"column:year;not null;uniqueIndex:idx_reading_goals_user_id_year"`
+	Type   string `gorm:"column:type;not null"`
+	Target int    `gorm:"column:target;not null"`
+}
+
+func (*ReadingGoal) TableName() string {
+	return "reading_goals"
+}
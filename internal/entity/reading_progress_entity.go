@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+type ReadingProgress struct {
+	ID         int       `gorm:"column:id;primaryKey"`
+	UserID     int       `gorm:"column:user_id;not null;index:idx_reading_progresses_user_id_book_id"`
+	BookID     int       `gorm:"column:book_id;not null;index:idx_reading_progresses_user_id_book_id"`
+	Page       int       `gorm:"column:page;not null"`
+	Percentage float64   `gorm:"column:percentage;not null"`
+	ReadAt     time.Time `gorm:"column:read_at;not null;index"`
+	CreatedAt  time.Time `gorm:"column:created_at"`
+
+	Book Book `gorm:"foreignKey:book_id;references:id"`
+}
+
+func (*ReadingProgress) TableName() string {
+	return "reading_progresses"
+}
@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+type Review struct {
+	ID        int       `gorm:"column:id;primaryKey"`
+	UserID    int       `gorm:"column:user_id;not null;uniqueIndex:idx_reviews_user_id_book_id"`
+	BookID    int       `gorm:"column:book_id;not null;uniqueIndex:idx_reviews_user_id_book_id;index"`
+	Rating    int       `gorm:"column:rating;not null"`
+	Text      string    `gorm:"column:text"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at"`
+
+	User User `gorm:"foreignKey:user_id;references:id"`
+}
+
+func (*Review) TableName() string {
+	return "reviews"
+}
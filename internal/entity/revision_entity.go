@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+const (
+	RevisionEntityAuthor = "author"
+	RevisionEntityBook   = "book"
+)
+
+// Revision is a snapshot of an author or a book as it was saved, numbered
+// from 1 for each record. Data holds the snapshot as JSON.
+type Revision struct {
+	ID         int       `gorm:"column:id;primaryKey"`
+	EntityType string    `gorm:"column:entity_type;not null;uniqueIndex:idx_revisions_entity_revision"`
+	EntityID   int       `gorm:"column:entity_id;not null;uniqueIndex:idx_revisions_entity_revision"`
+	Revision   int       `gorm:"column:revision;not null;uniqueIndex:idx_revisions_entity_revision"`
+	Data       string    `gorm:"column:data;not null"`
+	CreatedBy  *int      `gorm:"column:created_by"`
+	CreatedAt  time.Time `gorm:"column:created_at"`
+}
+
+func (*Revision) TableName() string {
+	return "revisions"
+}
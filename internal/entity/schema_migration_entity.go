@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+type SchemaMigration struct {
+	Version   int       `gorm:"column:version;primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"column:name;not null"`
+	Checksum  string    `gorm:"column:checksum;not null"`
+	AppliedAt time.Time `gorm:"column:applied_at;not null"`
+}
+
+func (*SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
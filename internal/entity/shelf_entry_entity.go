@@ -0,0 +1,17 @@
+package entity
+
+import "time"
+
+type ShelfEntry struct {
+	ID        int       `gorm:"column:id;primaryKey"`
+	UserID    int       `gorm:"column:user_id;not null;uniqueIndex:idx_shelf_entries_user_id_shelf_book_id"`
+	Shelf     string    `gorm:"column:shelf;not null;uniqueIndex:idx_shelf_entries_user_id_shelf_book_id"`
+	BookID    int       `gorm:"column:book_id;not null;uniqueIndex:idx_shelf_entries_user_id_shelf_book_id;index"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+
+	Book Book `gorm:"foreignKey:book_id;references:id"`
+}
+
+func (*ShelfEntry) TableName() string {
+	return "shelf_entries"
+}
@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+const (
+	TrashItemTypeUser   = "user"
+	TrashItemTypeAuthor = "author"
+	TrashItemTypeBook   = "book"
+)
+
+// TrashItem is a deleted user, author or book, which stays in the trash until
+// it is restored or purged. Name is the username, the name or the title.
+type TrashItem struct {
+	Type      string    `gorm:"column:type"`
+	ID        int       `gorm:"column:id"`
+	Name      string    `gorm:"column:name"`
+	DeletedAt time.Time `gorm:"column:deleted_at"`
+	DeletedBy *int      `gorm:"column:deleted_by"`
+}
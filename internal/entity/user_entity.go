@@ -1,9 +1,20 @@
 package entity
 
+import "gorm.io/gorm"
+
+const (
+	UserRoleUser      = "user"
+	UserRoleModerator = "moderator"
+	UserRoleAdmin     = "admin"
+)
+
 type User struct {
-	ID       int    `gorm:"column:id;primaryKey"`
-	Username string `gorm:"column:username;not null;unique"`
-	Password string `gorm:"column:password"`
+	ID        int            `gorm:"column:id;primaryKey"`
+	Username  string         `gorm:"column:username;not null;unique"`
+	Password  string         `gorm:"column:password"`
+	Role      string         `gorm:"column:role;not null;default:user"`
+	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at;index"`
+	DeletedBy *int           `gorm:"column:deleted_by"`
 }
 
 func (*User) TableName() string {
@@ -0,0 +1,92 @@
+package bibtex
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+type Field struct {
+	Name  string
+	Value string
+}
+
+type Entry struct {
+	Type   string
+	Key    string
+	Fields []Field
+}
+
+type Writer struct {
+	w       io.Writer
+	written bool
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// Write writes an entry, skipping fields with empty values. Entries are
+// separated by a blank line.
+func (w *Writer) Write(entry *Entry) error {
+	var b strings.Builder
+	if w.written {
+		b.WriteString("\n")
+	}
+
+	fmt.Fprintf(&b, "@%s{%s", entry.Type, entry.Key)
+	for _, field := range entry.Fields {
+		if field.Value == "" {
+			continue
+		}
+		fmt.Fprintf(&b, ",\n  %s = {%s}", field.Name, Escape(field.Value))
+	}
+	b.WriteString("\n}\n")
+
+	if _, err := io.WriteString(w.w, b.String()); err != nil {
+		return err
+	}
+	w.written = true
+	return nil
+}
+
+var escaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`&`, `\&`,
+	`%`, `\%`,
+	`$`, `\$`,
+	`#`, `\#`,
+	`_`, `\_`,
+	`~`, `\textasciitilde{}`,
+	`^`, `\textasciicircum{}`,
+)
+
+// Escape escapes the characters that have a special meaning in LaTeX.
+func Escape(value string) string {
+	return escaper.Replace(value)
+}
+
+// Key builds a citation key from a name and a suffix, keeping only ASCII
+// letters and digits, e.g. Key("George Orwell", 1) is "orwell1".
+func Key(name string, suffix any) string {
+	fields := strings.Fields(name)
+	last := ""
+	if len(fields) > 0 {
+		last = fields[len(fields)-1]
+	}
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(last) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() == 0 {
+		b.WriteString("book")
+	}
+	fmt.Fprint(&b, suffix)
+	return b.String()
+}
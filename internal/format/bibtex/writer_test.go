@@ -0,0 +1,54 @@
+package bibtex_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/format/bibtex"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestWriter(t *testing.T) {
+	t.Run("Positive Case - entries", func(t *testing.T) {
+		var b strings.Builder
+		w := bibtex.NewWriter(&b)
+		assert.NoError(t, w.Write(&bibtex.Entry{
+			Type: "book",
+			Key:  bibtex.Key("George Orwell", 1),
+			Fields: []bibtex.Field{
+				{Name: "title", Value: "Nineteen Eighty-Four {100% & $5_#1}"},
+				{Name: "author", Value: "George Orwell"},
+				{Name: "isbn", Value: ""},
+			},
+		}))
+		assert.NoError(t, w.Write(&bibtex.Entry{Type: "book", Key: bibtex.Key("", 2)}))
+		assert.EqualValues(t, "@book{orwell1,\n"+
+			"  title = {Nineteen Eighty-Four \\{100\\% \\& \\$5\\_\\#1\\}},\n"+
+			"  author = {George Orwell}\n"+
+			"}\n"+
+			"\n"+
+			"@book{book2\n"+
+			"}\n", b.String())
+	})
+
+	t.Run("Negative Case - failing writer", func(t *testing.T) {
+		err := bibtex.NewWriter(failingWriter{}).Write(&bibtex.Entry{Type: "book", Key: "orwell1"})
+		assert.Error(t, err)
+	})
+}
+
+func TestEscape(t *testing.T) {
+	assert.EqualValues(t, `\textbackslash{}\textasciitilde{}\textasciicircum{}`, bibtex.Escape(`\~^`))
+}
+
+func TestKey(t *testing.T) {
+	assert.EqualValues(t, "garcamrquez7", bibtex.Key("Gabriel García-Márquez", 7))
+	assert.EqualValues(t, "book3", bibtex.Key("李白", 3))
+}
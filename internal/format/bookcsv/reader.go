@@ -0,0 +1,156 @@
+package bookcsv
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	birthdateLayout = "2006-01-02"
+	bom             = "\ufeff"
+)
+
+const (
+	ColumnTitle           = "title"
+	ColumnISBN            = "isbn"
+	ColumnAuthor          = "author"
+	ColumnAuthorBirthdate = "author_birthdate"
+	ColumnPageCount       = "page_count"
+)
+
+var columnAliases = map[string]string{
+	"author_name": ColumnAuthor,
+	"pages":       ColumnPageCount,
+}
+
+// Row is a single data row of a book CSV file. Err is set when the row is
+// malformed, so one bad row does not prevent the others from being read.
+type Row struct {
+	Line            int
+	Title           string
+	ISBN            string
+	Author          string
+	AuthorBirthdate *time.Time
+	PageCount       *int
+	Err             error
+}
+
+// Read parses a CSV file whose first line is a header naming the title, isbn
+// and author columns. The author_birthdate (YYYY-MM-DD) and page_count columns
+// are optional and unknown columns are ignored.
+func Read(r io.Reader) ([]Row, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("missing header")
+		}
+		return nil, err
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, bom)))
+		if alias, ok := columnAliases[name]; ok {
+			name = alias
+		}
+		if _, ok := columns[name]; !ok {
+			columns[name] = i
+		}
+	}
+	for _, required := range []string{ColumnTitle, ColumnISBN, ColumnAuthor} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("missing %s column", required)
+		}
+	}
+
+	var rows []Row
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return nil, fmt.Errorf("line %d: %w", parseErr.Line, parseErr.Err)
+			}
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		if isBlank(record) {
+			continue
+		}
+
+		rows = append(rows, parseRow(line, record, columns))
+	}
+
+	return rows, nil
+}
+
+func parseRow(line int, record []string, columns map[string]int) Row {
+	field := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	row := Row{
+		Line:   line,
+		Title:  field(ColumnTitle),
+		ISBN:   field(ColumnISBN),
+		Author: field(ColumnAuthor),
+	}
+
+	switch {
+	case row.Title == "":
+		row.Err = errors.New("title is required")
+		return row
+	case row.ISBN == "":
+		row.Err = errors.New("isbn is required")
+		return row
+	case row.Author == "":
+		row.Err = errors.New("author is required")
+		return row
+	}
+
+	if value := field(ColumnAuthorBirthdate); value != "" {
+		birthdate, err := time.Parse(birthdateLayout, value)
+		if err != nil {
+			row.Err = fmt.Errorf("invalid author_birthdate %q, expected YYYY-MM-DD", value)
+			return row
+		}
+		row.AuthorBirthdate = &birthdate
+	}
+
+	if value := field(ColumnPageCount); value != "" {
+		pageCount, err := strconv.Atoi(value)
+		if err != nil || pageCount < 0 {
+			row.Err = fmt.Errorf("invalid page_count %q", value)
+			return row
+		}
+		row.PageCount = &pageCount
+	}
+
+	return row
+}
+
+func isBlank(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
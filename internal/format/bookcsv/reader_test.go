@@ -0,0 +1,64 @@
+package bookcsv_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/format/bookcsv"
+	"github.com/mnaufalhilmym/bookshelf/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRead(t *testing.T) {
+	t.Run("Positive Case - rows with optional and aliased columns", func(t *testing.T) {
+		rows, err := bookcsv.Read(strings.NewReader("\ufeffTitle,ISBN,author_name,author_birthdate,pages,shelf\n" +
+			"1984,9780451524935,George Orwell,1903-06-25,328,read\n" +
+			",,,,\n" +
+			"Dune,9780441172719,Frank Herbert,,,\n"))
+		assert.NoError(t, err)
+		assert.EqualValues(t, []bookcsv.Row{
+			{
+				Line:            2,
+				Title:           "1984",
+				ISBN:            "9780451524935",
+				Author:          "George Orwell",
+				AuthorBirthdate: util.ToPointer(time.Date(1903, time.June, 25, 0, 0, 0, 0, time.UTC)),
+				PageCount:       util.ToPointer(328),
+			},
+			{
+				Line:   4,
+				Title:  "Dune",
+				ISBN:   "9780441172719",
+				Author: "Frank Herbert",
+			},
+		}, rows)
+	})
+
+	t.Run("Positive Case - malformed rows are reported one by one", func(t *testing.T) {
+		rows, err := bookcsv.Read(strings.NewReader("title,isbn,author,author_birthdate,page_count\n" +
+			",9780451524935,George Orwell,,\n" +
+			"1984,,George Orwell,,\n" +
+			"1984,9780451524935,,,\n" +
+			"1984,9780451524935,George Orwell,25/06/1903,\n" +
+			"1984,9780451524935,George Orwell,,-1\n" +
+			"1984,9780451524935,George Orwell\n"))
+		assert.NoError(t, err)
+		assert.Len(t, rows, 6)
+		for _, row := range rows[:5] {
+			assert.Error(t, row.Err, "line %d", row.Line)
+		}
+		assert.NoError(t, rows[5].Err)
+	})
+
+	t.Run("Negative Case - malformed file", func(t *testing.T) {
+		for name, input := range map[string]string{
+			"missing header":        "",
+			"missing author column": "title,isbn\n1984,9780451524935\n",
+			"unterminated quote":    "title,isbn,author\n\"1984,9780451524935,George Orwell\n",
+		} {
+			_, err := bookcsv.Read(strings.NewReader(input))
+			assert.Error(t, err, name)
+		}
+	})
+}
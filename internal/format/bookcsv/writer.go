@@ -0,0 +1,89 @@
+package bookcsv
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+const (
+	ColumnID            = "id"
+	ColumnAuthorID      = "author_id"
+	ColumnAverageRating = "average_rating"
+	ColumnRatingCount   = "rating_count"
+)
+
+var writerColumns = []string{
+	ColumnID,
+	ColumnTitle,
+	ColumnISBN,
+	ColumnAuthor,
+	ColumnAuthorBirthdate,
+	ColumnPageCount,
+	ColumnAuthorID,
+	ColumnAverageRating,
+	ColumnRatingCount,
+}
+
+type Record struct {
+	ID              int
+	Title           string
+	ISBN            string
+	AuthorID        int
+	Author          string
+	AuthorBirthdate time.Time
+	PageCount       int
+	AverageRating   float64
+	RatingCount     int64
+}
+
+// Writer writes book records with a header that Read accepts, so an exported
+// file can be imported again.
+type Writer struct {
+	writer        *csv.Writer
+	headerWritten bool
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{writer: csv.NewWriter(w)}
+}
+
+func (w *Writer) Write(record *Record) error {
+	if !w.headerWritten {
+		if err := w.writer.Write(writerColumns); err != nil {
+			return err
+		}
+		w.headerWritten = true
+	}
+
+	birthdate := ""
+	if !record.AuthorBirthdate.IsZero() {
+		birthdate = record.AuthorBirthdate.Format(birthdateLayout)
+	}
+
+	return w.writer.Write([]string{
+		strconv.Itoa(record.ID),
+		record.Title,
+		record.ISBN,
+		record.Author,
+		birthdate,
+		strconv.Itoa(record.PageCount),
+		strconv.Itoa(record.AuthorID),
+		strconv.FormatFloat(record.AverageRating, 'f', -1, 64),
+		strconv.FormatInt(record.RatingCount, 10),
+	})
+}
+
+// Flush writes buffered records and writes the header if no record was
+// written, so an empty export is still a valid file.
+func (w *Writer) Flush() error {
+	if !w.headerWritten {
+		if err := w.writer.Write(writerColumns); err != nil {
+			return err
+		}
+		w.headerWritten = true
+	}
+	w.writer.Flush()
+	return w.writer.Error()
+}
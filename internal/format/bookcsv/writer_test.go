@@ -0,0 +1,61 @@
+package bookcsv_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/format/bookcsv"
+	"github.com/mnaufalhilmym/bookshelf/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriter(t *testing.T) {
+	t.Run("Positive Case - round trip", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := bookcsv.NewWriter(&buf)
+		assert.NoError(t, w.Write(&bookcsv.Record{
+			ID:              1,
+			Title:           "Nineteen Eighty-Four, a Novel",
+			ISBN:            "9780451524935",
+			AuthorID:        1,
+			Author:          "George Orwell",
+			AuthorBirthdate: time.Date(1903, time.June, 25, 0, 0, 0, 0, time.UTC),
+			PageCount:       328,
+			AverageRating:   4.5,
+			RatingCount:     2,
+		}))
+		assert.NoError(t, w.Write(&bookcsv.Record{ID: 2, Title: "Dune", ISBN: "9780441172719", AuthorID: 2, Author: "Frank Herbert"}))
+		assert.NoError(t, w.Flush())
+
+		rows, err := bookcsv.Read(&buf)
+		assert.NoError(t, err)
+		assert.EqualValues(t, []bookcsv.Row{
+			{
+				Line:            2,
+				Title:           "Nineteen Eighty-Four, a Novel",
+				ISBN:            "9780451524935",
+				Author:          "George Orwell",
+				AuthorBirthdate: util.ToPointer(time.Date(1903, time.June, 25, 0, 0, 0, 0, time.UTC)),
+				PageCount:       util.ToPointer(328),
+			},
+			{
+				Line:      3,
+				Title:     "Dune",
+				ISBN:      "9780441172719",
+				Author:    "Frank Herbert",
+				PageCount: util.ToPointer(0),
+			},
+		}, rows)
+	})
+
+	t.Run("Positive Case - empty export has a header", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, bookcsv.NewWriter(&buf).Flush())
+		assert.EqualValues(t, "id,title,isbn,author,author_birthdate,page_count,author_id,average_rating,rating_count\n", buf.String())
+
+		rows, err := bookcsv.Read(&buf)
+		assert.NoError(t, err)
+		assert.Empty(t, rows)
+	})
+}
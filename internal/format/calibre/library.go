@@ -0,0 +1,145 @@
+package calibre
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type Book struct {
+	ID          int
+	UUID        string
+	Title       string
+	Authors     []string
+	ISBN        string
+	Tags        []string
+	Series      string
+	SeriesIndex float64
+	Language    string
+}
+
+// Read loads the books of a Calibre library from its metadata.db file. The
+// file is opened read-only and is never modified.
+func Read(path string) ([]Book, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+
+	db, err := gorm.Open(sqlite.Open("file:"+path+"?mode=ro&immutable=1"), &gorm.Config{
+		Logger: logger.Discard,
+	})
+	if err != nil {
+		return nil, err
+	}
+	connection, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	defer connection.Close()
+
+	for _, table := range []string{"books", "authors", "books_authors_link", "identifiers"} {
+		if !db.Migrator().HasTable(table) {
+			return nil, fmt.Errorf("not a calibre library, missing %s table", table)
+		}
+	}
+
+	var rows []struct {
+		ID          int
+		UUID        string
+		Title       string
+		ISBN        string
+		SeriesIndex float64
+	}
+	if err := db.Raw("SELECT id, COALESCE(uuid, '') AS uuid, title, COALESCE(isbn, '') AS isbn, COALESCE(series_index, 0) AS series_index FROM books ORDER BY id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	books := make([]Book, len(rows))
+	index := make(map[int]*Book, len(rows))
+	for i, row := range rows {
+		books[i] = Book{
+			ID:          row.ID,
+			UUID:        row.UUID,
+			Title:       row.Title,
+			ISBN:        row.ISBN,
+			SeriesIndex: row.SeriesIndex,
+		}
+		index[row.ID] = &books[i]
+	}
+
+	var links []struct {
+		Book  int
+		Value string
+	}
+
+	if err := db.Raw("SELECT l.book AS book, a.name AS value FROM books_authors_link l JOIN authors a ON a.id = l.author ORDER BY l.id").
+		Scan(&links).Error; err != nil {
+		return nil, err
+	}
+	for _, link := range links {
+		if book, ok := index[link.Book]; ok {
+			book.Authors = append(book.Authors, strings.ReplaceAll(link.Value, "|", ","))
+		}
+	}
+
+	// The isbn column of the books table is legacy, newer libraries keep ISBNs
+	// in identifiers, which therefore take precedence.
+	links = links[:0]
+	if err := db.Raw("SELECT book, val AS value FROM identifiers WHERE LOWER(type) = 'isbn' ORDER BY id").
+		Scan(&links).Error; err != nil {
+		return nil, err
+	}
+	for _, link := range links {
+		if book, ok := index[link.Book]; ok {
+			book.ISBN = link.Value
+		}
+	}
+
+	if db.Migrator().HasTable("books_tags_link") && db.Migrator().HasTable("tags") {
+		links = links[:0]
+		if err := db.Raw("SELECT l.book AS book, t.name AS value FROM books_tags_link l JOIN tags t ON t.id = l.tag ORDER BY t.name").
+			Scan(&links).Error; err != nil {
+			return nil, err
+		}
+		for _, link := range links {
+			if book, ok := index[link.Book]; ok {
+				book.Tags = append(book.Tags, link.Value)
+			}
+		}
+	}
+
+	if db.Migrator().HasTable("books_series_link") && db.Migrator().HasTable("series") {
+		links = links[:0]
+		if err := db.Raw("SELECT l.book AS book, s.name AS value FROM books_series_link l JOIN series s ON s.id = l.series").
+			Scan(&links).Error; err != nil {
+			return nil, err
+		}
+		for _, link := range links {
+			if book, ok := index[link.Book]; ok {
+				book.Series = link.Value
+			}
+		}
+	}
+
+	// Calibre keeps ISO 639 codes, such as eng, and a book may have several
+	// languages of which the first is kept.
+	if db.Migrator().HasTable("books_languages_link") && db.Migrator().HasTable("languages") {
+		links = links[:0]
+		if err := db.Raw("SELECT l.book AS book, g.lang_code AS value FROM books_languages_link l JOIN languages g ON g.id = l.lang_code ORDER BY l.item_order DESC").
+			Scan(&links).Error; err != nil {
+			return nil, err
+		}
+		for _, link := range links {
+			if book, ok := index[link.Book]; ok {
+				book.Language = link.Value
+			}
+		}
+	}
+
+	return books, nil
+}
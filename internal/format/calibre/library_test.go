@@ -0,0 +1,107 @@
+package calibre_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/format/calibre"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newLibrary(t *testing.T, statements ...string) string {
+	path := filepath.Join(t.TempDir(), "metadata.db")
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{Logger: logger.Discard})
+	assert.NoError(t, err)
+
+	for _, statement := range statements {
+		assert.NoError(t, db.Exec(statement).Error)
+	}
+
+	connection, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, connection.Close())
+
+	return path
+}
+
+var schema = []string{
+	"CREATE TABLE books (id INTEGER PRIMARY KEY, title TEXT NOT NULL, isbn TEXT DEFAULT '', uuid TEXT, series_index REAL NOT NULL DEFAULT 1.0)",
+	"CREATE TABLE authors (id INTEGER PRIMARY KEY, name TEXT NOT NULL)",
+	"CREATE TABLE books_authors_link (id INTEGER PRIMARY KEY, book INTEGER NOT NULL, author INTEGER NOT NULL)",
+	"CREATE TABLE identifiers (id INTEGER PRIMARY KEY, book INTEGER NOT NULL, type TEXT NOT NULL DEFAULT 'isbn', val TEXT NOT NULL)",
+}
+
+func TestRead(t *testing.T) {
+	t.Run("Positive Case - books with authors, identifiers, tags, series and languages", func(t *testing.T) {
+		path := newLibrary(t, append(schema,
+			"CREATE TABLE tags (id INTEGER PRIMARY KEY, name TEXT NOT NULL)",
+			"CREATE TABLE books_tags_link (id INTEGER PRIMARY KEY, book INTEGER NOT NULL, tag INTEGER NOT NULL)",
+			"CREATE TABLE series (id INTEGER PRIMARY KEY, name TEXT NOT NULL)",
+			"CREATE TABLE books_series_link (id INTEGER PRIMARY KEY, book INTEGER NOT NULL, series INTEGER NOT NULL)",
+			"CREATE TABLE languages (id INTEGER PRIMARY KEY, lang_code TEXT NOT NULL)",
+			"CREATE TABLE books_languages_link (id INTEGER PRIMARY KEY, book INTEGER NOT NULL, lang_code INTEGER NOT NULL, item_order INTEGER NOT NULL DEFAULT 0)",
+			"INSERT INTO books (id, title, isbn, uuid, series_index) VALUES (1, 'Good Omens', '0000000000', 'a', 1), (2, 'Foundation', NULL, NULL, 2)",
+			"INSERT INTO authors (id, name) VALUES (1, 'Terry Pratchett'), (2, 'Neil Gaiman'), (3, 'Asimov| Isaac')",
+			"INSERT INTO books_authors_link (book, author) VALUES (1, 1), (1, 2), (2, 3)",
+			"INSERT INTO identifiers (book, type, val) VALUES (1, 'ISBN', '9780060853983'), (1, 'goodreads', '12067')",
+			"INSERT INTO tags (id, name) VALUES (1, 'science fiction'), (2, 'classics')",
+			"INSERT INTO books_tags_link (book, tag) VALUES (2, 1), (2, 2)",
+			"INSERT INTO series (id, name) VALUES (1, 'Foundation')",
+			"INSERT INTO books_series_link (book, series) VALUES (2, 1)",
+			"INSERT INTO languages (id, lang_code) VALUES (1, 'fra'), (2, 'eng')",
+			"INSERT INTO books_languages_link (book, lang_code, item_order) VALUES (2, 1, 1), (2, 2, 0)",
+		)...)
+
+		books, err := calibre.Read(path)
+		assert.NoError(t, err)
+		assert.EqualValues(t, []calibre.Book{
+			{
+				ID:          1,
+				UUID:        "a",
+				Title:       "Good Omens",
+				Authors:     []string{"Terry Pratchett", "Neil Gaiman"},
+				ISBN:        "9780060853983",
+				SeriesIndex: 1,
+			},
+			{
+				ID:          2,
+				Title:       "Foundation",
+				Authors:     []string{"Asimov, Isaac"},
+				Tags:        []string{"classics", "science fiction"},
+				Series:      "Foundation",
+				SeriesIndex: 2,
+				Language:    "eng",
+			},
+		}, books)
+	})
+
+	t.Run("Positive Case - optional tables missing", func(t *testing.T) {
+		path := newLibrary(t, append(schema, "INSERT INTO books (id, title) VALUES (1, 'Dune')")...)
+
+		books, err := calibre.Read(path)
+		assert.NoError(t, err)
+		assert.EqualValues(t, []calibre.Book{{ID: 1, Title: "Dune", SeriesIndex: 1}}, books)
+	})
+
+	t.Run("Negative Case - missing file", func(t *testing.T) {
+		_, err := calibre.Read(filepath.Join(t.TempDir(), "metadata.db"))
+		assert.Error(t, err)
+	})
+
+	t.Run("Negative Case - not a calibre library", func(t *testing.T) {
+		_, err := calibre.Read(newLibrary(t, schema[:3]...))
+		assert.ErrorContains(t, err, "missing identifiers table")
+	})
+
+	t.Run("Negative Case - not a database", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "metadata.db")
+		assert.NoError(t, os.WriteFile(path, []byte("title,isbn,author\n"), 0o600))
+
+		_, err := calibre.Read(path)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,74 @@
+// Package dublincore encodes records in the simple Dublin Core element set.
+package dublincore
+
+import "encoding/xml"
+
+const (
+	Namespace      = "http://purl.org/dc/elements/1.1/"
+	OAINamespace   = "http://www.openarchives.org/OAI/2.0/oai_dc/"
+	OAISchema      = "http://www.openarchives.org/OAI/2.0/oai_dc.xsd"
+	TypeText       = "Text"
+	xsiNamespace   = "http://www.w3.org/2001/XMLSchema-instance"
+	schemaLocation = OAINamespace + " " + OAISchema
+)
+
+// Record holds the elements bookshelf describes books with. Every element may
+// repeat, and empty values are left out.
+type Record struct {
+	Titles      []string
+	Creators    []string
+	Subjects    []string
+	Types       []string
+	Formats     []string
+	Identifiers []string
+	Languages   []string
+	Relations   []string
+	Dates       []string
+}
+
+type oaiDC struct {
+	XMLName        xml.Name `xml:"oai_dc:dc"`
+	XmlnsOAIDC     string   `xml:"xmlns:oai_dc,attr"`
+	XmlnsDC        string   `xml:"xmlns:dc,attr"`
+	XmlnsXSI       string   `xml:"xmlns:xsi,attr"`
+	SchemaLocation string   `xml:"xsi:schemaLocation,attr"`
+	Titles         []string `xml:"dc:title"`
+	Creators       []string `xml:"dc:creator"`
+	Subjects       []string `xml:"dc:subject"`
+	Types          []string `xml:"dc:type"`
+	Formats        []string `xml:"dc:format"`
+	Identifiers    []string `xml:"dc:identifier"`
+	Languages      []string `xml:"dc:language"`
+	Relations      []string `xml:"dc:relation"`
+	Dates          []string `xml:"dc:date"`
+}
+
+// MarshalXML encodes the record in the oai_dc container defined by OAI-PMH,
+// which is the usual XML serialization of simple Dublin Core.
+func (r *Record) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	return e.Encode(&oaiDC{
+		XmlnsOAIDC:     OAINamespace,
+		XmlnsDC:        Namespace,
+		XmlnsXSI:       xsiNamespace,
+		SchemaLocation: schemaLocation,
+		Titles:         nonEmpty(r.Titles),
+		Creators:       nonEmpty(r.Creators),
+		Subjects:       nonEmpty(r.Subjects),
+		Types:          nonEmpty(r.Types),
+		Formats:        nonEmpty(r.Formats),
+		Identifiers:    nonEmpty(r.Identifiers),
+		Languages:      nonEmpty(r.Languages),
+		Relations:      nonEmpty(r.Relations),
+		Dates:          nonEmpty(r.Dates),
+	})
+}
+
+func nonEmpty(values []string) []string {
+	var result []string
+	for _, value := range values {
+		if value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
@@ -0,0 +1,81 @@
+package ebook
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+)
+
+const (
+	FormatEPUB = "epub"
+	FormatPDF  = "pdf"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported file format, expected epub or pdf")
+
+// Metadata is what an ebook file tells about itself. Every field may be empty
+// as publishers fill in as little or as much as they like.
+type Metadata struct {
+	Format    string
+	Title     string
+	Authors   []string
+	ISBN      string
+	Language  string
+	Cover     []byte
+	CoverType string
+}
+
+// Detect returns the format of the file from its content, ignoring its name.
+func Detect(content []byte) (string, error) {
+	switch {
+	case bytes.HasPrefix(content, []byte("%PDF-")):
+		return FormatPDF, nil
+	case bytes.HasPrefix(content, []byte("PK\x03\x04")):
+		return FormatEPUB, nil
+	}
+	return "", ErrUnsupportedFormat
+}
+
+// ContentType returns the media type files of the format are served with.
+func ContentType(format string) string {
+	switch format {
+	case FormatEPUB:
+		return "application/epub+zip"
+	case FormatPDF:
+		return "application/pdf"
+	}
+	return "application/octet-stream"
+}
+
+func Read(content []byte) (*Metadata, error) {
+	format, err := Detect(content)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == FormatPDF {
+		return ReadPDF(content)
+	}
+	return ReadEPUB(bytes.NewReader(content), int64(len(content)))
+}
+
+// isbnFrom returns the ISBN-10 or ISBN-13 in value without hyphens and
+// spaces, or an empty string when value is not an ISBN.
+func isbnFrom(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) > 9 && strings.EqualFold(value[:9], "urn:isbn:") {
+		value = value[9:]
+	}
+	value = strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(value))
+
+	if len(value) != 10 && len(value) != 13 {
+		return ""
+	}
+	for i, c := range value {
+		if c >= '0' && c <= '9' || c == 'X' && len(value) == 10 && i == 9 {
+			continue
+		}
+		return ""
+	}
+	return value
+}
@@ -0,0 +1,25 @@
+package ebook_test
+
+import (
+	"testing"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/format/ebook"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetect(t *testing.T) {
+	format, err := ebook.Detect([]byte(pdfDocument))
+	assert.NoError(t, err)
+	assert.EqualValues(t, ebook.FormatPDF, format)
+	assert.EqualValues(t, "application/pdf", ebook.ContentType(format))
+
+	format, err = ebook.Detect(newEPUB(t, map[string]string{"mimetype": "application/epub+zip"}))
+	assert.NoError(t, err)
+	assert.EqualValues(t, ebook.FormatEPUB, format)
+	assert.EqualValues(t, "application/epub+zip", ebook.ContentType(format))
+
+	for _, content := range []string{"", "%PD", "<html></html>"} {
+		_, err = ebook.Read([]byte(content))
+		assert.ErrorIs(t, err, ebook.ErrUnsupportedFormat, content)
+	}
+}
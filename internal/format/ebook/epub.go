@@ -0,0 +1,173 @@
+package ebook
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+const maxCoverSize = 10 << 20
+
+type epubContainer struct {
+	Rootfiles []struct {
+		FullPath  string `xml:"full-path,attr"`
+		MediaType string `xml:"media-type,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+type epubPackage struct {
+	Metadata struct {
+		Titles   []string `xml:"title"`
+		Creators []struct {
+			Role  string `xml:"role,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"creator"`
+		Identifiers []struct {
+			Scheme string `xml:"scheme,attr"`
+			Value  string `xml:",chardata"`
+		} `xml:"identifier"`
+		Languages []string `xml:"language"`
+		Metas     []struct {
+			Name    string `xml:"name,attr"`
+			Content string `xml:"content,attr"`
+		} `xml:"meta"`
+	} `xml:"metadata"`
+	Manifest []struct {
+		ID         string `xml:"id,attr"`
+		Href       string `xml:"href,attr"`
+		MediaType  string `xml:"media-type,attr"`
+		Properties string `xml:"properties,attr"`
+	} `xml:"manifest>item"`
+}
+
+// ReadEPUB reads the metadata of the package document (OPF) the container
+// points to, and the cover image it declares.
+func ReadEPUB(r io.ReaderAt, size int64) (*Metadata, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("invalid epub: %w", err)
+	}
+
+	container := new(epubContainer)
+	if err := decodeZipXML(archive, "META-INF/container.xml", container); err != nil {
+		return nil, err
+	}
+
+	opfPath := ""
+	for _, rootfile := range container.Rootfiles {
+		if rootfile.MediaType == "" || rootfile.MediaType == "application/oebps-package+xml" {
+			opfPath = rootfile.FullPath
+			break
+		}
+	}
+	if opfPath == "" {
+		return nil, errors.New("invalid epub: container has no package document")
+	}
+
+	pkg := new(epubPackage)
+	if err := decodeZipXML(archive, opfPath, pkg); err != nil {
+		return nil, err
+	}
+
+	metadata := &Metadata{Format: FormatEPUB}
+
+	if len(pkg.Metadata.Titles) > 0 {
+		metadata.Title = strings.TrimSpace(pkg.Metadata.Titles[0])
+	}
+
+	for _, creator := range pkg.Metadata.Creators {
+		if name := strings.TrimSpace(creator.Value); name != "" && (creator.Role == "" || creator.Role == "aut") {
+			metadata.Authors = append(metadata.Authors, name)
+		}
+	}
+
+	for _, identifier := range pkg.Metadata.Identifiers {
+		isbn := isbnFrom(identifier.Value)
+		if isbn != "" && (strings.EqualFold(identifier.Scheme, "isbn") || strings.HasPrefix(strings.ToLower(identifier.Value), "urn:isbn:") || identifier.Scheme == "") {
+			metadata.ISBN = isbn
+			break
+		}
+	}
+
+	if len(pkg.Metadata.Languages) > 0 {
+		metadata.Language = strings.TrimSpace(pkg.Metadata.Languages[0])
+	}
+
+	if href := epubCoverHref(pkg); href != "" {
+		name := path.Join(path.Dir(opfPath), href)
+		if unescaped, err := url.PathUnescape(name); err == nil {
+			name = unescaped
+		}
+
+		// A missing or unreadable cover leaves the book without one rather
+		// than rejecting an otherwise valid file.
+		if cover, err := readZipFile(archive, name, maxCoverSize); err == nil {
+			if contentType := http.DetectContentType(cover); strings.HasPrefix(contentType, "image/") {
+				metadata.Cover = cover
+				metadata.CoverType = contentType
+			}
+		}
+	}
+
+	return metadata, nil
+}
+
+// epubCoverHref finds the cover the EPUB 3 way, as the manifest item with the
+// cover-image property, then the EPUB 2 way, as the item named by the cover
+// meta element.
+func epubCoverHref(pkg *epubPackage) string {
+	for _, item := range pkg.Manifest {
+		for _, property := range strings.Fields(item.Properties) {
+			if property == "cover-image" {
+				return item.Href
+			}
+		}
+	}
+
+	for _, meta := range pkg.Metadata.Metas {
+		if meta.Name != "cover" {
+			continue
+		}
+		for _, item := range pkg.Manifest {
+			if item.ID == meta.Content && strings.HasPrefix(item.MediaType, "image/") {
+				return item.Href
+			}
+		}
+	}
+
+	return ""
+}
+
+func decodeZipXML(archive *zip.Reader, name string, v any) error {
+	content, err := readZipFile(archive, name, 1<<20)
+	if err != nil {
+		return err
+	}
+	if err := xml.Unmarshal(content, v); err != nil {
+		return fmt.Errorf("invalid epub: %s: %w", name, err)
+	}
+	return nil
+}
+
+func readZipFile(archive *zip.Reader, name string, limit int64) ([]byte, error) {
+	file, err := archive.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid epub: missing %s", name)
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(io.LimitReader(file, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("invalid epub: %s: %w", name, err)
+	}
+	if int64(len(content)) > limit {
+		return nil, fmt.Errorf("invalid epub: %s is too large", name)
+	}
+	return content, nil
+}
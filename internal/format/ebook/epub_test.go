@@ -0,0 +1,99 @@
+package ebook_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/format/ebook"
+	"github.com/stretchr/testify/assert"
+)
+
+const container = `<?xml version="1.0"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>`
+
+const opf = `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="uid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:title> Good Omens </dc:title>
+    <dc:creator>Terry Pratchett</dc:creator>
+    <dc:creator>Neil Gaiman</dc:creator>
+    <dc:identifier>9b2b7a4e-1f7e-4c4b-8a4e-2f6f2d1f0a11</dc:identifier>
+    <dc:identifier id="uid">urn:isbn:978-0-06-085398-3</dc:identifier>
+    <dc:language>en</dc:language>
+  </metadata>
+  <manifest>
+    <item id="cover" href="images/cover%20art.gif" media-type="image/gif" properties="cover-image"/>
+  </manifest>
+</package>`
+
+var gifImage = []byte("GIF89a\x01\x00\x01\x00\x00\x00\x00;")
+
+func newEPUB(t *testing.T, files map[string]string) []byte {
+	buf := new(bytes.Buffer)
+	archive := zip.NewWriter(buf)
+	for name, content := range files {
+		w, err := archive.Create(name)
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, archive.Close())
+	return buf.Bytes()
+}
+
+func TestReadEPUB(t *testing.T) {
+	t.Run("Positive Case - metadata and cover", func(t *testing.T) {
+		content := newEPUB(t, map[string]string{
+			"mimetype":                   "application/epub+zip",
+			"META-INF/container.xml":     container,
+			"OEBPS/content.opf":          opf,
+			"OEBPS/images/cover art.gif": string(gifImage),
+		})
+
+		metadata, err := ebook.Read(content)
+		assert.NoError(t, err)
+		assert.EqualValues(t, &ebook.Metadata{
+			Format:    ebook.FormatEPUB,
+			Title:     "Good Omens",
+			Authors:   []string{"Terry Pratchett", "Neil Gaiman"},
+			ISBN:      "9780060853983",
+			Language:  "en",
+			Cover:     gifImage,
+			CoverType: "image/gif",
+		}, metadata)
+	})
+
+	t.Run("Positive Case - missing cover", func(t *testing.T) {
+		metadata, err := ebook.Read(newEPUB(t, map[string]string{
+			"META-INF/container.xml": container,
+			"OEBPS/content.opf":      opf,
+		}))
+		assert.NoError(t, err)
+		assert.EqualValues(t, "Good Omens", metadata.Title)
+		assert.Nil(t, metadata.Cover)
+	})
+
+	t.Run("Negative Case - malformed files", func(t *testing.T) {
+		archive := newEPUB(t, map[string]string{"META-INF/container.xml": container, "OEBPS/content.opf": opf})
+		for name, content := range map[string][]byte{
+			"truncated archive": archive[:len(archive)/2],
+			"missing container": newEPUB(t, map[string]string{"OEBPS/content.opf": opf}),
+			"no package document": newEPUB(t, map[string]string{
+				"META-INF/container.xml": `<container><rootfiles/></container>`,
+			}),
+			"missing package document": newEPUB(t, map[string]string{"META-INF/container.xml": container}),
+			"malformed package document": newEPUB(t, map[string]string{
+				"META-INF/container.xml": container,
+				"OEBPS/content.opf":      `<package><metadata><title>Good Omens</metadata></package>`,
+			}),
+		} {
+			_, err := ebook.ReadEPUB(bytes.NewReader(content), int64(len(content)))
+			assert.Error(t, err, name)
+		}
+	})
+}
@@ -0,0 +1,254 @@
+package ebook
+
+import (
+	"bytes"
+	"encoding/hex"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+var (
+	pdfInfoPattern = regexp.MustCompile(`/Info\s+(\d+)\s+(\d+)\s+R`)
+	pdfLangPattern = regexp.MustCompile(`/Lang\s*\(([^()\\]*)\)`)
+	pdfISBNPattern = regexp.MustCompile(`(?i)ISBN(?:-1[03])?[:\s]*((?:97[89][-\s]?)?(?:\d[-\s]?){9}[\dX])`)
+)
+
+// ReadPDF reads the document information dictionary and the catalog language
+// of a PDF. Documents that keep them in compressed object streams are
+// accepted but yield no metadata. PDF has no notion of a cover, so none is
+// extracted.
+func ReadPDF(content []byte) (*Metadata, error) {
+	metadata := &Metadata{Format: FormatPDF}
+
+	// The last trailer wins, since incremental updates append a new one.
+	refs := pdfInfoPattern.FindAllSubmatch(content, -1)
+	if len(refs) > 0 {
+		ref := refs[len(refs)-1]
+		if info := pdfObjectDictionary(content, string(ref[1]), string(ref[2])); info != nil {
+			metadata.Title = strings.TrimSpace(info["Title"])
+			for _, author := range strings.Split(info["Author"], ";") {
+				if author = strings.TrimSpace(author); author != "" {
+					metadata.Authors = append(metadata.Authors, author)
+				}
+			}
+			for _, key := range []string{"Subject", "Keywords", "Title"} {
+				if matches := pdfISBNPattern.FindStringSubmatch(info[key]); matches != nil {
+					if isbn := isbnFrom(matches[1]); isbn != "" {
+						metadata.ISBN = isbn
+						break
+					}
+				}
+			}
+		}
+	}
+
+	if matches := pdfLangPattern.FindSubmatch(content); matches != nil {
+		metadata.Language = strings.TrimSpace(string(matches[1]))
+	}
+
+	return metadata, nil
+}
+
+// pdfObjectDictionary returns the string entries of the dictionary of an
+// indirect object, or nil when the object is not found.
+func pdfObjectDictionary(content []byte, number string, generation string) map[string]string {
+	pattern := regexp.MustCompile(`(?:^|[\s>])` + number + `\s+` + generation + `\s+obj\s*<<`)
+	loc := pattern.FindIndex(content)
+	if loc == nil {
+		return nil
+	}
+
+	entries := map[string]string{}
+	p := &pdfParser{content: content, pos: loc[1]}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.content) || p.peek(">>") {
+			return entries
+		}
+		if p.content[p.pos] != '/' {
+			return entries
+		}
+
+		key := p.readName()
+		p.skipSpace()
+
+		value, ok := p.readString()
+		if ok {
+			entries[key] = value
+		} else if !p.skipValue() {
+			return entries
+		}
+	}
+}
+
+type pdfParser struct {
+	content []byte
+	pos     int
+}
+
+func (p *pdfParser) peek(s string) bool {
+	return bytes.HasPrefix(p.content[p.pos:], []byte(s))
+}
+
+func (p *pdfParser) skipSpace() {
+	for p.pos < len(p.content) && strings.IndexByte(" \t\r\n\f\x00", p.content[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *pdfParser) readName() string {
+	start := p.pos + 1
+	p.pos = start
+	for p.pos < len(p.content) && strings.IndexByte(" \t\r\n\f\x00/<>()[]{}%", p.content[p.pos]) < 0 {
+		p.pos++
+	}
+	return string(p.content[start:p.pos])
+}
+
+// readString reads a literal "(...)" or hexadecimal "<...>" string.
+func (p *pdfParser) readString() (string, bool) {
+	if p.pos >= len(p.content) {
+		return "", false
+	}
+
+	switch {
+	case p.content[p.pos] == '(':
+		return decodePDFText(p.readLiteral()), true
+	case p.content[p.pos] == '<' && !p.peek("<<"):
+		end := bytes.IndexByte(p.content[p.pos:], '>')
+		if end < 0 {
+			return "", false
+		}
+		digits := strings.Join(strings.Fields(string(p.content[p.pos+1:p.pos+end])), "")
+		p.pos += end + 1
+		if len(digits)%2 == 1 {
+			digits += "0"
+		}
+		value, err := hex.DecodeString(digits)
+		if err != nil {
+			return "", true
+		}
+		return decodePDFText(value), true
+	}
+	return "", false
+}
+
+func (p *pdfParser) readLiteral() []byte {
+	var value []byte
+	depth := 0
+	p.pos++
+	for p.pos < len(p.content) {
+		c := p.content[p.pos]
+		p.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				return value
+			}
+			depth--
+		case '\\':
+			if p.pos >= len(p.content) {
+				return value
+			}
+			c = p.content[p.pos]
+			p.pos++
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r', '\n':
+				// A backslash at the end of a line continues the string.
+				if c == '\r' && p.pos < len(p.content) && p.content[p.pos] == '\n' {
+					p.pos++
+				}
+				continue
+			default:
+				if c >= '0' && c <= '7' {
+					end := p.pos
+					for end < len(p.content) && end < p.pos+2 && p.content[end] >= '0' && p.content[end] <= '7' {
+						end++
+					}
+					octal, _ := strconv.ParseUint(string(p.content[p.pos-1:end]), 8, 8)
+					p.pos = end
+					c = byte(octal)
+				}
+			}
+		}
+		value = append(value, c)
+	}
+	return value
+}
+
+// skipValue skips a value that is not a string, such as a number, a name, a
+// reference, an array or a nested dictionary.
+func (p *pdfParser) skipValue() bool {
+	if p.pos < len(p.content) && p.content[p.pos] == '/' {
+		p.readName()
+		return true
+	}
+
+	depth := 0
+	for p.pos < len(p.content) {
+		c := p.content[p.pos]
+		switch {
+		case p.peek("<<"):
+			depth++
+			p.pos += 2
+		case c == '[':
+			depth++
+			p.pos++
+		case p.peek(">>") || c == ']':
+			if depth == 0 {
+				return true
+			}
+			depth--
+			if c == ']' {
+				p.pos++
+			} else {
+				p.pos += 2
+			}
+			if depth == 0 {
+				return true
+			}
+		case c == '(':
+			p.readLiteral()
+			if depth == 0 {
+				return true
+			}
+		case c == '/' && depth == 0:
+			return true
+		default:
+			p.pos++
+		}
+	}
+	return false
+}
+
+// decodePDFText decodes UTF-16BE text marked with a byte order mark and reads
+// anything else as Latin-1, which PDFDocEncoding matches for letters.
+func decodePDFText(value []byte) string {
+	if len(value) >= 2 && value[0] == 0xFE && value[1] == 0xFF {
+		units := make([]uint16, 0, len(value)/2)
+		for i := 2; i+1 < len(value); i += 2 {
+			units = append(units, uint16(value[i])<<8|uint16(value[i+1]))
+		}
+		return string(utf16.Decode(units))
+	}
+
+	runes := make([]rune, len(value))
+	for i, b := range value {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
@@ -0,0 +1,45 @@
+package ebook_test
+
+import (
+	"testing"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/format/ebook"
+	"github.com/stretchr/testify/assert"
+)
+
+const pdfDocument = "%PDF-1.4\n" +
+	"1 0 obj\n<< /Type /Catalog /Pages 2 0 R /Lang (de) >>\nendobj\n" +
+	"2 0 obj\n<< /Type /Pages /Kids [] /Count 0 >>\nendobj\n" +
+	"3 0 obj\n<< /Title <FEFF0044007500660074> /Author (Terry Pratchett; Neil Gaiman) /Producer (Writer \\(1.0\\)) /Keywords (ISBN-13: 978-0-06-085398-3) >>\nendobj\n" +
+	"4 0 obj\n<< /Title (Good Omens) /Author (Terry Pratchett; Neil Gaiman) /Subject (Caf\\351 [ISBN 978-0-06-085398-3]) /Pages [1 0 R] >>\nendobj\n" +
+	"trailer\n<< /Size 4 /Root 1 0 R /Info 3 0 R >>\n" +
+	"trailer\n<< /Size 5 /Root 1 0 R /Info 4 0 R /Prev 0 >>\n%%EOF\n"
+
+func TestReadPDF(t *testing.T) {
+	t.Run("Positive Case - information dictionary of the last trailer", func(t *testing.T) {
+		metadata, err := ebook.Read([]byte(pdfDocument))
+		assert.NoError(t, err)
+		assert.EqualValues(t, &ebook.Metadata{
+			Format:   ebook.FormatPDF,
+			Title:    "Good Omens",
+			Authors:  []string{"Terry Pratchett", "Neil Gaiman"},
+			ISBN:     "9780060853983",
+			Language: "de",
+		}, metadata)
+	})
+
+	t.Run("Positive Case - no information dictionary", func(t *testing.T) {
+		metadata, err := ebook.ReadPDF([]byte("%PDF-1.4\n%%EOF\n"))
+		assert.NoError(t, err)
+		assert.EqualValues(t, &ebook.Metadata{Format: ebook.FormatPDF}, metadata)
+	})
+
+	t.Run("Negative Case - truncated document", func(t *testing.T) {
+		for i := range pdfDocument {
+			assert.NotPanics(t, func() {
+				_, err := ebook.ReadPDF([]byte(pdfDocument[:i]))
+				assert.NoError(t, err)
+			}, "truncated at %d", i)
+		}
+	})
+}
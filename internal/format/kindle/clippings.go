@@ -0,0 +1,180 @@
+package kindle
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	KindHighlight = "highlight"
+	KindNote      = "note"
+	KindBookmark  = "bookmark"
+)
+
+const (
+	separator = "=========="
+	bom       = "\ufeff"
+)
+
+type Clipping struct {
+	Title    string
+	Author   string
+	Kind     string
+	Page     int
+	Location string
+	AddedAt  time.Time
+	Text     string
+}
+
+// LocationEnd returns the last location of the clipping range, which is where
+// Kindle anchors a note attached to a highlight.
+func (c *Clipping) LocationEnd() string {
+	if i := strings.LastIndex(c.Location, "-"); i >= 0 {
+		return c.Location[i+1:]
+	}
+	return c.Location
+}
+
+var (
+	titleAuthorPattern = regexp.MustCompile(`^(.*?)\s*\(([^()]*)\)\s*$`)
+	kindPattern        = regexp.MustCompile(`(?i)^-\s*(?:your\s+)?(highlight|note|bookmark)`)
+	pagePattern        = regexp.MustCompile(`(?i)\bpage\s+(\S+)`)
+	locationPattern    = regexp.MustCompile(`(?i)\b(?:location|loc\.)\s+([0-9]+(?:-[0-9]+)?)`)
+	addedOnPattern     = regexp.MustCompile(`(?i)\badded on\s+(.+)$`)
+)
+
+var addedOnLayouts = []string{
+	"Monday, 2 January 2006 15:04:05",
+	"Monday, January 2, 2006 3:04:05 PM",
+	"Monday, January 2, 2006, 3:04:05 PM",
+	"Monday, 2 January 06 15:04:05",
+	"Monday, January 02, 2006 3:04:05 PM",
+}
+
+// Parse reads a Kindle "My Clippings.txt" file. Entries are separated by a line
+// of equal signs and consist of a "Title (Author)" line, a metadata line, a
+// blank line and the clipping text.
+func Parse(r io.Reader) ([]Clipping, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var clippings []Clipping
+	var lines []string
+	entry := 0
+
+	flush := func() error {
+		defer func() { lines = lines[:0] }()
+		entry++
+
+		for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+			lines = lines[1:]
+		}
+		if len(lines) == 0 {
+			return nil
+		}
+		if len(lines) < 2 {
+			return fmt.Errorf("entry %d: missing metadata line", entry)
+		}
+
+		clipping, err := parseEntry(lines)
+		if err != nil {
+			return fmt.Errorf("entry %d: %w", entry, err)
+		}
+		clippings = append(clippings, *clipping)
+		return nil
+	}
+
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(clippings) == 0 && len(lines) == 0 {
+			line = strings.TrimPrefix(line, bom)
+		}
+
+		if strings.TrimSpace(line) == separator {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return clippings, nil
+}
+
+func parseEntry(lines []string) (*Clipping, error) {
+	clipping := &Clipping{}
+
+	header := strings.TrimSpace(strings.TrimPrefix(lines[0], bom))
+	if matches := titleAuthorPattern.FindStringSubmatch(header); matches != nil {
+		clipping.Title = strings.TrimSpace(matches[1])
+		clipping.Author = NormalizeAuthor(matches[2])
+	} else {
+		clipping.Title = header
+	}
+	if clipping.Title == "" {
+		return nil, fmt.Errorf("missing title")
+	}
+
+	metadata := strings.TrimSpace(lines[1])
+	matches := kindPattern.FindStringSubmatch(metadata)
+	if matches == nil {
+		return nil, fmt.Errorf("unrecognized metadata line %q", metadata)
+	}
+	clipping.Kind = strings.ToLower(matches[1])
+
+	for _, part := range strings.Split(metadata, "|") {
+		if matches := pagePattern.FindStringSubmatch(part); matches != nil {
+			if page, err := strconv.Atoi(matches[1]); err == nil {
+				clipping.Page = page
+			}
+		}
+		if matches := locationPattern.FindStringSubmatch(part); matches != nil {
+			clipping.Location = matches[1]
+		}
+		if matches := addedOnPattern.FindStringSubmatch(strings.TrimSpace(part)); matches != nil {
+			clipping.AddedAt = parseAddedOn(matches[1])
+		}
+	}
+
+	clipping.Text = strings.TrimSpace(strings.Join(lines[2:], "\n"))
+
+	return clipping, nil
+}
+
+func parseAddedOn(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range addedOnLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// NormalizeAuthor turns "Orwell, George" into "George Orwell" and joins
+// multiple authors separated by semicolons with commas.
+func NormalizeAuthor(author string) string {
+	parts := strings.Split(author, ";")
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if last, first, ok := strings.Cut(part, ","); ok && !strings.Contains(first, ",") {
+			part = strings.TrimSpace(first) + " " + strings.TrimSpace(last)
+		}
+		parts[i] = part
+	}
+	return strings.Join(parts, ", ")
+}
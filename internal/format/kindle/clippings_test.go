@@ -0,0 +1,70 @@
+package kindle_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/format/kindle"
+	"github.com/stretchr/testify/assert"
+)
+
+const clippings = "\ufeff1984 (Orwell, George)\r\n" +
+	"- Your Highlight on page 3 | Location 40-42 | Added on Monday, 2 January 2023 10:04:05\r\n" +
+	"\r\n" +
+	"It was a bright cold day in April, and the clocks were striking thirteen.\r\n" +
+	"==========\r\n" +
+	"1984 (Orwell, George)\r\n" +
+	"- Your Note on Location 42 | Added on Monday, January 2, 2023 10:05:00 AM\r\n" +
+	"\r\n" +
+	"Opening line.\r\n" +
+	"==========\r\n"
+
+func TestParse(t *testing.T) {
+	t.Run("Positive Case - highlight and note", func(t *testing.T) {
+		parsed, err := kindle.Parse(strings.NewReader(clippings))
+		assert.NoError(t, err)
+		assert.EqualValues(t, []kindle.Clipping{
+			{
+				Title:    "1984",
+				Author:   "George Orwell",
+				Kind:     kindle.KindHighlight,
+				Page:     3,
+				Location: "40-42",
+				AddedAt:  time.Date(2023, time.January, 2, 10, 4, 5, 0, time.UTC),
+				Text:     "It was a bright cold day in April, and the clocks were striking thirteen.",
+			},
+			{
+				Title:    "1984",
+				Author:   "George Orwell",
+				Kind:     kindle.KindNote,
+				Location: "42",
+				AddedAt:  time.Date(2023, time.January, 2, 10, 5, 0, 0, time.UTC),
+				Text:     "Opening line.",
+			},
+		}, parsed)
+		assert.EqualValues(t, "42", parsed[0].LocationEnd())
+	})
+
+	t.Run("Positive Case - empty file", func(t *testing.T) {
+		parsed, err := kindle.Parse(strings.NewReader(""))
+		assert.NoError(t, err)
+		assert.Empty(t, parsed)
+	})
+
+	t.Run("Negative Case - malformed entries", func(t *testing.T) {
+		for name, input := range map[string]string{
+			"missing metadata line": "1984 (George Orwell)\n==========\n",
+			"unrecognized metadata": "1984 (George Orwell)\n- Your Clip on page 3\n\nText\n==========\n",
+			"missing title":         "(George Orwell)\n- Your Highlight on page 3\n\nText\n==========\n",
+		} {
+			_, err := kindle.Parse(strings.NewReader(input))
+			assert.Error(t, err, name)
+		}
+	})
+}
+
+func TestNormalizeAuthor(t *testing.T) {
+	assert.EqualValues(t, "George Orwell", kindle.NormalizeAuthor("Orwell, George"))
+	assert.EqualValues(t, "Neil Gaiman, Terry Pratchett", kindle.NormalizeAuthor("Gaiman, Neil; Pratchett, Terry"))
+}
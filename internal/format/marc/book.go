@@ -0,0 +1,133 @@
+package marc
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Book holds the fields bookshelf maps to and from a bibliographic record:
+// 001 (control number), 020 (ISBN), 100/700 (author), 245 (title) and
+// 300 (extent).
+type Book struct {
+	ID              int
+	ISBN            string
+	Title           string
+	Author          string
+	AuthorBirthdate *time.Time
+	PageCount       int
+}
+
+var (
+	pagesPattern     = regexp.MustCompile(`(\d+)\s*(?:p\b|pages)`)
+	birthYearPattern = regexp.MustCompile(`^\s*(\d{4})`)
+	isbnPattern      = regexp.MustCompile(`^[0-9Xx-]+`)
+)
+
+func NewRecord(book *Book) *Record {
+	record := &Record{Leader: DefaultLeader}
+
+	if book.ID > 0 {
+		record.Fields = append(record.Fields, Field{Tag: "001", Value: strconv.Itoa(book.ID)})
+	}
+
+	if book.ISBN != "" {
+		record.Fields = append(record.Fields, Field{
+			Tag: "020", Ind1: ' ', Ind2: ' ',
+			Subfields: []Subfield{{Code: 'a', Value: book.ISBN}},
+		})
+	}
+
+	if book.Author != "" {
+		author := Field{
+			Tag: "100", Ind1: '1', Ind2: ' ',
+			Subfields: []Subfield{{Code: 'a', Value: InvertName(book.Author) + ","}},
+		}
+		if book.AuthorBirthdate != nil && !book.AuthorBirthdate.IsZero() {
+			author.Subfields = append(author.Subfields, Subfield{Code: 'd', Value: book.AuthorBirthdate.Format("2006") + "-"})
+		}
+		record.Fields = append(record.Fields, author)
+	}
+
+	title := Field{Tag: "245", Ind1: '0', Ind2: '0', Subfields: []Subfield{{Code: 'a', Value: book.Title}}}
+	if book.Author != "" {
+		title.Ind1 = '1'
+		title.Subfields[0].Value += " /"
+		title.Subfields = append(title.Subfields, Subfield{Code: 'c', Value: book.Author + "."})
+	} else {
+		title.Subfields[0].Value += "."
+	}
+	record.Fields = append(record.Fields, title)
+
+	if book.PageCount > 0 {
+		record.Fields = append(record.Fields, Field{
+			Tag: "300", Ind1: ' ', Ind2: ' ',
+			Subfields: []Subfield{{Code: 'a', Value: strconv.Itoa(book.PageCount) + " pages"}},
+		})
+	}
+
+	return record
+}
+
+// Book extracts the mapped fields. The first 100 field is used as the author,
+// falling back to the first 700 field when the record has no main entry.
+func (r *Record) Book() *Book {
+	book := &Book{}
+
+	if id, err := strconv.Atoi(strings.TrimSpace(r.ControlField("001"))); err == nil {
+		book.ID = id
+	}
+
+	book.ISBN = isbnPattern.FindString(strings.TrimSpace(r.Subfield("020", 'a')))
+
+	title := trimPunctuation(r.Subfield("245", 'a'))
+	if subtitle := trimPunctuation(r.Subfield("245", 'b')); subtitle != "" {
+		title += ": " + subtitle
+	}
+	book.Title = title
+
+	author := r.Field("100")
+	if author == nil {
+		author = r.Field("700")
+	}
+	if author != nil {
+		book.Author = NormalizeName(author.Subfield('a'))
+		if matches := birthYearPattern.FindStringSubmatch(author.Subfield('d')); matches != nil {
+			year, _ := strconv.Atoi(matches[1])
+			birthdate := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+			book.AuthorBirthdate = &birthdate
+		}
+	}
+
+	if matches := pagesPattern.FindStringSubmatch(r.Subfield("300", 'a')); matches != nil {
+		book.PageCount, _ = strconv.Atoi(matches[1])
+	}
+
+	return book
+}
+
+// InvertName turns "George Orwell" into "Orwell, George", the form MARC uses
+// for personal names.
+func InvertName(name string) string {
+	fields := strings.Fields(name)
+	if len(fields) < 2 || strings.Contains(name, ",") {
+		return name
+	}
+	return fields[len(fields)-1] + ", " + strings.Join(fields[:len(fields)-1], " ")
+}
+
+// NormalizeName turns "Orwell, George," into "George Orwell".
+func NormalizeName(name string) string {
+	name = trimPunctuation(name)
+	if last, first, ok := strings.Cut(name, ","); ok {
+		return strings.TrimSpace(strings.TrimSpace(first) + " " + strings.TrimSpace(last))
+	}
+	return name
+}
+
+// trimPunctuation removes the ISBD punctuation MARC puts at the end of
+// subfields, such as " /", " :" and trailing commas or periods.
+func trimPunctuation(value string) string {
+	return strings.TrimSpace(strings.TrimRight(strings.TrimSpace(value), " /:;,."))
+}
@@ -0,0 +1,165 @@
+package marc
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Reader reads MARC 21 records in the ISO 2709 transmission format.
+type Reader struct {
+	r      *bufio.Reader
+	record int
+}
+
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Read returns the next record, or io.EOF when there are no more records.
+func (r *Reader) Read() (*Record, error) {
+	data, err := r.r.ReadBytes(recordTerminator)
+	if errors.Is(err, io.EOF) && len(bytes.TrimSpace(data)) == 0 {
+		return nil, io.EOF
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	r.record++
+
+	// Files are sometimes saved with line breaks between records.
+	data = bytes.TrimLeft(data, "\r\n")
+
+	record, err := decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("record %d: %w", r.record, err)
+	}
+	return record, nil
+}
+
+func decode(data []byte) (*Record, error) {
+	if len(data) < leaderLength+1 {
+		return nil, errors.New("record is too short")
+	}
+
+	leader := string(data[:leaderLength])
+	baseAddress, err := parseNumber(leader[12:17])
+	if err != nil || baseAddress <= leaderLength || baseAddress > len(data) {
+		return nil, errors.New("invalid base address of data")
+	}
+
+	directory := data[leaderLength : baseAddress-1]
+	if len(directory)%directoryEntryLength != 0 {
+		return nil, errors.New("invalid directory length")
+	}
+
+	record := &Record{Leader: leader}
+	for i := 0; i < len(directory); i += directoryEntryLength {
+		entry := string(directory[i : i+directoryEntryLength])
+		length, err1 := parseNumber(entry[3:7])
+		start, err2 := parseNumber(entry[7:12])
+		if err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("invalid directory entry %q", entry)
+		}
+
+		begin := baseAddress + start
+		end := begin + length
+		if end > len(data) || length == 0 {
+			return nil, fmt.Errorf("field %s is out of bounds", entry[:3])
+		}
+
+		field := Field{Tag: entry[:3]}
+		value := bytes.TrimSuffix(data[begin:end], []byte{fieldTerminator})
+		if field.IsControl() {
+			field.Value = string(value)
+		} else {
+			if len(value) < 2 {
+				return nil, fmt.Errorf("field %s is missing indicators", field.Tag)
+			}
+			field.Ind1, field.Ind2 = value[0], value[1]
+			for _, subfield := range bytes.Split(value[2:], []byte{subfieldDelimiter})[1:] {
+				if len(subfield) == 0 {
+					continue
+				}
+				field.Subfields = append(field.Subfields, Subfield{Code: subfield[0], Value: string(subfield[1:])})
+			}
+		}
+		record.Fields = append(record.Fields, field)
+	}
+
+	return record, nil
+}
+
+// parseNumber parses the digits of a length or an offset, which have no sign.
+func parseNumber(digits string) (int, error) {
+	n, err := strconv.ParseUint(digits, 10, 32)
+	return int(n), err
+}
+
+// Writer writes MARC 21 records in the ISO 2709 transmission format.
+type Writer struct {
+	w io.Writer
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+func (w *Writer) Write(record *Record) error {
+	var directory, data bytes.Buffer
+
+	for _, field := range record.Fields {
+		start := data.Len()
+		if field.IsControl() {
+			data.WriteString(field.Value)
+		} else {
+			data.WriteByte(indicator(field.Ind1))
+			data.WriteByte(indicator(field.Ind2))
+			for _, subfield := range field.Subfields {
+				data.WriteByte(subfieldDelimiter)
+				data.WriteByte(subfield.Code)
+				data.WriteString(subfield.Value)
+			}
+		}
+		data.WriteByte(fieldTerminator)
+
+		length := data.Len() - start
+		if length > 9999 || start > 99999 {
+			return fmt.Errorf("field %s is too long", field.Tag)
+		}
+		fmt.Fprintf(&directory, "%3s%04d%05d", field.Tag, length, start)
+	}
+	directory.WriteByte(fieldTerminator)
+	data.WriteByte(recordTerminator)
+
+	baseAddress := leaderLength + directory.Len()
+	recordLength := baseAddress + data.Len()
+	if recordLength > 99999 {
+		return errors.New("record is too long")
+	}
+
+	leader := record.Leader
+	if len(leader) != leaderLength {
+		leader = DefaultLeader
+	}
+	leader = fmt.Sprintf("%05d%s%05d%s", recordLength, leader[5:12], baseAddress, leader[17:])
+
+	if _, err := io.WriteString(w.w, leader); err != nil {
+		return err
+	}
+	if _, err := w.w.Write(directory.Bytes()); err != nil {
+		return err
+	}
+	_, err := w.w.Write(data.Bytes())
+	return err
+}
+
+func indicator(b byte) byte {
+	if b == 0 {
+		return ' '
+	}
+	return b
+}
@@ -0,0 +1,90 @@
+package marc_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/format/marc"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMARCBook() *marc.Book {
+	birthdate := time.Date(1903, 1, 1, 0, 0, 0, 0, time.UTC)
+	return &marc.Book{
+		ID:              7,
+		ISBN:            "9780451524935",
+		Title:           "Nineteen Eighty-Four",
+		Author:          "George Orwell",
+		AuthorBirthdate: &birthdate,
+		PageCount:       328,
+	}
+}
+
+func writeISO2709(t *testing.T, books ...*marc.Book) []byte {
+	var buf bytes.Buffer
+	w := marc.NewWriter(&buf)
+	for _, book := range books {
+		assert.NoError(t, w.Write(marc.NewRecord(book)))
+	}
+	return buf.Bytes()
+}
+
+func TestReader(t *testing.T) {
+	t.Run("Positive Case - round trip", func(t *testing.T) {
+		book := newMARCBook()
+		r := marc.NewReader(bytes.NewReader(writeISO2709(t, book, &marc.Book{Title: "Untitled"})))
+
+		record, err := r.Read()
+		assert.NoError(t, err)
+		assert.EqualValues(t, book, record.Book())
+
+		record, err = r.Read()
+		assert.NoError(t, err)
+		assert.EqualValues(t, "Untitled", record.Book().Title)
+
+		_, err = r.Read()
+		assert.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("Positive Case - line breaks between records", func(t *testing.T) {
+		data := writeISO2709(t, newMARCBook())
+		r := marc.NewReader(bytes.NewReader(append(append(data, "\r\n"...), data...)))
+
+		for i := 0; i < 2; i++ {
+			record, err := r.Read()
+			assert.NoError(t, err)
+			assert.EqualValues(t, "Nineteen Eighty-Four", record.Book().Title)
+		}
+		_, err := r.Read()
+		assert.ErrorIs(t, err, io.EOF)
+	})
+
+	valid := string(writeISO2709(t, newMARCBook()))
+	// The directory starts after the leader, its first entry is the 001
+	// control field.
+	entry := valid[24:36]
+
+	for name, data := range map[string]string{
+		"too short":                 valid[:20],
+		"truncated":                 valid[:len(valid)-10],
+		"base address not a number": valid[:12] + "00x37" + valid[17:],
+		"base address past the end": valid[:12] + "99999" + valid[17:],
+		"negative base address":     valid[:12] + "-0037" + valid[17:],
+		"negative field length":     strings.Replace(valid, entry, entry[:3]+"-001"+entry[7:], 1),
+		"negative field start":      strings.Replace(valid, entry, entry[:7]+"-0001", 1),
+		"signed field length":       strings.Replace(valid, entry, entry[:3]+"+001"+entry[7:], 1),
+		"empty field":               strings.Replace(valid, entry, entry[:3]+"0000"+entry[7:], 1),
+		"field past the end":        strings.Replace(valid, entry, entry[:7]+"99999", 1),
+		"directory not in entries":  valid[:12] + "00036" + valid[17:],
+	} {
+		t.Run("Negative Case - "+name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				_, err := marc.NewReader(strings.NewReader(data)).Read()
+				assert.Error(t, err)
+			})
+		})
+	}
+}
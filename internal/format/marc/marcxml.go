@@ -0,0 +1,177 @@
+package marc
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	XMLNamespace = "http://www.loc.gov/MARC21/slim"
+	XMLSchema    = "http://www.loc.gov/standards/marcxml/schema/MARC21slim.xsd"
+)
+
+type xmlRecord struct {
+	XMLName       xml.Name          `xml:"record"`
+	Xmlns         string            `xml:"xmlns,attr,omitempty"`
+	Leader        string            `xml:"leader"`
+	ControlFields []xmlControlField `xml:"controlfield"`
+	DataFields    []xmlDataField    `xml:"datafield"`
+}
+
+type xmlControlField struct {
+	Tag   string `xml:"tag,attr"`
+	Value string `xml:",chardata"`
+}
+
+type xmlDataField struct {
+	Tag       string        `xml:"tag,attr"`
+	Ind1      string        `xml:"ind1,attr"`
+	Ind2      string        `xml:"ind2,attr"`
+	Subfields []xmlSubfield `xml:"subfield"`
+}
+
+type xmlSubfield struct {
+	Code  string `xml:"code,attr"`
+	Value string `xml:",chardata"`
+}
+
+// XMLReader reads MARCXML records, either a single record or a collection.
+type XMLReader struct {
+	decoder *xml.Decoder
+	record  int
+}
+
+func NewXMLReader(r io.Reader) *XMLReader {
+	return &XMLReader{decoder: xml.NewDecoder(r)}
+}
+
+// Read returns the next record, or io.EOF when there are no more records.
+func (r *XMLReader) Read() (*Record, error) {
+	for {
+		token, err := r.decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, io.EOF
+			}
+			return nil, fmt.Errorf("record %d: %w", r.record+1, err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "record" {
+			continue
+		}
+		r.record++
+
+		var x xmlRecord
+		if err := r.decoder.DecodeElement(&x, &start); err != nil {
+			return nil, fmt.Errorf("record %d: %w", r.record, err)
+		}
+
+		record := &Record{Leader: x.Leader}
+		// MARCXML keeps control fields before data fields, which matches the
+		// tag order MARC 21 requires anyway.
+		for _, field := range x.ControlFields {
+			record.Fields = append(record.Fields, Field{Tag: field.Tag, Value: field.Value})
+		}
+		for _, field := range x.DataFields {
+			f := Field{Tag: field.Tag, Ind1: firstByte(field.Ind1), Ind2: firstByte(field.Ind2)}
+			for _, subfield := range field.Subfields {
+				f.Subfields = append(f.Subfields, Subfield{Code: firstByte(subfield.Code), Value: subfield.Value})
+			}
+			record.Fields = append(record.Fields, f)
+		}
+		return record, nil
+	}
+}
+
+// XMLWriter writes records as a MARCXML collection. Close must be called to
+// end the collection.
+type XMLWriter struct {
+	w       io.Writer
+	encoder *xml.Encoder
+	started bool
+}
+
+func NewXMLWriter(w io.Writer) *XMLWriter {
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	return &XMLWriter{w: w, encoder: encoder}
+}
+
+func (w *XMLWriter) start() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+
+	if _, err := io.WriteString(w.w, xml.Header); err != nil {
+		return err
+	}
+	return w.encoder.EncodeToken(xml.StartElement{
+		Name: xml.Name{Local: "collection"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: XMLNamespace}},
+	})
+}
+
+func (w *XMLWriter) Write(record *Record) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	return w.encoder.Encode(newXMLRecord(record))
+}
+
+// Flush writes buffered output without ending the collection.
+func (w *XMLWriter) Flush() error {
+	return w.encoder.Flush()
+}
+
+func (w *XMLWriter) Close() error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	if err := w.encoder.EncodeToken(xml.EndElement{Name: xml.Name{Local: "collection"}}); err != nil {
+		return err
+	}
+	if err := w.encoder.Flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w.w, "\n")
+	return err
+}
+
+// MarshalXML encodes the record as a standalone MARCXML record, as embedded
+// in other documents such as OAI-PMH responses.
+func (r *Record) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	x := newXMLRecord(r)
+	x.Xmlns = XMLNamespace
+	return e.Encode(x)
+}
+
+func newXMLRecord(record *Record) *xmlRecord {
+	x := &xmlRecord{Leader: record.Leader}
+	if len(x.Leader) != leaderLength {
+		x.Leader = DefaultLeader
+	}
+	for _, field := range record.Fields {
+		if field.IsControl() {
+			x.ControlFields = append(x.ControlFields, xmlControlField{Tag: field.Tag, Value: field.Value})
+			continue
+		}
+		f := xmlDataField{Tag: field.Tag, Ind1: string(indicator(field.Ind1)), Ind2: string(indicator(field.Ind2))}
+		for _, subfield := range field.Subfields {
+			f.Subfields = append(f.Subfields, xmlSubfield{Code: string(subfield.Code), Value: subfield.Value})
+		}
+		x.DataFields = append(x.DataFields, f)
+	}
+	return x
+}
+
+func firstByte(value string) byte {
+	if value == "" {
+		return ' '
+	}
+	return value[0]
+}
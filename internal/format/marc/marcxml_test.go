@@ -0,0 +1,53 @@
+package marc_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/format/marc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestXMLReader(t *testing.T) {
+	t.Run("Positive Case - round trip", func(t *testing.T) {
+		book := newMARCBook()
+
+		var buf bytes.Buffer
+		w := marc.NewXMLWriter(&buf)
+		assert.NoError(t, w.Write(marc.NewRecord(book)))
+		assert.NoError(t, w.Close())
+
+		r := marc.NewXMLReader(&buf)
+		record, err := r.Read()
+		assert.NoError(t, err)
+		assert.EqualValues(t, book, record.Book())
+
+		_, err = r.Read()
+		assert.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("Positive Case - single record", func(t *testing.T) {
+		r := marc.NewXMLReader(strings.NewReader(`<record xmlns="http://www.loc.gov/MARC21/slim">
+  <leader>00000nam a2200000 i 4500</leader>
+  <datafield tag="245" ind1="0" ind2="0"><subfield code="a">Animal Farm.</subfield></datafield>
+</record>`))
+		record, err := r.Read()
+		assert.NoError(t, err)
+		assert.EqualValues(t, "Animal Farm", record.Book().Title)
+	})
+
+	for name, data := range map[string]string{
+		"truncated":        `<collection><record><leader>00000nam a2200000 i 4500</leader><datafield tag="245"`,
+		"mismatched tags":  `<collection><record><leader>00000nam a2200000 i 4500</leader></datafield></record></collection>`,
+		"not xml in field": `<collection><record><datafield tag="245"><subfield code="a">&bogus;</subfield></datafield></record></collection>`,
+	} {
+		t.Run("Negative Case - "+name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				_, err := marc.NewXMLReader(strings.NewReader(data)).Read()
+				assert.Error(t, err)
+			})
+		})
+	}
+}
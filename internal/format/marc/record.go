@@ -0,0 +1,72 @@
+package marc
+
+import "strings"
+
+const (
+	subfieldDelimiter = 0x1f
+	fieldTerminator   = 0x1e
+	recordTerminator  = 0x1d
+
+	leaderLength         = 24
+	directoryEntryLength = 12
+)
+
+// DefaultLeader describes a Unicode (UTF-8) bibliographic record of a
+// monograph. Lengths and the base address are filled in when writing.
+const DefaultLeader = "00000nam a2200000 i 4500"
+
+type Subfield struct {
+	Code  byte
+	Value string
+}
+
+// Field is either a control field (tags 001-009) with a Value, or a data
+// field with two indicators and subfields.
+type Field struct {
+	Tag       string
+	Value     string
+	Ind1      byte
+	Ind2      byte
+	Subfields []Subfield
+}
+
+func (f *Field) IsControl() bool {
+	return strings.HasPrefix(f.Tag, "00")
+}
+
+func (f *Field) Subfield(code byte) string {
+	for _, subfield := range f.Subfields {
+		if subfield.Code == code {
+			return subfield.Value
+		}
+	}
+	return ""
+}
+
+type Record struct {
+	Leader string
+	Fields []Field
+}
+
+func (r *Record) Field(tag string) *Field {
+	for i := range r.Fields {
+		if r.Fields[i].Tag == tag {
+			return &r.Fields[i]
+		}
+	}
+	return nil
+}
+
+func (r *Record) ControlField(tag string) string {
+	if field := r.Field(tag); field != nil {
+		return field.Value
+	}
+	return ""
+}
+
+func (r *Record) Subfield(tag string, code byte) string {
+	if field := r.Field(tag); field != nil {
+		return field.Subfield(code)
+	}
+	return ""
+}
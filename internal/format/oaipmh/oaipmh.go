@@ -0,0 +1,353 @@
+// Package oaipmh writes responses of the Open Archives Initiative Protocol
+// for Metadata Harvesting 2.0, which union catalogs use to harvest records.
+package oaipmh
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+const ContentType = "text/xml; charset=utf-8"
+
+const (
+	VerbIdentify            = "Identify"
+	VerbListMetadataFormats = "ListMetadataFormats"
+	VerbListSets            = "ListSets"
+	VerbListIdentifiers     = "ListIdentifiers"
+	VerbListRecords         = "ListRecords"
+	VerbGetRecord           = "GetRecord"
+)
+
+const (
+	ErrBadArgument             = "badArgument"
+	ErrBadResumptionToken      = "badResumptionToken"
+	ErrBadVerb                 = "badVerb"
+	ErrCannotDisseminateFormat = "cannotDisseminateFormat"
+	ErrIDDoesNotExist          = "idDoesNotExist"
+	ErrNoRecordsMatch          = "noRecordsMatch"
+	ErrNoMetadataFormats       = "noMetadataFormats"
+	ErrNoSetHierarchy          = "noSetHierarchy"
+)
+
+const (
+	DeletedRecordNo         = "no"
+	DeletedRecordTransient  = "transient"
+	DeletedRecordPersistent = "persistent"
+)
+
+// Datestamps are UTC, with the granularity of either days or seconds.
+const (
+	GranularityDay    = "YYYY-MM-DD"
+	GranularitySecond = "YYYY-MM-DDThh:mm:ssZ"
+	DayFormat         = "2006-01-02"
+	SecondFormat      = "2006-01-02T15:04:05Z"
+)
+
+const (
+	namespace      = "http://www.openarchives.org/OAI/2.0/"
+	xsiNamespace   = "http://www.w3.org/2001/XMLSchema-instance"
+	schemaLocation = namespace + " http://www.openarchives.org/OAI/2.0/OAI-PMH.xsd"
+)
+
+// Request holds the arguments of a request, which are echoed in the response.
+type Request struct {
+	Verb            string
+	Identifier      string
+	MetadataPrefix  string
+	From            string
+	Until           string
+	Set             string
+	ResumptionToken string
+}
+
+// Response is the reply to a request. Either Errors or the part answering
+// the verb of the request is set.
+type Response struct {
+	Date    time.Time
+	BaseURL string
+	Request Request
+	Errors  []Error
+
+	Identify        *Identify
+	MetadataFormats []MetadataFormat
+	Sets            []Set
+	Headers         []Header
+	Records         []Record
+	// ResumptionToken is set on every page of an incomplete list. Its Token
+	// is empty on the last page.
+	ResumptionToken *ResumptionToken
+}
+
+type Error struct {
+	Code    string
+	Message string
+}
+
+type Identify struct {
+	RepositoryName    string
+	AdminEmails       []string
+	EarliestDatestamp time.Time
+	DeletedRecord     string
+	Granularity       string
+}
+
+type MetadataFormat struct {
+	Prefix    string
+	Schema    string
+	Namespace string
+}
+
+type Set struct {
+	Spec string
+	Name string
+}
+
+type Header struct {
+	Identifier string
+	Datestamp  time.Time
+	Sets       []string
+	Deleted    bool
+}
+
+// Record is a header with its metadata, which is encoded with encoding/xml.
+// Deleted records have no metadata.
+type Record struct {
+	Header
+	Metadata any
+}
+
+type ResumptionToken struct {
+	Token            string
+	CompleteListSize int64
+	Cursor           int64
+}
+
+type xmlResponse struct {
+	XMLName         xml.Name        `xml:"OAI-PMH"`
+	Xmlns           string          `xml:"xmlns,attr"`
+	XmlnsXSI        string          `xml:"xmlns:xsi,attr"`
+	SchemaLocation  string          `xml:"xsi:schemaLocation,attr"`
+	ResponseDate    string          `xml:"responseDate"`
+	Request         xmlRequest      `xml:"request"`
+	Errors          []xmlError      `xml:"error"`
+	Identify        *xmlIdentify    `xml:"Identify"`
+	MetadataFormats *xmlFormats     `xml:"ListMetadataFormats"`
+	Sets            *xmlSets        `xml:"ListSets"`
+	Identifiers     *xmlIdentifiers `xml:"ListIdentifiers"`
+	Records         *xmlRecords     `xml:"ListRecords"`
+	Record          *xmlRecords     `xml:"GetRecord"`
+}
+
+type xmlRequest struct {
+	Verb            string `xml:"verb,attr,omitempty"`
+	Identifier      string `xml:"identifier,attr,omitempty"`
+	MetadataPrefix  string `xml:"metadataPrefix,attr,omitempty"`
+	From            string `xml:"from,attr,omitempty"`
+	Until           string `xml:"until,attr,omitempty"`
+	Set             string `xml:"set,attr,omitempty"`
+	ResumptionToken string `xml:"resumptionToken,attr,omitempty"`
+	BaseURL         string `xml:",chardata"`
+}
+
+type xmlError struct {
+	Code    string `xml:"code,attr"`
+	Message string `xml:",chardata"`
+}
+
+type xmlIdentify struct {
+	RepositoryName    string   `xml:"repositoryName"`
+	BaseURL           string   `xml:"baseURL"`
+	ProtocolVersion   string   `xml:"protocolVersion"`
+	AdminEmails       []string `xml:"adminEmail"`
+	EarliestDatestamp string   `xml:"earliestDatestamp"`
+	DeletedRecord     string   `xml:"deletedRecord"`
+	Granularity       string   `xml:"granularity"`
+}
+
+type xmlFormats struct {
+	Formats []xmlFormat `xml:"metadataFormat"`
+}
+
+type xmlFormat struct {
+	Prefix    string `xml:"metadataPrefix"`
+	Schema    string `xml:"schema"`
+	Namespace string `xml:"metadataNamespace"`
+}
+
+type xmlSets struct {
+	Sets            []xmlSet            `xml:"set"`
+	ResumptionToken *xmlResumptionToken `xml:"resumptionToken"`
+}
+
+type xmlSet struct {
+	Spec string `xml:"setSpec"`
+	Name string `xml:"setName"`
+}
+
+type xmlIdentifiers struct {
+	Headers         []xmlHeader         `xml:"header"`
+	ResumptionToken *xmlResumptionToken `xml:"resumptionToken"`
+}
+
+type xmlRecords struct {
+	Records         []xmlRecord         `xml:"record"`
+	ResumptionToken *xmlResumptionToken `xml:"resumptionToken"`
+}
+
+type xmlRecord struct {
+	Header   xmlHeader    `xml:"header"`
+	Metadata *xmlMetadata `xml:"metadata"`
+}
+
+type xmlHeader struct {
+	Status     string   `xml:"status,attr,omitempty"`
+	Identifier string   `xml:"identifier"`
+	Datestamp  string   `xml:"datestamp"`
+	Sets       []string `xml:"setSpec"`
+}
+
+type xmlMetadata struct {
+	Value any
+}
+
+type xmlResumptionToken struct {
+	CompleteListSize int64  `xml:"completeListSize,attr"`
+	Cursor           int64  `xml:"cursor,attr"`
+	Token            string `xml:",chardata"`
+}
+
+func Write(w io.Writer, response *Response) error {
+	doc := xmlResponse{
+		Xmlns:          namespace,
+		XmlnsXSI:       xsiNamespace,
+		SchemaLocation: schemaLocation,
+		ResponseDate:   response.Date.UTC().Format(SecondFormat),
+		Request:        xmlRequest{BaseURL: response.BaseURL},
+	}
+
+	// The arguments of a request with a bad verb or argument must not be
+	// echoed.
+	echo := true
+	for _, err := range response.Errors {
+		doc.Errors = append(doc.Errors, xmlError{Code: err.Code, Message: err.Message})
+		if err.Code == ErrBadVerb || err.Code == ErrBadArgument {
+			echo = false
+		}
+	}
+	if echo {
+		doc.Request = xmlRequest{
+			Verb:            response.Request.Verb,
+			Identifier:      response.Request.Identifier,
+			MetadataPrefix:  response.Request.MetadataPrefix,
+			From:            response.Request.From,
+			Until:           response.Request.Until,
+			Set:             response.Request.Set,
+			ResumptionToken: response.Request.ResumptionToken,
+			BaseURL:         response.BaseURL,
+		}
+	}
+
+	if len(response.Errors) == 0 {
+		writeVerb(&doc, response)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func writeVerb(doc *xmlResponse, response *Response) {
+	token := toXMLResumptionToken(response.ResumptionToken)
+
+	switch response.Request.Verb {
+	case VerbIdentify:
+		identify := response.Identify
+		doc.Identify = &xmlIdentify{
+			RepositoryName:    identify.RepositoryName,
+			BaseURL:           response.BaseURL,
+			ProtocolVersion:   "2.0",
+			AdminEmails:       identify.AdminEmails,
+			EarliestDatestamp: formatDatestamp(identify.EarliestDatestamp, identify.Granularity),
+			DeletedRecord:     identify.DeletedRecord,
+			Granularity:       identify.Granularity,
+		}
+	case VerbListMetadataFormats:
+		doc.MetadataFormats = &xmlFormats{}
+		for _, format := range response.MetadataFormats {
+			doc.MetadataFormats.Formats = append(doc.MetadataFormats.Formats, xmlFormat(format))
+		}
+	case VerbListSets:
+		doc.Sets = &xmlSets{ResumptionToken: token}
+		for _, set := range response.Sets {
+			doc.Sets.Sets = append(doc.Sets.Sets, xmlSet(set))
+		}
+	case VerbListIdentifiers:
+		doc.Identifiers = &xmlIdentifiers{ResumptionToken: token}
+		for _, header := range response.Headers {
+			doc.Identifiers.Headers = append(doc.Identifiers.Headers, toXMLHeader(&header))
+		}
+	case VerbListRecords, VerbGetRecord:
+		records := &xmlRecords{ResumptionToken: token}
+		for _, record := range response.Records {
+			x := xmlRecord{Header: toXMLHeader(&record.Header)}
+			if !record.Deleted && record.Metadata != nil {
+				x.Metadata = &xmlMetadata{Value: record.Metadata}
+			}
+			records.Records = append(records.Records, x)
+		}
+		if response.Request.Verb == VerbGetRecord {
+			records.ResumptionToken = nil
+			doc.Record = records
+		} else {
+			doc.Records = records
+		}
+	}
+}
+
+func toXMLHeader(header *Header) xmlHeader {
+	x := xmlHeader{
+		Identifier: header.Identifier,
+		Datestamp:  header.Datestamp.UTC().Format(SecondFormat),
+		Sets:       header.Sets,
+	}
+	if header.Deleted {
+		x.Status = "deleted"
+	}
+	return x
+}
+
+func toXMLResumptionToken(token *ResumptionToken) *xmlResumptionToken {
+	if token == nil {
+		return nil
+	}
+	return &xmlResumptionToken{
+		CompleteListSize: token.CompleteListSize,
+		Cursor:           token.Cursor,
+		Token:            token.Token,
+	}
+}
+
+func formatDatestamp(t time.Time, granularity string) string {
+	if granularity == GranularityDay {
+		return t.UTC().Format(DayFormat)
+	}
+	return t.UTC().Format(SecondFormat)
+}
+
+// ParseDatestamp parses a from or until argument, and reports whether it has
+// the granularity of seconds rather than days.
+func ParseDatestamp(value string) (time.Time, bool, error) {
+	if t, err := time.Parse(SecondFormat, value); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(DayFormat, value)
+	return t, false, err
+}
@@ -0,0 +1,135 @@
+package oaipmh_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/format/oaipmh"
+	"github.com/stretchr/testify/assert"
+)
+
+type title struct {
+	XMLName xml.Name `xml:"title"`
+	Value   string   `xml:",chardata"`
+}
+
+type response struct {
+	ResponseDate string `xml:"responseDate"`
+	Request      struct {
+		Verb           string `xml:"verb,attr"`
+		MetadataPrefix string `xml:"metadataPrefix,attr"`
+		BaseURL        string `xml:",chardata"`
+	} `xml:"request"`
+	Errors []struct {
+		Code string `xml:"code,attr"`
+	} `xml:"error"`
+	Identify struct {
+		RepositoryName    string `xml:"repositoryName"`
+		EarliestDatestamp string `xml:"earliestDatestamp"`
+	} `xml:"Identify"`
+	Records []struct {
+		Header struct {
+			Status     string `xml:"status,attr"`
+			Identifier string `xml:"identifier"`
+			Datestamp  string `xml:"datestamp"`
+		} `xml:"header"`
+		Title *string `xml:"metadata>title"`
+	} `xml:"ListRecords>record"`
+	ResumptionToken struct {
+		CompleteListSize int64  `xml:"completeListSize,attr"`
+		Cursor           int64  `xml:"cursor,attr"`
+		Token            string `xml:",chardata"`
+	} `xml:"ListRecords>resumptionToken"`
+}
+
+func write(t *testing.T, r *oaipmh.Response) *response {
+	var buf bytes.Buffer
+	assert.NoError(t, oaipmh.Write(&buf, r))
+
+	decoded := new(response)
+	assert.NoError(t, xml.Unmarshal(buf.Bytes(), decoded))
+	return decoded
+}
+
+func TestWrite(t *testing.T) {
+	date := time.Date(2024, time.January, 2, 3, 4, 5, 0, time.FixedZone("WIB", 7*60*60))
+
+	t.Run("Positive Case - Identify", func(t *testing.T) {
+		decoded := write(t, &oaipmh.Response{
+			Date:    date,
+			BaseURL: "https://example.com/oai",
+			Request: oaipmh.Request{Verb: oaipmh.VerbIdentify},
+			Identify: &oaipmh.Identify{
+				RepositoryName:    "Bookshelf",
+				EarliestDatestamp: date,
+				DeletedRecord:     oaipmh.DeletedRecordPersistent,
+				Granularity:       oaipmh.GranularityDay,
+			},
+		})
+		assert.EqualValues(t, "2024-01-01T20:04:05Z", decoded.ResponseDate)
+		assert.EqualValues(t, "https://example.com/oai", decoded.Request.BaseURL)
+		assert.EqualValues(t, "Bookshelf", decoded.Identify.RepositoryName)
+		assert.EqualValues(t, "2024-01-01", decoded.Identify.EarliestDatestamp)
+	})
+
+	t.Run("Positive Case - ListRecords with a deleted record", func(t *testing.T) {
+		decoded := write(t, &oaipmh.Response{
+			Date:    date,
+			BaseURL: "https://example.com/oai",
+			Request: oaipmh.Request{Verb: oaipmh.VerbListRecords, MetadataPrefix: "oai_dc"},
+			Records: []oaipmh.Record{
+				{Header: oaipmh.Header{Identifier: "oai:example.com:1", Datestamp: date}, Metadata: title{Value: "Dune"}},
+				{Header: oaipmh.Header{Identifier: "oai:example.com:2", Datestamp: date, Deleted: true}, Metadata: title{Value: "Gone"}},
+			},
+			ResumptionToken: &oaipmh.ResumptionToken{Token: "page-2", CompleteListSize: 3, Cursor: 0},
+		})
+		assert.EqualValues(t, oaipmh.VerbListRecords, decoded.Request.Verb)
+		assert.EqualValues(t, "oai_dc", decoded.Request.MetadataPrefix)
+		assert.Len(t, decoded.Records, 2)
+		assert.EqualValues(t, "oai:example.com:1", decoded.Records[0].Header.Identifier)
+		assert.EqualValues(t, "2024-01-01T20:04:05Z", decoded.Records[0].Header.Datestamp)
+		assert.EqualValues(t, "Dune", *decoded.Records[0].Title)
+		assert.EqualValues(t, "deleted", decoded.Records[1].Header.Status)
+		assert.Nil(t, decoded.Records[1].Title)
+		assert.EqualValues(t, "page-2", decoded.ResumptionToken.Token)
+		assert.EqualValues(t, 3, decoded.ResumptionToken.CompleteListSize)
+	})
+
+	t.Run("Negative Case - bad argument is not echoed", func(t *testing.T) {
+		decoded := write(t, &oaipmh.Response{
+			Date:    date,
+			BaseURL: "https://example.com/oai",
+			Request: oaipmh.Request{Verb: oaipmh.VerbListRecords, MetadataPrefix: "<bad>"},
+			Errors:  []oaipmh.Error{{Code: oaipmh.ErrBadArgument, Message: "bad metadataPrefix"}},
+		})
+		assert.Empty(t, decoded.Request.Verb)
+		assert.Empty(t, decoded.Request.MetadataPrefix)
+		assert.EqualValues(t, "https://example.com/oai", decoded.Request.BaseURL)
+		assert.Len(t, decoded.Errors, 1)
+		assert.EqualValues(t, oaipmh.ErrBadArgument, decoded.Errors[0].Code)
+		assert.Empty(t, decoded.Records)
+	})
+}
+
+func TestParseDatestamp(t *testing.T) {
+	t.Run("Positive Case - granularities", func(t *testing.T) {
+		datestamp, seconds, err := oaipmh.ParseDatestamp("2024-01-02T03:04:05Z")
+		assert.NoError(t, err)
+		assert.True(t, seconds)
+		assert.EqualValues(t, time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC), datestamp)
+
+		datestamp, seconds, err = oaipmh.ParseDatestamp("2024-01-02")
+		assert.NoError(t, err)
+		assert.False(t, seconds)
+		assert.EqualValues(t, time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC), datestamp)
+	})
+
+	t.Run("Negative Case - malformed datestamps", func(t *testing.T) {
+		for _, value := range []string{"", "2024", "2024-13-01", "2024-01-02T03:04:05+07:00", "2024-01-02T03:04Z"} {
+			_, _, err := oaipmh.ParseDatestamp(value)
+			assert.Error(t, err, value)
+		}
+	})
+}
@@ -0,0 +1,166 @@
+package opds
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+type atomFeed struct {
+	XMLName         xml.Name    `xml:"feed"`
+	Xmlns           string      `xml:"xmlns,attr"`
+	XmlnsDC         string      `xml:"xmlns:dc,attr"`
+	XmlnsOPDS       string      `xml:"xmlns:opds,attr"`
+	XmlnsOpenSearch string      `xml:"xmlns:opensearch,attr"`
+	XmlnsThr        string      `xml:"xmlns:thr,attr"`
+	ID              string      `xml:"id"`
+	Title           string      `xml:"title"`
+	Updated         string      `xml:"updated"`
+	TotalResults    *int64      `xml:"opensearch:totalResults"`
+	ItemsPerPage    *int        `xml:"opensearch:itemsPerPage"`
+	StartIndex      *int        `xml:"opensearch:startIndex"`
+	Links           []atomLink  `xml:"link"`
+	Entries         []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel    string `xml:"rel,attr,omitempty"`
+	Href   string `xml:"href,attr"`
+	Type   string `xml:"type,attr,omitempty"`
+	Title  string `xml:"title,attr,omitempty"`
+	Length int64  `xml:"length,attr,omitempty"`
+	Count  int64  `xml:"thr:count,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title      string       `xml:"title"`
+	ID         string       `xml:"id"`
+	Updated    string       `xml:"updated"`
+	Authors    []atomAuthor `xml:"author"`
+	Language   string       `xml:"dc:language,omitempty"`
+	Identifier string       `xml:"dc:identifier,omitempty"`
+	Content    *atomContent `xml:"content"`
+	Links      []atomLink   `xml:"link"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri,omitempty"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+}
+
+func writeAtom(w io.Writer, feed *Feed) error {
+	doc := atomFeed{
+		Xmlns:           "http://www.w3.org/2005/Atom",
+		XmlnsDC:         "http://purl.org/dc/terms/",
+		XmlnsOPDS:       "http://opds-spec.org/2010/catalog",
+		XmlnsOpenSearch: "http://a9.com/-/spec/opensearch/1.1/",
+		XmlnsThr:        "http://purl.org/syndication/thread/1.0",
+		ID:              feed.ID,
+		Title:           feed.Title,
+		Updated:         atomTime(feed.Updated),
+	}
+
+	if feed.ItemsPerPage > 0 {
+		startIndex := (max(feed.Page, 1)-1)*feed.ItemsPerPage + 1
+		doc.TotalResults = &feed.TotalResults
+		doc.ItemsPerPage = &feed.ItemsPerPage
+		doc.StartIndex = &startIndex
+	}
+
+	feedType := linkType(feed.Kind)
+	doc.Links = append(doc.Links,
+		atomLink{Rel: "self", Href: feed.Self, Type: feedType},
+		atomLink{Rel: "start", Href: feed.Start, Type: contentTypeNavigation},
+	)
+	if feed.Up != "" {
+		doc.Links = append(doc.Links, atomLink{Rel: "up", Href: feed.Up, Type: contentTypeNavigation})
+	}
+	if feed.Search != "" {
+		doc.Links = append(doc.Links, atomLink{Rel: "search", Href: feed.Search, Type: contentTypeOpenSearch})
+	}
+	if feed.Previous != "" {
+		doc.Links = append(doc.Links, atomLink{Rel: "previous", Href: feed.Previous, Type: feedType})
+	}
+	if feed.Next != "" {
+		doc.Links = append(doc.Links, atomLink{Rel: "next", Href: feed.Next, Type: feedType})
+	}
+
+	for _, navigation := range feed.Navigation {
+		entry := atomEntry{
+			Title:   navigation.Title,
+			ID:      navigation.ID,
+			Updated: doc.Updated,
+			Links: []atomLink{{
+				Rel:   "subsection",
+				Href:  navigation.Href,
+				Type:  linkType(navigation.Kind),
+				Count: navigation.Count,
+			}},
+		}
+		if navigation.Count > 0 {
+			entry.Content = &atomContent{Type: "text", Text: fmt.Sprintf("%d books", navigation.Count)}
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	for _, publication := range feed.Publications {
+		entry := atomEntry{
+			Title:    publication.Title,
+			ID:       publication.ID,
+			Updated:  atomTime(publication.Updated),
+			Language: publication.Language,
+		}
+		if publication.ISBN != "" {
+			entry.Identifier = "urn:isbn:" + publication.ISBN
+		}
+		for _, author := range publication.Authors {
+			entry.Authors = append(entry.Authors, atomAuthor{Name: author.Name, URI: author.Href})
+		}
+		if publication.Series != "" {
+			entry.Content = &atomContent{Type: "text", Text: seriesText(publication)}
+		}
+
+		if publication.Image != "" {
+			entry.Links = append(entry.Links, atomLink{Rel: "http://opds-spec.org/image", Href: publication.Image, Type: publication.ImageType})
+		}
+		if publication.Thumbnail != "" {
+			entry.Links = append(entry.Links, atomLink{Rel: "http://opds-spec.org/image/thumbnail", Href: publication.Thumbnail, Type: publication.ThumbnailType})
+		}
+		for _, acquisition := range publication.Acquisitions {
+			entry.Links = append(entry.Links, atomLink{
+				Rel:    "http://opds-spec.org/acquisition",
+				Href:   acquisition.Href,
+				Type:   acquisition.Type,
+				Title:  acquisition.Title,
+				Length: acquisition.Length,
+			})
+		}
+
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	return encoder.Encode(doc)
+}
+
+func atomTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func seriesText(publication Publication) string {
+	if publication.SeriesIndex == 0 {
+		return publication.Series
+	}
+	return publication.Series + " #" + strconv.FormatFloat(publication.SeriesIndex, 'f', -1, 64)
+}
@@ -0,0 +1,175 @@
+package opds
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+type jsonFeed struct {
+	Metadata     jsonFeedMetadata   `json:"metadata"`
+	Links        []jsonLink         `json:"links"`
+	Navigation   []jsonLink         `json:"navigation,omitempty"`
+	Publications *[]jsonPublication `json:"publications,omitempty"`
+}
+
+type jsonFeedMetadata struct {
+	Title         string `json:"title"`
+	Modified      string `json:"modified"`
+	NumberOfItems *int64 `json:"numberOfItems,omitempty"`
+	ItemsPerPage  int    `json:"itemsPerPage,omitempty"`
+	CurrentPage   int    `json:"currentPage,omitempty"`
+}
+
+type jsonLink struct {
+	Rel        string              `json:"rel,omitempty"`
+	Href       string              `json:"href"`
+	Type       string              `json:"type,omitempty"`
+	Title      string              `json:"title,omitempty"`
+	Templated  bool                `json:"templated,omitempty"`
+	Properties *jsonLinkProperties `json:"properties,omitempty"`
+}
+
+type jsonLinkProperties struct {
+	NumberOfItems int64 `json:"numberOfItems,omitempty"`
+	Length        int64 `json:"length,omitempty"`
+}
+
+type jsonPublication struct {
+	Metadata jsonPublicationMetadata `json:"metadata"`
+	Links    []jsonLink              `json:"links"`
+	Images   []jsonLink              `json:"images,omitempty"`
+}
+
+type jsonPublicationMetadata struct {
+	Type       string            `json:"@type"`
+	Identifier string            `json:"identifier,omitempty"`
+	Title      string            `json:"title"`
+	Author     []jsonContributor `json:"author,omitempty"`
+	Language   string            `json:"language,omitempty"`
+	Modified   string            `json:"modified"`
+	BelongsTo  *jsonBelongsTo    `json:"belongsTo,omitempty"`
+}
+
+type jsonContributor struct {
+	Name  string     `json:"name"`
+	Links []jsonLink `json:"links,omitempty"`
+}
+
+type jsonBelongsTo struct {
+	Series []jsonSeries `json:"series"`
+}
+
+type jsonSeries struct {
+	Name     string  `json:"name"`
+	Position float64 `json:"position,omitempty"`
+}
+
+func writeJSON(w io.Writer, feed *Feed) error {
+	doc := jsonFeed{
+		Metadata: jsonFeedMetadata{
+			Title:    feed.Title,
+			Modified: jsonTime(feed.Updated),
+		},
+		Links: []jsonLink{
+			{Rel: "self", Href: feed.Self, Type: contentTypeJSON},
+			{Rel: "start", Href: feed.Start, Type: contentTypeJSON},
+		},
+	}
+
+	if feed.ItemsPerPage > 0 {
+		doc.Metadata.NumberOfItems = &feed.TotalResults
+		doc.Metadata.ItemsPerPage = feed.ItemsPerPage
+		doc.Metadata.CurrentPage = max(feed.Page, 1)
+	}
+
+	if feed.Up != "" {
+		doc.Links = append(doc.Links, jsonLink{Rel: "up", Href: feed.Up, Type: contentTypeJSON})
+	}
+	if feed.Search != "" {
+		doc.Links = append(doc.Links, jsonLink{Rel: "search", Href: feed.Search, Type: contentTypeJSON, Templated: true})
+	}
+	if feed.Previous != "" {
+		doc.Links = append(doc.Links, jsonLink{Rel: "previous", Href: feed.Previous, Type: contentTypeJSON})
+	}
+	if feed.Next != "" {
+		doc.Links = append(doc.Links, jsonLink{Rel: "next", Href: feed.Next, Type: contentTypeJSON})
+	}
+
+	for _, navigation := range feed.Navigation {
+		link := jsonLink{Rel: "subsection", Href: navigation.Href, Type: contentTypeJSON, Title: navigation.Title}
+		if navigation.Count > 0 {
+			link.Properties = &jsonLinkProperties{NumberOfItems: navigation.Count}
+		}
+		doc.Navigation = append(doc.Navigation, link)
+	}
+
+	// An acquisition feed lists its publications even when there are none,
+	// since OPDS 2.0 requires a feed to have at least one collection.
+	if feed.Kind == KindAcquisition {
+		publications := make([]jsonPublication, 0, len(feed.Publications))
+		for _, publication := range feed.Publications {
+			publications = append(publications, toJSONPublication(publication))
+		}
+		doc.Publications = &publications
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(doc)
+}
+
+func toJSONPublication(publication Publication) jsonPublication {
+	doc := jsonPublication{
+		Metadata: jsonPublicationMetadata{
+			Type:     "http://schema.org/Book",
+			Title:    publication.Title,
+			Language: publication.Language,
+			Modified: jsonTime(publication.Updated),
+		},
+		Links: []jsonLink{},
+	}
+
+	if publication.ISBN != "" {
+		doc.Metadata.Identifier = "urn:isbn:" + publication.ISBN
+	}
+	for _, author := range publication.Authors {
+		contributor := jsonContributor{Name: author.Name}
+		if author.Href != "" {
+			contributor.Links = []jsonLink{{Href: author.Href, Type: contentTypeJSON}}
+		}
+		doc.Metadata.Author = append(doc.Metadata.Author, contributor)
+	}
+	if publication.Series != "" {
+		doc.Metadata.BelongsTo = &jsonBelongsTo{Series: []jsonSeries{{
+			Name:     publication.Series,
+			Position: publication.SeriesIndex,
+		}}}
+	}
+
+	for _, acquisition := range publication.Acquisitions {
+		link := jsonLink{
+			Rel:   "http://opds-spec.org/acquisition",
+			Href:  acquisition.Href,
+			Type:  acquisition.Type,
+			Title: acquisition.Title,
+		}
+		if acquisition.Length > 0 {
+			link.Properties = &jsonLinkProperties{Length: acquisition.Length}
+		}
+		doc.Links = append(doc.Links, link)
+	}
+
+	if publication.Image != "" {
+		doc.Images = append(doc.Images, jsonLink{Href: publication.Image, Type: publication.ImageType})
+	}
+	if publication.Thumbnail != "" {
+		doc.Images = append(doc.Images, jsonLink{Href: publication.Thumbnail, Type: publication.ThumbnailType})
+	}
+
+	return doc
+}
+
+func jsonTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
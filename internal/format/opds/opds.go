@@ -0,0 +1,158 @@
+// Package opds writes catalog feeds for e-reader apps in OPDS 1.2, which is
+// Atom based, and OPDS 2.0, which is JSON based.
+package opds
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	Version1 = "1.2"
+	Version2 = "2.0"
+)
+
+const (
+	KindNavigation  = "navigation"
+	KindAcquisition = "acquisition"
+)
+
+const (
+	contentTypeNavigation  = "application/atom+xml;profile=opds-catalog;kind=navigation"
+	contentTypeAcquisition = "application/atom+xml;profile=opds-catalog;kind=acquisition"
+	contentTypeJSON        = "application/opds+json"
+	contentTypeOpenSearch  = "application/opensearchdescription+xml"
+)
+
+// Feed is a navigation feed, which links to other feeds, or an acquisition
+// feed, which lists publications. Links are paths relative to the server.
+type Feed struct {
+	Kind    string
+	ID      string
+	Title   string
+	Updated time.Time
+
+	Self     string
+	Start    string
+	Up       string
+	Next     string
+	Previous string
+	// Search is the OpenSearch description in OPDS 1.2 and a URI template
+	// with a "q" variable in OPDS 2.0.
+	Search string
+
+	// TotalResults and ItemsPerPage are set for paginated feeds.
+	TotalResults int64
+	ItemsPerPage int
+	Page         int
+
+	Navigation   []Navigation
+	Publications []Publication
+}
+
+type Navigation struct {
+	ID    string
+	Title string
+	Href  string
+	// Kind is the kind of the linked feed.
+	Kind string
+	// Count is the number of items in the linked feed, or 0 when unknown.
+	Count int64
+}
+
+type Publication struct {
+	ID          string
+	Title       string
+	Authors     []Author
+	ISBN        string
+	Language    string
+	Series      string
+	SeriesIndex float64
+	Updated     time.Time
+
+	Image         string
+	ImageType     string
+	Thumbnail     string
+	ThumbnailType string
+	Acquisitions  []Acquisition
+}
+
+type Author struct {
+	Name string
+	Href string
+}
+
+type Acquisition struct {
+	Href   string
+	Type   string
+	Title  string
+	Length int64
+}
+
+// ContentType returns the media type of a feed of the kind in the version.
+func ContentType(version string, kind string) string {
+	if version == Version2 {
+		return contentTypeJSON
+	}
+	if kind == KindNavigation {
+		return contentTypeNavigation
+	}
+	return contentTypeAcquisition
+}
+
+func Write(w io.Writer, version string, feed *Feed) error {
+	switch version {
+	case Version1:
+		return writeAtom(w, feed)
+	case Version2:
+		return writeJSON(w, feed)
+	}
+	return fmt.Errorf("unsupported opds version %q", version)
+}
+
+// OpenSearchContentType is the media type of the OpenSearch description.
+func OpenSearchContentType() string {
+	return contentTypeOpenSearch
+}
+
+// WriteOpenSearch writes the OpenSearch description that OPDS 1.2 clients
+// use to search the catalog. The template must be an absolute URL with a
+// {searchTerms} parameter.
+func WriteOpenSearch(w io.Writer, title string, template string) error {
+	description := struct {
+		XMLName        xml.Name `xml:"OpenSearchDescription"`
+		Xmlns          string   `xml:"xmlns,attr"`
+		ShortName      string   `xml:"ShortName"`
+		Description    string   `xml:"Description"`
+		InputEncoding  string   `xml:"InputEncoding"`
+		OutputEncoding string   `xml:"OutputEncoding"`
+		URL            struct {
+			Type     string `xml:"type,attr"`
+			Template string `xml:"template,attr"`
+		} `xml:"Url"`
+	}{
+		Xmlns:          "http://a9.com/-/spec/opensearch/1.1/",
+		ShortName:      title,
+		Description:    "Search " + title,
+		InputEncoding:  "UTF-8",
+		OutputEncoding: "UTF-8",
+	}
+	description.URL.Type = contentTypeAcquisition
+	description.URL.Template = template
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	return encoder.Encode(description)
+}
+
+func linkType(kind string) string {
+	if kind == KindNavigation {
+		return contentTypeNavigation
+	}
+	return contentTypeAcquisition
+}
@@ -0,0 +1,159 @@
+package opds_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/format/opds"
+	"github.com/stretchr/testify/assert"
+)
+
+func newFeed() *opds.Feed {
+	return &opds.Feed{
+		Kind:         opds.KindAcquisition,
+		ID:           "urn:bookshelf:books",
+		Title:        "Books & More",
+		Updated:      time.Date(2024, time.January, 2, 3, 4, 5, 0, time.FixedZone("WIB", 7*60*60)),
+		Self:         "/opds/v1.2/books?page=2",
+		Start:        "/opds/v1.2",
+		Next:         "/opds/v1.2/books?page=3",
+		Previous:     "/opds/v1.2/books?page=1",
+		TotalResults: 21,
+		ItemsPerPage: 10,
+		Page:         2,
+		Publications: []opds.Publication{{
+			ID:           "urn:isbn:9780553293357",
+			Title:        "Foundation",
+			Authors:      []opds.Author{{Name: "Isaac Asimov", Href: "/opds/v1.2/authors/1"}},
+			ISBN:         "9780553293357",
+			Language:     "en",
+			Series:       "Foundation",
+			SeriesIndex:  1,
+			Image:        "/books/1/cover",
+			ImageType:    "image/jpeg",
+			Acquisitions: []opds.Acquisition{{Href: "/books/1/files/1", Type: "application/epub+zip", Title: "EPUB", Length: 1024}},
+		}},
+	}
+}
+
+type atomLink struct {
+	Rel    string `xml:"rel,attr"`
+	Href   string `xml:"href,attr"`
+	Type   string `xml:"type,attr"`
+	Length int64  `xml:"length,attr"`
+}
+
+type atomFeed struct {
+	Title        string     `xml:"title"`
+	Updated      string     `xml:"updated"`
+	TotalResults int64      `xml:"totalResults"`
+	StartIndex   int        `xml:"startIndex"`
+	Links        []atomLink `xml:"link"`
+	Entries      []struct {
+		Title      string     `xml:"title"`
+		Author     string     `xml:"author>name"`
+		Identifier string     `xml:"identifier"`
+		Content    string     `xml:"content"`
+		Links      []atomLink `xml:"link"`
+	} `xml:"entry"`
+}
+
+func TestWrite(t *testing.T) {
+	t.Run("Positive Case - OPDS 1.2", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, opds.Write(&buf, opds.Version1, newFeed()))
+
+		feed := new(atomFeed)
+		assert.NoError(t, xml.Unmarshal(buf.Bytes(), feed))
+		assert.EqualValues(t, "Books & More", feed.Title)
+		assert.EqualValues(t, "2024-01-01T20:04:05Z", feed.Updated)
+		assert.EqualValues(t, 21, feed.TotalResults)
+		assert.EqualValues(t, 11, feed.StartIndex)
+		assert.EqualValues(t, []atomLink{
+			{Rel: "self", Href: "/opds/v1.2/books?page=2", Type: opds.ContentType(opds.Version1, opds.KindAcquisition)},
+			{Rel: "start", Href: "/opds/v1.2", Type: opds.ContentType(opds.Version1, opds.KindNavigation)},
+			{Rel: "previous", Href: "/opds/v1.2/books?page=1", Type: opds.ContentType(opds.Version1, opds.KindAcquisition)},
+			{Rel: "next", Href: "/opds/v1.2/books?page=3", Type: opds.ContentType(opds.Version1, opds.KindAcquisition)},
+		}, feed.Links)
+
+		assert.Len(t, feed.Entries, 1)
+		assert.EqualValues(t, "Foundation", feed.Entries[0].Title)
+		assert.EqualValues(t, "Isaac Asimov", feed.Entries[0].Author)
+		assert.EqualValues(t, "urn:isbn:9780553293357", feed.Entries[0].Identifier)
+		assert.EqualValues(t, "Foundation #1", feed.Entries[0].Content)
+		assert.EqualValues(t, []atomLink{
+			{Rel: "http://opds-spec.org/image", Href: "/books/1/cover", Type: "image/jpeg"},
+			{Rel: "http://opds-spec.org/acquisition", Href: "/books/1/files/1", Type: "application/epub+zip", Length: 1024},
+		}, feed.Entries[0].Links)
+	})
+
+	t.Run("Positive Case - OPDS 2.0", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, opds.Write(&buf, opds.Version2, newFeed()))
+
+		var feed struct {
+			Metadata struct {
+				Title         string `json:"title"`
+				NumberOfItems int64  `json:"numberOfItems"`
+				CurrentPage   int    `json:"currentPage"`
+			} `json:"metadata"`
+			Links        []map[string]any `json:"links"`
+			Publications []struct {
+				Metadata struct {
+					Identifier string `json:"identifier"`
+					Title      string `json:"title"`
+					BelongsTo  struct {
+						Series []struct {
+							Name     string  `json:"name"`
+							Position float64 `json:"position"`
+						} `json:"series"`
+					} `json:"belongsTo"`
+				} `json:"metadata"`
+				Links  []map[string]any `json:"links"`
+				Images []map[string]any `json:"images"`
+			} `json:"publications"`
+		}
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &feed))
+		assert.EqualValues(t, "Books & More", feed.Metadata.Title)
+		assert.EqualValues(t, 21, feed.Metadata.NumberOfItems)
+		assert.EqualValues(t, 2, feed.Metadata.CurrentPage)
+		assert.Len(t, feed.Links, 4)
+
+		assert.Len(t, feed.Publications, 1)
+		assert.EqualValues(t, "urn:isbn:9780553293357", feed.Publications[0].Metadata.Identifier)
+		assert.EqualValues(t, "Foundation", feed.Publications[0].Metadata.BelongsTo.Series[0].Name)
+		assert.EqualValues(t, 1, feed.Publications[0].Metadata.BelongsTo.Series[0].Position)
+		assert.EqualValues(t, "/books/1/files/1", feed.Publications[0].Links[0]["href"])
+		assert.EqualValues(t, "/books/1/cover", feed.Publications[0].Images[0]["href"])
+	})
+
+	t.Run("Positive Case - empty acquisition feed in OPDS 2.0", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, opds.Write(&buf, opds.Version2, &opds.Feed{Kind: opds.KindAcquisition, Title: "Books"}))
+		assert.Contains(t, buf.String(), `"publications":[]`)
+	})
+
+	t.Run("Negative Case - unsupported version", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.Error(t, opds.Write(&buf, "3.0", newFeed()))
+		assert.Empty(t, buf.String())
+	})
+}
+
+func TestWriteOpenSearch(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, opds.WriteOpenSearch(&buf, "Bookshelf", "https://example.com/opds/v1.2/search?q={searchTerms}"))
+
+	var description struct {
+		ShortName string `xml:"ShortName"`
+		URL       struct {
+			Template string `xml:"template,attr"`
+		} `xml:"Url"`
+	}
+	assert.NoError(t, xml.Unmarshal(buf.Bytes(), &description))
+	assert.EqualValues(t, "Bookshelf", description.ShortName)
+	assert.EqualValues(t, "https://example.com/opds/v1.2/search?q={searchTerms}", description.URL.Template)
+}
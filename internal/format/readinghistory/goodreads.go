@@ -0,0 +1,67 @@
+package readinghistory
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ReadGoodreads reads the CSV export of "My Books" on Goodreads.
+func ReadGoodreads(r io.Reader) ([]Entry, error) {
+	t, err := newTable(r, "Title", "Author", "Exclusive Shelf")
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for {
+		line, err := t.next()
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		entry := Entry{
+			Line:   line,
+			Title:  t.field("Title"),
+			Review: t.field("My Review"),
+			Status: strings.ToLower(t.field("Exclusive Shelf")),
+		}
+
+		if author := t.field("Author"); author != "" {
+			entry.Authors = append(entry.Authors, author)
+		}
+		entry.Authors = appendUnique(entry.Authors, splitList(t.field("Additional Authors"))...)
+
+		entry.ISBN = CleanISBN(t.field("ISBN13"))
+		if entry.ISBN == "" {
+			entry.ISBN = CleanISBN(t.field("ISBN"))
+		}
+
+		if pages := t.field("Number of Pages"); pages != "" {
+			entry.PageCount, _ = strconv.Atoi(pages)
+		}
+
+		entry.Shelves = appendUnique([]string{entry.Status}, splitList(t.field("Bookshelves"))...)
+
+		entry.Rating, entry.Err = parseRating(t.field("My Rating"))
+
+		if date := t.field("Date Read"); date != "" && entry.Err == nil {
+			readAt, err := parseDate(date)
+			if err != nil {
+				entry.Err = err
+			} else {
+				entry.ReadDates = append(entry.ReadDates, readAt)
+			}
+		}
+
+		if entry.Title == "" && entry.Err == nil {
+			entry.Err = errors.New("title is required")
+		}
+
+		entries = append(entries, entry)
+	}
+}
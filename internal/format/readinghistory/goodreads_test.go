@@ -0,0 +1,70 @@
+package readinghistory_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/format/readinghistory"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadGoodreads(t *testing.T) {
+	t.Run("Positive Case - export", func(t *testing.T) {
+		entries, err := readinghistory.ReadGoodreads(strings.NewReader("\ufeffBook Id,Title,Author,Additional Authors,ISBN,ISBN13,My Rating,Number of Pages,Date Read,Bookshelves,Exclusive Shelf,My Review\n" +
+			`1,Good Omens,Terry Pratchett,"Neil Gaiman, Terry Pratchett","=""0060853980""","=""9780060853983""",5,432,2023/01/20,"favorites, read",read,Funny.` + "\n" +
+			",,,,,,,,,,,\n" +
+			`2,Dune,Frank Herbert,,"=""0441172717""","=""""",0,,,,to-read,` + "\n"))
+		assert.NoError(t, err)
+		assert.EqualValues(t, []readinghistory.Entry{
+			{
+				Line:      2,
+				Title:     "Good Omens",
+				Authors:   []string{"Terry Pratchett", "Neil Gaiman"},
+				ISBN:      "9780060853983",
+				PageCount: 432,
+				Rating:    5,
+				Review:    "Funny.",
+				Status:    readinghistory.StatusRead,
+				Shelves:   []string{"read", "favorites"},
+				ReadDates: []time.Time{time.Date(2023, time.January, 20, 0, 0, 0, 0, time.UTC)},
+			},
+			{
+				Line:    4,
+				Title:   "Dune",
+				Authors: []string{"Frank Herbert"},
+				ISBN:    "0441172717",
+				Status:  readinghistory.StatusToRead,
+				Shelves: []string{"to-read"},
+			},
+		}, entries)
+	})
+
+	t.Run("Positive Case - malformed rows are reported one by one", func(t *testing.T) {
+		entries, err := readinghistory.ReadGoodreads(strings.NewReader("Title,Author,My Rating,Date Read,Exclusive Shelf\n" +
+			"Dune,Frank Herbert,6,,read\n" +
+			"Dune,Frank Herbert,5,20 January 2023,read\n" +
+			",Frank Herbert,5,,read\n"))
+		assert.NoError(t, err)
+		assert.Len(t, entries, 3)
+		for _, entry := range entries {
+			assert.Error(t, entry.Err, "line %d", entry.Line)
+		}
+	})
+
+	t.Run("Negative Case - malformed file", func(t *testing.T) {
+		for name, input := range map[string]string{
+			"missing header":       "",
+			"missing shelf column": "Title,Author\nDune,Frank Herbert\n",
+			"storygraph export":    "Title,Authors,Read Status\nDune,Frank Herbert,read\n",
+		} {
+			_, err := readinghistory.ReadGoodreads(strings.NewReader(input))
+			assert.Error(t, err, name)
+		}
+	})
+}
+
+func TestCleanISBN(t *testing.T) {
+	assert.EqualValues(t, "0451524934", readinghistory.CleanISBN(` ="0451524934" `))
+	assert.EqualValues(t, "9780451524935", readinghistory.CleanISBN("9780451524935"))
+}
@@ -0,0 +1,159 @@
+package readinghistory
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	StatusRead             = "read"
+	StatusCurrentlyReading = "currently-reading"
+	StatusToRead           = "to-read"
+	StatusDidNotFinish     = "did-not-finish"
+)
+
+const bom = "\ufeff"
+
+var dateLayouts = []string{"2006/01/02", "2006-01-02", "2006/1/2"}
+
+// Entry is a book of a reading history export with what the user recorded
+// about it.
+type Entry struct {
+	Line      int
+	Title     string
+	Authors   []string
+	ISBN      string
+	PageCount int
+	// Rating is between 0 and 5 where 0 means the book was not rated.
+	Rating    float64
+	Review    string
+	Status    string
+	Shelves   []string
+	ReadDates []time.Time
+	Err       error
+}
+
+// CleanISBN removes the ="..." wrapping spreadsheet-safe exports use to keep
+// leading zeros, e.g. ="0451524934" becomes 0451524934.
+func CleanISBN(value string) string {
+	value = strings.TrimSpace(value)
+	value = strings.TrimPrefix(value, "=")
+	value = strings.Trim(value, `"`)
+	return strings.TrimSpace(value)
+}
+
+type table struct {
+	reader  *csv.Reader
+	columns map[string]int
+	record  []string
+}
+
+func newTable(r io.Reader, required ...string) (*table, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("missing header")
+		}
+		return nil, err
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, bom)))
+		if _, ok := columns[name]; !ok {
+			columns[name] = i
+		}
+	}
+	for _, name := range required {
+		if _, ok := columns[strings.ToLower(name)]; !ok {
+			return nil, fmt.Errorf("missing %s column", name)
+		}
+	}
+
+	return &table{reader: reader, columns: columns}, nil
+}
+
+// next advances to the next non-blank row and returns its line number.
+func (t *table) next() (int, error) {
+	for {
+		record, err := t.reader.Read()
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return 0, fmt.Errorf("line %d: %w", parseErr.Line, parseErr.Err)
+			}
+			return 0, err
+		}
+
+		for _, value := range record {
+			if strings.TrimSpace(value) != "" {
+				t.record = record
+				line, _ := t.reader.FieldPos(0)
+				return line, nil
+			}
+		}
+	}
+}
+
+func (t *table) field(name string) string {
+	i, ok := t.columns[strings.ToLower(name)]
+	if !ok || i >= len(t.record) {
+		return ""
+	}
+	return strings.TrimSpace(t.record[i])
+}
+
+func parseDate(value string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+func parseRating(value string) (float64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	rating, err := strconv.ParseFloat(value, 64)
+	if err != nil || rating < 0 || rating > 5 {
+		return 0, fmt.Errorf("invalid rating %q", value)
+	}
+	return rating, nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func appendUnique(items []string, values ...string) []string {
+	for _, value := range values {
+		found := false
+		for _, item := range items {
+			if strings.EqualFold(item, value) {
+				found = true
+				break
+			}
+		}
+		if !found && value != "" {
+			items = append(items, value)
+		}
+	}
+	return items
+}
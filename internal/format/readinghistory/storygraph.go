@@ -0,0 +1,81 @@
+package readinghistory
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+// ReadStoryGraph reads the CSV export of The StoryGraph.
+func ReadStoryGraph(r io.Reader) ([]Entry, error) {
+	t, err := newTable(r, "Title", "Authors", "Read Status")
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for {
+		line, err := t.next()
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		entry := Entry{
+			Line:    line,
+			Title:   t.field("Title"),
+			Authors: splitList(t.field("Authors")),
+			ISBN:    CleanISBN(t.field("ISBN/UID")),
+			Review:  t.field("Review"),
+			Status:  strings.ToLower(t.field("Read Status")),
+		}
+
+		entry.Shelves = appendUnique([]string{entry.Status}, splitList(t.field("Tags"))...)
+
+		entry.Rating, entry.Err = parseRating(t.field("Star Rating"))
+
+		if entry.Err == nil {
+			entry.ReadDates, entry.Err = storyGraphReadDates(t.field("Dates Read"), t.field("Last Date Read"))
+		}
+
+		if entry.Title == "" && entry.Err == nil {
+			entry.Err = errors.New("title is required")
+		}
+
+		entries = append(entries, entry)
+	}
+}
+
+// storyGraphReadDates returns the finish date of every read. "Dates Read"
+// lists reads as "2023/01/02-2023/01/20" ranges separated by commas, with
+// "Last Date Read" as a fallback for exports that only carry the last one.
+func storyGraphReadDates(datesRead string, lastDateRead string) ([]time.Time, error) {
+	var dates []time.Time
+	for _, read := range splitList(datesRead) {
+		finished := read
+		if _, end, ok := strings.Cut(read, "-"); ok && strings.Contains(read, "/") {
+			finished = strings.TrimSpace(end)
+		}
+		if finished == "" {
+			continue
+		}
+		date, err := parseDate(finished)
+		if err != nil {
+			return nil, err
+		}
+		dates = append(dates, date)
+	}
+
+	if len(dates) == 0 && lastDateRead != "" {
+		date, err := parseDate(lastDateRead)
+		if err != nil {
+			return nil, err
+		}
+		dates = append(dates, date)
+	}
+
+	return dates, nil
+}
@@ -0,0 +1,77 @@
+package readinghistory_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/format/readinghistory"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadStoryGraph(t *testing.T) {
+	t.Run("Positive Case - export", func(t *testing.T) {
+		entries, err := readinghistory.ReadStoryGraph(strings.NewReader("Title,Authors,ISBN/UID,Format,Read Status,Dates Read,Last Date Read,Star Rating,Review,Tags\n" +
+			`Good Omens,"Terry Pratchett, Neil Gaiman",9780060853983,paperback,read,"2022/12/01-2022/12/24, 2023/01/02-2023/01/20",2023/01/20,4.5,Funny.,"humor, Read"` + "\n" +
+			"Dune,Frank Herbert,9780441172719,digital,read,,2023/03/01,,,\n" +
+			"Foundation,Isaac Asimov,9780553293357,audio,did-not-finish,,,,,\n"))
+		assert.NoError(t, err)
+		assert.EqualValues(t, []readinghistory.Entry{
+			{
+				Line:    2,
+				Title:   "Good Omens",
+				Authors: []string{"Terry Pratchett", "Neil Gaiman"},
+				ISBN:    "9780060853983",
+				Rating:  4.5,
+				Review:  "Funny.",
+				Status:  readinghistory.StatusRead,
+				Shelves: []string{"read", "humor"},
+				ReadDates: []time.Time{
+					time.Date(2022, time.December, 24, 0, 0, 0, 0, time.UTC),
+					time.Date(2023, time.January, 20, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			{
+				Line:      3,
+				Title:     "Dune",
+				Authors:   []string{"Frank Herbert"},
+				ISBN:      "9780441172719",
+				Status:    readinghistory.StatusRead,
+				Shelves:   []string{"read"},
+				ReadDates: []time.Time{time.Date(2023, time.March, 1, 0, 0, 0, 0, time.UTC)},
+			},
+			{
+				Line:    4,
+				Title:   "Foundation",
+				Authors: []string{"Isaac Asimov"},
+				ISBN:    "9780553293357",
+				Status:  readinghistory.StatusDidNotFinish,
+				Shelves: []string{"did-not-finish"},
+			},
+		}, entries)
+	})
+
+	t.Run("Positive Case - malformed rows are reported one by one", func(t *testing.T) {
+		entries, err := readinghistory.ReadStoryGraph(strings.NewReader("Title,Authors,Read Status,Dates Read,Last Date Read,Star Rating\n" +
+			"Dune,Frank Herbert,read,,,-1\n" +
+			"Dune,Frank Herbert,read,2023/01/02-yesterday,,\n" +
+			"Dune,Frank Herbert,read,,March 2023,\n" +
+			",Frank Herbert,read,,,\n"))
+		assert.NoError(t, err)
+		assert.Len(t, entries, 4)
+		for _, entry := range entries {
+			assert.Error(t, entry.Err, "line %d", entry.Line)
+		}
+	})
+
+	t.Run("Negative Case - malformed file", func(t *testing.T) {
+		for name, input := range map[string]string{
+			"missing header":        "",
+			"missing status column": "Title,Authors\nDune,Frank Herbert\n",
+			"goodreads export":      "Title,Author,Exclusive Shelf\nDune,Frank Herbert,read\n",
+		} {
+			_, err := readinghistory.ReadStoryGraph(strings.NewReader(input))
+			assert.Error(t, err, name)
+		}
+	})
+}
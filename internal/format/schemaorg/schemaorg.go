@@ -0,0 +1,54 @@
+// Package schemaorg describes books and people with the schema.org
+// vocabulary, encoded as JSON-LD.
+package schemaorg
+
+const (
+	Context     = "https://schema.org"
+	ContentType = "application/ld+json"
+)
+
+const (
+	TypeBook            = "Book"
+	TypePerson          = "Person"
+	TypeAggregateRating = "AggregateRating"
+	TypeBookSeries      = "BookSeries"
+)
+
+type Book struct {
+	Context         string           `json:"@context,omitempty"`
+	Type            string           `json:"@type"`
+	ID              string           `json:"@id,omitempty"`
+	URL             string           `json:"url,omitempty"`
+	Name            string           `json:"name"`
+	ISBN            string           `json:"isbn,omitempty"`
+	NumberOfPages   int              `json:"numberOfPages,omitempty"`
+	InLanguage      string           `json:"inLanguage,omitempty"`
+	Author          *Person          `json:"author,omitempty"`
+	AggregateRating *AggregateRating `json:"aggregateRating,omitempty"`
+	Image           string           `json:"image,omitempty"`
+	ThumbnailURL    string           `json:"thumbnailUrl,omitempty"`
+	IsPartOf        *BookSeries      `json:"isPartOf,omitempty"`
+	Position        float64          `json:"position,omitempty"`
+}
+
+type Person struct {
+	Context   string `json:"@context,omitempty"`
+	Type      string `json:"@type"`
+	ID        string `json:"@id,omitempty"`
+	URL       string `json:"url,omitempty"`
+	Name      string `json:"name"`
+	BirthDate string `json:"birthDate,omitempty"`
+}
+
+type AggregateRating struct {
+	Type        string  `json:"@type"`
+	RatingValue float64 `json:"ratingValue"`
+	RatingCount int64   `json:"ratingCount"`
+	BestRating  int     `json:"bestRating"`
+	WorstRating int     `json:"worstRating"`
+}
+
+type BookSeries struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
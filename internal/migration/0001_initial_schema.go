@@ -0,0 +1,253 @@
+package migration
+
+import "time"
+
+// initialSchema is the schema repositories used to create on startup. It
+// also completes databases created that way, which lack the columns added
+// to the entities after their tables were created.
+var initialSchema = Migration{
+	Version: 1,
+	Name:    "initial_schema",
+	Up: []Step{
+		AutoMigrate(&initialUser{}),
+		AutoMigrate(&initialAuthor{}),
+		AutoMigrate(&initialBook{}),
+		AutoMigrate(&initialDeletedBook{}),
+		AutoMigrate(&initialReview{}),
+		AutoMigrate(&initialReadingProgress{}),
+		AutoMigrate(&initialReadingGoal{}),
+		AutoMigrate(&initialChallenge{}),
+		AutoMigrate(&initialChallengeParticipant{}),
+		AutoMigrate(&initialAnnotation{}),
+		AutoMigrate(&initialShelfEntry{}),
+		AutoMigrate(&initialImportJob{}),
+		AutoMigrate(&initialBookFile{}),
+		AutoMigrate(&initialAPIKey{}),
+	},
+	Down: []Step{
+		DropTable(&initialAPIKey{}),
+		DropTable(&initialBookFile{}),
+		DropTable(&initialImportJob{}),
+		DropTable(&initialShelfEntry{}),
+		DropTable(&initialAnnotation{}),
+		DropTable(&initialChallengeParticipant{}),
+		DropTable(&initialChallenge{}),
+		DropTable(&initialReadingGoal{}),
+		DropTable(&initialReadingProgress{}),
+		DropTable(&initialReview{}),
+		DropTable(&initialDeletedBook{}),
+		DropTable(&initialBook{}),
+		DropTable(&initialAuthor{}),
+		DropTable(&initialUser{}),
+	},
+}
+
+type initialUser struct {
+	ID       int    `gorm:"column:id;primaryKey"`
+	Username string `gorm:"column:username;not null;unique"`
+	Password string `gorm:"column:password"`
+	Role     string `gorm:"column:role;not null;default:user"`
+}
+
+func (*initialUser) TableName() string {
+	return "users"
+}
+
+type initialAuthor struct {
+	ID        int       `gorm:"column:id;primaryKey"`
+	Name      string    `gorm:"column:name"`
+	Birthdate time.Time `gorm:"column:birthdate"`
+}
+
+func (*initialAuthor) TableName() string {
+	return "authors"
+}
+
+type initialBook struct {
+	ID               int       `gorm:"column:id;primaryKey"`
+	Title            string    `gorm:"column:title"`
+	ISBN             string    `gorm:"column:isbn;not null;unique"`
+	AuthorID         int       `gorm:"column:author_id"`
+	PageCount        int       `gorm:"column:page_count;not null;default:0"`
+	RatingAverage    float64   `gorm:"column:rating_average;not null;default:0"`
+	RatingCount      int64     `gorm:"column:rating_count;not null;default:0"`
+	Language         string    `gorm:"column:language"`
+	Series           string    `gorm:"column:series;index"`
+	SeriesIndex      float64   `gorm:"column:series_index;not null;default:0"`
+	CoverKey         string    `gorm:"column:cover_key"`
+	CoverContentType string    `gorm:"column:cover_content_type"`
+	CoverChecksum    string    `gorm:"column:cover_checksum"`
+	UpdatedAt        time.Time `gorm:"column:updated_at;index"`
+
+	Author initialAuthor `gorm:"foreignKey:author_id;references:id"`
+}
+
+func (*initialBook) TableName() string {
+	return "books"
+}
+
+type initialDeletedBook struct {
+	ID        int       `gorm:"column:id;primaryKey;autoIncrement:false"`
+	AuthorID  int       `gorm:"column:author_id;index"`
+	DeletedAt time.Time `gorm:"column:deleted_at;index"`
+}
+
+func (*initialDeletedBook) TableName() string {
+	return "deleted_books"
+}
+
+type initialReview struct {
+	ID        int       `gorm:"column:id;primaryKey"`
+	UserID    int       `gorm:"column:user_id;not null;uniqueIndex:idx_reviews_user_id_book_id"`
+	BookID    int       `gorm:"column:book_id;not null;uniqueIndex:idx_reviews_user_id_book_id;index"`
+	Rating    int       `gorm:"column:rating;not null"`
+	Text      string    `gorm:"column:text"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at"`
+
+	User initialUser `gorm:"foreignKey:user_id;references:id"`
+}
+
+func (*initialReview) TableName() string {
+	return "reviews"
+}
+
+type initialReadingProgress struct {
+	ID         int       `gorm:"column:id;primaryKey"`
+	UserID     int       `gorm:"column:user_id;not null;index:idx_reading_progresses_user_id_book_id"`
+	BookID     int       `gorm:"column:book_id;not null;index:idx_reading_progresses_user_id_book_id"`
+	Page       int       `gorm:"column:page;not null"`
+	Percentage float64   `gorm:"column:percentage;not null"`
+	ReadAt     time.Time `gorm:"column:read_at;not null;index"`
+	CreatedAt  time.Time `gorm:"column:created_at"`
+
+	Book initialBook `gorm:"foreignKey:book_id;references:id"`
+}
+
+func (*initialReadingProgress) TableName() string {
+	return "reading_progresses"
+}
+
+type initialReadingGoal struct {
+	ID     int    `gorm:"column:id;primaryKey"`
+	UserID int    `gorm:"column:user_id;not null;uniqueIndex:idx_reading_goals_user_id_year"`
+	Year   int    `gorm:"column:year;not null;uniqueIndex:idx_reading_goals_user_id_year"`
+	Type   string `gorm:"column:type;not null"`
+	Target int    `gorm:"column:target;not null"`
+}
+
+func (*initialReadingGoal) TableName() string {
+	return "reading_goals"
+}
+
+type initialChallenge struct {
+	ID               int        `gorm:"column:id;primaryKey"`
+	Name             string     `gorm:"column:name;not null"`
+	Description      string     `gorm:"column:description"`
+	StartAt          time.Time  `gorm:"column:start_at;not null"`
+	EndAt            time.Time  `gorm:"column:end_at;not null"`
+	TargetBooks      int        `gorm:"column:target_books;not null"`
+	AuthorBornBefore *time.Time `gorm:"column:author_born_before"`
+	AuthorBornAfter  *time.Time `gorm:"column:author_born_after"`
+}
+
+func (*initialChallenge) TableName() string {
+	return "challenges"
+}
+
+type initialChallengeParticipant struct {
+	ID          int       `gorm:"column:id;primaryKey"`
+	ChallengeID int       `gorm:"column:challenge_id;not null;uniqueIndex:idx_challenge_participants_challenge_id_user_id"`
+	UserID      int       `gorm:"column:user_id;not null;uniqueIndex:idx_challenge_participants_challenge_id_user_id"`
+	JoinedAt    time.Time `gorm:"column:joined_at;autoCreateTime"`
+
+	User initialUser `gorm:"foreignKey:user_id;references:id"`
+}
+
+func (*initialChallengeParticipant) TableName() string {
+	return "challenge_participants"
+}
+
+type initialAnnotation struct {
+	ID         int       `gorm:"column:id;primaryKey"`
+	UserID     int       `gorm:"column:user_id;not null;index"`
+	BookID     int       `gorm:"column:book_id;not null;index"`
+	Page       int       `gorm:"column:page"`
+	Location   string    `gorm:"column:location"`
+	Text       string    `gorm:"column:text;not null"`
+	Note       string    `gorm:"column:note"`
+	Visibility string    `gorm:"column:visibility;not null;default:private"`
+	CreatedAt  time.Time `gorm:"column:created_at"`
+	UpdatedAt  time.Time `gorm:"column:updated_at"`
+
+	User initialUser `gorm:"foreignKey:user_id;references:id"`
+	Book initialBook `gorm:"foreignKey:book_id;references:id"`
+}
+
+func (*initialAnnotation) TableName() string {
+	return "annotations"
+}
+
+type initialShelfEntry struct {
+	ID        int       `gorm:"column:id;primaryKey"`
+	UserID    int       `gorm:"column:user_id;not null;uniqueIndex:idx_shelf_entries_user_id_shelf_book_id"`
+	Shelf     string    `gorm:"column:shelf;not null;uniqueIndex:idx_shelf_entries_user_id_shelf_book_id"`
+	BookID    int       `gorm:"column:book_id;not null;uniqueIndex:idx_shelf_entries_user_id_shelf_book_id;index"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+
+	Book initialBook `gorm:"foreignKey:book_id;references:id"`
+}
+
+func (*initialShelfEntry) TableName() string {
+	return "shelf_entries"
+}
+
+type initialImportJob struct {
+	ID         int        `gorm:"column:id;primaryKey"`
+	UserID     int        `gorm:"column:user_id;not null;index"`
+	Source     string     `gorm:"column:source;not null"`
+	Status     string     `gorm:"column:status;not null;default:pending"`
+	Total      int        `gorm:"column:total;not null"`
+	Processed  int        `gorm:"column:processed;not null"`
+	Result     string     `gorm:"column:result"`
+	Error      string     `gorm:"column:error"`
+	CreatedAt  time.Time  `gorm:"column:created_at"`
+	UpdatedAt  time.Time  `gorm:"column:updated_at"`
+	FinishedAt *time.Time `gorm:"column:finished_at"`
+}
+
+func (*initialImportJob) TableName() string {
+	return "import_jobs"
+}
+
+type initialBookFile struct {
+	ID          int       `gorm:"column:id;primaryKey"`
+	BookID      int       `gorm:"column:book_id;not null;uniqueIndex:idx_book_files_book_id_checksum"`
+	Format      string    `gorm:"column:format;not null"`
+	Filename    string    `gorm:"column:filename;not null"`
+	ContentType string    `gorm:"column:content_type;not null"`
+	Size        int64     `gorm:"column:size;not null"`
+	Checksum    string    `gorm:"column:checksum;not null;uniqueIndex:idx_book_files_book_id_checksum"`
+	StorageKey  string    `gorm:"column:storage_key;not null"`
+	CreatedAt   time.Time `gorm:"column:created_at"`
+}
+
+func (*initialBookFile) TableName() string {
+	return "book_files"
+}
+
+type initialAPIKey struct {
+	ID         int        `gorm:"column:id;primaryKey"`
+	UserID     int        `gorm:"column:user_id;not null;index"`
+	Name       string     `gorm:"column:name;not null"`
+	Prefix     string     `gorm:"column:prefix;not null"`
+	KeyHash    string     `gorm:"column:key_hash;not null;unique"`
+	CreatedAt  time.Time  `gorm:"column:created_at"`
+	LastUsedAt *time.Time `gorm:"column:last_used_at"`
+
+	User initialUser `gorm:"foreignKey:user_id;references:id"`
+}
+
+func (*initialAPIKey) TableName() string {
+	return "api_keys"
+}
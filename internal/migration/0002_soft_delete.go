@@ -0,0 +1,115 @@
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// softDelete keeps deleted users, authors and books in the trash until they
+// are purged. An ISBN only has to be unique among the books which are not in
+// the trash, MySQL cannot index part of a table and indexes a column holding
+// the ISBN of those books instead.
+var softDelete = Migration{
+	Version: 2,
+	Name:    "soft_delete",
+	Up: []Step{
+		AddColumn(&softDeleteUser{}, "DeletedAt"),
+		AddColumn(&softDeleteUser{}, "DeletedBy"),
+		CreateIndex(&softDeleteUser{}, "DeletedAt"),
+		AddColumn(&softDeleteAuthor{}, "DeletedAt"),
+		AddColumn(&softDeleteAuthor{}, "DeletedBy"),
+		CreateIndex(&softDeleteAuthor{}, "DeletedAt"),
+		AddColumn(&softDeleteBook{}, "DeletedAt"),
+		AddColumn(&softDeleteBook{}, "DeletedBy"),
+		CreateIndex(&softDeleteBook{}, "DeletedAt"),
+		Dialect("sqlite",
+			RebuildTable(&softDeleteBook{}),
+		),
+		Dialect("postgres",
+			Exec("ALTER TABLE books DROP CONSTRAINT uni_books_isbn"),
+			Exec("CREATE UNIQUE INDEX idx_books_isbn ON books (isbn) WHERE deleted_at IS NULL"),
+		),
+		Dialect("mysql",
+			Exec("ALTER TABLE books DROP INDEX uni_books_isbn"),
+			Exec("ALTER TABLE books ADD COLUMN live_isbn varchar(191) AS (CASE WHEN deleted_at IS NULL THEN isbn END) STORED"),
+			Exec("CREATE UNIQUE INDEX idx_books_isbn ON books (live_isbn)"),
+		),
+	},
+	Down: []Step{
+		Dialect("sqlite",
+			RebuildTable(&initialBook{}),
+		),
+		Dialect("postgres",
+			DropIndex(&softDeleteBook{}, "idx_books_isbn"),
+			Exec("ALTER TABLE books ADD CONSTRAINT uni_books_isbn UNIQUE (isbn)"),
+			DropIndex(&softDeleteBook{}, "DeletedAt"),
+			DropColumn(&softDeleteBook{}, "DeletedBy"),
+			DropColumn(&softDeleteBook{}, "DeletedAt"),
+		),
+		Dialect("mysql",
+			DropIndex(&softDeleteBook{}, "idx_books_isbn"),
+			Exec("ALTER TABLE books DROP COLUMN live_isbn"),
+			Exec("ALTER TABLE books ADD CONSTRAINT uni_books_isbn UNIQUE (isbn)"),
+			DropIndex(&softDeleteBook{}, "DeletedAt"),
+			DropColumn(&softDeleteBook{}, "DeletedBy"),
+			DropColumn(&softDeleteBook{}, "DeletedAt"),
+		),
+		DropIndex(&softDeleteAuthor{}, "DeletedAt"),
+		DropColumn(&softDeleteAuthor{}, "DeletedBy"),
+		DropColumn(&softDeleteAuthor{}, "DeletedAt"),
+		DropIndex(&softDeleteUser{}, "DeletedAt"),
+		DropColumn(&softDeleteUser{}, "DeletedBy"),
+		DropColumn(&softDeleteUser{}, "DeletedAt"),
+	},
+}
+
+type softDeleteUser struct {
+	ID        int            `gorm:"column:id;primaryKey"`
+	Username  string         `gorm:"column:username;not null;unique"`
+	Password  string         `gorm:"column:password"`
+	Role      string         `gorm:"column:role;not null;default:user"`
+	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at;index"`
+	DeletedBy *int           `gorm:"column:deleted_by"`
+}
+
+func (*softDeleteUser) TableName() string {
+	return "users"
+}
+
+type softDeleteAuthor struct {
+	ID        int            `gorm:"column:id;primaryKey"`
+	Name      string         `gorm:"column:name"`
+	Birthdate time.Time      `gorm:"column:birthdate"`
+	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at;index"`
+	DeletedBy *int           `gorm:"column:deleted_by"`
+}
+
+func (*softDeleteAuthor) TableName() string {
+	return "authors"
+}
+
+type softDeleteBook struct {
+	ID               int            `gorm:"column:id;primaryKey"`
+	Title            string         `gorm:"column:title"`
+	ISBN             string         `gorm:"column:isbn;not null;uniqueIndex:idx_books_isbn,where:deleted_at IS NULL"`
+	AuthorID         int            `gorm:"column:author_id"`
+	PageCount        int            `gorm:"column:page_count;not null;default:0"`
+	RatingAverage    float64        `gorm:"column:rating_average;not null;default:0"`
+	RatingCount      int64          `gorm:"column:rating_count;not null;default:0"`
+	Language         string         `gorm:"column:language"`
+	Series           string         `gorm:"column:series;index"`
+	SeriesIndex      float64        `gorm:"column:series_index;not null;default:0"`
+	CoverKey         string         `gorm:"column:cover_key"`
+	CoverContentType string         `gorm:"column:cover_content_type"`
+	CoverChecksum    string         `gorm:"column:cover_checksum"`
+	UpdatedAt        time.Time      `gorm:"column:updated_at;index"`
+	DeletedAt        gorm.DeletedAt `gorm:"column:deleted_at;index"`
+	DeletedBy        *int           `gorm:"column:deleted_by"`
+
+	Author softDeleteAuthor `gorm:"foreignKey:author_id;references:id"`
+}
+
+func (*softDeleteBook) TableName() string {
+	return "books"
+}
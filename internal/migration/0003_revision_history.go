@@ -0,0 +1,28 @@
+package migration
+
+import "time"
+
+var revisionHistory = Migration{
+	Version: 3,
+	Name:    "revision_history",
+	Up: []Step{
+		AutoMigrate(&revisionHistoryRevision{}),
+	},
+	Down: []Step{
+		DropTable(&revisionHistoryRevision{}),
+	},
+}
+
+type revisionHistoryRevision struct {
+	ID         int       `gorm:"column:id;primaryKey"`
+	EntityType string    `gorm:"column:entity_type;not null;uniqueIndex:idx_revisions_entity_revision"`
+	EntityID   int       `gorm:"column:entity_id;not null;uniqueIndex:idx_revisions_entity_revision"`
+	Revision   int       `gorm:"column:revision;not null;uniqueIndex:idx_revisions_entity_revision"`
+	Data       string    `gorm:"column:data;not null"`
+	CreatedBy  *int      `gorm:"column:created_by"`
+	CreatedAt  time.Time `gorm:"column:created_at"`
+}
+
+func (*revisionHistoryRevision) TableName() string {
+	return "revisions"
+}
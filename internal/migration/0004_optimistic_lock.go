@@ -0,0 +1,73 @@
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// optimisticLock numbers the saved versions of authors and books, so that an
+// update of a record which was saved since it was read is refused. SQLite
+// drops a column by rebuilding the table, which loses its indexes, and
+// rebuilds the tables from their previous schema instead.
+var optimisticLock = Migration{
+	Version: 4,
+	Name:    "optimistic_lock",
+	Up: []Step{
+		AddColumn(&optimisticLockAuthor{}, "Version"),
+		AddColumn(&optimisticLockBook{}, "Version"),
+	},
+	Down: []Step{
+		Dialect("sqlite",
+			RebuildTable(&softDeleteBook{}),
+			RebuildTable(&softDeleteAuthor{}),
+		),
+		Dialect("postgres",
+			DropColumn(&optimisticLockBook{}, "Version"),
+			DropColumn(&optimisticLockAuthor{}, "Version"),
+		),
+		Dialect("mysql",
+			DropColumn(&optimisticLockBook{}, "Version"),
+			DropColumn(&optimisticLockAuthor{}, "Version"),
+		),
+	},
+}
+
+type optimisticLockAuthor struct {
+	ID        int            `gorm:"column:id;primaryKey"`
+	Name      string         `gorm:"column:name"`
+	Birthdate time.Time      `gorm:"column:birthdate"`
+	Version   int            `gorm:"column:version;not null;default:1"`
+	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at;index"`
+	DeletedBy *int           `gorm:"column:deleted_by"`
+}
+
+func (*optimisticLockAuthor) TableName() string {
+	return "authors"
+}
+
+type optimisticLockBook struct {
+	ID               int            `gorm:"column:id;primaryKey"`
+	Title            string         `gorm:"column:title"`
+	ISBN             string         `gorm:"column:isbn;not null;uniqueIndex:idx_books_isbn,where:deleted_at IS NULL"`
+	AuthorID         int            `gorm:"column:author_id"`
+	PageCount        int            `gorm:"column:page_count;not null;default:0"`
+	RatingAverage    float64        `gorm:"column:rating_average;not null;default:0"`
+	RatingCount      int64          `gorm:"column:rating_count;not null;default:0"`
+	Language         string         `gorm:"column:language"`
+	Series           string         `gorm:"column:series;index"`
+	SeriesIndex      float64        `gorm:"column:series_index;not null;default:0"`
+	CoverKey         string         `gorm:"column:cover_key"`
+	CoverContentType string         `gorm:"column:cover_content_type"`
+	CoverChecksum    string         `gorm:"column:cover_checksum"`
+	Version          int            `gorm:"column:version;not null;default:1"`
+	UpdatedAt        time.Time      `gorm:"column:updated_at;index"`
+	DeletedAt        gorm.DeletedAt `gorm:"column:deleted_at;index"`
+	DeletedBy        *int           `gorm:"column:deleted_by"`
+
+	Author optimisticLockAuthor `gorm:"foreignKey:author_id;references:id"`
+}
+
+func (*optimisticLockBook) TableName() string {
+	return "books"
+}
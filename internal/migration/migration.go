@@ -0,0 +1,309 @@
+// Package migration defines the ordered, versioned changes that bring a
+// database to the schema the entities expect.
+package migration
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Migration changes the schema from the previous version to Version with Up,
+// and back with Down. A migration must not be edited once it is released,
+// its Checksum tells when it was.
+type Migration struct {
+	Version int
+	Name    string
+	Up      []Step
+	Down    []Step
+}
+
+// Step is a change of a migration. String describes the change, including
+// the columns of models, so that it is part of the checksum.
+type Step interface {
+	Apply(db *gorm.DB) error
+	String() string
+}
+
+// All returns the migrations of bookshelf, ordered by version.
+func All() []Migration {
+	return []Migration{
+		initialSchema,
+		softDelete,
+		revisionHistory,
+		optimisticLock,
+	}
+}
+
+func (m *Migration) Checksum() string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%d %s\n", m.Version, m.Name)
+	for _, step := range m.Up {
+		fmt.Fprintf(hash, "up %s\n", step)
+	}
+	for _, step := range m.Down {
+		fmt.Fprintf(hash, "down %s\n", step)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// AutoMigrate creates the table of model, or adds the columns, indexes and
+// constraints it lacks. Models are snapshots of the entities declared next
+// to the migration, the entities themselves change with later migrations.
+func AutoMigrate(model any) Step {
+	return &autoMigrate{model}
+}
+
+func DropTable(model any) Step {
+	return &dropTable{model}
+}
+
+func AddColumn(model any, field string) Step {
+	return &addColumn{model, field}
+}
+
+func DropColumn(model any, field string) Step {
+	return &dropColumn{model, field}
+}
+
+// CreateIndex creates the index of model named name, or the index of the
+// field named name.
+func CreateIndex(model any, name string) Step {
+	return &createIndex{model, name}
+}
+
+func DropIndex(model any, name string) Step {
+	return &dropIndex{model, name}
+}
+
+func Exec(statement string) Step {
+	return &exec{statement}
+}
+
+// Dialect applies steps only to databases of the dialect, such as sqlite,
+// postgres or mysql, for changes the dialects make differently.
+func Dialect(name string, steps ...Step) Step {
+	return &dialect{name, steps}
+}
+
+// RebuildTable creates the table of model anew and copies the rows of the
+// columns both tables have, the way SQLite changes the constraints of a
+// table. Foreign keys must not be enforced while the old table is dropped.
+func RebuildTable(model any) Step {
+	return &rebuildTable{model}
+}
+
+type autoMigrate struct{ model any }
+
+func (s *autoMigrate) Apply(db *gorm.DB) error {
+	return db.Migrator().AutoMigrate(s.model)
+}
+
+func (s *autoMigrate) String() string {
+	return "auto migrate " + describe(s.model)
+}
+
+type dropTable struct{ model any }
+
+func (s *dropTable) Apply(db *gorm.DB) error {
+	return db.Migrator().DropTable(s.model)
+}
+
+func (s *dropTable) String() string {
+	return "drop table " + describe(s.model)
+}
+
+type addColumn struct {
+	model any
+	field string
+}
+
+func (s *addColumn) Apply(db *gorm.DB) error {
+	return db.Migrator().AddColumn(s.model, s.field)
+}
+
+func (s *addColumn) String() string {
+	return "add column " + s.field + " to " + describe(s.model)
+}
+
+type dropColumn struct {
+	model any
+	field string
+}
+
+func (s *dropColumn) Apply(db *gorm.DB) error {
+	return db.Migrator().DropColumn(s.model, s.field)
+}
+
+func (s *dropColumn) String() string {
+	return "drop column " + s.field + " from " + describe(s.model)
+}
+
+type createIndex struct {
+	model any
+	name  string
+}
+
+func (s *createIndex) Apply(db *gorm.DB) error {
+	return db.Migrator().CreateIndex(s.model, s.name)
+}
+
+func (s *createIndex) String() string {
+	return "create index " + s.name + " on " + describe(s.model)
+}
+
+type dropIndex struct {
+	model any
+	name  string
+}
+
+func (s *dropIndex) Apply(db *gorm.DB) error {
+	return db.Migrator().DropIndex(s.model, s.name)
+}
+
+func (s *dropIndex) String() string {
+	return "drop index " + s.name + " from " + describe(s.model)
+}
+
+type exec struct{ statement string }
+
+func (s *exec) Apply(db *gorm.DB) error {
+	return db.Exec(s.statement).Error
+}
+
+func (s *exec) String() string {
+	return "exec " + s.statement
+}
+
+type dialect struct {
+	name  string
+	steps []Step
+}
+
+func (s *dialect) Apply(db *gorm.DB) error {
+	if db.Dialector.Name() != s.name {
+		return nil
+	}
+	for _, step := range s.steps {
+		if err := step.Apply(db); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *dialect) String() string {
+	descriptions := make([]string, len(s.steps))
+	for i, step := range s.steps {
+		descriptions[i] = step.String()
+	}
+	return "on " + s.name + ": " + strings.Join(descriptions, "; ")
+}
+
+type rebuildTable struct{ model any }
+
+func (s *rebuildTable) Apply(db *gorm.DB) error {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(s.model); err != nil {
+		return err
+	}
+	table := stmt.Schema.Table
+	rebuilt := table + "__rebuild"
+
+	columnTypes, err := db.Migrator().ColumnTypes(table)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool, len(columnTypes))
+	for _, columnType := range columnTypes {
+		existing[columnType.Name()] = true
+	}
+	var columns []string
+	for _, name := range stmt.Schema.DBNames {
+		if existing[name] {
+			columns = append(columns, stmt.Quote(name))
+		}
+	}
+
+	// The statements name the constraints and indexes after the table, the
+	// new table only goes by another name until the old one is dropped.
+	statements, err := createTableStatements(db, s.model)
+	if err != nil {
+		return err
+	}
+	statements[0] = strings.Replace(statements[0], "CREATE TABLE "+stmt.Quote(table), "CREATE TABLE "+stmt.Quote(rebuilt), 1)
+
+	list := strings.Join(columns, ", ")
+	rebuild := []string{
+		statements[0],
+		fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s", stmt.Quote(rebuilt), list, list, stmt.Quote(table)),
+		"DROP TABLE " + stmt.Quote(table),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", stmt.Quote(rebuilt), stmt.Quote(table)),
+	}
+	for _, statement := range append(rebuild, statements[1:]...) {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *rebuildTable) String() string {
+	return "rebuild table " + describe(s.model)
+}
+
+// createTableStatements returns the statements which create the table of
+// model and its indexes, without running them.
+func createTableStatements(db *gorm.DB, model any) ([]string, error) {
+	recorder := &statementRecorder{Interface: db.Logger}
+	if err := db.Session(&gorm.Session{DryRun: true, Logger: recorder}).Migrator().CreateTable(model); err != nil {
+		return nil, err
+	}
+	if len(recorder.statements) == 0 {
+		return nil, fmt.Errorf("no statement creates %s", describe(model))
+	}
+	return recorder.statements, nil
+}
+
+type statementRecorder struct {
+	logger.Interface
+	statements []string
+}
+
+func (r *statementRecorder) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	statement, _ := fc()
+	r.statements = append(r.statements, statement)
+}
+
+type tabler interface {
+	TableName() string
+}
+
+// describe lists the table name and the fields of model with their tags.
+func describe(model any) string {
+	var b strings.Builder
+	if t, ok := model.(tabler); ok {
+		b.WriteString(t.TableName())
+	}
+	typ := reflect.TypeOf(model)
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	b.WriteString(" (")
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "%s %s %q", field.Name, field.Type, field.Tag)
+	}
+	b.WriteString(")")
+	return b.String()
+}
@@ -0,0 +1,44 @@
+package model
+
+type GetManyAnnotationsRequest struct {
+	paginationRequest
+	ViewerID int     `form:"-" uri:"-"`
+	Query    *string `form:"q" uri:"-"` // case insensitive | contains text or note
+	BookID   *int    `form:"book_id" uri:"id" binding:"omitempty,gt=0"`
+	UserID   *int    `form:"user_id" uri:"-" binding:"omitempty,gt=0"`
+}
+
+type GetAnnotationRequest struct {
+	ViewerID int `uri:"-"`
+	ID       int `uri:"id" binding:"required,gt=0"`
+}
+
+type CreateAnnotationRequest struct {
+	UserID     int    `json:"-" uri:"-"`
+	BookID     int    `json:"-" uri:"id" binding:"omitempty,gt=0"`
+	Page       int    `json:"page" uri:"-" binding:"omitempty,gte=0"`
+	Location   string `json:"location" uri:"-"`
+	Text       string `json:"text" uri:"-" binding:"required"`
+	Note       string `json:"note" uri:"-"`
+	Visibility string `json:"visibility" uri:"-" binding:"omitempty,oneof=private public"`
+}
+
+type UpdateAnnotationRequest struct {
+	UserID     int     `json:"-" uri:"-"`
+	ID         int     `json:"-" uri:"id" binding:"required,gt=0"`
+	Page       *int    `json:"page" uri:"-" binding:"omitempty,gte=0"`
+	Location   *string `json:"location" uri:"-"`
+	Text       *string `json:"text" uri:"-"`
+	Note       *string `json:"note" uri:"-"`
+	Visibility *string `json:"visibility" uri:"-" binding:"omitempty,oneof=private public"`
+}
+
+type DeleteAnnotationRequest struct {
+	UserID int `uri:"-"`
+	ID     int `uri:"id" binding:"required,gt=0"`
+}
+
+type ExportAnnotationsRequest struct {
+	UserID int  `form:"-"`
+	BookID *int `form:"book_id" binding:"omitempty,gt=0"`
+}
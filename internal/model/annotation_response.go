@@ -0,0 +1,47 @@
+package model
+
+import (
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+)
+
+type AnnotationResponse struct {
+	ID         int       `json:"id"`
+	UserID     int       `json:"user_id"`
+	Username   string    `json:"username"`
+	BookID     int       `json:"book_id"`
+	BookTitle  string    `json:"book_title"`
+	Page       int       `json:"page"`
+	Location   string    `json:"location"`
+	Text       string    `json:"text"`
+	Note       string    `json:"note"`
+	Visibility string    `json:"visibility"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func ToAnnotationResponse(annotation *entity.Annotation) *AnnotationResponse {
+	return &AnnotationResponse{
+		ID:         annotation.ID,
+		UserID:     annotation.UserID,
+		Username:   annotation.User.Username,
+		BookID:     annotation.BookID,
+		BookTitle:  annotation.Book.Title,
+		Page:       annotation.Page,
+		Location:   annotation.Location,
+		Text:       annotation.Text,
+		Note:       annotation.Note,
+		Visibility: annotation.Visibility,
+		CreatedAt:  annotation.CreatedAt,
+		UpdatedAt:  annotation.UpdatedAt,
+	}
+}
+
+func ToAnnotationsResponse(annotations []entity.Annotation) []AnnotationResponse {
+	response := make([]AnnotationResponse, len(annotations))
+	for i, annotation := range annotations {
+		response[i] = *ToAnnotationResponse(&annotation)
+	}
+	return response
+}
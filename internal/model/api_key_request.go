@@ -0,0 +1,15 @@
+package model
+
+type GetManyAPIKeysRequest struct {
+	UserID int `form:"-"`
+}
+
+type CreateAPIKeyRequest struct {
+	UserID int    `json:"-"`
+	Name   string `json:"name" binding:"required,max=100"`
+}
+
+type DeleteAPIKeyRequest struct {
+	UserID int `uri:"-"`
+	ID     int `uri:"id" binding:"required,gt=0"`
+}
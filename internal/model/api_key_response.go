@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+)
+
+type APIKeyResponse struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+func ToAPIKeyResponse(apiKey *entity.APIKey) *APIKeyResponse {
+	return &APIKeyResponse{
+		ID:         apiKey.ID,
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		CreatedAt:  apiKey.CreatedAt,
+		LastUsedAt: apiKey.LastUsedAt,
+	}
+}
+
+func ToAPIKeysResponse(apiKeys []entity.APIKey) []APIKeyResponse {
+	response := make([]APIKeyResponse, len(apiKeys))
+	for i, apiKey := range apiKeys {
+		response[i] = *ToAPIKeyResponse(&apiKey)
+	}
+	return response
+}
+
+// CreateAPIKeyResponse is the only response that includes the key itself.
+type CreateAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
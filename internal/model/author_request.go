@@ -22,8 +22,14 @@ type UpdateAuthorRequest struct {
 	ID        int        `json:"-" uri:"id" binding:"required,gt=0"`
 	Name      *string    `json:"name" uri:"-"`
 	Birthdate *time.Time `json:"birthdate" uri:"-"`
+	UpdatedBy *int       `json:"-" uri:"-"`
+	IfMatch   string     `json:"-" uri:"-"`
 }
 
 type DeleteAuthorRequest struct {
-	ID int `uri:"id" binding:"required,gt=0"`
+	ID         int    `uri:"id" form:"-" binding:"required,gt=0"`
+	Cascade    bool   `uri:"-" form:"cascade"`                              // delete the books of the author too
+	ReassignTo *int   `uri:"-" form:"reassign_to" binding:"omitempty,gt=0"` // move the books of the author to this author
+	DeletedBy  *int   `uri:"-" form:"-"`
+	IfMatch    string `uri:"-" form:"-"`
 }
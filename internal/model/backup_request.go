@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+// RestoreBackupRequest names the snapshot to restore. Without a name, the
+// latest snapshot taken at or before At is restored, or the latest one when
+// At is nil.
+type RestoreBackupRequest struct {
+	Name string     `json:"-"`
+	At   *time.Time `json:"-"`
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/backup"
+)
+
+type BackupResponse struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type RestoreBackupResponse struct {
+	Backup BackupResponse `json:"backup"`
+	// PreviousPath is where the replaced database was moved to.
+	PreviousPath string `json:"previous_path,omitempty"`
+}
+
+func ToBackupResponse(snapshot *backup.Snapshot) *BackupResponse {
+	return &BackupResponse{
+		Name:      snapshot.Name,
+		Size:      snapshot.Size,
+		CreatedAt: snapshot.CreatedAt,
+	}
+}
@@ -0,0 +1,27 @@
+package model
+
+type UploadBookFileRequest struct {
+	BookID   int    `uri:"id" form:"-" binding:"required,gt=0"`
+	Filename string `uri:"-" form:"-"`
+	Content  []byte `uri:"-" form:"-"`
+}
+
+// UploadBookRequest creates a book from an ebook file. The fields given in
+// the form take precedence over the metadata found in the file.
+type UploadBookRequest struct {
+	Title    string `form:"title"`
+	ISBN     string `form:"isbn"`
+	Author   string `form:"author"`
+	Language string `form:"language" binding:"omitempty,max=35"`
+	Filename string `form:"-"`
+	Content  []byte `form:"-"`
+}
+
+type GetManyBookFilesRequest struct {
+	BookID int `uri:"id" binding:"required,gt=0"`
+}
+
+type GetBookFileRequest struct {
+	BookID int `uri:"id" binding:"required,gt=0"`
+	ID     int `uri:"file_id" binding:"required,gt=0"`
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+)
+
+type BookFileResponse struct {
+	ID          int       `json:"id"`
+	BookID      int       `json:"book_id"`
+	Format      string    `json:"format"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Checksum    string    `json:"checksum"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func ToBookFileResponse(file *entity.BookFile) *BookFileResponse {
+	return &BookFileResponse{
+		ID:          file.ID,
+		BookID:      file.BookID,
+		Format:      file.Format,
+		Filename:    file.Filename,
+		ContentType: file.ContentType,
+		Size:        file.Size,
+		Checksum:    file.Checksum,
+		CreatedAt:   file.CreatedAt,
+	}
+}
+
+func ToBookFilesResponse(files []entity.BookFile) []BookFileResponse {
+	response := make([]BookFileResponse, len(files))
+	for i, file := range files {
+		response[i] = *ToBookFileResponse(&file)
+	}
+	return response
+}
+
+type UploadBookFileResponse struct {
+	Book     BookResponse          `json:"book"`
+	File     BookFileResponse      `json:"file"`
+	Metadata EbookMetadataResponse `json:"metadata"`
+}
+
+// EbookMetadataResponse is the metadata found in the uploaded file, which may
+// differ from the book when it was already in the catalog.
+type EbookMetadataResponse struct {
+	Title    string   `json:"title"`
+	Authors  []string `json:"authors"`
+	ISBN     string   `json:"isbn"`
+	Language string   `json:"language"`
+	HasCover bool     `json:"has_cover"`
+}
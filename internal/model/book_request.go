@@ -6,6 +6,7 @@ type GetManyBooksRequest struct {
 	ISBN       *string `form:"isbn"`  // case insensitive | contains
 	AuthorID   *int    `form:"author_id" binding:"omitempty,gt=0"`
 	AuthorName *string `form:"author_name"` // case insensitive | contains
+	Sort       *string `form:"sort" binding:"omitempty,oneof=id -id title -title rating -rating"`
 }
 
 type GetBookRequest struct {
@@ -13,18 +14,30 @@ type GetBookRequest struct {
 }
 
 type CreateBookRequest struct {
-	Title    string `json:"title" binding:"required"`
-	ISBN     string `json:"isbn" binding:"required"`
-	AuthorID int    `json:"author_id" binding:"required,gt=0"`
+	Title       string  `json:"title" binding:"required"`
+	ISBN        string  `json:"isbn" binding:"required"`
+	AuthorID    int     `json:"author_id" binding:"required,gt=0"`
+	PageCount   int     `json:"page_count" binding:"omitempty,gte=0"`
+	Language    string  `json:"language" binding:"omitempty,max=35"`
+	Series      string  `json:"series" binding:"omitempty,max=255"`
+	SeriesIndex float64 `json:"series_index" binding:"omitempty,gte=0"`
 }
 
 type UpdateBookRequest struct {
-	ID       int     `json:"-" uri:"id" binding:"required,gt=0"`
-	Title    *string `json:"title" uri:"-"`
-	ISBN     *string `json:"isbn" uri:"-"`
-	AuthorID *int    `json:"author_id" binding:"omitempty,gt=0"`
+	ID          int      `json:"-" uri:"id" binding:"required,gt=0"`
+	Title       *string  `json:"title" uri:"-"`
+	ISBN        *string  `json:"isbn" uri:"-"`
+	AuthorID    *int     `json:"author_id" binding:"omitempty,gt=0"`
+	PageCount   *int     `json:"page_count" binding:"omitempty,gte=0"`
+	Language    *string  `json:"language" binding:"omitempty,max=35"`
+	Series      *string  `json:"series" binding:"omitempty,max=255"`
+	SeriesIndex *float64 `json:"series_index" binding:"omitempty,gte=0"`
+	UpdatedBy   *int     `json:"-" uri:"-"`
+	IfMatch     string   `json:"-" uri:"-"`
 }
 
 type DeleteBookRequest struct {
-	ID int `uri:"id" binding:"required,gt=0"`
+	ID        int    `uri:"id" binding:"required,gt=0"`
+	DeletedBy *int   `uri:"-"`
+	IfMatch   string `uri:"-"`
 }
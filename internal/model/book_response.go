@@ -1,22 +1,48 @@
 package model
 
-import "github.com/mnaufalhilmym/bookshelf/internal/entity"
+import (
+	"fmt"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+)
 
 type BookResponse struct {
-	ID         int    `json:"id"`
-	Title      string `json:"title"`
-	ISBN       string `json:"isbn"`
-	AuthorID   int    `json:"author_id"`
-	AuthorName string `json:"author_name"`
+	ID            int            `json:"id"`
+	Title         string         `json:"title"`
+	ISBN          string         `json:"isbn"`
+	AuthorID      int            `json:"author_id"`
+	AuthorName    string         `json:"author_name"`
+	PageCount     int            `json:"page_count"`
+	AverageRating float64        `json:"average_rating"`
+	RatingCount   int64          `json:"rating_count"`
+	Language      string         `json:"language"`
+	Series        string         `json:"series"`
+	SeriesIndex   float64        `json:"series_index"`
+	Cover         *CoverResponse `json:"cover,omitempty"`
+	Version       int            `json:"version"`
+}
+
+// versionTag identifies the book as edited, which ratings and author names
+// do not change.
+func (r BookResponse) versionTag() string {
+	return fmt.Sprintf("%d-%d-", r.ID, r.Version)
 }
 
 func ToBookResponse(book *entity.Book) *BookResponse {
 	return &BookResponse{
-		ID:         book.ID,
-		Title:      book.Title,
-		ISBN:       book.ISBN,
-		AuthorID:   book.AuthorID,
-		AuthorName: book.Author.Name,
+		ID:            book.ID,
+		Title:         book.Title,
+		ISBN:          book.ISBN,
+		AuthorID:      book.AuthorID,
+		AuthorName:    book.Author.Name,
+		PageCount:     book.PageCount,
+		AverageRating: book.RatingAverage,
+		RatingCount:   book.RatingCount,
+		Language:      book.Language,
+		Series:        book.Series,
+		SeriesIndex:   book.SeriesIndex,
+		Cover:         ToCoverResponse(book),
+		Version:       book.Version,
 	}
 }
 
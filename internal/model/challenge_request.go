@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+type GetManyChallengesRequest struct {
+	paginationRequest
+	Active *bool `form:"active"`
+}
+
+type GetChallengeRequest struct {
+	ID int `uri:"id" binding:"required,gt=0"`
+}
+
+type CreateChallengeRequest struct {
+	Name             string     `json:"name" binding:"required"`
+	Description      string     `json:"description"`
+	StartAt          time.Time  `json:"start_at" binding:"required"`
+	EndAt            time.Time  `json:"end_at" binding:"required,gtfield=StartAt"`
+	TargetBooks      int        `json:"target_books" binding:"required,gt=0"`
+	AuthorBornBefore *time.Time `json:"author_born_before"`
+	AuthorBornAfter  *time.Time `json:"author_born_after"`
+}
+
+type UpdateChallengeRequest struct {
+	ID               int        `json:"-" uri:"id" binding:"required,gt=0"`
+	Name             *string    `json:"name" uri:"-"`
+	Description      *string    `json:"description" uri:"-"`
+	StartAt          *time.Time `json:"start_at" uri:"-"`
+	EndAt            *time.Time `json:"end_at" uri:"-"`
+	TargetBooks      *int       `json:"target_books" uri:"-" binding:"omitempty,gt=0"`
+	AuthorBornBefore *time.Time `json:"author_born_before" uri:"-"`
+	AuthorBornAfter  *time.Time `json:"author_born_after" uri:"-"`
+}
+
+type DeleteChallengeRequest struct {
+	ID int `uri:"id" binding:"required,gt=0"`
+}
+
+type JoinChallengeRequest struct {
+	UserID int `uri:"-"`
+	ID     int `uri:"id" binding:"required,gt=0"`
+}
+
+type GetChallengeLeaderboardRequest struct {
+	ID int `uri:"id" binding:"required,gt=0"`
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+)
+
+type ChallengeResponse struct {
+	ID               int        `json:"id"`
+	Name             string     `json:"name"`
+	Description      string     `json:"description"`
+	StartAt          time.Time  `json:"start_at"`
+	EndAt            time.Time  `json:"end_at"`
+	TargetBooks      int        `json:"target_books"`
+	AuthorBornBefore *time.Time `json:"author_born_before"`
+	AuthorBornAfter  *time.Time `json:"author_born_after"`
+}
+
+func ToChallengeResponse(challenge *entity.Challenge) *ChallengeResponse {
+	return &ChallengeResponse{
+		ID:               challenge.ID,
+		Name:             challenge.Name,
+		Description:      challenge.Description,
+		StartAt:          challenge.StartAt,
+		EndAt:            challenge.EndAt,
+		TargetBooks:      challenge.TargetBooks,
+		AuthorBornBefore: challenge.AuthorBornBefore,
+		AuthorBornAfter:  challenge.AuthorBornAfter,
+	}
+}
+
+func ToChallengesResponse(challenges []entity.Challenge) []ChallengeResponse {
+	response := make([]ChallengeResponse, len(challenges))
+	for i, challenge := range challenges {
+		response[i] = *ToChallengeResponse(&challenge)
+	}
+	return response
+}
+
+type ChallengeParticipantResponse struct {
+	ChallengeID int       `json:"challenge_id"`
+	UserID      int       `json:"user_id"`
+	JoinedAt    time.Time `json:"joined_at"`
+}
+
+func ToChallengeParticipantResponse(participant *entity.ChallengeParticipant) *ChallengeParticipantResponse {
+	return &ChallengeParticipantResponse{
+		ChallengeID: participant.ChallengeID,
+		UserID:      participant.UserID,
+		JoinedAt:    participant.JoinedAt,
+	}
+}
+
+type ChallengeLeaderboardEntryResponse struct {
+	Rank          int    `json:"rank"`
+	UserID        int    `json:"user_id"`
+	Username      string `json:"username"`
+	BooksFinished int    `json:"books_finished"`
+	Completed     bool   `json:"completed"`
+}
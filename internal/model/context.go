@@ -0,0 +1,6 @@
+package model
+
+const (
+	JWTClaimsContextKey = "jwt_claims"
+	UserContextKey      = "user"
+)
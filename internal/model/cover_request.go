@@ -0,0 +1,24 @@
+package model
+
+const (
+	CoverSizeSmall  = "small"
+	CoverSizeMedium = "medium"
+	CoverSizeLarge  = "large"
+)
+
+type UploadBookCoverRequest struct {
+	BookID  int    `uri:"id" binding:"required,gt=0"`
+	Content []byte `uri:"-"`
+}
+
+type DeleteBookCoverRequest struct {
+	BookID int `uri:"id" binding:"required,gt=0"`
+}
+
+// GetBookCoverRequest returns the original cover when Size is empty. Version
+// is the "v" parameter of the cover URLs in BookResponse.
+type GetBookCoverRequest struct {
+	BookID  int    `uri:"id" form:"-" binding:"required,gt=0"`
+	Size    string `uri:"size" form:"-" binding:"omitempty,oneof=small medium large"`
+	Version string `uri:"-" form:"v"`
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"fmt"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+)
+
+// CoverResponse holds the URLs of the cover and its thumbnails. The URLs
+// carry the cover version, so they change whenever the cover does and can be
+// cached indefinitely.
+type CoverResponse struct {
+	Original string `json:"original"`
+	Small    string `json:"small"`
+	Medium   string `json:"medium"`
+	Large    string `json:"large"`
+}
+
+func ToCoverResponse(book *entity.Book) *CoverResponse {
+	if book.CoverKey == "" {
+		return nil
+	}
+
+	query := ""
+	if version := CoverVersion(book); version != "" {
+		query = "?v=" + version
+	}
+
+	return &CoverResponse{
+		Original: fmt.Sprintf("/books/%d/cover%s", book.ID, query),
+		Small:    fmt.Sprintf("/books/%d/cover/%s%s", book.ID, CoverSizeSmall, query),
+		Medium:   fmt.Sprintf("/books/%d/cover/%s%s", book.ID, CoverSizeMedium, query),
+		Large:    fmt.Sprintf("/books/%d/cover/%s%s", book.ID, CoverSizeLarge, query),
+	}
+}
+
+// CoverVersion identifies the current cover of the book, or is empty when
+// the book has none.
+func CoverVersion(book *entity.Book) string {
+	if len(book.CoverChecksum) > 16 {
+		return book.CoverChecksum[:16]
+	}
+	return book.CoverChecksum
+}
+
+// CoverImageResponse describes the cover image being served.
+type CoverImageResponse struct {
+	ContentType string
+	ETag        string
+	// Immutable is set when the request named the current cover version.
+	Immutable bool
+}
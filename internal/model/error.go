@@ -8,6 +8,8 @@ import (
 type Error struct {
 	Code int
 	Err  error
+	// Data is sent along with the error, to tell what caused it.
+	Data any
 }
 
 func (e Error) Error() string {
@@ -28,6 +30,13 @@ func ErrorUnauthorized(err error) error {
 	}
 }
 
+func ErrorForbidden(err error) error {
+	return &Error{
+		Code: http.StatusForbidden,
+		Err:  err,
+	}
+}
+
 func ErrorNotFound(err error) error {
 	return &Error{
 		Code: http.StatusNotFound,
@@ -35,6 +44,49 @@ func ErrorNotFound(err error) error {
 	}
 }
 
+func ErrorConflict(err error) error {
+	return &Error{
+		Code: http.StatusConflict,
+		Err:  err,
+	}
+}
+
+func ErrorConflictWithData(err error, data any) error {
+	return &Error{
+		Code: http.StatusConflict,
+		Err:  err,
+		Data: data,
+	}
+}
+
+func ErrorPreconditionFailed(err error) error {
+	return &Error{
+		Code: http.StatusPreconditionFailed,
+		Err:  err,
+	}
+}
+
+func ErrorPreconditionRequired(err error) error {
+	return &Error{
+		Code: http.StatusPreconditionRequired,
+		Err:  err,
+	}
+}
+
+func ErrorRequestEntityTooLarge(err error) error {
+	return &Error{
+		Code: http.StatusRequestEntityTooLarge,
+		Err:  err,
+	}
+}
+
+func ErrorUnsupportedMediaType(err error) error {
+	return &Error{
+		Code: http.StatusUnsupportedMediaType,
+		Err:  err,
+	}
+}
+
 func ErrorInternalServerError(err error) error {
 	return &Error{
 		Code: http.StatusInternalServerError,
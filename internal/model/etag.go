@@ -0,0 +1,51 @@
+package model
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/util"
+)
+
+// versioned is data with fields derived from other records, such as the
+// ratings of a book. Its entity tag starts with the version tag, so that
+// If-Match compares the version alone and a new rating does not make the
+// tag a client holds stale.
+type versioned interface {
+	versionTag() string
+}
+
+// ETag is the strong entity tag of data, its data_hash in quotes, preceded by
+// the version tag of versioned data.
+func ETag(data any) string {
+	return etag(data, dataHash(data))
+}
+
+func etag(data any, hash string) string {
+	if v, ok := data.(versioned); ok {
+		return `"` + v.versionTag() + hash + `"`
+	}
+	return `"` + hash + `"`
+}
+
+func dataHash(data any) string {
+	dataJSON, _ := json.Marshal(data)
+	return util.CalculateHash(string(dataJSON))
+}
+
+// MatchETag reports whether the If-Match header ifMatch matches the entity
+// tag of data, or of versioned data at the same version. Weak tags never
+// match, an update needs the exact data.
+func MatchETag(ifMatch string, data any) bool {
+	etag := ETag(data)
+	for _, tag := range strings.Split(ifMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || tag == etag {
+			return true
+		}
+		if v, ok := data.(versioned); ok && strings.HasPrefix(tag, `"`+v.versionTag()) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+package model
+
+const (
+	ExportFormatCSV     = "csv"
+	ExportFormatNDJSON  = "ndjson"
+	ExportFormatBibTeX  = "bibtex"
+	ExportFormatMARC    = "marc"
+	ExportFormatMARCXML = "marcxml"
+)
+
+type ExportBooksRequest struct {
+	Format     string  `form:"format" binding:"required,oneof=csv ndjson bibtex marc marcxml"`
+	Title      *string `form:"title"` // case insensitive | contains
+	ISBN       *string `form:"isbn"`  // case insensitive | contains
+	AuthorID   *int    `form:"author_id" binding:"omitempty,gt=0"`
+	AuthorName *string `form:"author_name"` // case insensitive | contains
+	Sort       *string `form:"sort" binding:"omitempty,oneof=id -id title -title rating -rating"`
+}
+
+type ExportBookRequest struct {
+	ID     int    `uri:"id" binding:"required,gt=0"`
+	Format string `uri:"-"`
+}
@@ -0,0 +1,25 @@
+package model
+
+import "github.com/mnaufalhilmym/bookshelf/internal/entity"
+
+type ExportBookResponse struct {
+	ID            int            `json:"id"`
+	Title         string         `json:"title"`
+	ISBN          string         `json:"isbn"`
+	PageCount     int            `json:"page_count"`
+	AverageRating float64        `json:"average_rating"`
+	RatingCount   int64          `json:"rating_count"`
+	Author        AuthorResponse `json:"author"`
+}
+
+func ToExportBookResponse(book *entity.Book) *ExportBookResponse {
+	return &ExportBookResponse{
+		ID:            book.ID,
+		Title:         book.Title,
+		ISBN:          book.ISBN,
+		PageCount:     book.PageCount,
+		AverageRating: book.RatingAverage,
+		RatingCount:   book.RatingCount,
+		Author:        *ToAuthorResponse(&book.Author),
+	}
+}
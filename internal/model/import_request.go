@@ -0,0 +1,31 @@
+package model
+
+type ImportKindleRequest struct {
+	UserID        int    `form:"-"`
+	DryRun        bool   `form:"dry_run"`
+	CreateMissing bool   `form:"create_missing"`
+	Content       []byte `form:"-"`
+}
+
+type ImportBooksRequest struct {
+	DryRun  bool   `form:"dry_run"`
+	Mode    string `form:"mode" binding:"omitempty,oneof=transaction per_row"`
+	Content []byte `form:"-"`
+}
+
+type ImportCalibreRequest struct {
+	DryRun    bool   `form:"dry_run"`
+	Mode      string `form:"mode" binding:"omitempty,oneof=transaction per_row"`
+	Overwrite bool   `form:"overwrite"`
+	Path      string `form:"-"`
+}
+
+type ImportReadingHistoryRequest struct {
+	UserID  int    `form:"-"`
+	Content []byte `form:"-"`
+}
+
+type GetImportJobRequest struct {
+	UserID int `uri:"-"`
+	ID     int `uri:"id" binding:"required,gt=0"`
+}
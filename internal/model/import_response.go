@@ -0,0 +1,147 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+)
+
+const (
+	ImportStatusImported  = "imported"
+	ImportStatusAttached  = "attached"
+	ImportStatusDuplicate = "duplicate"
+	ImportStatusUnmatched = "unmatched"
+	ImportStatusSkipped   = "skipped"
+	ImportStatusCreated   = "created"
+	ImportStatusUpdated   = "updated"
+	ImportStatusFailed    = "failed"
+	ImportStatusConflict  = "conflict"
+)
+
+const (
+	ImportSourceGoodreads  = "goodreads"
+	ImportSourceStoryGraph = "storygraph"
+)
+
+const (
+	ImportModeTransaction = "transaction"
+	ImportModePerRow      = "per_row"
+)
+
+type ImportKindleResponse struct {
+	DryRun         bool                           `json:"dry_run"`
+	Total          int                            `json:"total"`
+	Imported       int                            `json:"imported"`
+	Attached       int                            `json:"attached"`
+	Duplicates     int                            `json:"duplicates"`
+	Unmatched      int                            `json:"unmatched"`
+	Skipped        int                            `json:"skipped"`
+	CreatedBooks   int                            `json:"created_books"`
+	CreatedAuthors int                            `json:"created_authors"`
+	Items          []ImportKindleClippingResponse `json:"items"`
+}
+
+type ImportKindleClippingResponse struct {
+	Index        int    `json:"index"`
+	Title        string `json:"title"`
+	Author       string `json:"author"`
+	Kind         string `json:"kind"`
+	Page         int    `json:"page,omitempty"`
+	Location     string `json:"location,omitempty"`
+	Status       string `json:"status"`
+	Reason       string `json:"reason,omitempty"`
+	BookID       *int   `json:"book_id,omitempty"`
+	AnnotationID *int   `json:"annotation_id,omitempty"`
+}
+
+type ImportBooksResponse struct {
+	DryRun         bool                    `json:"dry_run"`
+	Mode           string                  `json:"mode"`
+	Committed      bool                    `json:"committed"`
+	Total          int                     `json:"total"`
+	Created        int                     `json:"created"`
+	Updated        int                     `json:"updated"`
+	Skipped        int                     `json:"skipped"`
+	Failed         int                     `json:"failed"`
+	Conflicts      int                     `json:"conflicts"`
+	CreatedAuthors int                     `json:"created_authors"`
+	Rows           []ImportBookRowResponse `json:"rows"`
+}
+
+type ImportBookRowResponse struct {
+	Line     int    `json:"line,omitempty"`
+	Record   int    `json:"record,omitempty"`
+	Title    string `json:"title"`
+	ISBN     string `json:"isbn"`
+	Author   string `json:"author"`
+	Status   string `json:"status"`
+	Reason   string `json:"reason,omitempty"`
+	BookID   *int   `json:"book_id,omitempty"`
+	AuthorID *int   `json:"author_id,omitempty"`
+
+	Conflicts []ImportConflictResponse `json:"conflicts,omitempty"`
+	Notes     []string                 `json:"notes,omitempty"`
+}
+
+type ImportConflictResponse struct {
+	Field    string `json:"field"`
+	Current  string `json:"current"`
+	Incoming string `json:"incoming"`
+}
+
+type ImportJobResponse struct {
+	ID         int                           `json:"id"`
+	Source     string                        `json:"source"`
+	Status     string                        `json:"status"`
+	Total      int                           `json:"total"`
+	Processed  int                           `json:"processed"`
+	Error      string                        `json:"error,omitempty"`
+	Result     *ImportReadingHistoryResponse `json:"result,omitempty"`
+	CreatedAt  time.Time                     `json:"created_at"`
+	UpdatedAt  time.Time                     `json:"updated_at"`
+	FinishedAt *time.Time                    `json:"finished_at,omitempty"`
+}
+
+func ToImportJobResponse(job *entity.ImportJob) *ImportJobResponse {
+	response := &ImportJobResponse{
+		ID:         job.ID,
+		Source:     job.Source,
+		Status:     job.Status,
+		Total:      job.Total,
+		Processed:  job.Processed,
+		Error:      job.Error,
+		CreatedAt:  job.CreatedAt,
+		UpdatedAt:  job.UpdatedAt,
+		FinishedAt: job.FinishedAt,
+	}
+	if job.Result != "" {
+		result := new(ImportReadingHistoryResponse)
+		if err := json.Unmarshal([]byte(job.Result), result); err == nil {
+			response.Result = result
+		}
+	}
+	return response
+}
+
+type ImportReadingHistoryResponse struct {
+	Total          int                               `json:"total"`
+	Imported       int                               `json:"imported"`
+	Failed         int                               `json:"failed"`
+	CreatedBooks   int                               `json:"created_books"`
+	CreatedAuthors int                               `json:"created_authors"`
+	Rows           []ImportReadingHistoryRowResponse `json:"rows"`
+}
+
+type ImportReadingHistoryRowResponse struct {
+	Line      int      `json:"line"`
+	Title     string   `json:"title"`
+	ISBN      string   `json:"isbn,omitempty"`
+	Author    string   `json:"author"`
+	Status    string   `json:"status"`
+	Reason    string   `json:"reason,omitempty"`
+	BookID    *int     `json:"book_id,omitempty"`
+	Shelves   []string `json:"shelves,omitempty"`
+	Rating    int      `json:"rating,omitempty"`
+	ReadDates int      `json:"read_dates,omitempty"`
+}
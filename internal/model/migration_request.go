@@ -0,0 +1,10 @@
+package model
+
+type MigrateUpRequest struct {
+	// Target is the version to migrate to, 0 for the latest.
+	Target int
+}
+
+type MigrateDownRequest struct {
+	Steps int
+}
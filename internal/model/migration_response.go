@@ -0,0 +1,54 @@
+package model
+
+import (
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+	"github.com/mnaufalhilmym/bookshelf/internal/migration"
+)
+
+const (
+	MigrationStatusApplied  = "applied"
+	MigrationStatusPending  = "pending"
+	MigrationStatusModified = "modified"
+	MigrationStatusUnknown  = "unknown"
+)
+
+type MigrationResponse struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Checksum  string     `json:"checksum"`
+	Status    string     `json:"status"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// ToMigrationResponse describes a migration of this build, applied is nil
+// when it is pending.
+func ToMigrationResponse(m *migration.Migration, applied *entity.SchemaMigration) MigrationResponse {
+	response := MigrationResponse{
+		Version:  m.Version,
+		Name:     m.Name,
+		Checksum: m.Checksum(),
+		Status:   MigrationStatusPending,
+	}
+	if applied != nil {
+		response.Status = MigrationStatusApplied
+		if applied.Checksum != response.Checksum {
+			response.Status = MigrationStatusModified
+		}
+		response.AppliedAt = &applied.AppliedAt
+	}
+	return response
+}
+
+// ToUnknownMigrationResponse describes an applied migration this build does
+// not have, which a newer build applied.
+func ToUnknownMigrationResponse(applied *entity.SchemaMigration) MigrationResponse {
+	return MigrationResponse{
+		Version:   applied.Version,
+		Name:      applied.Name,
+		Checksum:  applied.Checksum,
+		Status:    MigrationStatusUnknown,
+		AppliedAt: &applied.AppliedAt,
+	}
+}
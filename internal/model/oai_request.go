@@ -0,0 +1,10 @@
+package model
+
+import "net/url"
+
+// OAIRequest holds the raw arguments of an OAI-PMH request, since the
+// protocol reports repeated and unknown arguments as errors.
+type OAIRequest struct {
+	BaseURL   string
+	Arguments url.Values
+}
@@ -0,0 +1,8 @@
+package model
+
+// OAIResponse is an OAI-PMH response document. Protocol errors are part of
+// the document, so it is sent with status 200.
+type OAIResponse struct {
+	ContentType string
+	Content     []byte
+}
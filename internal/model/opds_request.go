@@ -0,0 +1,36 @@
+package model
+
+const (
+	OPDSVersion1 = "1.2"
+	OPDSVersion2 = "2.0"
+)
+
+type OPDSFeedRequest struct {
+	Version string `form:"-"`
+	Page    int    `form:"page" binding:"omitempty,gt=0"`
+}
+
+type OPDSAuthorFeedRequest struct {
+	Version  string `uri:"-" form:"-"`
+	AuthorID int    `uri:"id" form:"-" binding:"required,gt=0"`
+	Page     int    `uri:"-" form:"page" binding:"omitempty,gt=0"`
+}
+
+// OPDSSeriesFeedRequest takes the series from the query, since series names
+// may contain slashes.
+type OPDSSeriesFeedRequest struct {
+	Version string `form:"-"`
+	Series  string `form:"name" binding:"required"`
+}
+
+type OPDSSearchFeedRequest struct {
+	Version string `form:"-"`
+	Query   string `form:"q" binding:"required"`
+	Page    int    `form:"page" binding:"omitempty,gt=0"`
+}
+
+// OPDSOpenSearchRequest needs the URL the server is reached at, since the
+// OpenSearch template must be absolute.
+type OPDSOpenSearchRequest struct {
+	BaseURL string
+}
@@ -0,0 +1,7 @@
+package model
+
+// OPDSResponse is a feed or document encoded for an e-reader app.
+type OPDSResponse struct {
+	ContentType string
+	Content     []byte
+}
@@ -0,0 +1,22 @@
+package model
+
+type GetManyReadingGoalsRequest struct {
+	UserID int `uri:"-"`
+}
+
+type GetReadingGoalRequest struct {
+	UserID int `uri:"-"`
+	Year   int `uri:"year" binding:"required,gt=0"`
+}
+
+type UpsertReadingGoalRequest struct {
+	UserID int    `json:"-" uri:"-"`
+	Year   int    `json:"-" uri:"year" binding:"omitempty,gt=0"`
+	Type   string `json:"type" uri:"-" binding:"required,oneof=books pages"`
+	Target int    `json:"target" uri:"-" binding:"required,gt=0"`
+}
+
+type DeleteReadingGoalRequest struct {
+	UserID int `uri:"-"`
+	Year   int `uri:"year" binding:"required,gt=0"`
+}
@@ -0,0 +1,12 @@
+package model
+
+type ReadingGoalResponse struct {
+	Year       int    `json:"year"`
+	Type       string `json:"type"`
+	Target     int    `json:"target"`
+	Progress   int    `json:"progress"`
+	Expected   int    `json:"expected"`
+	Difference int    `json:"difference"`
+	Pace       string `json:"pace"`
+	Completed  bool   `json:"completed"`
+}
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+type GetManyReadingProgressesRequest struct {
+	paginationRequest
+	UserID int `uri:"-" form:"-"`
+	BookID int `uri:"id" binding:"required,gt=0"`
+}
+
+type CreateReadingProgressRequest struct {
+	UserID     int        `json:"-" uri:"-"`
+	BookID     int        `json:"-" uri:"id" binding:"omitempty,gt=0"`
+	Page       *int       `json:"page" uri:"-" binding:"required_without=Percentage,omitempty,gte=0"`
+	Percentage *float64   `json:"percentage" uri:"-" binding:"required_without=Page,omitempty,gte=0,lte=100"`
+	ReadAt     *time.Time `json:"read_at" uri:"-"`
+}
+
+type GetReadingStatsRequest struct {
+	UserID int `form:"-"`
+}
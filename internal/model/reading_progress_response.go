@@ -0,0 +1,56 @@
+package model
+
+import (
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+)
+
+type ReadingProgressResponse struct {
+	ID         int       `json:"id"`
+	BookID     int       `json:"book_id"`
+	Page       int       `json:"page"`
+	Percentage float64   `json:"percentage"`
+	ReadAt     time.Time `json:"read_at"`
+}
+
+func ToReadingProgressResponse(progress *entity.ReadingProgress) *ReadingProgressResponse {
+	return &ReadingProgressResponse{
+		ID:         progress.ID,
+		BookID:     progress.BookID,
+		Page:       progress.Page,
+		Percentage: progress.Percentage,
+		ReadAt:     progress.ReadAt,
+	}
+}
+
+func ToReadingProgressesResponse(progresses []entity.ReadingProgress) []ReadingProgressResponse {
+	response := make([]ReadingProgressResponse, len(progresses))
+	for i, progress := range progresses {
+		response[i] = *ToReadingProgressResponse(&progress)
+	}
+	return response
+}
+
+type ReadingStatsResponse struct {
+	BooksFinished        int64                    `json:"books_finished"`
+	BooksFinishedByMonth []PeriodCountResponse    `json:"books_finished_by_month"`
+	BooksFinishedByYear  []PeriodCountResponse    `json:"books_finished_by_year"`
+	PagesRead            int64                    `json:"pages_read"`
+	AveragePagesPerDay   float64                  `json:"average_pages_per_day"`
+	FavoriteAuthors      []FavoriteAuthorResponse `json:"favorite_authors"`
+	CurrentStreakDays    int                      `json:"current_streak_days"`
+	LongestStreakDays    int                      `json:"longest_streak_days"`
+}
+
+type PeriodCountResponse struct {
+	Period string `json:"period"`
+	Count  int64  `json:"count"`
+}
+
+type FavoriteAuthorResponse struct {
+	AuthorID      int    `json:"author_id"`
+	AuthorName    string `json:"author_name"`
+	BooksFinished int64  `json:"books_finished"`
+	PagesRead     int64  `json:"pages_read"`
+}
@@ -0,0 +1,116 @@
+package model
+
+import (
+	"fmt"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/format/dublincore"
+	"github.com/mnaufalhilmym/bookshelf/internal/format/schemaorg"
+	"github.com/mnaufalhilmym/bookshelf/internal/util"
+)
+
+// Representations of a book or an author besides the JSON envelope, which
+// clients ask for in the Accept header.
+const (
+	RepresentationJSONLD     = "jsonld"
+	RepresentationDublinCore = "dublincore"
+)
+
+// ToBookJSONLD describes the book as a schema.org Book. Links are absolute
+// below baseURL.
+func ToBookJSONLD(book *BookResponse, baseURL string) *schemaorg.Book {
+	url := fmt.Sprintf("%s/books/%d", baseURL, book.ID)
+	document := &schemaorg.Book{
+		Context:       schemaorg.Context,
+		Type:          schemaorg.TypeBook,
+		ID:            url,
+		URL:           url,
+		Name:          book.Title,
+		NumberOfPages: book.PageCount,
+		InLanguage:    book.Language,
+		Author: &schemaorg.Person{
+			Type: schemaorg.TypePerson,
+			ID:   fmt.Sprintf("%s/authors/%d", baseURL, book.AuthorID),
+			Name: book.AuthorName,
+		},
+	}
+
+	// Books created by imports carry a placeholder instead of an ISBN.
+	if util.IsISBN(book.ISBN) {
+		document.ISBN = util.CompactISBN(book.ISBN)
+	}
+
+	if book.RatingCount > 0 {
+		document.AggregateRating = &schemaorg.AggregateRating{
+			Type:        schemaorg.TypeAggregateRating,
+			RatingValue: book.AverageRating,
+			RatingCount: book.RatingCount,
+			BestRating:  5,
+			WorstRating: 1,
+		}
+	}
+
+	if book.Cover != nil {
+		document.Image = baseURL + book.Cover.Original
+		document.ThumbnailURL = baseURL + book.Cover.Medium
+	}
+
+	if book.Series != "" {
+		document.IsPartOf = &schemaorg.BookSeries{Type: schemaorg.TypeBookSeries, Name: book.Series}
+		document.Position = book.SeriesIndex
+	}
+
+	return document
+}
+
+// ToAuthorJSONLD describes the author as a schema.org Person.
+func ToAuthorJSONLD(author *AuthorResponse, baseURL string) *schemaorg.Person {
+	url := fmt.Sprintf("%s/authors/%d", baseURL, author.ID)
+	document := &schemaorg.Person{
+		Context: schemaorg.Context,
+		Type:    schemaorg.TypePerson,
+		ID:      url,
+		URL:     url,
+		Name:    author.Name,
+	}
+	if !author.Birthdate.IsZero() {
+		document.BirthDate = author.Birthdate.Format("2006-01-02")
+	}
+	return document
+}
+
+// ToBookDublinCore describes the book in Dublin Core. The URL of the book is
+// left out of the identifiers when baseURL is empty.
+func ToBookDublinCore(book *BookResponse, baseURL string) *dublincore.Record {
+	record := &dublincore.Record{
+		Titles:    []string{book.Title},
+		Creators:  []string{book.AuthorName},
+		Types:     []string{dublincore.TypeText},
+		Languages: []string{book.Language},
+	}
+
+	if baseURL != "" {
+		record.Identifiers = append(record.Identifiers, fmt.Sprintf("%s/books/%d", baseURL, book.ID))
+	}
+	if util.IsISBN(book.ISBN) {
+		record.Identifiers = append(record.Identifiers, "urn:isbn:"+util.CompactISBN(book.ISBN))
+	}
+
+	if book.Series != "" {
+		record.Relations = append(record.Relations, book.Series)
+	}
+
+	return record
+}
+
+// ToAuthorDublinCore describes the author in Dublin Core, with the name as
+// title and the birthdate as date.
+func ToAuthorDublinCore(author *AuthorResponse, baseURL string) *dublincore.Record {
+	record := &dublincore.Record{
+		Titles:      []string{author.Name},
+		Identifiers: []string{fmt.Sprintf("%s/authors/%d", baseURL, author.ID)},
+	}
+	if !author.Birthdate.IsZero() {
+		record.Dates = append(record.Dates, author.Birthdate.Format("2006-01-02"))
+	}
+	return record
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"math"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/mnaufalhilmym/bookshelf/internal/util"
@@ -24,32 +26,52 @@ type pagination struct {
 }
 
 func ResponseCreated[T any](ctx *gin.Context, data T) {
-	dataJSON, _ := json.Marshal(data)
+	dataHash := dataHash(data)
+	ctx.Header("ETag", etag(data, dataHash))
 	ctx.JSON(http.StatusCreated, Response[T]{
 		Data:     data,
-		DataHash: util.CalculateHash(string(dataJSON)),
+		DataHash: dataHash,
+	})
+}
+
+func ResponseAccepted[T any](ctx *gin.Context, data T) {
+	dataHash := dataHash(data)
+	ctx.Header("ETag", etag(data, dataHash))
+	ctx.JSON(http.StatusAccepted, Response[T]{
+		Data:     data,
+		DataHash: dataHash,
 	})
 }
 
 func ResponseOK[T any](ctx *gin.Context, data T) {
-	dataJSON, _ := json.Marshal(data)
+	dataHash := dataHash(data)
+	if notModified(ctx, etag(data, dataHash)) {
+		return
+	}
 	ctx.JSON(http.StatusOK, Response[T]{
 		Data:     data,
-		DataHash: util.CalculateHash(string(dataJSON)),
+		DataHash: dataHash,
 	})
 }
 
+// ResponseOKPaginated tags the page with the hash of its data and of the
+// pagination, which changes with the items on the other pages.
 func ResponseOKPaginated[T any](ctx *gin.Context, data []T, totalItem int64, page int, size int) {
-	dataJSON, _ := json.Marshal(data)
+	pagination := &pagination{
+		Page:      page,
+		Size:      size,
+		TotalItem: totalItem,
+		TotalPage: int64(math.Ceil(float64(totalItem) / float64(size))),
+	}
+	paginationJSON, _ := json.Marshal(pagination)
+	dataHash := dataHash(data)
+	if notModified(ctx, `"`+util.CalculateHash(dataHash+string(paginationJSON))+`"`) {
+		return
+	}
 	ctx.JSON(http.StatusOK, Response[[]T]{
-		Data:     data,
-		DataHash: util.CalculateHash(string(dataJSON)),
-		Pagination: &pagination{
-			Page:      page,
-			Size:      size,
-			TotalItem: totalItem,
-			TotalPage: int64(math.Ceil(float64(totalItem) / float64(size))),
-		},
+		Data:       data,
+		DataHash:   dataHash,
+		Pagination: pagination,
 	})
 }
 
@@ -64,5 +86,49 @@ func ResponseError(ctx *gin.Context, err error) {
 
 	ctx.JSON(appError.Code, Response[any]{
 		Error: appError.Err.Error(),
+		Data:  appError.Data,
 	})
 }
+
+// LastModified sends when the data of the response last changed, for clients
+// revalidating it with If-Modified-Since. HTTP dates are in whole seconds.
+func LastModified(ctx *gin.Context, modifiedAt time.Time) {
+	ctx.Header("Last-Modified", modifiedAt.UTC().Format(http.TimeFormat))
+}
+
+// notModified tags the response with the strong etag, and responds 304 Not
+// Modified instead when the client already has the data. If-None-Match takes
+// precedence over If-Modified-Since, which is only compared with the
+// Last-Modified header of the response.
+func notModified(ctx *gin.Context, etag string) bool {
+	ctx.Header("ETag", etag)
+
+	if ctx.Request.Method != http.MethodGet && ctx.Request.Method != http.MethodHead {
+		return false
+	}
+
+	if ifNoneMatch := ctx.GetHeader("If-None-Match"); ifNoneMatch != "" {
+		for _, tag := range strings.Split(ifNoneMatch, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == etag {
+				ctx.Status(http.StatusNotModified)
+				return true
+			}
+		}
+		return false
+	}
+
+	ifModifiedSince, err := http.ParseTime(ctx.GetHeader("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	lastModified, err := http.ParseTime(ctx.Writer.Header().Get("Last-Modified"))
+	if err != nil {
+		return false
+	}
+	if !lastModified.After(ifModifiedSince) {
+		ctx.Status(http.StatusNotModified)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+package model
+
+type GetManyReviewsRequest struct {
+	paginationRequest
+	BookID int `uri:"id" binding:"required,gt=0"`
+}
+
+type UpsertReviewRequest struct {
+	UserID int    `json:"-" uri:"-"`
+	BookID int    `json:"-" uri:"id" binding:"omitempty,gt=0"`
+	Rating int    `json:"rating" uri:"-" binding:"required,min=1,max=5"`
+	Text   string `json:"text" uri:"-"`
+}
+
+type DeleteOwnReviewRequest struct {
+	UserID int `uri:"-"`
+	BookID int `uri:"id" binding:"required,gt=0"`
+}
+
+type DeleteReviewRequest struct {
+	BookID   int `uri:"id" binding:"required,gt=0"`
+	ReviewID int `uri:"review_id" binding:"required,gt=0"`
+}
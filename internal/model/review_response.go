@@ -0,0 +1,43 @@
+package model
+
+import (
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+)
+
+type ReviewResponse struct {
+	ID        int       `json:"id"`
+	BookID    int       `json:"book_id"`
+	UserID    int       `json:"user_id"`
+	Username  string    `json:"username"`
+	Rating    int       `json:"rating"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func ToReviewResponse(review *entity.Review) *ReviewResponse {
+	return &ReviewResponse{
+		ID:        review.ID,
+		BookID:    review.BookID,
+		UserID:    review.UserID,
+		Username:  review.User.Username,
+		Rating:    review.Rating,
+		Text:      review.Text,
+		CreatedAt: review.CreatedAt,
+		UpdatedAt: review.UpdatedAt,
+	}
+}
+
+func ToReviewsResponse(reviews []entity.Review) []ReviewResponse {
+	response := make([]ReviewResponse, len(reviews))
+	for i, review := range reviews {
+		response[i] = *ToReviewResponse(&review)
+	}
+	return response
+}
+
+type RecalculateRatingsResponse struct {
+	Books int `json:"books"`
+}
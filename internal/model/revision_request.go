@@ -0,0 +1,20 @@
+package model
+
+type GetManyRevisionsRequest struct {
+	paginationRequest
+	ID int `uri:"id" form:"-" binding:"required,gt=0"`
+}
+
+// DiffRevisionsRequest compares revision From of a record with revision To.
+// To defaults to the latest revision, and From to the revision before To.
+type DiffRevisionsRequest struct {
+	ID   int `uri:"id" form:"-" binding:"required,gt=0"`
+	From int `uri:"-" form:"from" binding:"omitempty,gt=0"`
+	To   int `uri:"-" form:"to" binding:"omitempty,gt=0"`
+}
+
+type RestoreRevisionRequest struct {
+	ID         int  `uri:"id" binding:"required,gt=0"`
+	Revision   int  `uri:"rev" binding:"required,gt=0"`
+	RestoredBy *int `uri:"-"`
+}
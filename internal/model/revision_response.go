@@ -0,0 +1,45 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+)
+
+type RevisionResponse struct {
+	Revision  int             `json:"revision"`
+	Data      json.RawMessage `json:"data"`
+	CreatedBy *int            `json:"created_by"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type RevisionDiffResponse struct {
+	From    int                      `json:"from"`
+	To      int                      `json:"to"`
+	Changes []RevisionChangeResponse `json:"changes"`
+}
+
+// RevisionChangeResponse is a field which differs between two revisions.
+type RevisionChangeResponse struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+func ToRevisionResponse(revision *entity.Revision) *RevisionResponse {
+	return &RevisionResponse{
+		Revision:  revision.Revision,
+		Data:      json.RawMessage(revision.Data),
+		CreatedBy: revision.CreatedBy,
+		CreatedAt: revision.CreatedAt,
+	}
+}
+
+func ToRevisionsResponse(revisions []entity.Revision) []RevisionResponse {
+	response := make([]RevisionResponse, len(revisions))
+	for i, revision := range revisions {
+		response[i] = *ToRevisionResponse(&revision)
+	}
+	return response
+}
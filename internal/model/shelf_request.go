@@ -0,0 +1,11 @@
+package model
+
+type GetManyShelvesRequest struct {
+	UserID int `form:"-"`
+}
+
+type GetShelfRequest struct {
+	paginationRequest
+	UserID int    `uri:"-" form:"-"`
+	Shelf  string `uri:"shelf" form:"-" binding:"required"`
+}
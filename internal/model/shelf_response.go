@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+)
+
+type ShelfResponse struct {
+	Name      string `json:"name"`
+	BookCount int64  `json:"book_count"`
+}
+
+type ShelfEntryResponse struct {
+	Shelf   string       `json:"shelf"`
+	Book    BookResponse `json:"book"`
+	AddedAt time.Time    `json:"added_at"`
+}
+
+func ToShelfEntryResponse(entry *entity.ShelfEntry) *ShelfEntryResponse {
+	return &ShelfEntryResponse{
+		Shelf:   entry.Shelf,
+		Book:    *ToBookResponse(&entry.Book),
+		AddedAt: entry.CreatedAt,
+	}
+}
+
+func ToShelfEntriesResponse(entries []entity.ShelfEntry) []ShelfEntryResponse {
+	response := make([]ShelfEntryResponse, len(entries))
+	for i, entry := range entries {
+		response[i] = *ToShelfEntryResponse(&entry)
+	}
+	return response
+}
@@ -0,0 +1,11 @@
+package model
+
+type GetManyTrashRequest struct {
+	paginationRequest
+	Type *string `form:"type" binding:"omitempty,oneof=user author book"`
+}
+
+type RestoreTrashRequest struct {
+	Type string `uri:"type" binding:"required,oneof=user author book"`
+	ID   int    `uri:"id" binding:"required,gt=0"`
+}
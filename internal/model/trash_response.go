@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+)
+
+type TrashItemResponse struct {
+	Type      string    `json:"type"`
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	DeletedAt time.Time `json:"deleted_at"`
+	DeletedBy *int      `json:"deleted_by"`
+}
+
+func ToTrashItemResponse(item *entity.TrashItem) *TrashItemResponse {
+	return &TrashItemResponse{
+		Type:      item.Type,
+		ID:        item.ID,
+		Name:      item.Name,
+		DeletedAt: item.DeletedAt,
+		DeletedBy: item.DeletedBy,
+	}
+}
+
+func ToTrashItemsResponse(items []entity.TrashItem) []TrashItemResponse {
+	response := make([]TrashItemResponse, len(items))
+	for i, item := range items {
+		response[i] = *ToTrashItemResponse(&item)
+	}
+	return response
+}
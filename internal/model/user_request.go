@@ -9,3 +9,23 @@ type LoginRequest struct {
 	Username string `json:"username" binding:"required,gt=0"`
 	Password string `json:"password" binding:"required,gt=0"`
 }
+
+type CreateUserRequest struct {
+	Username string `json:"username" binding:"required,gt=0"`
+	Password string `json:"password" binding:"required,gt=0"`
+	Role     string `json:"role" binding:"required,oneof=user moderator admin"`
+}
+
+type SetUserRoleRequest struct {
+	Username string `json:"username" binding:"required,gt=0"`
+	Role     string `json:"role" binding:"required,oneof=user moderator admin"`
+}
+
+type ResetUserPasswordRequest struct {
+	Username string `json:"username" binding:"required,gt=0"`
+	Password string `json:"password" binding:"required,gt=0"`
+}
+
+type DeleteUserRequest struct {
+	Username string `json:"username" binding:"required,gt=0"`
+}
@@ -5,12 +5,14 @@ import "github.com/mnaufalhilmym/bookshelf/internal/entity"
 type UserResponse struct {
 	ID       int    `json:"id"`
 	Username string `json:"username"`
+	Role     string `json:"role"`
 }
 
 func ToUserResponse(user *entity.User) *UserResponse {
 	return &UserResponse{
 		ID:       user.ID,
 		Username: user.Username,
+		Role:     user.Role,
 	}
 }
 
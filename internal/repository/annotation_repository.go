@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+	"github.com/mnaufalhilmym/goasync"
+	"github.com/mnaufalhilmym/gotracing"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AnnotationRepository struct {
+	repository[entity.Annotation]
+}
+
+func NewAnnotationRepository() *AnnotationRepository {
+	return &AnnotationRepository{}
+}
+
+func (r *AnnotationRepository) Search(
+	ctx context.Context,
+	db *gorm.DB,
+	viewerID int,
+	query *string,
+	bookID *int,
+	userID *int,
+	page int,
+	size int,
+) ([]entity.Annotation, int64, error) {
+	offset := 0
+	if page > 0 {
+		offset = (page - 1) * size
+	}
+
+	filter := r.searchFilter(viewerID, query, bookID, userID)
+
+	annotationsTask := goasync.Spawn(func(ctx context.Context) (annotations []entity.Annotation, err error) {
+		err = db.InnerJoins("User").
+			InnerJoins("Book").
+			Scopes(filter).
+			Order("annotations.book_id").
+			Order("annotations.page").
+			Order("annotations.id").
+			Offset(offset).
+			Limit(size).
+			Find(&annotations).Error
+		return
+	})
+
+	totalTask := goasync.Spawn(func(ctx context.Context) (total int64, err error) {
+		err = db.Model(&entity.Annotation{}).InnerJoins("User").InnerJoins("Book").Scopes(filter).Count(&total).Error
+		return
+	})
+
+	annotations, err := annotationsTask.Await(ctx)
+	if err != nil {
+		gotracing.Error("Failed to find entities from database", err)
+		return nil, 0, err
+	}
+
+	total, err := totalTask.Await(ctx)
+	if err != nil {
+		gotracing.Error("Failed to count entities from database", err)
+		return nil, 0, err
+	}
+
+	return annotations, total, nil
+}
+
+func (*AnnotationRepository) FindByID(db *gorm.DB, id int) (*entity.Annotation, error) {
+	var entity *entity.Annotation
+	if err := db.InnerJoins("User").InnerJoins("Book").Where("annotations.id = ?", id).First(&entity).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			gotracing.Error("Failed to find entity from database", err)
+		}
+		return nil, err
+	}
+	return entity, nil
+}
+
+func (*AnnotationRepository) FindAllByUserID(db *gorm.DB, userID int, bookID *int) ([]entity.Annotation, error) {
+	tx := db.InnerJoins("Book").InnerJoins("Book.Author").Where("annotations.user_id = ?", userID)
+	if bookID != nil {
+		tx = tx.Where("annotations.book_id = ?", *bookID)
+	}
+
+	var entities []entity.Annotation
+	if err := tx.Order(clause.OrderByColumn{Column: column("Book", "title")}).
+		Order("annotations.book_id").
+		Order("annotations.page").
+		Order("annotations.id").
+		Find(&entities).Error; err != nil {
+		gotracing.Error("Failed to find entities from database", err)
+		return nil, err
+	}
+	return entities, nil
+}
+
+func (*AnnotationRepository) searchFilter(
+	viewerID int,
+	query *string,
+	bookID *int,
+	userID *int,
+) func(tx *gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		tx = tx.Where("(annotations.visibility = ? OR annotations.user_id = ?)", entity.AnnotationVisibilityPublic, viewerID)
+
+		if query != nil && *query != "" {
+			tx = tx.Where(
+				"(? OR ?)",
+				containsFold(tx, column("annotations", "text"), *query),
+				containsFold(tx, column("annotations", "note"), *query),
+			)
+		}
+
+		if bookID != nil {
+			tx = tx.Where("annotations.book_id = ?", *bookID)
+		}
+
+		if userID != nil {
+			tx = tx.Where("annotations.user_id = ?", *userID)
+		}
+
+		return tx
+	}
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+	"github.com/mnaufalhilmym/gotracing"
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository struct {
+	repository[entity.APIKey]
+}
+
+func NewAPIKeyRepository() *APIKeyRepository {
+	return &APIKeyRepository{}
+}
+
+func (*APIKeyRepository) FindByKeyHash(db *gorm.DB, keyHash string) (*entity.APIKey, error) {
+	var entity *entity.APIKey
+	if err := db.InnerJoins("User").Where("api_keys.key_hash = ?", keyHash).First(&entity).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			gotracing.Error("Failed to find entity from database", err)
+		}
+		return nil, err
+	}
+	return entity, nil
+}
+
+func (*APIKeyRepository) FindByUserIDAndID(db *gorm.DB, userID int, id int) (*entity.APIKey, error) {
+	var entity *entity.APIKey
+	if err := db.Where("user_id = ? AND id = ?", userID, id).First(&entity).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			gotracing.Error("Failed to find entity from database", err)
+		}
+		return nil, err
+	}
+	return entity, nil
+}
+
+func (*APIKeyRepository) FindAllByUserID(db *gorm.DB, userID int) ([]entity.APIKey, error) {
+	var entities []entity.APIKey
+	if err := db.Where("user_id = ?", userID).Order("id").Find(&entities).Error; err != nil {
+		gotracing.Error("Failed to find entities from database", err)
+		return nil, err
+	}
+	return entities, nil
+}
+
+func (*APIKeyRepository) UpdateLastUsedAt(db *gorm.DB, id int, lastUsedAt time.Time) error {
+	if err := db.Model(&entity.APIKey{}).Where("id = ?", id).Update("last_used_at", lastUsedAt).Error; err != nil {
+		gotracing.Error("Failed to update entity to database", err)
+		return err
+	}
+	return nil
+}
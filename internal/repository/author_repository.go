@@ -2,8 +2,7 @@ package repository
 
 import (
 	"context"
-	"fmt"
-	"strings"
+	"errors"
 	"time"
 
 	"github.com/mnaufalhilmym/bookshelf/internal/entity"
@@ -14,18 +13,29 @@ import (
 
 type AuthorRepository struct {
 	repository[entity.Author]
+	trashable[entity.Author]
 }
 
-func NewAuthorRepository(db *gorm.DB) *AuthorRepository {
-	if err := db.Migrator().CreateTable(&entity.Author{}); err != nil {
-		if !strings.Contains(err.Error(), "already exists") {
-			panic(fmt.Errorf("failed to migrate entity: %w", err))
-		}
-	}
-
+func NewAuthorRepository() *AuthorRepository {
 	return &AuthorRepository{}
 }
 
+// Update saves the author, unless it was saved by someone else since it was
+// read.
+func (*AuthorRepository) Update(db *gorm.DB, author *entity.Author) error {
+	return updateVersioned(db, author, &author.Version)
+}
+
+// Purge deletes the author for good, along with their revisions.
+func (r *AuthorRepository) Purge(db *gorm.DB, author *entity.Author) error {
+	if err := db.Where("entity_type = ? AND entity_id = ?", entity.RevisionEntityAuthor, author.ID).
+		Delete(&entity.Revision{}).Error; err != nil {
+		gotracing.Error("Failed to delete entities from database", err)
+		return err
+	}
+	return r.trashable.Purge(db, author)
+}
+
 func (r *AuthorRepository) Search(
 	ctx context.Context,
 	db *gorm.DB,
@@ -67,6 +77,37 @@ func (r *AuthorRepository) Search(
 	return authors, total, nil
 }
 
+func (*AuthorRepository) FindByName(db *gorm.DB, name string) (*entity.Author, error) {
+	var entity *entity.Author
+	if err := db.Where("LOWER(name) = LOWER(?)", name).Order("id").First(&entity).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			gotracing.Error("Failed to find entity from database", err)
+		}
+		return nil, err
+	}
+	return entity, nil
+}
+
+// FindAllAfterID returns up to limit authors with an ID after afterID,
+// ordered by ID.
+func (*AuthorRepository) FindAllAfterID(db *gorm.DB, afterID int, limit int) ([]entity.Author, error) {
+	var entities []entity.Author
+	if err := db.Where("id > ?", afterID).Order("id").Limit(limit).Find(&entities).Error; err != nil {
+		gotracing.Error("Failed to find entities from database", err)
+		return nil, err
+	}
+	return entities, nil
+}
+
+func (*AuthorRepository) Count(db *gorm.DB) (int64, error) {
+	var total int64
+	if err := db.Model(&entity.Author{}).Count(&total).Error; err != nil {
+		gotracing.Error("Failed to count entities from database", err)
+		return 0, err
+	}
+	return total, nil
+}
+
 func (*AuthorRepository) searchFilter(
 	name *string,
 	birthdateStart *time.Time,
@@ -74,8 +115,7 @@ func (*AuthorRepository) searchFilter(
 ) func(tx *gorm.DB) *gorm.DB {
 	return func(tx *gorm.DB) *gorm.DB {
 		if name != nil && *name != "" {
-			fname := "%" + *name + "%"
-			tx = tx.Where("LOWER(name) LIKE LOWER(?)", fname)
+			tx = tx.Where(containsFold(tx, column("authors", "name"), *name))
 		}
 
 		if birthdateStart != nil {
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"github.com/mnaufalhilmym/gotracing"
+	"gorm.io/gorm"
+)
+
+type BackupRepository struct{}
+
+func NewBackupRepository() *BackupRepository {
+	return &BackupRepository{}
+}
+
+// IsSupported tells whether the database can be backed up, which only
+// SQLite databases can. Database servers come with their own tools.
+func (*BackupRepository) IsSupported(db *gorm.DB) bool {
+	return db.Dialector.Name() == "sqlite"
+}
+
+// VacuumInto writes a consistent copy of the database to path while the
+// database stays online.
+func (*BackupRepository) VacuumInto(db *gorm.DB, path string) error {
+	if err := db.Exec("VACUUM INTO ?", path).Error; err != nil {
+		gotracing.Error("Failed to back up database", err)
+		return err
+	}
+	return nil
+}
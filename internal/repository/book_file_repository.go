@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+	"github.com/mnaufalhilmym/gotracing"
+	"gorm.io/gorm"
+)
+
+type BookFileRepository struct {
+	repository[entity.BookFile]
+}
+
+func NewBookFileRepository() *BookFileRepository {
+	return &BookFileRepository{}
+}
+
+func (*BookFileRepository) FindByBookIDAndID(db *gorm.DB, bookID int, id int) (*entity.BookFile, error) {
+	var entity *entity.BookFile
+	if err := db.Where("book_id = ? AND id = ?", bookID, id).First(&entity).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			gotracing.Error("Failed to find entity from database", err)
+		}
+		return nil, err
+	}
+	return entity, nil
+}
+
+func (*BookFileRepository) FindByBookIDAndChecksum(db *gorm.DB, bookID int, checksum string) (*entity.BookFile, error) {
+	var entity *entity.BookFile
+	if err := db.Where("book_id = ? AND checksum = ?", bookID, checksum).First(&entity).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			gotracing.Error("Failed to find entity from database", err)
+		}
+		return nil, err
+	}
+	return entity, nil
+}
+
+func (*BookFileRepository) FindAllByBookID(db *gorm.DB, bookID int) ([]entity.BookFile, error) {
+	var entities []entity.BookFile
+	if err := db.Where("book_id = ?", bookID).Order("id").Find(&entities).Error; err != nil {
+		gotracing.Error("Failed to find entities from database", err)
+		return nil, err
+	}
+	return entities, nil
+}
+
+func (*BookFileRepository) FindAllByBookIDs(db *gorm.DB, bookIDs []int) ([]entity.BookFile, error) {
+	var entities []entity.BookFile
+	if err := db.Where("book_id IN ?", bookIDs).Order("book_id").Order("id").Find(&entities).Error; err != nil {
+		gotracing.Error("Failed to find entities from database", err)
+		return nil, err
+	}
+	return entities, nil
+}
@@ -3,26 +3,22 @@ package repository
 import (
 	"context"
 	"errors"
-	"fmt"
 	"strings"
+	"time"
 
 	"github.com/mnaufalhilmym/bookshelf/internal/entity"
 	"github.com/mnaufalhilmym/goasync"
 	"github.com/mnaufalhilmym/gotracing"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type BookRepository struct {
 	repository[entity.Book]
+	trashable[entity.Book]
 }
 
-func NewBookRepository(db *gorm.DB) *BookRepository {
-	if err := db.Migrator().CreateTable(&entity.Book{}); err != nil {
-		if !strings.Contains(err.Error(), "already exists") {
-			panic(fmt.Errorf("failed to migrate entity: %w", err))
-		}
-	}
-
+func NewBookRepository() *BookRepository {
 	return &BookRepository{}
 }
 
@@ -33,6 +29,7 @@ func (r *BookRepository) Search(
 	isbn *string,
 	authorID *int,
 	authorName *string,
+	sort *string,
 	page int,
 	size int,
 ) ([]entity.Book, int64, error) {
@@ -44,12 +41,12 @@ func (r *BookRepository) Search(
 	filter := r.searchFilter(title, isbn, authorID, authorName)
 
 	booksTask := goasync.Spawn(func(ctx context.Context) (books []entity.Book, err error) {
-		err = db.Joins("Author").Scopes(filter).Offset(offset).Limit(size).Find(&books).Error
+		err = db.InnerJoins("Author").Scopes(filter, r.sortOrder(sort)).Offset(offset).Limit(size).Find(&books).Error
 		return
 	})
 
 	totalTask := goasync.Spawn(func(ctx context.Context) (total int64, err error) {
-		err = db.Model(&entity.Book{}).Joins("Author").Scopes(filter).Count(&total).Error
+		err = db.Model(&entity.Book{}).InnerJoins("Author").Scopes(filter).Count(&total).Error
 		return
 	})
 
@@ -68,9 +65,66 @@ func (r *BookRepository) Search(
 	return books, total, nil
 }
 
+// SearchEach calls fn with consecutive batches of the books matching the
+// filters, so callers can stream the result without loading it all at once.
+func (r *BookRepository) SearchEach(
+	db *gorm.DB,
+	title *string,
+	isbn *string,
+	authorID *int,
+	authorName *string,
+	sort *string,
+	batchSize int,
+	fn func(books []entity.Book) error,
+) error {
+	filter := r.searchFilter(title, isbn, authorID, authorName)
+
+	for offset := 0; ; offset += batchSize {
+		var books []entity.Book
+		if err := db.InnerJoins("Author").Scopes(filter, r.sortOrder(sort)).Offset(offset).Limit(batchSize).Find(&books).Error; err != nil {
+			gotracing.Error("Failed to find entities from database", err)
+			return err
+		}
+
+		if len(books) == 0 {
+			return nil
+		}
+
+		if err := fn(books); err != nil {
+			return err
+		}
+
+		if len(books) < batchSize {
+			return nil
+		}
+	}
+}
+
+// Update saves the book, unless it was saved by someone else since it was
+// read. Ratings are left as they are, reviews change them without a new
+// version of the book.
+func (*BookRepository) Update(db *gorm.DB, book *entity.Book) error {
+	return updateVersioned(db, book, &book.Version, "rating_average", "rating_count")
+}
+
 func (*BookRepository) FindByID(db *gorm.DB, id int) (*entity.Book, error) {
 	var entity *entity.Book
-	if err := db.Joins("Author").Where("books.id = ?", id).First(&entity).Error; err != nil {
+	if err := db.InnerJoins("Author").Where("books.id = ?", id).First(&entity).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			gotracing.Error("Failed to find entity from database", err)
+		}
+		return nil, err
+	}
+	return entity, nil
+}
+
+// FindByISBN matches the ISBN ignoring hyphens, spaces and case, so
+// "978-0451524935" and "9780451524935" find the same book.
+func (*BookRepository) FindByISBN(db *gorm.DB, isbn string) (*entity.Book, error) {
+	normalized := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(isbn))
+
+	var entity *entity.Book
+	if err := db.InnerJoins("Author").Where("REPLACE(REPLACE(UPPER(books.isbn), '-', ''), ' ', '') = ?", normalized).First(&entity).Error; err != nil {
 		if !errors.Is(err, gorm.ErrRecordNotFound) {
 			gotracing.Error("Failed to find entity from database", err)
 		}
@@ -79,6 +133,303 @@ func (*BookRepository) FindByID(db *gorm.DB, id int) (*entity.Book, error) {
 	return entity, nil
 }
 
+func (*BookRepository) FindAllByTitle(db *gorm.DB, title string) ([]entity.Book, error) {
+	var entities []entity.Book
+	if err := db.InnerJoins("Author").Where("LOWER(books.title) = LOWER(?)", title).Order("books.id").Find(&entities).Error; err != nil {
+		gotracing.Error("Failed to find entities from database", err)
+		return nil, err
+	}
+	return entities, nil
+}
+
+// SearchText finds the books whose title, series, ISBN or author name
+// contains the query, ordered by title.
+func (r *BookRepository) SearchText(
+	ctx context.Context,
+	db *gorm.DB,
+	query string,
+	page int,
+	size int,
+) ([]entity.Book, int64, error) {
+	offset := 0
+	if page > 0 {
+		offset = (page - 1) * size
+	}
+
+	filter := func(tx *gorm.DB) *gorm.DB {
+		return tx.Where(
+			"? OR ? OR ? OR ?",
+			containsFold(tx, column("books", "title"), query),
+			containsFold(tx, column("books", "series"), query),
+			containsFold(tx, column("books", "isbn"), query),
+			containsFold(tx, column("Author", "name"), query),
+		)
+	}
+
+	booksTask := goasync.Spawn(func(ctx context.Context) (books []entity.Book, err error) {
+		err = db.InnerJoins("Author").Scopes(filter).Order("books.title").Order("books.id").Offset(offset).Limit(size).Find(&books).Error
+		return
+	})
+
+	totalTask := goasync.Spawn(func(ctx context.Context) (total int64, err error) {
+		err = db.Model(&entity.Book{}).InnerJoins("Author").Scopes(filter).Count(&total).Error
+		return
+	})
+
+	books, err := booksTask.Await(ctx)
+	if err != nil {
+		gotracing.Error("Failed to find entities from database", err)
+		return nil, 0, err
+	}
+
+	total, err := totalTask.Await(ctx)
+	if err != nil {
+		gotracing.Error("Failed to count entities from database", err)
+		return nil, 0, err
+	}
+
+	return books, total, nil
+}
+
+func (*BookRepository) FindAllBySeries(db *gorm.DB, series string) ([]entity.Book, error) {
+	var entities []entity.Book
+	if err := db.InnerJoins("Author").Where("books.series = ?", series).Order("books.series_index").Order("books.title").Find(&entities).Error; err != nil {
+		gotracing.Error("Failed to find entities from database", err)
+		return nil, err
+	}
+	return entities, nil
+}
+
+// CountBySeries returns the number of books in each series.
+func (*BookRepository) CountBySeries(db *gorm.DB) (map[string]int64, error) {
+	var rows []struct {
+		Series string
+		Count  int64
+	}
+	if err := db.Model(&entity.Book{}).
+		Select("series, COUNT(*) AS count").
+		Where("series <> ''").
+		Group("series").
+		Order("series").
+		Scan(&rows).Error; err != nil {
+		gotracing.Error("Failed to aggregate entities from database", err)
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Series] = row.Count
+	}
+	return counts, nil
+}
+
+func (*BookRepository) FindAllIDs(db *gorm.DB) ([]int, error) {
+	var ids []int
+	if err := db.Model(&entity.Book{}).Order("id").Pluck("id", &ids).Error; err != nil {
+		gotracing.Error("Failed to find entities from database", err)
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (*BookRepository) FindAllByAuthorID(db *gorm.DB, authorID int) ([]entity.Book, error) {
+	var entities []entity.Book
+	if err := db.InnerJoins("Author").Where("books.author_id = ?", authorID).Order("books.id").Find(&entities).Error; err != nil {
+		gotracing.Error("Failed to find entities from database", err)
+		return nil, err
+	}
+	return entities, nil
+}
+
+// UpdateRating leaves updated_at as is, since ratings are not part of the
+// records harvesters collect.
+func (*BookRepository) UpdateRating(db *gorm.DB, id int, average float64, count int64) error {
+	if err := db.Model(&entity.Book{}).Where("id = ?", id).UpdateColumns(map[string]any{
+		"rating_average": average,
+		"rating_count":   count,
+	}).Error; err != nil {
+		gotracing.Error("Failed to update entity to database", err)
+		return err
+	}
+	return nil
+}
+
+// Trash moves the book to the trash and records the deletion, which stays
+// recorded when the book is purged.
+func (r *BookRepository) Trash(db *gorm.DB, book *entity.Book, deletedBy *int) error {
+	deleted := &entity.DeletedBook{ID: book.ID, AuthorID: book.AuthorID, DeletedAt: time.Now()}
+	if err := db.Save(deleted).Error; err != nil {
+		gotracing.Error("Failed to create entity to database", err)
+		return err
+	}
+	return r.trashable.Trash(db, book, deletedBy)
+}
+
+// Restore takes the book out of the trash and forgets its deletion. Taking
+// it out modifies the book, so that harvesters learn about it again.
+func (r *BookRepository) Restore(db *gorm.DB, book *entity.Book) error {
+	if err := db.Delete(&entity.DeletedBook{ID: book.ID}).Error; err != nil {
+		gotracing.Error("Failed to delete entity from database", err)
+		return err
+	}
+	return r.trashable.Restore(db, book)
+}
+
+// Purge deletes the book for good, along with its revisions and the rows of
+// users and files which belong to it. The stored files and covers are left
+// to the caller.
+func (r *BookRepository) Purge(db *gorm.DB, book *entity.Book) error {
+	for _, dependent := range []any{
+		&entity.ReadingProgress{},
+		&entity.Annotation{},
+		&entity.ShelfEntry{},
+		&entity.Review{},
+		&entity.BookFile{},
+	} {
+		if err := db.Where("book_id = ?", book.ID).Delete(dependent).Error; err != nil {
+			gotracing.Error("Failed to delete entities from database", err)
+			return err
+		}
+	}
+	if err := db.Where("entity_type = ? AND entity_id = ?", entity.RevisionEntityBook, book.ID).
+		Delete(&entity.Revision{}).Error; err != nil {
+		gotracing.Error("Failed to delete entities from database", err)
+		return err
+	}
+	return r.trashable.Purge(db, book)
+}
+
+// FindAllModified returns up to limit books with an ID after afterID, ordered
+// by ID, that were modified in [from, until) and are by the author when
+// authorID is set.
+func (r *BookRepository) FindAllModified(
+	db *gorm.DB,
+	authorID *int,
+	from *time.Time,
+	until *time.Time,
+	afterID int,
+	limit int,
+) ([]entity.Book, error) {
+	var entities []entity.Book
+	if err := db.InnerJoins("Author").
+		Scopes(r.modifiedFilter("books.author_id", "books.updated_at", authorID, from, until)).
+		Where("books.id > ?", afterID).
+		Order("books.id").
+		Limit(limit).
+		Find(&entities).Error; err != nil {
+		gotracing.Error("Failed to find entities from database", err)
+		return nil, err
+	}
+	return entities, nil
+}
+
+func (r *BookRepository) CountModified(db *gorm.DB, authorID *int, from *time.Time, until *time.Time) (int64, error) {
+	var total int64
+	if err := db.Model(&entity.Book{}).
+		Scopes(r.modifiedFilter("author_id", "updated_at", authorID, from, until)).
+		Count(&total).Error; err != nil {
+		gotracing.Error("Failed to count entities from database", err)
+		return 0, err
+	}
+	return total, nil
+}
+
+// FindAllDeleted is FindAllModified for deleted books. A deleted book whose
+// ID was taken again by a new book is left out.
+func (r *BookRepository) FindAllDeleted(
+	db *gorm.DB,
+	authorID *int,
+	from *time.Time,
+	until *time.Time,
+	afterID int,
+	limit int,
+) ([]entity.DeletedBook, error) {
+	var entities []entity.DeletedBook
+	if err := db.Scopes(r.deletedFilter, r.modifiedFilter("author_id", "deleted_at", authorID, from, until)).
+		Where("id > ?", afterID).
+		Order("id").
+		Limit(limit).
+		Find(&entities).Error; err != nil {
+		gotracing.Error("Failed to find entities from database", err)
+		return nil, err
+	}
+	return entities, nil
+}
+
+func (r *BookRepository) CountDeleted(db *gorm.DB, authorID *int, from *time.Time, until *time.Time) (int64, error) {
+	var total int64
+	if err := db.Model(&entity.DeletedBook{}).
+		Scopes(r.deletedFilter, r.modifiedFilter("author_id", "deleted_at", authorID, from, until)).
+		Count(&total).Error; err != nil {
+		gotracing.Error("Failed to count entities from database", err)
+		return 0, err
+	}
+	return total, nil
+}
+
+func (r *BookRepository) FindDeletedByID(db *gorm.DB, id int) (*entity.DeletedBook, error) {
+	var entity *entity.DeletedBook
+	if err := db.Scopes(r.deletedFilter).Where("id = ?", id).First(&entity).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			gotracing.Error("Failed to find entity from database", err)
+		}
+		return nil, err
+	}
+	return entity, nil
+}
+
+// FindEarliestModification returns the earliest time a book was modified or
+// deleted, or the zero time when there are no books.
+func (*BookRepository) FindEarliestModification(db *gorm.DB) (time.Time, error) {
+	var earliest time.Time
+
+	var book entity.Book
+	if err := db.Select("id", "updated_at").Order("updated_at").Limit(1).Find(&book).Error; err != nil {
+		gotracing.Error("Failed to find entity from database", err)
+		return earliest, err
+	}
+	if book.ID != 0 {
+		earliest = book.UpdatedAt
+	}
+
+	var deleted entity.DeletedBook
+	if err := db.Order("deleted_at").Limit(1).Find(&deleted).Error; err != nil {
+		gotracing.Error("Failed to find entity from database", err)
+		return earliest, err
+	}
+	if deleted.ID != 0 && (earliest.IsZero() || deleted.DeletedAt.Before(earliest)) {
+		earliest = deleted.DeletedAt
+	}
+
+	return earliest, nil
+}
+
+// modifiedFilter compares in local time, the zone timestamps are stored in.
+func (*BookRepository) modifiedFilter(
+	authorColumn string,
+	timeColumn string,
+	authorID *int,
+	from *time.Time,
+	until *time.Time,
+) func(tx *gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		if authorID != nil {
+			tx = tx.Where(authorColumn+" = ?", *authorID)
+		}
+		if from != nil {
+			tx = tx.Where(timeColumn+" >= ?", from.Local())
+		}
+		if until != nil {
+			tx = tx.Where(timeColumn+" < ?", until.Local())
+		}
+		return tx
+	}
+}
+
+func (*BookRepository) deletedFilter(tx *gorm.DB) *gorm.DB {
+	return tx.Where("id NOT IN (?)", tx.Session(&gorm.Session{NewDB: true}).Model(&entity.Book{}).Select("id"))
+}
+
 func (*BookRepository) searchFilter(
 	title *string,
 	isbn *string,
@@ -87,24 +438,44 @@ func (*BookRepository) searchFilter(
 ) func(tx *gorm.DB) *gorm.DB {
 	return func(tx *gorm.DB) *gorm.DB {
 		if title != nil && *title != "" {
-			ftitle := "%" + *title + "%"
-			tx = tx.Where("LOWER(books.title) LIKE LOWER(?)", ftitle)
+			tx = tx.Where(containsFold(tx, column("books", "title"), *title))
 		}
 
 		if isbn != nil && *isbn != "" {
-			fisbn := "%" + *isbn + "%"
-			tx = tx.Where("LOWER(books.isbn) LIKE LOWER(?)", fisbn)
+			tx = tx.Where(containsFold(tx, column("books", "isbn"), *isbn))
 		}
 
 		if authorID != nil {
-			tx = tx.Where("Author.id = ?", *authorID)
+			tx = tx.Where(clause.Eq{Column: column("Author", "id"), Value: *authorID})
 		}
 
 		if authorName != nil && *authorName != "" {
-			fname := "%" + *authorName + "%"
-			tx = tx.Where("LOWER(Author.name) LIKE LOWER(?)", fname)
+			tx = tx.Where(containsFold(tx, column("Author", "name"), *authorName))
 		}
 
 		return tx
 	}
 }
+
+func (*BookRepository) sortOrder(sort *string) func(tx *gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		if sort == nil {
+			return tx.Order("books.id")
+		}
+
+		switch *sort {
+		case "-id":
+			return tx.Order("books.id DESC")
+		case "title":
+			return tx.Order("books.title").Order("books.id")
+		case "-title":
+			return tx.Order("books.title DESC").Order("books.id")
+		case "rating":
+			return tx.Order("books.rating_average").Order("books.rating_count").Order("books.id")
+		case "-rating":
+			return tx.Order("books.rating_average DESC").Order("books.rating_count DESC").Order("books.id")
+		default:
+			return tx.Order("books.id")
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+	"github.com/mnaufalhilmym/gotracing"
+	"gorm.io/gorm"
+)
+
+type ChallengeParticipantRepository struct {
+	repository[entity.ChallengeParticipant]
+}
+
+func NewChallengeParticipantRepository() *ChallengeParticipantRepository {
+	return &ChallengeParticipantRepository{}
+}
+
+func (*ChallengeParticipantRepository) FindByChallengeIDAndUserID(
+	db *gorm.DB,
+	challengeID int,
+	userID int,
+) (*entity.ChallengeParticipant, error) {
+	var entity *entity.ChallengeParticipant
+	if err := db.Where("challenge_id = ? AND user_id = ?", challengeID, userID).First(&entity).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			gotracing.Error("Failed to find entity from database", err)
+		}
+		return nil, err
+	}
+	return entity, nil
+}
+
+func (*ChallengeParticipantRepository) FindAllByChallengeID(db *gorm.DB, challengeID int) ([]entity.ChallengeParticipant, error) {
+	var entities []entity.ChallengeParticipant
+	if err := db.InnerJoins("User").
+		Where("challenge_participants.challenge_id = ?", challengeID).
+		Order("challenge_participants.id").
+		Find(&entities).Error; err != nil {
+		gotracing.Error("Failed to find entities from database", err)
+		return nil, err
+	}
+	return entities, nil
+}
+
+func (*ChallengeParticipantRepository) DeleteByChallengeID(db *gorm.DB, challengeID int) error {
+	if err := db.Where("challenge_id = ?", challengeID).Delete(&entity.ChallengeParticipant{}).Error; err != nil {
+		gotracing.Error("Failed to delete entities from database", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+	"github.com/mnaufalhilmym/goasync"
+	"github.com/mnaufalhilmym/gotracing"
+	"gorm.io/gorm"
+)
+
+type ChallengeRepository struct {
+	repository[entity.Challenge]
+}
+
+func NewChallengeRepository() *ChallengeRepository {
+	return &ChallengeRepository{}
+}
+
+func (r *ChallengeRepository) Search(
+	ctx context.Context,
+	db *gorm.DB,
+	activeAt *time.Time,
+	page int,
+	size int,
+) ([]entity.Challenge, int64, error) {
+	offset := 0
+	if page > 0 {
+		offset = (page - 1) * size
+	}
+
+	filter := r.searchFilter(activeAt)
+
+	challengesTask := goasync.Spawn(func(ctx context.Context) (challenges []entity.Challenge, err error) {
+		err = db.Scopes(filter).Order("start_at DESC").Order("id DESC").Offset(offset).Limit(size).Find(&challenges).Error
+		return
+	})
+
+	totalTask := goasync.Spawn(func(ctx context.Context) (total int64, err error) {
+		err = db.Model(&entity.Challenge{}).Scopes(filter).Count(&total).Error
+		return
+	})
+
+	challenges, err := challengesTask.Await(ctx)
+	if err != nil {
+		gotracing.Error("Failed to find entities from database", err)
+		return nil, 0, err
+	}
+
+	total, err := totalTask.Await(ctx)
+	if err != nil {
+		gotracing.Error("Failed to count entities from database", err)
+		return nil, 0, err
+	}
+
+	return challenges, total, nil
+}
+
+func (*ChallengeRepository) searchFilter(activeAt *time.Time) func(tx *gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		if activeAt != nil {
+			tx = tx.Where("start_at <= ? AND end_at >= ?", *activeAt, *activeAt)
+		}
+
+		return tx
+	}
+}
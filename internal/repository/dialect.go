@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// containsFold matches rows where column contains query, ignoring case. LIKE
+// ignores case in SQLite and MySQL but not in PostgreSQL, which has ILIKE.
+func containsFold(db *gorm.DB, column clause.Column, query string) clause.Expr {
+	pattern := "%" + query + "%"
+	if db.Dialector.Name() == "postgres" {
+		return gorm.Expr("? ILIKE ?", column, pattern)
+	}
+	return gorm.Expr("LOWER(?) LIKE LOWER(?)", column, pattern)
+}
+
+// column names a column of table, or of the alias of a joined association
+// such as Author, quoted for the dialect of the database.
+func column(table string, name string) clause.Column {
+	return clause.Column{Table: table, Name: name}
+}
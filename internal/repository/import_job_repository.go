@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+	"github.com/mnaufalhilmym/gotracing"
+	"gorm.io/gorm"
+)
+
+type ImportJobRepository struct {
+	repository[entity.ImportJob]
+}
+
+func NewImportJobRepository() *ImportJobRepository {
+	return &ImportJobRepository{}
+}
+
+func (*ImportJobRepository) UpdateProgress(db *gorm.DB, id int, processed int) error {
+	if err := db.Model(&entity.ImportJob{}).Where("id = ?", id).Updates(map[string]any{
+		"status":    entity.ImportJobStatusRunning,
+		"processed": processed,
+	}).Error; err != nil {
+		gotracing.Error("Failed to update entity to database", err)
+		return err
+	}
+	return nil
+}
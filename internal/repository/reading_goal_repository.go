@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+	"github.com/mnaufalhilmym/gotracing"
+	"gorm.io/gorm"
+)
+
+type ReadingGoalRepository struct {
+	repository[entity.ReadingGoal]
+}
+
+func NewReadingGoalRepository() *ReadingGoalRepository {
+	return &ReadingGoalRepository{}
+}
+
+func (*ReadingGoalRepository) FindByUserIDAndYear(db *gorm.DB, userID int, year int) (*entity.ReadingGoal, error) {
+	var entity *entity.ReadingGoal
+	if err := db.Where("user_id = ? AND year = ?", userID, year).First(&entity).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			gotracing.Error("Failed to find entity from database", err)
+		}
+		return nil, err
+	}
+	return entity, nil
+}
+
+func (*ReadingGoalRepository) FindAllByUserID(db *gorm.DB, userID int) ([]entity.ReadingGoal, error) {
+	var entities []entity.ReadingGoal
+	if err := db.Where("user_id = ?", userID).Order("year DESC").Find(&entities).Error; err != nil {
+		gotracing.Error("Failed to find entities from database", err)
+		return nil, err
+	}
+	return entities, nil
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+	"github.com/mnaufalhilmym/goasync"
+	"github.com/mnaufalhilmym/gotracing"
+	"gorm.io/gorm"
+)
+
+type ReadingProgressRepository struct {
+	repository[entity.ReadingProgress]
+}
+
+func NewReadingProgressRepository() *ReadingProgressRepository {
+	return &ReadingProgressRepository{}
+}
+
+func (*ReadingProgressRepository) Search(
+	ctx context.Context,
+	db *gorm.DB,
+	userID int,
+	bookID int,
+	page int,
+	size int,
+) ([]entity.ReadingProgress, int64, error) {
+	offset := 0
+	if page > 0 {
+		offset = (page - 1) * size
+	}
+
+	filter := func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("reading_progresses.user_id = ? AND reading_progresses.book_id = ?", userID, bookID)
+	}
+
+	progressesTask := goasync.Spawn(func(ctx context.Context) (progresses []entity.ReadingProgress, err error) {
+		err = db.Scopes(filter).
+			Order("reading_progresses.read_at DESC").
+			Order("reading_progresses.id DESC").
+			Offset(offset).
+			Limit(size).
+			Find(&progresses).Error
+		return
+	})
+
+	totalTask := goasync.Spawn(func(ctx context.Context) (total int64, err error) {
+		err = db.Model(&entity.ReadingProgress{}).Scopes(filter).Count(&total).Error
+		return
+	})
+
+	progresses, err := progressesTask.Await(ctx)
+	if err != nil {
+		gotracing.Error("Failed to find entities from database", err)
+		return nil, 0, err
+	}
+
+	total, err := totalTask.Await(ctx)
+	if err != nil {
+		gotracing.Error("Failed to count entities from database", err)
+		return nil, 0, err
+	}
+
+	return progresses, total, nil
+}
+
+func (*ReadingProgressRepository) FindAllByUserIDs(db *gorm.DB, userIDs []int) ([]entity.ReadingProgress, error) {
+	var entities []entity.ReadingProgress
+	if err := db.InnerJoins("Book").
+		InnerJoins("Book.Author").
+		Where("reading_progresses.user_id IN ?", userIDs).
+		Order("reading_progresses.read_at").
+		Order("reading_progresses.id").
+		Find(&entities).Error; err != nil {
+		gotracing.Error("Failed to find entities from database", err)
+		return nil, err
+	}
+	return entities, nil
+}
+
+func (*ReadingProgressRepository) FindByUserIDAndBookIDAndReadAt(
+	db *gorm.DB,
+	userID int,
+	bookID int,
+	readAt time.Time,
+) (*entity.ReadingProgress, error) {
+	var entity *entity.ReadingProgress
+	if err := db.Where("user_id = ? AND book_id = ? AND read_at = ?", userID, bookID, readAt).First(&entity).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			gotracing.Error("Failed to find entity from database", err)
+		}
+		return nil, err
+	}
+	return entity, nil
+}
@@ -5,8 +5,13 @@ import (
 
 	"github.com/mnaufalhilmym/gotracing"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrStaleEntity is returned when an entity was saved by someone else since
+// it was read.
+var ErrStaleEntity = errors.New("entity has been modified since it was read")
+
 type repository[T any] struct{}
 
 func (*repository[T]) Create(db *gorm.DB, entity *T) error {
@@ -25,6 +30,26 @@ func (*repository[T]) Update(db *gorm.DB, entity *T) error {
 	return nil
 }
 
+// updateVersioned saves entity only if its row still has the version it was
+// read with, and moves it to the next version. The omitted columns are left
+// as they are, for columns which change without a new version.
+func updateVersioned(db *gorm.DB, entity any, version *int, omit ...string) error {
+	current := *version
+	*version = current + 1
+
+	result := db.Model(entity).Select("*").Omit(append(omit, clause.Associations)...).Where("version = ?", current).Updates(entity)
+	if result.Error != nil {
+		*version = current
+		gotracing.Error("Failed to update entity to database", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		*version = current
+		return ErrStaleEntity
+	}
+	return nil
+}
+
 func (*repository[T]) Delete(db *gorm.DB, entity *T) error {
 	if err := db.Delete(entity).Error; err != nil {
 		gotracing.Error("Failed to delete entity from database", err)
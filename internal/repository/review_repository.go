@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+	"github.com/mnaufalhilmym/goasync"
+	"github.com/mnaufalhilmym/gotracing"
+	"gorm.io/gorm"
+)
+
+type ReviewRepository struct {
+	repository[entity.Review]
+}
+
+func NewReviewRepository() *ReviewRepository {
+	return &ReviewRepository{}
+}
+
+func (*ReviewRepository) Search(
+	ctx context.Context,
+	db *gorm.DB,
+	bookID int,
+	page int,
+	size int,
+) ([]entity.Review, int64, error) {
+	offset := 0
+	if page > 0 {
+		offset = (page - 1) * size
+	}
+
+	reviewsTask := goasync.Spawn(func(ctx context.Context) (reviews []entity.Review, err error) {
+		err = db.InnerJoins("User").
+			Where("reviews.book_id = ?", bookID).
+			Order("reviews.updated_at DESC").
+			Order("reviews.id DESC").
+			Offset(offset).
+			Limit(size).
+			Find(&reviews).Error
+		return
+	})
+
+	totalTask := goasync.Spawn(func(ctx context.Context) (total int64, err error) {
+		err = db.Model(&entity.Review{}).InnerJoins("User").Where("reviews.book_id = ?", bookID).Count(&total).Error
+		return
+	})
+
+	reviews, err := reviewsTask.Await(ctx)
+	if err != nil {
+		gotracing.Error("Failed to find entities from database", err)
+		return nil, 0, err
+	}
+
+	total, err := totalTask.Await(ctx)
+	if err != nil {
+		gotracing.Error("Failed to count entities from database", err)
+		return nil, 0, err
+	}
+
+	return reviews, total, nil
+}
+
+func (*ReviewRepository) FindByID(db *gorm.DB, id int) (*entity.Review, error) {
+	var entity *entity.Review
+	if err := db.InnerJoins("User").Where("reviews.id = ?", id).First(&entity).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			gotracing.Error("Failed to find entity from database", err)
+		}
+		return nil, err
+	}
+	return entity, nil
+}
+
+func (*ReviewRepository) FindByUserIDAndBookID(db *gorm.DB, userID int, bookID int) (*entity.Review, error) {
+	var entity *entity.Review
+	if err := db.InnerJoins("User").
+		Where("reviews.user_id = ? AND reviews.book_id = ?", userID, bookID).
+		First(&entity).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			gotracing.Error("Failed to find entity from database", err)
+		}
+		return nil, err
+	}
+	return entity, nil
+}
+
+func (*ReviewRepository) AggregateRating(db *gorm.DB, bookID int) (float64, int64, error) {
+	var result struct {
+		Average float64
+		Count   int64
+	}
+	if err := db.Model(&entity.Review{}).
+		Select("COALESCE(AVG(rating), 0) AS average, COUNT(*) AS count").
+		Where("book_id = ?", bookID).
+		Scan(&result).Error; err != nil {
+		gotracing.Error("Failed to aggregate entities from database", err)
+		return 0, 0, err
+	}
+	return result.Average, result.Count, nil
+}
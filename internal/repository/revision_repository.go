@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+	"github.com/mnaufalhilmym/goasync"
+	"github.com/mnaufalhilmym/gotracing"
+	"gorm.io/gorm"
+)
+
+type RevisionRepository struct {
+	repository[entity.Revision]
+}
+
+func NewRevisionRepository() *RevisionRepository {
+	return &RevisionRepository{}
+}
+
+// Search returns the revisions of a record, latest first.
+func (*RevisionRepository) Search(
+	ctx context.Context,
+	db *gorm.DB,
+	entityType string,
+	entityID int,
+	page int,
+	size int,
+) ([]entity.Revision, int64, error) {
+	offset := 0
+	if page > 0 {
+		offset = (page - 1) * size
+	}
+
+	revisionsTask := goasync.Spawn(func(ctx context.Context) (revisions []entity.Revision, err error) {
+		err = db.Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+			Order("revision DESC").
+			Offset(offset).
+			Limit(size).
+			Find(&revisions).Error
+		return
+	})
+
+	totalTask := goasync.Spawn(func(ctx context.Context) (total int64, err error) {
+		err = db.Model(&entity.Revision{}).Where("entity_type = ? AND entity_id = ?", entityType, entityID).Count(&total).Error
+		return
+	})
+
+	revisions, err := revisionsTask.Await(ctx)
+	if err != nil {
+		gotracing.Error("Failed to find entities from database", err)
+		return nil, 0, err
+	}
+
+	total, err := totalTask.Await(ctx)
+	if err != nil {
+		gotracing.Error("Failed to count entities from database", err)
+		return nil, 0, err
+	}
+
+	return revisions, total, nil
+}
+
+func (*RevisionRepository) FindByRevision(db *gorm.DB, entityType string, entityID int, revision int) (*entity.Revision, error) {
+	var entity *entity.Revision
+	if err := db.Where("entity_type = ? AND entity_id = ? AND revision = ?", entityType, entityID, revision).
+		First(&entity).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			gotracing.Error("Failed to find entity from database", err)
+		}
+		return nil, err
+	}
+	return entity, nil
+}
+
+func (*RevisionRepository) FindLatest(db *gorm.DB, entityType string, entityID int) (*entity.Revision, error) {
+	var entity *entity.Revision
+	if err := db.Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Order("revision DESC").
+		First(&entity).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			gotracing.Error("Failed to find entity from database", err)
+		}
+		return nil, err
+	}
+	return entity, nil
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+	"github.com/mnaufalhilmym/gotracing"
+	"gorm.io/gorm"
+)
+
+type SchemaMigrationRepository struct {
+	repository[entity.SchemaMigration]
+}
+
+func NewSchemaMigrationRepository() *SchemaMigrationRepository {
+	return &SchemaMigrationRepository{}
+}
+
+// CreateTable creates the table that records applied migrations, the only
+// table that is not created by a migration.
+func (*SchemaMigrationRepository) CreateTable(db *gorm.DB) error {
+	if db.Migrator().HasTable(&entity.SchemaMigration{}) {
+		return nil
+	}
+	if err := db.Migrator().CreateTable(&entity.SchemaMigration{}); err != nil {
+		gotracing.Error("Failed to create table in database", err)
+		return err
+	}
+	return nil
+}
+
+// FindAllApplied returns the applied migrations ordered by version, and none
+// when no migration was ever applied.
+func (*SchemaMigrationRepository) FindAllApplied(db *gorm.DB) ([]entity.SchemaMigration, error) {
+	if !db.Migrator().HasTable(&entity.SchemaMigration{}) {
+		return nil, nil
+	}
+	var entities []entity.SchemaMigration
+	if err := db.Order("version").Find(&entities).Error; err != nil {
+		gotracing.Error("Failed to find entities from database", err)
+		return nil, err
+	}
+	return entities, nil
+}
+
+// DisableForeignKeys stops SQLite from enforcing foreign keys, which it must
+// not while a migration rebuilds a table. Restore enforces them again when
+// they were. SQLite ignores the change inside a transaction.
+func (*SchemaMigrationRepository) DisableForeignKeys(db *gorm.DB) (restore func(), err error) {
+	if db.Dialector.Name() != "sqlite" {
+		return func() {}, nil
+	}
+	var enabled bool
+	if err := db.Raw("PRAGMA foreign_keys").Scan(&enabled).Error; err != nil {
+		gotracing.Error("Failed to read foreign keys setting of database", err)
+		return nil, err
+	}
+	if !enabled {
+		return func() {}, nil
+	}
+	if err := db.Exec("PRAGMA foreign_keys = OFF").Error; err != nil {
+		gotracing.Error("Failed to disable foreign keys of database", err)
+		return nil, err
+	}
+	return func() {
+		if err := db.Exec("PRAGMA foreign_keys = ON").Error; err != nil {
+			gotracing.Error("Failed to enable foreign keys of database", err)
+		}
+	}, nil
+}
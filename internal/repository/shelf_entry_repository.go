@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+	"github.com/mnaufalhilmym/goasync"
+	"github.com/mnaufalhilmym/gotracing"
+	"gorm.io/gorm"
+)
+
+type ShelfEntryRepository struct {
+	repository[entity.ShelfEntry]
+}
+
+func NewShelfEntryRepository() *ShelfEntryRepository {
+	return &ShelfEntryRepository{}
+}
+
+func (*ShelfEntryRepository) Search(
+	ctx context.Context,
+	db *gorm.DB,
+	userID int,
+	shelf string,
+	page int,
+	size int,
+) ([]entity.ShelfEntry, int64, error) {
+	offset := 0
+	if page > 0 {
+		offset = (page - 1) * size
+	}
+
+	filter := func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("shelf_entries.user_id = ? AND shelf_entries.shelf = ?", userID, shelf)
+	}
+
+	entriesTask := goasync.Spawn(func(ctx context.Context) (entries []entity.ShelfEntry, err error) {
+		err = db.InnerJoins("Book").
+			InnerJoins("Book.Author").
+			Scopes(filter).
+			Order("shelf_entries.created_at DESC").
+			Order("shelf_entries.id DESC").
+			Offset(offset).
+			Limit(size).
+			Find(&entries).Error
+		return
+	})
+
+	totalTask := goasync.Spawn(func(ctx context.Context) (total int64, err error) {
+		err = db.Model(&entity.ShelfEntry{}).InnerJoins("Book").Scopes(filter).Count(&total).Error
+		return
+	})
+
+	entries, err := entriesTask.Await(ctx)
+	if err != nil {
+		gotracing.Error("Failed to find entities from database", err)
+		return nil, 0, err
+	}
+
+	total, err := totalTask.Await(ctx)
+	if err != nil {
+		gotracing.Error("Failed to count entities from database", err)
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
+
+func (*ShelfEntryRepository) FindByUserIDAndShelfAndBookID(
+	db *gorm.DB,
+	userID int,
+	shelf string,
+	bookID int,
+) (*entity.ShelfEntry, error) {
+	var entity *entity.ShelfEntry
+	if err := db.Where("user_id = ? AND shelf = ? AND book_id = ?", userID, shelf, bookID).First(&entity).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			gotracing.Error("Failed to find entity from database", err)
+		}
+		return nil, err
+	}
+	return entity, nil
+}
+
+// CountByShelf returns the number of books on each of the user's shelves.
+func (*ShelfEntryRepository) CountByShelf(db *gorm.DB, userID int) (map[string]int64, error) {
+	var rows []struct {
+		Shelf string
+		Count int64
+	}
+	if err := db.Model(&entity.ShelfEntry{}).
+		Select("shelf, COUNT(*) AS count").
+		Where("user_id = ?", userID).
+		Group("shelf").
+		Order("shelf").
+		Scan(&rows).Error; err != nil {
+		gotracing.Error("Failed to aggregate entities from database", err)
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Shelf] = row.Count
+	}
+	return counts, nil
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+	"github.com/mnaufalhilmym/goasync"
+	"github.com/mnaufalhilmym/gotracing"
+	"gorm.io/gorm"
+)
+
+// trashable is embedded by the repositories of entities with a deleted_at
+// column. Queries leave out the rows in the trash, unless they are unscoped.
+type trashable[T any] struct{}
+
+// Trash moves the row of entity to the trash.
+func (*trashable[T]) Trash(db *gorm.DB, entity *T, deletedBy *int) error {
+	if err := db.Model(entity).Updates(map[string]any{
+		"deleted_at": time.Now(),
+		"deleted_by": deletedBy,
+	}).Error; err != nil {
+		gotracing.Error("Failed to update entity to database", err)
+		return err
+	}
+	return nil
+}
+
+func (*trashable[T]) FindTrashedByID(db *gorm.DB, id int) (*T, error) {
+	var entity *T
+	if err := db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&entity).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			gotracing.Error("Failed to find entity from database", err)
+		}
+		return nil, err
+	}
+	return entity, nil
+}
+
+// FindAllTrashedBefore returns the rows moved to the trash before the time.
+func (*trashable[T]) FindAllTrashedBefore(db *gorm.DB, before time.Time) ([]T, error) {
+	var entities []T
+	if err := db.Unscoped().Where("deleted_at < ?", before).Order("id").Find(&entities).Error; err != nil {
+		gotracing.Error("Failed to find entities from database", err)
+		return nil, err
+	}
+	return entities, nil
+}
+
+// Restore takes the row of entity out of the trash.
+func (*trashable[T]) Restore(db *gorm.DB, entity *T) error {
+	if err := db.Unscoped().Model(entity).Updates(map[string]any{
+		"deleted_at": nil,
+		"deleted_by": nil,
+	}).Error; err != nil {
+		gotracing.Error("Failed to update entity to database", err)
+		return err
+	}
+	return nil
+}
+
+// Purge deletes the row of entity for good.
+func (*trashable[T]) Purge(db *gorm.DB, entity *T) error {
+	if err := db.Unscoped().Delete(entity).Error; err != nil {
+		gotracing.Error("Failed to delete entity from database", err)
+		return err
+	}
+	return nil
+}
+
+type TrashRepository struct{}
+
+func NewTrashRepository() *TrashRepository {
+	return &TrashRepository{}
+}
+
+// Search returns the items in the trash of the type, or of every type when
+// itemType is nil, most recently deleted first.
+func (r *TrashRepository) Search(
+	ctx context.Context,
+	db *gorm.DB,
+	itemType *string,
+	page int,
+	size int,
+) ([]entity.TrashItem, int64, error) {
+	offset := 0
+	if page > 0 {
+		offset = (page - 1) * size
+	}
+
+	trash := r.trash(db, itemType)
+
+	itemsTask := goasync.Spawn(func(ctx context.Context) (items []entity.TrashItem, err error) {
+		err = trash().Order("deleted_at DESC").Order("type").Order("id").Offset(offset).Limit(size).Find(&items).Error
+		return
+	})
+
+	totalTask := goasync.Spawn(func(ctx context.Context) (total int64, err error) {
+		err = trash().Count(&total).Error
+		return
+	})
+
+	items, err := itemsTask.Await(ctx)
+	if err != nil {
+		gotracing.Error("Failed to find entities from database", err)
+		return nil, 0, err
+	}
+
+	total, err := totalTask.Await(ctx)
+	if err != nil {
+		gotracing.Error("Failed to count entities from database", err)
+		return nil, 0, err
+	}
+
+	return items, total, nil
+}
+
+// trash returns a function starting a query of the union of the trashed rows
+// of every table, since a query cannot be run twice.
+func (*TrashRepository) trash(db *gorm.DB, itemType *string) func() *gorm.DB {
+	tables := []struct {
+		itemType string
+		model    any
+		name     string
+	}{
+		{entity.TrashItemTypeUser, &entity.User{}, "username"},
+		{entity.TrashItemTypeAuthor, &entity.Author{}, "name"},
+		{entity.TrashItemTypeBook, &entity.Book{}, "title"},
+	}
+
+	return func() *gorm.DB {
+		var selects []string
+		var queries []any
+		for _, table := range tables {
+			if itemType != nil && *itemType != table.itemType {
+				continue
+			}
+			selects = append(selects, "?")
+			queries = append(queries, db.Session(&gorm.Session{NewDB: true}).
+				Unscoped().
+				Model(table.model).
+				Select("? AS type, id, "+table.name+" AS name, deleted_at, deleted_by", table.itemType).
+				Where("deleted_at IS NOT NULL"))
+		}
+		return db.Table("("+strings.Join(selects, " UNION ALL ")+") AS trash", queries...)
+	}
+}
@@ -2,8 +2,6 @@ package repository
 
 import (
 	"errors"
-	"fmt"
-	"strings"
 
 	"github.com/mnaufalhilmym/bookshelf/internal/entity"
 	"github.com/mnaufalhilmym/gotracing"
@@ -12,15 +10,10 @@ import (
 
 type UserRepository struct {
 	repository[entity.User]
+	trashable[entity.User]
 }
 
-func NewUserRepository(db *gorm.DB) *UserRepository {
-	if err := db.Migrator().CreateTable(&entity.User{}); err != nil {
-		if !strings.Contains(err.Error(), "already exists") {
-			panic(fmt.Errorf("failed to migrate entity: %w", err))
-		}
-	}
-
+func NewUserRepository() *UserRepository {
 	return &UserRepository{}
 }
 
@@ -34,3 +27,21 @@ func (*UserRepository) FindByUsername(db *gorm.DB, username string) (*entity.Use
 	}
 	return entity, nil
 }
+
+// Purge deletes the user for good, along with the rows of the user which
+// have no foreign key to it. Rows with one, such as reviews, keep the user
+// in the trash.
+func (r *UserRepository) Purge(db *gorm.DB, user *entity.User) error {
+	for _, owned := range []any{
+		&entity.ShelfEntry{},
+		&entity.ReadingGoal{},
+		&entity.ReadingProgress{},
+		&entity.ImportJob{},
+	} {
+		if err := db.Where("user_id = ?", user.ID).Delete(owned).Error; err != nil {
+			gotracing.Error("Failed to delete entities from database", err)
+			return err
+		}
+	}
+	return r.trashable.Purge(db, user)
+}
@@ -0,0 +1,19 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrNotFound = errors.New("object not found")
+
+// Storage keeps uploaded files as objects addressed by slash separated keys
+// such as "books/1/files/<checksum>.epub".
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	// Open returns ErrNotFound when no object is stored under the key.
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, error)
+	// Delete does not fail when no object is stored under the key.
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,97 @@
+// Package testdb opens empty databases for the test suites. They are SQLite
+// databases in memory, unless BOOKSHELF_TEST_DB_DRIVER and
+// BOOKSHELF_TEST_DB_DSN name a postgres or mysql database server to run the
+// suites against. There each database is a schema, or a database for mysql,
+// which Close drops.
+package testdb
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/mnaufalhilmym/bookshelf/internal/config"
+	"gorm.io/gorm"
+)
+
+var (
+	mu      sync.Mutex
+	server  *gorm.DB
+	created []string
+)
+
+func New() *gorm.DB {
+	driver := os.Getenv("BOOKSHELF_TEST_DB_DRIVER")
+	dsn := os.Getenv("BOOKSHELF_TEST_DB_DSN")
+	if driver == "" || driver == "sqlite" {
+		return config.NewDatabase("sqlite", ":memory:", 1, 1, 100, config.SQLiteOptions{ForeignKeys: true})
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if server == nil {
+		server = config.NewDatabase(driver, dsn, 1, 1, 100, config.SQLiteOptions{})
+	}
+
+	// Packages are tested in parallel processes, which share the server.
+	name := fmt.Sprintf("bookshelf_test_%d_%d", os.Getpid(), len(created)+1)
+	statement := "CREATE SCHEMA " + name
+	if driver == "mysql" {
+		statement = "CREATE DATABASE " + name
+	}
+	if err := server.Exec(statement).Error; err != nil {
+		panic(err)
+	}
+	created = append(created, name)
+
+	switch driver {
+	case "postgres":
+		dsn = withSearchPath(dsn, name)
+	case "mysql":
+		conf, err := mysqldriver.ParseDSN(dsn)
+		if err != nil {
+			panic(err)
+		}
+		conf.DBName = name
+		dsn = conf.FormatDSN()
+	}
+
+	return config.NewDatabase(driver, dsn, 1, 1, 100, config.SQLiteOptions{})
+}
+
+// Close drops the databases created on the server.
+func Close() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if server == nil {
+		return
+	}
+	for _, name := range created {
+		statement := "DROP SCHEMA " + name + " CASCADE"
+		if server.Dialector.Name() == "mysql" {
+			statement = "DROP DATABASE " + name
+		}
+		server.Exec(statement)
+	}
+	created = nil
+}
+
+// withSearchPath sets the schema of a postgres DSN, a URL or key=value pairs.
+func withSearchPath(dsn string, schema string) string {
+	if strings.Contains(dsn, "://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			panic(err)
+		}
+		query := u.Query()
+		query.Set("search_path", schema)
+		u.RawQuery = query.Encode()
+		return u.String()
+	}
+	return dsn + " search_path=" + schema
+}
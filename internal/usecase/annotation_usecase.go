@@ -0,0 +1,256 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/gotracing"
+	"gorm.io/gorm"
+)
+
+type AnnotationUsecase struct {
+	db             *gorm.DB
+	repository     *repository.AnnotationRepository
+	bookRepository *repository.BookRepository
+}
+
+func NewAnnotationUsecase(
+	db *gorm.DB,
+	repository *repository.AnnotationRepository,
+	bookRepository *repository.BookRepository,
+) *AnnotationUsecase {
+	return &AnnotationUsecase{
+		db,
+		repository,
+		bookRepository,
+	}
+}
+
+func (uc *AnnotationUsecase) GetMany(ctx context.Context, request *model.GetManyAnnotationsRequest) ([]model.AnnotationResponse, int64, error) {
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
+	defer tx.Rollback()
+
+	annotations, total, err := uc.repository.Search(
+		ctx,
+		tx,
+		request.ViewerID,
+		request.Query,
+		request.BookID,
+		request.UserID,
+		request.Page,
+		request.Size,
+	)
+	if err != nil {
+		return nil, 0, model.ErrorInternalServerError(errors.New("failed to get many annotations"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, 0, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return model.ToAnnotationsResponse(annotations), total, nil
+}
+
+func (uc *AnnotationUsecase) Get(ctx context.Context, request *model.GetAnnotationRequest) (*model.AnnotationResponse, error) {
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
+	defer tx.Rollback()
+
+	annotation, err := uc.repository.FindByID(tx, request.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrorNotFound(errors.New("annotation not found"))
+		}
+		return nil, model.ErrorInternalServerError(errors.New("failed to find annotation data by id"))
+	}
+
+	if annotation.Visibility != entity.AnnotationVisibilityPublic && annotation.UserID != request.ViewerID {
+		return nil, model.ErrorNotFound(errors.New("annotation not found"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return model.ToAnnotationResponse(annotation), nil
+}
+
+func (uc *AnnotationUsecase) Create(ctx context.Context, request *model.CreateAnnotationRequest) (*model.AnnotationResponse, error) {
+	tx := begin(ctx, uc.db)
+	defer tx.Rollback()
+
+	if _, err := uc.bookRepository.FindByID(tx, request.BookID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrorNotFound(errors.New("book not found"))
+		}
+		return nil, model.ErrorInternalServerError(errors.New("failed to find book data by id"))
+	}
+
+	annotation := &entity.Annotation{
+		UserID:     request.UserID,
+		BookID:     request.BookID,
+		Page:       request.Page,
+		Location:   request.Location,
+		Text:       request.Text,
+		Note:       request.Note,
+		Visibility: request.Visibility,
+	}
+
+	if annotation.Visibility == "" {
+		annotation.Visibility = entity.AnnotationVisibilityPrivate
+	}
+
+	if err := uc.repository.Create(tx, annotation); err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to create new annotation"))
+	}
+
+	annotation, err := uc.repository.FindByID(tx, annotation.ID)
+	if err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to find annotation data by id"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return model.ToAnnotationResponse(annotation), nil
+}
+
+func (uc *AnnotationUsecase) Update(ctx context.Context, request *model.UpdateAnnotationRequest) (*model.AnnotationResponse, error) {
+	tx := begin(ctx, uc.db)
+	defer tx.Rollback()
+
+	annotation, err := uc.repository.FindByID(tx, request.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrorNotFound(errors.New("id not found"))
+		}
+		return nil, model.ErrorInternalServerError(errors.New("failed to find annotation data by id"))
+	}
+
+	if annotation.UserID != request.UserID {
+		return nil, model.ErrorForbidden(errors.New("annotation belongs to another user"))
+	}
+
+	if request.Page != nil {
+		annotation.Page = *request.Page
+	}
+
+	if request.Location != nil {
+		annotation.Location = *request.Location
+	}
+
+	if request.Text != nil && *request.Text != "" {
+		annotation.Text = *request.Text
+	}
+
+	if request.Note != nil {
+		annotation.Note = *request.Note
+	}
+
+	if request.Visibility != nil && *request.Visibility != "" {
+		annotation.Visibility = *request.Visibility
+	}
+
+	if err := uc.repository.Update(tx, annotation); err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to update annotation"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return model.ToAnnotationResponse(annotation), nil
+}
+
+func (uc *AnnotationUsecase) Delete(ctx context.Context, request *model.DeleteAnnotationRequest) (*int, error) {
+	tx := begin(ctx, uc.db)
+	defer tx.Rollback()
+
+	annotation, err := uc.repository.FindByID(tx, request.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrorNotFound(errors.New("id not found"))
+		}
+		return nil, model.ErrorInternalServerError(errors.New("failed to find annotation data by id"))
+	}
+
+	if annotation.UserID != request.UserID {
+		return nil, model.ErrorForbidden(errors.New("annotation belongs to another user"))
+	}
+
+	if err := uc.repository.Delete(tx, annotation); err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to delete annotation"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return &annotation.ID, nil
+}
+
+func (uc *AnnotationUsecase) ExportMarkdown(ctx context.Context, request *model.ExportAnnotationsRequest) ([]byte, error) {
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
+	defer tx.Rollback()
+
+	annotations, err := uc.repository.FindAllByUserID(tx, request.UserID, request.BookID)
+	if err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to find annotations"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return renderAnnotationsMarkdown(annotations), nil
+}
+
+func renderAnnotationsMarkdown(annotations []entity.Annotation) []byte {
+	var sb strings.Builder
+	sb.WriteString("# Annotations\n")
+
+	bookID := 0
+	for _, annotation := range annotations {
+		if annotation.BookID != bookID {
+			bookID = annotation.BookID
+			fmt.Fprintf(&sb, "\n## %s\n", annotation.Book.Title)
+			if annotation.Book.Author.Name != "" {
+				fmt.Fprintf(&sb, "\n*%s*\n", annotation.Book.Author.Name)
+			}
+		}
+
+		sb.WriteString("\n")
+		for _, line := range strings.Split(strings.TrimSpace(annotation.Text), "\n") {
+			fmt.Fprintf(&sb, "> %s\n", line)
+		}
+
+		var position []string
+		if annotation.Page > 0 {
+			position = append(position, fmt.Sprintf("page %d", annotation.Page))
+		}
+		if annotation.Location != "" {
+			position = append(position, "location "+annotation.Location)
+		}
+		if len(position) > 0 {
+			fmt.Fprintf(&sb, "\n— %s\n", strings.Join(position, ", "))
+		}
+
+		if note := strings.TrimSpace(annotation.Note); note != "" {
+			fmt.Fprintf(&sb, "\n**Note:** %s\n", note)
+		}
+	}
+
+	return []byte(sb.String())
+}
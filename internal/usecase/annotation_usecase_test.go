@@ -0,0 +1,206 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+	"github.com/mnaufalhilmym/bookshelf/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+type annotationFixture struct {
+	annotationUc *usecase.AnnotationUsecase
+	user1        *model.UserResponse
+	user2        *model.UserResponse
+	book         *model.BookResponse
+}
+
+func newAnnotationFixture(t *testing.T) *annotationFixture {
+	db := newDatabase()
+	userRepo := repository.NewUserRepository()
+	authorRepo := repository.NewAuthorRepository()
+	bookRepo := repository.NewBookRepository()
+	annotationRepo := repository.NewAnnotationRepository()
+
+	userUc := usecase.NewUserUsecase(db, userRepo, "jwtKey", 10*time.Second)
+	authorUc := usecase.NewAuthorUsecase(db, authorRepo, bookRepo, repository.NewRevisionRepository())
+	bookUc := usecase.NewBookUsecase(db, bookRepo, authorRepo, repository.NewRevisionRepository())
+
+	f := &annotationFixture{
+		annotationUc: usecase.NewAnnotationUsecase(db, annotationRepo, bookRepo),
+	}
+
+	var err error
+	f.user1, err = userUc.Register(context.Background(), &model.RegisterUserRequest{
+		Username: "reader_1",
+		Password: "password",
+	})
+	assert.NoError(t, err)
+	f.user2, err = userUc.Register(context.Background(), &model.RegisterUserRequest{
+		Username: "reader_2",
+		Password: "password",
+	})
+	assert.NoError(t, err)
+
+	author, err := authorUc.Create(context.Background(), &model.CreateAuthorRequest{
+		Name:      "George Orwell",
+		Birthdate: time.Date(1903, 6, 25, 0, 0, 0, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+
+	f.book, err = bookUc.Create(context.Background(), &model.CreateBookRequest{
+		Title:    "Nineteen Eighty-Four",
+		ISBN:     "978-0451524935",
+		AuthorID: author.ID,
+	})
+	assert.NoError(t, err)
+
+	return f
+}
+
+func TestAnnotationUsecase_Create(t *testing.T) {
+	f := newAnnotationFixture(t)
+
+	t.Run("Positive Case - create private annotation", func(t *testing.T) {
+		resp, err := f.annotationUc.Create(context.Background(), &model.CreateAnnotationRequest{
+			UserID: f.user1.ID,
+			BookID: f.book.ID,
+			Page:   3,
+			Text:   "It was a bright cold day in April, and the clocks were striking thirteen.",
+		})
+		assert.NoError(t, err)
+		assert.EqualValues(t, "private", resp.Visibility)
+		assert.EqualValues(t, f.book.Title, resp.BookTitle)
+		assert.EqualValues(t, f.user1.Username, resp.Username)
+	})
+
+	t.Run("Negative Case - book not found", func(t *testing.T) {
+		resp, err := f.annotationUc.Create(context.Background(), &model.CreateAnnotationRequest{
+			UserID: f.user1.ID,
+			BookID: 0,
+			Text:   "quote",
+		})
+		assert.EqualValues(t, model.ErrorNotFound(errors.New("book not found")), err)
+		assert.Nil(t, resp)
+	})
+}
+
+func TestAnnotationUsecase_GetMany(t *testing.T) {
+	f := newAnnotationFixture(t)
+
+	private, err := f.annotationUc.Create(context.Background(), &model.CreateAnnotationRequest{
+		UserID: f.user1.ID,
+		BookID: f.book.ID,
+		Text:   "Big Brother is watching you.",
+	})
+	assert.NoError(t, err)
+	public, err := f.annotationUc.Create(context.Background(), &model.CreateAnnotationRequest{
+		UserID:     f.user2.ID,
+		BookID:     f.book.ID,
+		Text:       "War is peace.",
+		Note:       "Doublethink",
+		Visibility: "public",
+	})
+	assert.NoError(t, err)
+
+	t.Run("Positive Case 1 - owner sees private and public annotations", func(t *testing.T) {
+		request := &model.GetManyAnnotationsRequest{ViewerID: f.user1.ID, BookID: &f.book.ID}
+		request.Page = 1
+		request.Size = 10
+
+		resp, total, err := f.annotationUc.GetMany(context.Background(), request)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, total)
+		assert.EqualValues(t, private.ID, resp[0].ID)
+		assert.EqualValues(t, public.ID, resp[1].ID)
+	})
+
+	t.Run("Positive Case 2 - other users see public annotations only", func(t *testing.T) {
+		request := &model.GetManyAnnotationsRequest{ViewerID: f.user2.ID}
+		request.Page = 1
+		request.Size = 10
+
+		resp, total, err := f.annotationUc.GetMany(context.Background(), request)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, total)
+		assert.EqualValues(t, public.ID, resp[0].ID)
+	})
+
+	t.Run("Positive Case 3 - search by note", func(t *testing.T) {
+		request := &model.GetManyAnnotationsRequest{ViewerID: f.user1.ID, Query: util.ToPointer("doublethink")}
+		request.Page = 1
+		request.Size = 10
+
+		resp, total, err := f.annotationUc.GetMany(context.Background(), request)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, total)
+		assert.EqualValues(t, public.ID, resp[0].ID)
+	})
+
+	t.Run("Negative Case - private annotation of another user", func(t *testing.T) {
+		resp, err := f.annotationUc.Get(context.Background(), &model.GetAnnotationRequest{
+			ViewerID: f.user2.ID,
+			ID:       private.ID,
+		})
+		assert.EqualValues(t, model.ErrorNotFound(errors.New("annotation not found")), err)
+		assert.Nil(t, resp)
+	})
+}
+
+func TestAnnotationUsecase_Update(t *testing.T) {
+	f := newAnnotationFixture(t)
+
+	annotation, err := f.annotationUc.Create(context.Background(), &model.CreateAnnotationRequest{
+		UserID: f.user1.ID,
+		BookID: f.book.ID,
+		Text:   "Freedom is slavery.",
+	})
+	assert.NoError(t, err)
+
+	t.Run("Positive Case - publish annotation", func(t *testing.T) {
+		resp, err := f.annotationUc.Update(context.Background(), &model.UpdateAnnotationRequest{
+			UserID:     f.user1.ID,
+			ID:         annotation.ID,
+			Visibility: util.ToPointer("public"),
+		})
+		assert.NoError(t, err)
+		assert.EqualValues(t, "public", resp.Visibility)
+	})
+
+	t.Run("Negative Case - another user's annotation", func(t *testing.T) {
+		resp, err := f.annotationUc.Delete(context.Background(), &model.DeleteAnnotationRequest{
+			UserID: f.user2.ID,
+			ID:     annotation.ID,
+		})
+		assert.EqualValues(t, model.ErrorForbidden(errors.New("annotation belongs to another user")), err)
+		assert.Nil(t, resp)
+	})
+}
+
+func TestAnnotationUsecase_ExportMarkdown(t *testing.T) {
+	f := newAnnotationFixture(t)
+
+	_, err := f.annotationUc.Create(context.Background(), &model.CreateAnnotationRequest{
+		UserID:   f.user1.ID,
+		BookID:   f.book.ID,
+		Page:     5,
+		Location: "120-122",
+		Text:     "Ignorance is strength.",
+		Note:     "Party slogan",
+	})
+	assert.NoError(t, err)
+
+	resp, err := f.annotationUc.ExportMarkdown(context.Background(), &model.ExportAnnotationsRequest{UserID: f.user1.ID})
+	assert.NoError(t, err)
+	assert.EqualValues(t, "# Annotations\n"+
+		"\n## Nineteen Eighty-Four\n"+
+		"\n*George Orwell*\n"+
+		"\n> Ignorance is strength.\n"+
+		"\n— page 5, location 120-122\n"+
+		"\n**Note:** Party slogan\n", string(resp))
+}
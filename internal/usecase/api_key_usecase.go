@@ -0,0 +1,145 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/gotracing"
+	"gorm.io/gorm"
+)
+
+// APIKeyPrefix starts every API key, so keys are recognizable when they leak
+// and can be told apart from passwords.
+const APIKeyPrefix = "bks_"
+
+type APIKeyUsecase struct {
+	db         *gorm.DB
+	repository *repository.APIKeyRepository
+}
+
+func NewAPIKeyUsecase(
+	db *gorm.DB,
+	repository *repository.APIKeyRepository,
+) *APIKeyUsecase {
+	return &APIKeyUsecase{
+		db,
+		repository,
+	}
+}
+
+func (uc *APIKeyUsecase) GetMany(ctx context.Context, request *model.GetManyAPIKeysRequest) ([]model.APIKeyResponse, error) {
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
+	defer tx.Rollback()
+
+	apiKeys, err := uc.repository.FindAllByUserID(tx, request.UserID)
+	if err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to get api keys"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return model.ToAPIKeysResponse(apiKeys), nil
+}
+
+func (uc *APIKeyUsecase) Create(ctx context.Context, request *model.CreateAPIKeyRequest) (*model.CreateAPIKeyResponse, error) {
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		gotracing.Error("Failed to generate api key", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to generate api key"))
+	}
+	key := APIKeyPrefix + hex.EncodeToString(secret)
+
+	tx := begin(ctx, uc.db)
+	defer tx.Rollback()
+
+	apiKey := &entity.APIKey{
+		UserID:  request.UserID,
+		Name:    request.Name,
+		Prefix:  key[:len(APIKeyPrefix)+8],
+		KeyHash: hashAPIKey(key),
+	}
+
+	if err := uc.repository.Create(tx, apiKey); err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to create new api key"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return &model.CreateAPIKeyResponse{
+		APIKeyResponse: *model.ToAPIKeyResponse(apiKey),
+		Key:            key,
+	}, nil
+}
+
+func (uc *APIKeyUsecase) Delete(ctx context.Context, request *model.DeleteAPIKeyRequest) (*int, error) {
+	tx := begin(ctx, uc.db)
+	defer tx.Rollback()
+
+	apiKey, err := uc.repository.FindByUserIDAndID(tx, request.UserID, request.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrorNotFound(errors.New("api key not found"))
+		}
+		return nil, model.ErrorInternalServerError(errors.New("failed to find api key data by id"))
+	}
+
+	if err := uc.repository.Delete(tx, apiKey); err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to delete api key"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return &apiKey.ID, nil
+}
+
+// Authenticate returns the owner of the key and records when it was used.
+func (uc *APIKeyUsecase) Authenticate(ctx context.Context, key string) (*model.UserResponse, error) {
+	if !strings.HasPrefix(key, APIKeyPrefix) {
+		return nil, model.ErrorUnauthorized(errors.New("invalid api key"))
+	}
+
+	tx := begin(ctx, uc.db)
+	defer tx.Rollback()
+
+	apiKey, err := uc.repository.FindByKeyHash(tx, hashAPIKey(key))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrorUnauthorized(errors.New("invalid api key"))
+		}
+		return nil, model.ErrorInternalServerError(errors.New("failed to find api key data"))
+	}
+
+	if err := uc.repository.UpdateLastUsedAt(tx, apiKey.ID, time.Now()); err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to update api key data"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return model.ToUserResponse(&apiKey.User), nil
+}
+
+func hashAPIKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,71 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeyUsecase(t *testing.T) {
+	db := newDatabase()
+	userUc := usecase.NewUserUsecase(db, repository.NewUserRepository(), "jwtKey", 10*time.Second)
+	apiKeyUc := usecase.NewAPIKeyUsecase(db, repository.NewAPIKeyRepository())
+
+	user, err := userUc.Register(context.Background(), &model.RegisterUserRequest{
+		Username: "reader",
+		Password: "password",
+	})
+	assert.NoError(t, err)
+
+	var created *model.CreateAPIKeyResponse
+
+	t.Run("Positive Case - create", func(t *testing.T) {
+		created, err = apiKeyUc.Create(context.Background(), &model.CreateAPIKeyRequest{
+			UserID: user.ID,
+			Name:   "KOReader",
+		})
+		assert.NoError(t, err)
+
+		assert.True(t, strings.HasPrefix(created.Key, usecase.APIKeyPrefix))
+		assert.True(t, strings.HasPrefix(created.Key, created.Prefix))
+		assert.EqualValues(t, "KOReader", created.Name)
+		assert.Nil(t, created.LastUsedAt)
+	})
+
+	t.Run("Positive Case - authenticate", func(t *testing.T) {
+		res, err := apiKeyUc.Authenticate(context.Background(), created.Key)
+		assert.NoError(t, err)
+		assert.EqualValues(t, user, res)
+
+		keys, err := apiKeyUc.GetMany(context.Background(), &model.GetManyAPIKeysRequest{UserID: user.ID})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, len(keys))
+		assert.NotNil(t, keys[0].LastUsedAt)
+	})
+
+	t.Run("Negative Case - unknown key", func(t *testing.T) {
+		_, err := apiKeyUc.Authenticate(context.Background(), created.Key+"0")
+		assert.EqualValues(t, model.ErrorUnauthorized(errors.New("invalid api key")), err)
+	})
+
+	t.Run("Negative Case - delete key of another user", func(t *testing.T) {
+		_, err := apiKeyUc.Delete(context.Background(), &model.DeleteAPIKeyRequest{UserID: user.ID + 1, ID: created.ID})
+		assert.EqualValues(t, model.ErrorNotFound(errors.New("api key not found")), err)
+	})
+
+	t.Run("Positive Case - deleted key is rejected", func(t *testing.T) {
+		id, err := apiKeyUc.Delete(context.Background(), &model.DeleteAPIKeyRequest{UserID: user.ID, ID: created.ID})
+		assert.NoError(t, err)
+		assert.EqualValues(t, created.ID, *id)
+
+		_, err = apiKeyUc.Authenticate(context.Background(), created.Key)
+		assert.EqualValues(t, model.ErrorUnauthorized(errors.New("invalid api key")), err)
+	})
+}
@@ -3,6 +3,7 @@ package usecase
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 
 	"github.com/mnaufalhilmym/bookshelf/internal/entity"
@@ -13,22 +14,28 @@ import (
 )
 
 type AuthorUsecase struct {
-	db         *gorm.DB
-	repository *repository.AuthorRepository
+	db                 *gorm.DB
+	repository         *repository.AuthorRepository
+	bookRepository     *repository.BookRepository
+	revisionRepository *repository.RevisionRepository
 }
 
 func NewAuthorUsecase(
 	db *gorm.DB,
 	repository *repository.AuthorRepository,
+	bookRepository *repository.BookRepository,
+	revisionRepository *repository.RevisionRepository,
 ) *AuthorUsecase {
 	return &AuthorUsecase{
 		db,
 		repository,
+		bookRepository,
+		revisionRepository,
 	}
 }
 
 func (uc *AuthorUsecase) GetMany(ctx context.Context, request *model.GetManyAuthorsRequest) ([]model.AuthorResponse, int64, error) {
-	tx := uc.db.WithContext(ctx).Begin(&sql.TxOptions{ReadOnly: true})
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
 	defer tx.Rollback()
 
 	authors, total, err := uc.repository.Search(
@@ -53,7 +60,7 @@ func (uc *AuthorUsecase) GetMany(ctx context.Context, request *model.GetManyAuth
 }
 
 func (uc *AuthorUsecase) Get(ctx context.Context, request *model.GetAuthorRequest) (*model.AuthorResponse, error) {
-	tx := uc.db.WithContext(ctx).Begin(&sql.TxOptions{ReadOnly: true})
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
 	defer tx.Rollback()
 
 	author, err := uc.repository.FindByID(tx, request.ID)
@@ -73,7 +80,7 @@ func (uc *AuthorUsecase) Get(ctx context.Context, request *model.GetAuthorReques
 }
 
 func (uc *AuthorUsecase) Create(ctx context.Context, request *model.CreateAuthorRequest) (*model.AuthorResponse, error) {
-	tx := uc.db.WithContext(ctx).Begin()
+	tx := begin(ctx, uc.db)
 	defer tx.Rollback()
 
 	author := &entity.Author{
@@ -94,7 +101,7 @@ func (uc *AuthorUsecase) Create(ctx context.Context, request *model.CreateAuthor
 }
 
 func (uc *AuthorUsecase) Update(ctx context.Context, request *model.UpdateAuthorRequest) (*model.AuthorResponse, error) {
-	tx := uc.db.WithContext(ctx).Begin()
+	tx := begin(ctx, uc.db)
 	defer tx.Rollback()
 
 	author, err := uc.repository.FindByID(tx, request.ID)
@@ -105,6 +112,12 @@ func (uc *AuthorUsecase) Update(ctx context.Context, request *model.UpdateAuthor
 		return nil, model.ErrorInternalServerError(errors.New("failed to find author data by id"))
 	}
 
+	if request.IfMatch != "" && !model.MatchETag(request.IfMatch, model.ToAuthorResponse(author)) {
+		return nil, model.ErrorPreconditionFailed(errors.New("author has been modified"))
+	}
+
+	before := newAuthorSnapshot(author)
+
 	if request.Name != nil && *request.Name != "" {
 		author.Name = *request.Name
 	}
@@ -114,9 +127,110 @@ func (uc *AuthorUsecase) Update(ctx context.Context, request *model.UpdateAuthor
 	}
 
 	if err := uc.repository.Update(tx, author); err != nil {
+		if errors.Is(err, repository.ErrStaleEntity) {
+			return nil, model.ErrorPreconditionFailed(errors.New("author has been modified"))
+		}
+		return nil, model.ErrorInternalServerError(errors.New("failed to update author"))
+	}
+
+	if err := recordRevision(tx, uc.revisionRepository, entity.RevisionEntityAuthor, author.ID, before, newAuthorSnapshot(author), request.UpdatedBy); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return model.ToAuthorResponse(author), nil
+}
+
+func (uc *AuthorUsecase) GetRevisions(ctx context.Context, request *model.GetManyRevisionsRequest) ([]model.RevisionResponse, int64, error) {
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
+	defer tx.Rollback()
+
+	if _, err := uc.repository.FindByID(tx, request.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, 0, model.ErrorNotFound(errors.New("author not found"))
+		}
+		return nil, 0, model.ErrorInternalServerError(errors.New("failed to find author data by id"))
+	}
+
+	revisions, total, err := getRevisions(ctx, tx, uc.revisionRepository, entity.RevisionEntityAuthor, request)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, 0, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return revisions, total, nil
+}
+
+func (uc *AuthorUsecase) DiffRevisions(ctx context.Context, request *model.DiffRevisionsRequest) (*model.RevisionDiffResponse, error) {
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
+	defer tx.Rollback()
+
+	if _, err := uc.repository.FindByID(tx, request.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrorNotFound(errors.New("author not found"))
+		}
+		return nil, model.ErrorInternalServerError(errors.New("failed to find author data by id"))
+	}
+
+	diff, err := diffRevisions(tx, uc.revisionRepository, entity.RevisionEntityAuthor, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return diff, nil
+}
+
+// RestoreRevision rolls the author back to a revision, which is recorded as a
+// new revision.
+func (uc *AuthorUsecase) RestoreRevision(ctx context.Context, request *model.RestoreRevisionRequest) (*model.AuthorResponse, error) {
+	tx := begin(ctx, uc.db)
+	defer tx.Rollback()
+
+	author, err := uc.repository.FindByID(tx, request.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrorNotFound(errors.New("author not found"))
+		}
+		return nil, model.ErrorInternalServerError(errors.New("failed to find author data by id"))
+	}
+
+	revision, err := findRevision(tx, uc.revisionRepository, entity.RevisionEntityAuthor, author.ID, request.Revision)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := new(authorSnapshot)
+	if err := json.Unmarshal([]byte(revision.Data), snapshot); err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to decode revision"))
+	}
+
+	before := newAuthorSnapshot(author)
+	snapshot.apply(author)
+
+	if err := uc.repository.Update(tx, author); err != nil {
+		if errors.Is(err, repository.ErrStaleEntity) {
+			return nil, model.ErrorPreconditionFailed(errors.New("author has been modified"))
+		}
 		return nil, model.ErrorInternalServerError(errors.New("failed to update author"))
 	}
 
+	if err := recordRevision(tx, uc.revisionRepository, entity.RevisionEntityAuthor, author.ID, before, snapshot, request.RestoredBy); err != nil {
+		return nil, err
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		gotracing.Error("Failed to commit transaction", err)
 		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
@@ -125,8 +239,19 @@ func (uc *AuthorUsecase) Update(ctx context.Context, request *model.UpdateAuthor
 	return model.ToAuthorResponse(author), nil
 }
 
+// Delete refuses to delete an author who still has books, unless the request
+// asks to delete the books too or to move them to another author.
 func (uc *AuthorUsecase) Delete(ctx context.Context, request *model.DeleteAuthorRequest) (*int, error) {
-	tx := uc.db.WithContext(ctx).Begin()
+	if request.ReassignTo != nil {
+		if request.Cascade {
+			return nil, model.ErrorBadRequest(errors.New("cascade and reassign_to cannot be used together"))
+		}
+		if *request.ReassignTo == request.ID {
+			return nil, model.ErrorBadRequest(errors.New("cannot reassign books to the author being deleted"))
+		}
+	}
+
+	tx := begin(ctx, uc.db)
 	defer tx.Rollback()
 
 	author, err := uc.repository.FindByID(tx, request.ID)
@@ -137,7 +262,42 @@ func (uc *AuthorUsecase) Delete(ctx context.Context, request *model.DeleteAuthor
 		return nil, model.ErrorInternalServerError(errors.New("failed to find author data by id"))
 	}
 
-	if err := uc.repository.Delete(tx, author); err != nil {
+	if request.IfMatch != "" && !model.MatchETag(request.IfMatch, model.ToAuthorResponse(author)) {
+		return nil, model.ErrorPreconditionFailed(errors.New("author has been modified"))
+	}
+
+	if request.ReassignTo != nil {
+		if _, err := uc.repository.FindByID(tx, *request.ReassignTo); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, model.ErrorNotFound(errors.New("reassign_to author not found"))
+			}
+			return nil, model.ErrorInternalServerError(errors.New("failed to find author data by id"))
+		}
+	}
+
+	books, err := uc.bookRepository.FindAllByAuthorID(tx, author.ID)
+	if err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to find books of author"))
+	}
+
+	if len(books) > 0 {
+		switch {
+		case request.ReassignTo != nil:
+			if err := uc.reassignBooks(tx, author.ID, *request.ReassignTo, request.DeletedBy); err != nil {
+				return nil, err
+			}
+		case request.Cascade:
+			for i := range books {
+				if err := uc.bookRepository.Trash(tx, &books[i], request.DeletedBy); err != nil {
+					return nil, model.ErrorInternalServerError(errors.New("failed to delete books"))
+				}
+			}
+		default:
+			return nil, model.ErrorConflictWithData(errors.New("author still has books"), model.ToBooksResponse(books))
+		}
+	}
+
+	if err := uc.repository.Trash(tx, author, request.DeletedBy); err != nil {
 		return nil, model.ErrorInternalServerError(errors.New("failed to delete author"))
 	}
 
@@ -148,3 +308,27 @@ func (uc *AuthorUsecase) Delete(ctx context.Context, request *model.DeleteAuthor
 
 	return &author.ID, nil
 }
+
+// reassignBooks moves all books of an author, those in the trash too, to
+// another author. Every book is updated and gets a revision, as when it is
+// edited.
+func (uc *AuthorUsecase) reassignBooks(tx *gorm.DB, fromAuthorID int, toAuthorID int, reassignedBy *int) error {
+	books, err := uc.bookRepository.FindAllByAuthorID(tx.Unscoped(), fromAuthorID)
+	if err != nil {
+		return model.ErrorInternalServerError(errors.New("failed to find books of author"))
+	}
+
+	for i := range books {
+		before := newBookSnapshot(&books[i])
+		books[i].AuthorID = toAuthorID
+
+		if err := uc.bookRepository.Update(tx.Unscoped(), &books[i]); err != nil {
+			return model.ErrorInternalServerError(errors.New("failed to reassign books"))
+		}
+
+		if err := recordRevision(tx, uc.revisionRepository, entity.RevisionEntityBook, books[i].ID, before, newBookSnapshot(&books[i]), reassignedBy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
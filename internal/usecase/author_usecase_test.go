@@ -3,27 +3,28 @@ package usecase_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
-	"github.com/mnaufalhilmym/bookshelf/internal/config"
 	"github.com/mnaufalhilmym/bookshelf/internal/model"
 	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/bookshelf/internal/testdb"
 	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
 	"github.com/mnaufalhilmym/bookshelf/internal/util"
 	"github.com/stretchr/testify/assert"
 )
 
 func newAuthorUsecase() *usecase.AuthorUsecase {
-	db := config.NewDatabase(":memory:", 1, 1, 100)
-	repo := repository.NewAuthorRepository(db)
-	return usecase.NewAuthorUsecase(db, repo)
+	db := newDatabase()
+	repo := repository.NewAuthorRepository()
+	return usecase.NewAuthorUsecase(db, repo, repository.NewBookRepository(), repository.NewRevisionRepository())
 }
 
 func newFailAuthorUsecase() *usecase.AuthorUsecase {
-	db := config.NewDatabase(":memory:", 1, 1, 100)
+	db := testdb.New()
 	repo := &repository.AuthorRepository{}
-	return usecase.NewAuthorUsecase(db, repo)
+	return usecase.NewAuthorUsecase(db, repo, &repository.BookRepository{}, &repository.RevisionRepository{})
 }
 
 func TestAuthorUsecase_GetMany(t *testing.T) {
@@ -502,4 +503,208 @@ func TestAuthorUsecase_Delete(t *testing.T) {
 		assert.EqualValues(t, returned.err, err)
 		assert.EqualValues(t, returned.data, resp)
 	})
+
+	authorUc, bookUc := newAuthorAndBookUsecase()
+	author1, err := authorUc.Create(context.Background(), &model.CreateAuthorRequest{
+		Name: "Author Name 1",
+	})
+	assert.NoError(t, err)
+	author2, err := authorUc.Create(context.Background(), &model.CreateAuthorRequest{
+		Name: "Author Name 2",
+	})
+	assert.NoError(t, err)
+	book, err := bookUc.Create(context.Background(), &model.CreateBookRequest{
+		Title:    "Book Title",
+		ISBN:     "978-0451524935",
+		AuthorID: author1.ID,
+	})
+	assert.NoError(t, err)
+
+	t.Run("Negative Case 3 - author has books", func(t *testing.T) {
+		params := params{
+			ctx: context.Background(),
+			request: &model.DeleteAuthorRequest{
+				ID: author1.ID,
+			},
+		}
+		returned := returned{
+			data: nil,
+			err:  model.ErrorConflictWithData(errors.New("author still has books"), []model.BookResponse{*book}),
+		}
+
+		resp, err := authorUc.Delete(params.ctx, params.request)
+		assert.EqualValues(t, returned.err, err)
+		assert.EqualValues(t, returned.data, resp)
+	})
+
+	t.Run("Negative Case 4 - cascade and reassign", func(t *testing.T) {
+		params := params{
+			ctx: context.Background(),
+			request: &model.DeleteAuthorRequest{
+				ID:         author1.ID,
+				Cascade:    true,
+				ReassignTo: &author2.ID,
+			},
+		}
+		returned := returned{
+			data: nil,
+			err:  model.ErrorBadRequest(errors.New("cascade and reassign_to cannot be used together")),
+		}
+
+		resp, err := authorUc.Delete(params.ctx, params.request)
+		assert.EqualValues(t, returned.err, err)
+		assert.EqualValues(t, returned.data, resp)
+	})
+
+	t.Run("Negative Case 5 - reassign to unknown author", func(t *testing.T) {
+		params := params{
+			ctx: context.Background(),
+			request: &model.DeleteAuthorRequest{
+				ID:         author1.ID,
+				ReassignTo: util.ToPointer(100),
+			},
+		}
+		returned := returned{
+			data: nil,
+			err:  model.ErrorNotFound(errors.New("reassign_to author not found")),
+		}
+
+		resp, err := authorUc.Delete(params.ctx, params.request)
+		assert.EqualValues(t, returned.err, err)
+		assert.EqualValues(t, returned.data, resp)
+	})
+
+	t.Run("Positive Case 2 - reassign books", func(t *testing.T) {
+		params := params{
+			ctx: context.Background(),
+			request: &model.DeleteAuthorRequest{
+				ID:         author1.ID,
+				ReassignTo: &author2.ID,
+			},
+		}
+		returned := returned{
+			data: &author1.ID,
+			err:  nil,
+		}
+
+		resp, err := authorUc.Delete(params.ctx, params.request)
+		assert.EqualValues(t, returned.err, err)
+		assert.EqualValues(t, returned.data, resp)
+
+		reassigned, err := bookUc.Get(context.Background(), &model.GetBookRequest{ID: book.ID})
+		assert.NoError(t, err)
+		assert.EqualValues(t, author2.ID, reassigned.AuthorID)
+		assert.EqualValues(t, author2.Name, reassigned.AuthorName)
+
+		request := &model.GetManyRevisionsRequest{ID: book.ID}
+		request.Page = 1
+		request.Size = 10
+		revisions, total, err := bookUc.GetRevisions(context.Background(), request)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, total)
+		assert.JSONEq(t, fmt.Sprintf(`{"title":"Book Title","isbn":"978-0451524935","author_id":%d,"page_count":0,"language":"","series":"","series_index":0}`, author2.ID), string(revisions[0].Data))
+	})
+
+	t.Run("Positive Case 3 - cascade", func(t *testing.T) {
+		params := params{
+			ctx: context.Background(),
+			request: &model.DeleteAuthorRequest{
+				ID:      author2.ID,
+				Cascade: true,
+			},
+		}
+		returned := returned{
+			data: &author2.ID,
+			err:  nil,
+		}
+
+		resp, err := authorUc.Delete(params.ctx, params.request)
+		assert.EqualValues(t, returned.err, err)
+		assert.EqualValues(t, returned.data, resp)
+
+		_, err = bookUc.Get(context.Background(), &model.GetBookRequest{ID: book.ID})
+		assert.EqualValues(t, model.ErrorNotFound(errors.New("book not found")), err)
+	})
+}
+
+func TestAuthorUsecase_IfMatch(t *testing.T) {
+	authorUc, _ := newAuthorAndBookUsecase()
+
+	author, err := authorUc.Create(context.Background(), &model.CreateAuthorRequest{Name: "Author Name 1"})
+	assert.NoError(t, err)
+	etag := model.ETag(author)
+
+	t.Run("Positive Case 1 - update with current etag", func(t *testing.T) {
+		res, err := authorUc.Update(context.Background(), &model.UpdateAuthorRequest{
+			ID:      author.ID,
+			Name:    util.ToPointer("Author Name 2"),
+			IfMatch: etag,
+		})
+		assert.NoError(t, err)
+		etag = model.ETag(res)
+	})
+
+	t.Run("Negative Case 1 - update with stale etag", func(t *testing.T) {
+		_, err := authorUc.Update(context.Background(), &model.UpdateAuthorRequest{
+			ID:      author.ID,
+			Name:    util.ToPointer("Author Name 3"),
+			IfMatch: model.ETag(author),
+		})
+		assert.EqualValues(t, model.ErrorPreconditionFailed(errors.New("author has been modified")), err)
+	})
+
+	t.Run("Positive Case 2 - delete with one of the etags", func(t *testing.T) {
+		id, err := authorUc.Delete(context.Background(), &model.DeleteAuthorRequest{
+			ID:      author.ID,
+			IfMatch: model.ETag(author) + ", " + etag,
+		})
+		assert.NoError(t, err)
+		assert.EqualValues(t, author.ID, *id)
+	})
+}
+
+func TestAuthorUsecase_Revisions(t *testing.T) {
+	uc := newAuthorUsecase()
+
+	author, err := uc.Create(context.Background(), &model.CreateAuthorRequest{
+		Name:      "Author Name 1",
+		Birthdate: time.Date(2011, 1, 11, 1, 11, 11, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+
+	_, err = uc.Update(context.Background(), &model.UpdateAuthorRequest{
+		ID:        author.ID,
+		Name:      util.ToPointer("Author Name 1 (Typo)"),
+		Birthdate: util.ToPointer(time.Date(2012, 2, 12, 2, 12, 12, 0, time.UTC)),
+	})
+	assert.NoError(t, err)
+
+	t.Run("Positive Case 1 - diff", func(t *testing.T) {
+		res, err := uc.DiffRevisions(context.Background(), &model.DiffRevisionsRequest{ID: author.ID, From: 1, To: 2})
+		assert.NoError(t, err)
+		assert.EqualValues(t, []model.RevisionChangeResponse{
+			{Field: "birthdate", From: "2011-01-11T01:11:11Z", To: "2012-02-12T02:12:12Z"},
+			{Field: "name", From: "Author Name 1", To: "Author Name 1 (Typo)"},
+		}, res.Changes)
+	})
+
+	t.Run("Negative Case 1 - author not found", func(t *testing.T) {
+		_, err := uc.RestoreRevision(context.Background(), &model.RestoreRevisionRequest{ID: 99, Revision: 1})
+		assert.EqualValues(t, model.ErrorNotFound(errors.New("author not found")), err)
+	})
+
+	t.Run("Positive Case 2 - restore", func(t *testing.T) {
+		res, err := uc.RestoreRevision(context.Background(), &model.RestoreRevisionRequest{ID: author.ID, Revision: 1})
+		assert.NoError(t, err)
+		assert.EqualValues(t, "Author Name 1", res.Name)
+		assert.True(t, time.Date(2011, 1, 11, 1, 11, 11, 0, time.UTC).Equal(res.Birthdate))
+
+		request := &model.GetManyRevisionsRequest{ID: author.ID}
+		request.Page = 1
+		request.Size = 10
+		revisions, total, err := uc.GetRevisions(context.Background(), request)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 3, total)
+		assert.EqualValues(t, revisions[2].Data, revisions[0].Data)
+	})
 }
@@ -0,0 +1,260 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/backup"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/gotracing"
+	"gorm.io/gorm"
+)
+
+type BackupUsecase struct {
+	db           *gorm.DB
+	repository   *repository.BackupRepository
+	store        *backup.Store
+	databasePath string
+	keep         int
+	maxAge       time.Duration
+	mu           sync.Mutex
+}
+
+// NewBackupUsecase keeps snapshots of the database at databasePath in store,
+// which is empty for databases other than SQLite.
+// Creating a snapshot prunes all but the newest keep snapshots and those
+// older than maxAge, zero meaning no limit. The newest snapshot is kept
+// regardless.
+func NewBackupUsecase(
+	db *gorm.DB,
+	repository *repository.BackupRepository,
+	store *backup.Store,
+	databasePath string,
+	keep int,
+	maxAge time.Duration,
+) *BackupUsecase {
+	return &BackupUsecase{
+		db:           db,
+		repository:   repository,
+		store:        store,
+		databasePath: databasePath,
+		keep:         keep,
+		maxAge:       maxAge,
+	}
+}
+
+func (uc *BackupUsecase) Create(ctx context.Context) (*model.BackupResponse, error) {
+	if err := uc.checkSupported(); err != nil {
+		return nil, err
+	}
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	path, err := uc.store.TempPath()
+	if err != nil {
+		gotracing.Error("Failed to create backup directory", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to create backup directory"))
+	}
+	defer os.Remove(path)
+
+	createdAt := time.Now()
+	if err := uc.repository.VacuumInto(uc.db.WithContext(ctx), path); err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to back up database"))
+	}
+
+	if err := backup.IntegrityCheck(path); err != nil {
+		gotracing.Error("Failed to check backup integrity", err)
+		return nil, model.ErrorInternalServerError(errors.New("backup failed integrity check"))
+	}
+
+	snapshot, err := uc.store.Save(path, createdAt)
+	if err != nil {
+		gotracing.Error("Failed to save backup", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to save backup"))
+	}
+
+	uc.prune(createdAt)
+
+	return model.ToBackupResponse(snapshot), nil
+}
+
+func (uc *BackupUsecase) GetMany(ctx context.Context) ([]model.BackupResponse, error) {
+	snapshots, err := uc.store.List()
+	if err != nil {
+		gotracing.Error("Failed to list backups", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to list backups"))
+	}
+
+	responses := make([]model.BackupResponse, 0, len(snapshots))
+	for i := range snapshots {
+		responses = append(responses, *model.ToBackupResponse(&snapshots[i]))
+	}
+	return responses, nil
+}
+
+// Restore replaces the database with a snapshot. It works on the database
+// file alone, the usecase may have no database pool. The server must not be
+// running, its connections would see the database change under them, and
+// the restore is refused when the database is found in use. The replaced
+// database is kept next to it.
+func (uc *BackupUsecase) Restore(ctx context.Context, request *model.RestoreBackupRequest) (*model.RestoreBackupResponse, error) {
+	if uc.databasePath == "" {
+		return nil, model.ErrorBadRequest(errors.New("backups are only supported for sqlite databases"))
+	}
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	snapshot, err := uc.find(request)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC().Format("20060102T150405")
+	restored := uc.databasePath + ".restore-" + now
+	defer os.Remove(restored)
+
+	if err := uc.store.Extract(snapshot.Name, restored); err != nil {
+		gotracing.Error("Failed to extract backup", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to extract backup"))
+	}
+	if err := backup.IntegrityCheck(restored); err != nil {
+		gotracing.Error("Failed to check backup integrity", err)
+		return nil, model.ErrorInternalServerError(errors.New("backup failed integrity check"))
+	}
+
+	if err := backup.CheckNotInUse(uc.databasePath); err != nil {
+		if errors.Is(err, backup.ErrInUse) {
+			return nil, model.ErrorConflict(errors.New("database is in use, stop the server before restoring"))
+		}
+		gotracing.Error("Failed to check database", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to check database"))
+	}
+
+	response := &model.RestoreBackupResponse{Backup: *model.ToBackupResponse(snapshot)}
+
+	// The database file is copied rather than renamed, it may be a bind
+	// mount which cannot be replaced. The journal files belong to the
+	// replaced database, SQLite would apply them to the restored one.
+	previous := uc.databasePath + ".before-restore-" + now
+	if err := copyFile(uc.databasePath, previous); err == nil {
+		response.PreviousPath = previous
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		gotracing.Error("Failed to copy database", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to copy database"))
+	}
+	for _, suffix := range []string{"-wal", "-shm", "-journal"} {
+		if err := os.Rename(uc.databasePath+suffix, previous+suffix); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			gotracing.Error("Failed to move database journal", err)
+			return nil, model.ErrorInternalServerError(errors.New("failed to move database journal"))
+		}
+	}
+
+	if err := copyFile(restored, uc.databasePath); err != nil {
+		gotracing.Error("Failed to restore backup", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to restore backup"))
+	}
+
+	return response, nil
+}
+
+// Schedule creates a snapshot every interval until ctx is done.
+func (uc *BackupUsecase) Schedule(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := uc.Create(ctx); err != nil {
+				gotracing.Error("Failed to create scheduled backup", err)
+			}
+		}
+	}
+}
+
+func (uc *BackupUsecase) checkSupported() error {
+	if !uc.repository.IsSupported(uc.db) {
+		return model.ErrorBadRequest(errors.New("backups are only supported for sqlite databases"))
+	}
+	return nil
+}
+
+func (uc *BackupUsecase) find(request *model.RestoreBackupRequest) (*backup.Snapshot, error) {
+	snapshots, err := uc.store.List()
+	if err != nil {
+		gotracing.Error("Failed to list backups", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to list backups"))
+	}
+
+	for i := range snapshots {
+		switch {
+		case request.Name != "":
+			if snapshots[i].Name == request.Name {
+				return &snapshots[i], nil
+			}
+		case request.At != nil:
+			if !snapshots[i].CreatedAt.After(*request.At) {
+				return &snapshots[i], nil
+			}
+		default:
+			return &snapshots[i], nil
+		}
+	}
+
+	if request.Name == "" && request.At != nil {
+		return nil, model.ErrorNotFound(fmt.Errorf("no backup was taken at or before %s", request.At.Format(time.RFC3339)))
+	}
+	return nil, model.ErrorNotFound(errors.New("backup not found"))
+}
+
+func (uc *BackupUsecase) prune(now time.Time) {
+	snapshots, err := uc.store.List()
+	if err != nil {
+		gotracing.Error("Failed to list backups", err)
+		return
+	}
+
+	for i, snapshot := range snapshots {
+		if i == 0 {
+			continue
+		}
+		if (uc.keep > 0 && i >= uc.keep) || (uc.maxAge > 0 && now.Sub(snapshot.CreatedAt) > uc.maxAge) {
+			if err := uc.store.Delete(snapshot.Name); err != nil {
+				gotracing.Error("Failed to delete backup", err)
+			}
+		}
+	}
+}
+
+func copyFile(source string, target string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, in); err != nil {
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,190 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/backup"
+	"github.com/mnaufalhilmym/bookshelf/internal/config"
+	"github.com/mnaufalhilmym/bookshelf/internal/migration"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newFileDatabase(t *testing.T, path string) *gorm.DB {
+	db := config.NewDatabase("sqlite", path, 1, 1, 100, config.SQLiteOptions{})
+	t.Cleanup(func() {
+		if connection, err := db.DB(); err == nil {
+			connection.Close()
+		}
+	})
+	_, err := usecase.NewMigrationUsecase(db, repository.NewSchemaMigrationRepository(), migration.All()).
+		Up(context.Background(), &model.MigrateUpRequest{})
+	assert.NoError(t, err)
+	return db
+}
+
+func TestBackupUsecase_Create(t *testing.T) {
+	dir := t.TempDir()
+	databasePath := filepath.Join(dir, "sqlite.db")
+	db := newFileDatabase(t, databasePath)
+	uc := usecase.NewBackupUsecase(
+		db,
+		repository.NewBackupRepository(),
+		backup.NewStore(filepath.Join(dir, "backups")),
+		databasePath,
+		2,
+		0,
+	)
+
+	_, err := usecase.NewAuthorUsecase(db, repository.NewAuthorRepository(), repository.NewBookRepository(), repository.NewRevisionRepository()).Create(context.Background(), &model.CreateAuthorRequest{
+		Name: "Author Name 1",
+	})
+	assert.NoError(t, err)
+
+	t.Run("Positive Case - back up", func(t *testing.T) {
+		res, err := uc.Create(context.Background())
+		assert.NoError(t, err)
+		assert.Regexp(t, `^bookshelf-\d{8}T\d{6}\.\d{3}Z\.db\.gz$`, res.Name)
+		assert.Greater(t, res.Size, int64(0))
+
+		backups, err := uc.GetMany(context.Background())
+		assert.NoError(t, err)
+		assert.EqualValues(t, []model.BackupResponse{*res}, backups)
+	})
+
+	t.Run("Positive Case - keep the newest backups", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			time.Sleep(2 * time.Millisecond)
+			_, err := uc.Create(context.Background())
+			assert.NoError(t, err)
+		}
+
+		backups, err := uc.GetMany(context.Background())
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, len(backups))
+		assert.True(t, backups[0].CreatedAt.After(backups[1].CreatedAt))
+	})
+}
+
+func TestBackupUsecase_Restore(t *testing.T) {
+	dir := t.TempDir()
+	databasePath := filepath.Join(dir, "sqlite.db")
+	db := newFileDatabase(t, databasePath)
+	uc := usecase.NewBackupUsecase(
+		db,
+		repository.NewBackupRepository(),
+		backup.NewStore(filepath.Join(dir, "backups")),
+		databasePath,
+		0,
+		0,
+	)
+	authorUc := usecase.NewAuthorUsecase(db, repository.NewAuthorRepository(), repository.NewBookRepository(), repository.NewRevisionRepository())
+
+	_, err := authorUc.Create(context.Background(), &model.CreateAuthorRequest{Name: "Author Name 1"})
+	assert.NoError(t, err)
+	first, err := uc.Create(context.Background())
+	assert.NoError(t, err)
+
+	time.Sleep(2 * time.Millisecond)
+	_, err = authorUc.Create(context.Background(), &model.CreateAuthorRequest{Name: "Author Name 2"})
+	assert.NoError(t, err)
+	second, err := uc.Create(context.Background())
+	assert.NoError(t, err)
+
+	_, err = authorUc.Create(context.Background(), &model.CreateAuthorRequest{Name: "Author Name 3"})
+	assert.NoError(t, err)
+
+	connection, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, connection.Close())
+
+	countAuthors := func(t *testing.T) int {
+		restored, err := gorm.Open(sqlite.Open(databasePath), &gorm.Config{})
+		assert.NoError(t, err)
+		defer func() {
+			if connection, err := restored.DB(); err == nil {
+				connection.Close()
+			}
+		}()
+		authors, err := repository.NewAuthorRepository().FindAll(restored)
+		assert.NoError(t, err)
+		return len(authors)
+	}
+
+	t.Run("Negative Case - no backup at time", func(t *testing.T) {
+		at := first.CreatedAt.Add(-time.Second)
+		_, err := uc.Restore(context.Background(), &model.RestoreBackupRequest{At: &at})
+		assert.EqualValues(t, model.ErrorNotFound(errors.New("no backup was taken at or before "+at.Format(time.RFC3339))), err)
+	})
+
+	t.Run("Negative Case - unknown name", func(t *testing.T) {
+		_, err := uc.Restore(context.Background(), &model.RestoreBackupRequest{Name: "sqlite.db"})
+		assert.EqualValues(t, model.ErrorNotFound(errors.New("backup not found")), err)
+	})
+
+	openDatabase := func(t *testing.T, journalMode string) *gorm.DB {
+		open, err := gorm.Open(sqlite.Open(databasePath), &gorm.Config{})
+		assert.NoError(t, err)
+		t.Cleanup(func() {
+			if connection, err := open.DB(); err == nil {
+				connection.Close()
+			}
+		})
+		assert.NoError(t, open.Exec("PRAGMA journal_mode = "+journalMode).Error)
+		_, err = repository.NewAuthorRepository().FindAll(open)
+		assert.NoError(t, err)
+		return open
+	}
+
+	t.Run("Negative Case - database open in WAL mode", func(t *testing.T) {
+		openDatabase(t, "WAL")
+
+		_, err := uc.Restore(context.Background(), &model.RestoreBackupRequest{})
+		assert.EqualValues(t, model.ErrorConflict(errors.New("database is in use, stop the server before restoring")), err)
+		assert.EqualValues(t, 3, countAuthors(t))
+	})
+
+	t.Run("Negative Case - database locked", func(t *testing.T) {
+		tx := openDatabase(t, "DELETE").Begin()
+		defer tx.Rollback()
+		_, err := repository.NewAuthorRepository().FindAll(tx)
+		assert.NoError(t, err)
+
+		_, err = uc.Restore(context.Background(), &model.RestoreBackupRequest{})
+		assert.EqualValues(t, model.ErrorConflict(errors.New("database is in use, stop the server before restoring")), err)
+	})
+
+	t.Run("Positive Case - restore latest", func(t *testing.T) {
+		res, err := uc.Restore(context.Background(), &model.RestoreBackupRequest{})
+		assert.NoError(t, err)
+		assert.EqualValues(t, second.Name, res.Backup.Name)
+		assert.FileExists(t, res.PreviousPath)
+		assert.EqualValues(t, 2, countAuthors(t))
+	})
+
+	t.Run("Positive Case - restore at time", func(t *testing.T) {
+		at := second.CreatedAt.Add(-time.Millisecond)
+		res, err := uc.Restore(context.Background(), &model.RestoreBackupRequest{At: &at})
+		assert.NoError(t, err)
+		assert.EqualValues(t, first.Name, res.Backup.Name)
+		assert.EqualValues(t, 1, countAuthors(t))
+	})
+
+	t.Run("Negative Case - corrupt backup", func(t *testing.T) {
+		err := os.WriteFile(filepath.Join(dir, "backups", second.Name), []byte("not gzip"), 0o644)
+		assert.NoError(t, err)
+
+		_, err = uc.Restore(context.Background(), &model.RestoreBackupRequest{Name: second.Name})
+		assert.EqualValues(t, model.ErrorInternalServerError(errors.New("failed to extract backup")), err)
+		assert.EqualValues(t, 1, countAuthors(t))
+	})
+}
@@ -0,0 +1,273 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+	"github.com/mnaufalhilmym/bookshelf/internal/format/ebook"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/bookshelf/internal/storage"
+	"github.com/mnaufalhilmym/gotracing"
+	"gorm.io/gorm"
+)
+
+type BookFileUsecase struct {
+	db               *gorm.DB
+	storage          storage.Storage
+	repository       *repository.BookFileRepository
+	bookRepository   *repository.BookRepository
+	authorRepository *repository.AuthorRepository
+}
+
+func NewBookFileUsecase(
+	db *gorm.DB,
+	storage storage.Storage,
+	repository *repository.BookFileRepository,
+	bookRepository *repository.BookRepository,
+	authorRepository *repository.AuthorRepository,
+) *BookFileUsecase {
+	return &BookFileUsecase{
+		db,
+		storage,
+		repository,
+		bookRepository,
+		authorRepository,
+	}
+}
+
+func (uc *BookFileUsecase) GetMany(ctx context.Context, request *model.GetManyBookFilesRequest) ([]model.BookFileResponse, error) {
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
+	defer tx.Rollback()
+
+	if _, err := uc.bookRepository.FindByID(tx, request.BookID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrorNotFound(errors.New("book not found"))
+		}
+		return nil, model.ErrorInternalServerError(errors.New("failed to find book data by id"))
+	}
+
+	files, err := uc.repository.FindAllByBookID(tx, request.BookID)
+	if err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to get book files"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return model.ToBookFilesResponse(files), nil
+}
+
+// Open returns the stored file for download. The caller must close it.
+func (uc *BookFileUsecase) Open(ctx context.Context, request *model.GetBookFileRequest) (*model.BookFileResponse, io.ReadSeekCloser, error) {
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
+	defer tx.Rollback()
+
+	file, err := uc.repository.FindByBookIDAndID(tx, request.BookID, request.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, model.ErrorNotFound(errors.New("file not found"))
+		}
+		return nil, nil, model.ErrorInternalServerError(errors.New("failed to find book file data by id"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	content, err := openStored(ctx, uc.storage, file.StorageKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return model.ToBookFileResponse(file), content, nil
+}
+
+func (uc *BookFileUsecase) Upload(ctx context.Context, request *model.UploadBookFileRequest) (*model.UploadBookFileResponse, error) {
+	metadata, err := readEbook(request.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := begin(ctx, uc.db)
+	defer tx.Rollback()
+
+	book, err := uc.bookRepository.FindByID(tx, request.BookID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrorNotFound(errors.New("book not found"))
+		}
+		return nil, model.ErrorInternalServerError(errors.New("failed to find book data by id"))
+	}
+
+	if book.Language == "" {
+		book.Language = metadata.Language
+	}
+
+	return uc.attach(ctx, tx, book, request.Filename, request.Content, metadata)
+}
+
+// UploadBook finds the book of the file in the catalog, by ISBN and then by
+// title and author, and creates it when missing.
+func (uc *BookFileUsecase) UploadBook(ctx context.Context, request *model.UploadBookRequest) (*model.UploadBookFileResponse, error) {
+	metadata, err := readEbook(request.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	query := &bookQuery{
+		isbn:   firstNonEmpty(request.ISBN, metadata.ISBN),
+		title:  firstNonEmpty(request.Title, metadata.Title),
+		author: request.Author,
+	}
+	if query.author == "" && len(metadata.Authors) > 0 {
+		query.author = metadata.Authors[0]
+	}
+
+	if query.title == "" {
+		return nil, model.ErrorBadRequest(errors.New("title is required, the file has none"))
+	}
+
+	tx := begin(ctx, uc.db)
+	defer tx.Rollback()
+
+	book, err := newBookResolver(uc.authorRepository, uc.bookRepository, tx, true, "upload-").resolve(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if book.Language == "" {
+		book.Language = firstNonEmpty(request.Language, metadata.Language)
+	}
+
+	return uc.attach(ctx, tx, book, request.Filename, request.Content, metadata)
+}
+
+// attach stores the file, and the cover it embeds when the book has none, and
+// commits tx. Stored objects are removed again if the commit fails.
+func (uc *BookFileUsecase) attach(
+	ctx context.Context,
+	tx *gorm.DB,
+	book *entity.Book,
+	filename string,
+	content []byte,
+	metadata *ebook.Metadata,
+) (*model.UploadBookFileResponse, error) {
+	checksum := sha256.Sum256(content)
+
+	file := &entity.BookFile{
+		BookID:      book.ID,
+		Format:      metadata.Format,
+		Filename:    filepath.Base(filename),
+		ContentType: ebook.ContentType(metadata.Format),
+		Size:        int64(len(content)),
+		Checksum:    hex.EncodeToString(checksum[:]),
+	}
+	if file.Filename == "." || file.Filename == string(filepath.Separator) {
+		file.Filename = "book." + metadata.Format
+	}
+	file.StorageKey = fmt.Sprintf("books/%d/files/%s.%s", book.ID, file.Checksum, file.Format)
+
+	if _, err := uc.repository.FindByBookIDAndChecksum(tx, book.ID, file.Checksum); err == nil {
+		return nil, model.ErrorBadRequest(errors.New("file is already attached to the book"))
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, model.ErrorInternalServerError(errors.New("failed to find book file data"))
+	}
+
+	var stored []string
+	committed := false
+	defer func() {
+		if !committed {
+			removeStored(uc.storage, stored)
+		}
+	}()
+
+	if err := uc.storage.Put(ctx, file.StorageKey, bytes.NewReader(content)); err != nil {
+		gotracing.Error("Failed to store book file", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to store file"))
+	}
+	stored = append(stored, file.StorageKey)
+
+	// An embedded cover that is not a usable image is skipped rather than
+	// failing the upload of the file.
+	if book.CoverKey == "" && len(metadata.Cover) > 0 {
+		if cover, err := newCoverImage(metadata.Cover); err != nil {
+			gotracing.Error("Failed to read embedded cover", err)
+		} else {
+			keys, err := cover.store(ctx, uc.storage, book)
+			stored = append(stored, keys...)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := uc.bookRepository.Update(tx, book); err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to update book data"))
+	}
+
+	if err := uc.repository.Create(tx, file); err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to create new book file"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+	committed = true
+
+	return &model.UploadBookFileResponse{
+		Book: *model.ToBookResponse(book),
+		File: *model.ToBookFileResponse(file),
+		Metadata: model.EbookMetadataResponse{
+			Title:    metadata.Title,
+			Authors:  metadata.Authors,
+			ISBN:     metadata.ISBN,
+			Language: metadata.Language,
+			HasCover: len(metadata.Cover) > 0,
+		},
+	}, nil
+}
+
+func readEbook(content []byte) (*ebook.Metadata, error) {
+	metadata, err := ebook.Read(content)
+	if err != nil {
+		gotracing.Error("Failed to read ebook metadata", err)
+		return nil, model.ErrorBadRequest(errors.New(err.Error()))
+	}
+	return metadata, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func imageExtension(contentType string) string {
+	switch contentType {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/png":
+		return ".png"
+	case "image/gif":
+		return ".gif"
+	case "image/webp":
+		return ".webp"
+	}
+	return ".img"
+}
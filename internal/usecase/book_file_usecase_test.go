@@ -0,0 +1,292 @@
+package usecase_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/bookshelf/internal/storage"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+const epubPackage = `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="2.0" unique-identifier="uid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:opf="http://www.idpf.org/2007/opf">
+    <dc:title>Dune</dc:title>
+    <dc:creator opf:role="aut" opf:file-as="Herbert, Frank">Frank Herbert</dc:creator>
+    <dc:creator opf:role="edt">Some Editor</dc:creator>
+    <dc:identifier opf:scheme="UUID">9b2b7a4e-1f7e-4c4b-8a4e-2f6f2d1f0a11</dc:identifier>
+    <dc:identifier id="uid" opf:scheme="ISBN">978-0-441-17271-9</dc:identifier>
+    <dc:language>en</dc:language>
+    <meta name="cover" content="cover-image"/>
+  </metadata>
+  <manifest>
+    <item id="cover-image" href="images/cover%20art.png" media-type="image/png"/>
+    <item id="chapter-1" href="chapter1.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+</package>`
+
+const pdfDocument = "%PDF-1.4\n" +
+	"1 0 obj\n<< /Type /Catalog /Pages 2 0 R /Lang (de) >>\nendobj\n" +
+	"2 0 obj\n<< /Type /Pages /Kids [] /Count 0 >>\nendobj\n" +
+	"3 0 obj\n<< /Title <FEFF0042006F006F006B0020005400690074006C0065002000310020> /Author (Author Name 1) /Subject (ISBN 978-1451673319) /CreationDate (D:20240101000000Z) >>\nendobj\n" +
+	"trailer\n<< /Size 4 /Root 1 0 R /Info 3 0 R >>\n%%EOF\n"
+
+func newEPUB(t *testing.T, opf string, cover []byte) []byte {
+	buf := new(bytes.Buffer)
+	archive := zip.NewWriter(buf)
+
+	files := []struct {
+		name    string
+		content []byte
+	}{
+		{"mimetype", []byte("application/epub+zip")},
+		{"META-INF/container.xml", []byte(`<?xml version="1.0"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>`)},
+		{"OEBPS/content.opf", []byte(opf)},
+		{"OEBPS/chapter1.xhtml", []byte("<html><body><p>In the week before their departure to Arrakis...</p></body></html>")},
+	}
+	if cover != nil {
+		files = append(files, struct {
+			name    string
+			content []byte
+		}{"OEBPS/images/cover art.png", cover})
+	}
+
+	for _, file := range files {
+		w, err := archive.Create(file.name)
+		assert.NoError(t, err)
+		_, err = w.Write(file.content)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, archive.Close())
+
+	return buf.Bytes()
+}
+
+type bookFileFixture struct {
+	bookFileUc *usecase.BookFileUsecase
+	bookUc     *usecase.BookUsecase
+	coverUc    *usecase.CoverUsecase
+	storage    storage.Storage
+	book       *model.BookResponse
+}
+
+func newBookFileFixture(t *testing.T) *bookFileFixture {
+	db := newDatabase()
+	authorRepo := repository.NewAuthorRepository()
+	bookRepo := repository.NewBookRepository()
+	bookFileRepo := repository.NewBookFileRepository()
+
+	f := &bookFileFixture{
+		bookUc:  usecase.NewBookUsecase(db, bookRepo, authorRepo, repository.NewRevisionRepository()),
+		storage: storage.NewLocal(t.TempDir()),
+	}
+	f.bookFileUc = usecase.NewBookFileUsecase(db, f.storage, bookFileRepo, bookRepo, authorRepo)
+	f.coverUc = usecase.NewCoverUsecase(db, f.storage, bookRepo)
+
+	author, err := usecase.NewAuthorUsecase(db, authorRepo, bookRepo, repository.NewRevisionRepository()).Create(context.Background(), &model.CreateAuthorRequest{
+		Name:      "Author Name 1",
+		Birthdate: time.Date(2011, 11, 11, 11, 11, 11, 111, time.UTC),
+	})
+	assert.NoError(t, err)
+
+	f.book, err = f.bookUc.Create(context.Background(), &model.CreateBookRequest{
+		Title:    "Book Title 1",
+		ISBN:     "978-1451673319",
+		AuthorID: author.ID,
+	})
+	assert.NoError(t, err)
+
+	return f
+}
+
+func TestBookFileUsecase_Upload(t *testing.T) {
+	f := newBookFileFixture(t)
+	epub := newEPUB(t, epubPackage, pngImage)
+
+	t.Run("Positive Case - attach epub with cover", func(t *testing.T) {
+		res, err := f.bookFileUc.Upload(context.Background(), &model.UploadBookFileRequest{
+			BookID:   f.book.ID,
+			Filename: "dune.epub",
+			Content:  epub,
+		})
+		assert.NoError(t, err)
+
+		assert.EqualValues(t, "epub", res.File.Format)
+		assert.EqualValues(t, "application/epub+zip", res.File.ContentType)
+		assert.EqualValues(t, "dune.epub", res.File.Filename)
+		assert.EqualValues(t, len(epub), res.File.Size)
+		assert.EqualValues(t, model.EbookMetadataResponse{
+			Title:    "Dune",
+			Authors:  []string{"Frank Herbert"},
+			ISBN:     "9780441172719",
+			Language: "en",
+			HasCover: true,
+		}, res.Metadata)
+		assert.EqualValues(t, "Book Title 1", res.Book.Title)
+		assert.EqualValues(t, "en", res.Book.Language)
+		assert.NotNil(t, res.Book.Cover)
+
+		image, cover, err := f.coverUc.Open(context.Background(), &model.GetBookCoverRequest{BookID: f.book.ID})
+		assert.NoError(t, err)
+		defer cover.Close()
+		assert.EqualValues(t, "image/png", image.ContentType)
+
+		content, err := io.ReadAll(cover)
+		assert.NoError(t, err)
+		assert.EqualValues(t, pngImage, content)
+	})
+
+	t.Run("Negative Case - same file twice", func(t *testing.T) {
+		_, err := f.bookFileUc.Upload(context.Background(), &model.UploadBookFileRequest{
+			BookID:   f.book.ID,
+			Filename: "dune.epub",
+			Content:  epub,
+		})
+		assert.EqualValues(t, model.ErrorBadRequest(errors.New("file is already attached to the book")), err)
+	})
+
+	t.Run("Negative Case - unsupported file", func(t *testing.T) {
+		_, err := f.bookFileUc.Upload(context.Background(), &model.UploadBookFileRequest{
+			BookID:   f.book.ID,
+			Filename: "notes.txt",
+			Content:  []byte("just text"),
+		})
+		assert.EqualValues(t, model.ErrorBadRequest(errors.New("unsupported file format, expected epub or pdf")), err)
+	})
+
+	t.Run("Negative Case - epub without package document", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		archive := zip.NewWriter(buf)
+		_, err := archive.Create("mimetype")
+		assert.NoError(t, err)
+		assert.NoError(t, archive.Close())
+
+		_, err = f.bookFileUc.Upload(context.Background(), &model.UploadBookFileRequest{
+			BookID:   f.book.ID,
+			Filename: "broken.epub",
+			Content:  buf.Bytes(),
+		})
+		assert.EqualValues(t, model.ErrorBadRequest(errors.New("invalid epub: missing META-INF/container.xml")), err)
+	})
+
+	t.Run("Negative Case - book not found", func(t *testing.T) {
+		_, err := f.bookFileUc.Upload(context.Background(), &model.UploadBookFileRequest{
+			BookID:  100,
+			Content: []byte(pdfDocument),
+		})
+		assert.EqualValues(t, model.ErrorNotFound(errors.New("book not found")), err)
+	})
+}
+
+func TestBookFileUsecase_UploadBook(t *testing.T) {
+	f := newBookFileFixture(t)
+
+	t.Run("Positive Case - pdf matches existing book by isbn", func(t *testing.T) {
+		res, err := f.bookFileUc.UploadBook(context.Background(), &model.UploadBookRequest{
+			Filename: "book.pdf",
+			Content:  []byte(pdfDocument),
+		})
+		assert.NoError(t, err)
+
+		assert.EqualValues(t, f.book.ID, res.Book.ID)
+		assert.EqualValues(t, "de", res.Book.Language)
+		assert.EqualValues(t, "pdf", res.File.Format)
+		assert.EqualValues(t, model.EbookMetadataResponse{
+			Title:    "Book Title 1",
+			Authors:  []string{"Author Name 1"},
+			ISBN:     "9781451673319",
+			Language: "de",
+		}, res.Metadata)
+
+		_, _, err = f.coverUc.Open(context.Background(), &model.GetBookCoverRequest{BookID: f.book.ID})
+		assert.EqualValues(t, model.ErrorNotFound(errors.New("book has no cover")), err)
+	})
+
+	t.Run("Positive Case - epub creates book and author", func(t *testing.T) {
+		res, err := f.bookFileUc.UploadBook(context.Background(), &model.UploadBookRequest{
+			Filename: "dune.epub",
+			Content:  newEPUB(t, epubPackage, nil),
+		})
+		assert.NoError(t, err)
+
+		assert.EqualValues(t, "Dune", res.Book.Title)
+		assert.EqualValues(t, "9780441172719", res.Book.ISBN)
+		assert.EqualValues(t, "Frank Herbert", res.Book.AuthorName)
+		assert.EqualValues(t, "en", res.Book.Language)
+
+		files, err := f.bookFileUc.GetMany(context.Background(), &model.GetManyBookFilesRequest{BookID: res.Book.ID})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, len(files))
+		assert.EqualValues(t, res.File.Checksum, files[0].Checksum)
+
+		file, content, err := f.bookFileUc.Open(context.Background(), &model.GetBookFileRequest{BookID: res.Book.ID, ID: res.File.ID})
+		assert.NoError(t, err)
+		defer content.Close()
+		assert.EqualValues(t, res.File.Filename, file.Filename)
+
+		stored, err := io.ReadAll(content)
+		assert.NoError(t, err)
+		assert.EqualValues(t, res.File.Size, len(stored))
+	})
+
+	t.Run("Positive Case - epub with broken cover is attached without it", func(t *testing.T) {
+		opf := strings.Replace(epubPackage, "978-0-441-17271-9", "978-0-441-01359-3", 1)
+		res, err := f.bookFileUc.UploadBook(context.Background(), &model.UploadBookRequest{
+			Title:    "Children of Dune",
+			Filename: "children.epub",
+			Content:  newEPUB(t, opf, pngImage[:64]),
+		})
+		assert.NoError(t, err)
+
+		assert.True(t, res.Metadata.HasCover)
+		assert.Nil(t, res.Book.Cover)
+	})
+
+	t.Run("Negative Case - no title in file or form", func(t *testing.T) {
+		_, err := f.bookFileUc.UploadBook(context.Background(), &model.UploadBookRequest{
+			Filename: "untitled.pdf",
+			Content:  []byte("%PDF-1.4\n%%EOF\n"),
+		})
+		assert.EqualValues(t, model.ErrorBadRequest(errors.New("title is required, the file has none")), err)
+	})
+
+	t.Run("Positive Case - form fields take precedence", func(t *testing.T) {
+		res, err := f.bookFileUc.UploadBook(context.Background(), &model.UploadBookRequest{
+			Title:    "Untitled Notes",
+			ISBN:     "978-0-00-000000-2",
+			Author:   "Author Name 1",
+			Language: "id",
+			Filename: "untitled.pdf",
+			Content:  []byte("%PDF-1.4\n%%EOF\n"),
+		})
+		assert.NoError(t, err)
+
+		assert.EqualValues(t, "Untitled Notes", res.Book.Title)
+		assert.EqualValues(t, "978-0-00-000000-2", res.Book.ISBN)
+		assert.EqualValues(t, f.book.AuthorID, res.Book.AuthorID)
+		assert.EqualValues(t, "id", res.Book.Language)
+	})
+}
+
+func TestBookFileUsecase_Open(t *testing.T) {
+	f := newBookFileFixture(t)
+
+	t.Run("Negative Case - file not found", func(t *testing.T) {
+		_, _, err := f.bookFileUc.Open(context.Background(), &model.GetBookFileRequest{BookID: f.book.ID, ID: 100})
+		assert.EqualValues(t, model.ErrorNotFound(errors.New("file not found")), err)
+	})
+}
@@ -0,0 +1,151 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/bookshelf/internal/util"
+	"gorm.io/gorm"
+)
+
+const unknownAuthorName = "Unknown Author"
+
+type bookQuery struct {
+	isbn      string
+	title     string
+	author    string
+	pageCount int
+}
+
+// bookResolver finds the books imported records refer to, optionally
+// creating the missing books and authors. Results are cached, so a resolver
+// must not outlive the transaction it was created with.
+type bookResolver struct {
+	authorRepository *repository.AuthorRepository
+	bookRepository   *repository.BookRepository
+	tx               *gorm.DB
+	create           bool
+	// placeholderPrefix starts the ISBN given to created books when the
+	// source has none, since books.isbn is required and unique.
+	placeholderPrefix string
+	books             map[string]*entity.Book
+	createdBooks      int
+	createdAuthors    int
+}
+
+func newBookResolver(
+	authorRepository *repository.AuthorRepository,
+	bookRepository *repository.BookRepository,
+	tx *gorm.DB,
+	create bool,
+	placeholderPrefix string,
+) *bookResolver {
+	return &bookResolver{
+		authorRepository:  authorRepository,
+		bookRepository:    bookRepository,
+		tx:                tx,
+		create:            create,
+		placeholderPrefix: placeholderPrefix,
+		books:             map[string]*entity.Book{},
+	}
+}
+
+func (r *bookResolver) resolve(query *bookQuery) (*entity.Book, error) {
+	key := strings.ToLower(query.isbn + "|" + query.title + "|" + query.author)
+	if book, ok := r.books[key]; ok {
+		return book, nil
+	}
+
+	book, err := r.match(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if book == nil && r.create {
+		book, err = r.createBook(query)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	r.books[key] = book
+	return book, nil
+}
+
+// match looks the book up by ISBN, then by its full title and then by the
+// title without the subtitle, since exported titles often include it.
+func (r *bookResolver) match(query *bookQuery) (*entity.Book, error) {
+	if query.isbn != "" {
+		book, err := r.bookRepository.FindByISBN(r.tx, query.isbn)
+		if err == nil {
+			return book, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrorInternalServerError(errors.New("failed to find book data by isbn"))
+		}
+	}
+
+	titles := []string{query.title}
+	if short, _, ok := strings.Cut(query.title, ":"); ok && strings.TrimSpace(short) != "" {
+		titles = append(titles, strings.TrimSpace(short))
+	}
+
+	for _, title := range titles {
+		books, err := r.bookRepository.FindAllByTitle(r.tx, title)
+		if err != nil {
+			return nil, model.ErrorInternalServerError(errors.New("failed to find book data by title"))
+		}
+
+		for i := range books {
+			if query.author == "" || strings.EqualFold(books[i].Author.Name, query.author) {
+				return &books[i], nil
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+func (r *bookResolver) createBook(query *bookQuery) (*entity.Book, error) {
+	authorName := query.author
+	if authorName == "" {
+		authorName = unknownAuthorName
+	}
+
+	author, err := r.authorRepository.FindByName(r.tx, authorName)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, model.ErrorInternalServerError(errors.New("failed to find author data by name"))
+	}
+
+	if author == nil {
+		author = &entity.Author{Name: authorName}
+		if err := r.authorRepository.Create(r.tx, author); err != nil {
+			return nil, model.ErrorInternalServerError(errors.New("failed to create new author"))
+		}
+		r.createdAuthors++
+	}
+
+	// The placeholder is stable for the same title and author, so importing
+	// the same file again finds the book instead of creating it twice.
+	isbn := query.isbn
+	if isbn == "" {
+		isbn = r.placeholderPrefix + util.CalculateHash(strings.ToLower(query.title + "|" + authorName))[:16]
+	}
+
+	book := &entity.Book{
+		Title:     query.title,
+		ISBN:      isbn,
+		AuthorID:  author.ID,
+		PageCount: query.pageCount,
+		Author:    *author,
+	}
+	if err := r.bookRepository.Create(r.tx, book); err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to create new book"))
+	}
+	r.createdBooks++
+
+	return book, nil
+}
@@ -3,6 +3,7 @@ package usecase
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 
 	"github.com/mnaufalhilmym/bookshelf/internal/entity"
@@ -13,25 +14,28 @@ import (
 )
 
 type BookUsecase struct {
-	db               *gorm.DB
-	repository       *repository.BookRepository
-	authorRepository *repository.AuthorRepository
+	db                 *gorm.DB
+	repository         *repository.BookRepository
+	authorRepository   *repository.AuthorRepository
+	revisionRepository *repository.RevisionRepository
 }
 
 func NewBookUsecase(
 	db *gorm.DB,
 	repository *repository.BookRepository,
 	authorRepository *repository.AuthorRepository,
+	revisionRepository *repository.RevisionRepository,
 ) *BookUsecase {
 	return &BookUsecase{
 		db,
 		repository,
 		authorRepository,
+		revisionRepository,
 	}
 }
 
 func (uc *BookUsecase) GetMany(ctx context.Context, request *model.GetManyBooksRequest) ([]model.BookResponse, int64, error) {
-	tx := uc.db.WithContext(ctx).Begin(&sql.TxOptions{ReadOnly: true})
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
 	defer tx.Rollback()
 
 	books, total, err := uc.repository.Search(
@@ -41,6 +45,7 @@ func (uc *BookUsecase) GetMany(ctx context.Context, request *model.GetManyBooksR
 		request.ISBN,
 		request.AuthorID,
 		request.AuthorName,
+		request.Sort,
 		request.Page,
 		request.Size,
 	)
@@ -57,7 +62,7 @@ func (uc *BookUsecase) GetMany(ctx context.Context, request *model.GetManyBooksR
 }
 
 func (uc *BookUsecase) Get(ctx context.Context, request *model.GetBookRequest) (*model.BookResponse, error) {
-	tx := uc.db.WithContext(ctx).Begin(&sql.TxOptions{ReadOnly: true})
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
 	defer tx.Rollback()
 
 	book, err := uc.repository.FindByID(tx, request.ID)
@@ -77,7 +82,7 @@ func (uc *BookUsecase) Get(ctx context.Context, request *model.GetBookRequest) (
 }
 
 func (uc *BookUsecase) Create(ctx context.Context, request *model.CreateBookRequest) (*model.BookResponse, error) {
-	tx := uc.db.WithContext(ctx).Begin()
+	tx := begin(ctx, uc.db)
 	defer tx.Rollback()
 
 	author, err := uc.authorRepository.FindByID(tx, request.AuthorID)
@@ -89,10 +94,14 @@ func (uc *BookUsecase) Create(ctx context.Context, request *model.CreateBookRequ
 	}
 
 	book := &entity.Book{
-		Title:    request.Title,
-		ISBN:     request.ISBN,
-		AuthorID: request.AuthorID,
-		Author:   *author,
+		Title:       request.Title,
+		ISBN:        request.ISBN,
+		AuthorID:    request.AuthorID,
+		PageCount:   request.PageCount,
+		Language:    request.Language,
+		Series:      request.Series,
+		SeriesIndex: request.SeriesIndex,
+		Author:      *author,
 	}
 
 	if err := uc.repository.Create(tx, book); err != nil {
@@ -111,7 +120,7 @@ func (uc *BookUsecase) Create(ctx context.Context, request *model.CreateBookRequ
 }
 
 func (uc *BookUsecase) Update(ctx context.Context, request *model.UpdateBookRequest) (*model.BookResponse, error) {
-	tx := uc.db.WithContext(ctx).Begin()
+	tx := begin(ctx, uc.db)
 	defer tx.Rollback()
 
 	book, err := uc.repository.FindByID(tx, request.ID)
@@ -122,6 +131,12 @@ func (uc *BookUsecase) Update(ctx context.Context, request *model.UpdateBookRequ
 		return nil, model.ErrorInternalServerError(errors.New("failed to find book data by id"))
 	}
 
+	if request.IfMatch != "" && !model.MatchETag(request.IfMatch, model.ToBookResponse(book)) {
+		return nil, model.ErrorPreconditionFailed(errors.New("book has been modified"))
+	}
+
+	before := newBookSnapshot(book)
+
 	if request.Title != nil && *request.Title != "" {
 		book.Title = *request.Title
 	}
@@ -130,6 +145,22 @@ func (uc *BookUsecase) Update(ctx context.Context, request *model.UpdateBookRequ
 		book.ISBN = *request.ISBN
 	}
 
+	if request.PageCount != nil {
+		book.PageCount = *request.PageCount
+	}
+
+	if request.Language != nil {
+		book.Language = *request.Language
+	}
+
+	if request.Series != nil {
+		book.Series = *request.Series
+	}
+
+	if request.SeriesIndex != nil {
+		book.SeriesIndex = *request.SeriesIndex
+	}
+
 	if request.AuthorID != nil {
 		author, err := uc.authorRepository.FindByID(tx, *request.AuthorID)
 		if err != nil {
@@ -144,9 +175,122 @@ func (uc *BookUsecase) Update(ctx context.Context, request *model.UpdateBookRequ
 	}
 
 	if err := uc.repository.Update(tx, book); err != nil {
+		if errors.Is(err, repository.ErrStaleEntity) {
+			return nil, model.ErrorPreconditionFailed(errors.New("book has been modified"))
+		}
 		return nil, model.ErrorInternalServerError(errors.New("failed to update book data"))
 	}
 
+	if err := recordRevision(tx, uc.revisionRepository, entity.RevisionEntityBook, book.ID, before, newBookSnapshot(book), request.UpdatedBy); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return model.ToBookResponse(book), nil
+}
+
+func (uc *BookUsecase) GetRevisions(ctx context.Context, request *model.GetManyRevisionsRequest) ([]model.RevisionResponse, int64, error) {
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
+	defer tx.Rollback()
+
+	if _, err := uc.repository.FindByID(tx, request.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, 0, model.ErrorNotFound(errors.New("book not found"))
+		}
+		return nil, 0, model.ErrorInternalServerError(errors.New("failed to find book data by id"))
+	}
+
+	revisions, total, err := getRevisions(ctx, tx, uc.revisionRepository, entity.RevisionEntityBook, request)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, 0, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return revisions, total, nil
+}
+
+func (uc *BookUsecase) DiffRevisions(ctx context.Context, request *model.DiffRevisionsRequest) (*model.RevisionDiffResponse, error) {
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
+	defer tx.Rollback()
+
+	if _, err := uc.repository.FindByID(tx, request.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrorNotFound(errors.New("book not found"))
+		}
+		return nil, model.ErrorInternalServerError(errors.New("failed to find book data by id"))
+	}
+
+	diff, err := diffRevisions(tx, uc.revisionRepository, entity.RevisionEntityBook, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return diff, nil
+}
+
+// RestoreRevision rolls the book back to a revision, which is recorded as a
+// new revision.
+func (uc *BookUsecase) RestoreRevision(ctx context.Context, request *model.RestoreRevisionRequest) (*model.BookResponse, error) {
+	tx := begin(ctx, uc.db)
+	defer tx.Rollback()
+
+	book, err := uc.repository.FindByID(tx, request.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrorNotFound(errors.New("book not found"))
+		}
+		return nil, model.ErrorInternalServerError(errors.New("failed to find book data by id"))
+	}
+
+	revision, err := findRevision(tx, uc.revisionRepository, entity.RevisionEntityBook, book.ID, request.Revision)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := new(bookSnapshot)
+	if err := json.Unmarshal([]byte(revision.Data), snapshot); err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to decode revision"))
+	}
+
+	author, err := uc.authorRepository.FindByID(tx, snapshot.AuthorID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrorConflict(errors.New("author of the revision no longer exists"))
+		}
+		return nil, model.ErrorInternalServerError(errors.New("failed to find author data by id"))
+	}
+
+	before := newBookSnapshot(book)
+	snapshot.apply(book)
+	book.Author = *author
+
+	if err := uc.repository.Update(tx, book); err != nil {
+		if errors.Is(err, repository.ErrStaleEntity) {
+			return nil, model.ErrorPreconditionFailed(errors.New("book has been modified"))
+		}
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, model.ErrorConflict(errors.New("isbn is used by another book"))
+		}
+		return nil, model.ErrorInternalServerError(errors.New("failed to update book data"))
+	}
+
+	if err := recordRevision(tx, uc.revisionRepository, entity.RevisionEntityBook, book.ID, before, snapshot, request.RestoredBy); err != nil {
+		return nil, err
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		gotracing.Error("Failed to commit transaction", err)
 		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
@@ -156,7 +300,7 @@ func (uc *BookUsecase) Update(ctx context.Context, request *model.UpdateBookRequ
 }
 
 func (uc *BookUsecase) Delete(ctx context.Context, request *model.DeleteBookRequest) (*int, error) {
-	tx := uc.db.WithContext(ctx).Begin()
+	tx := begin(ctx, uc.db)
 	defer tx.Rollback()
 
 	book, err := uc.repository.FindByID(tx, request.ID)
@@ -167,8 +311,12 @@ func (uc *BookUsecase) Delete(ctx context.Context, request *model.DeleteBookRequ
 		return nil, model.ErrorInternalServerError(errors.New("failed to find book data by id"))
 	}
 
-	if err := uc.repository.Delete(tx, book); err != nil {
-		return nil, model.ErrorInternalServerError(errors.New("failed to delete author"))
+	if request.IfMatch != "" && !model.MatchETag(request.IfMatch, model.ToBookResponse(book)) {
+		return nil, model.ErrorPreconditionFailed(errors.New("book has been modified"))
+	}
+
+	if err := uc.repository.Trash(tx, book, request.DeletedBy); err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to delete book"))
 	}
 
 	if err := tx.Commit().Error; err != nil {
@@ -6,29 +6,29 @@ import (
 	"testing"
 	"time"
 
-	"github.com/mnaufalhilmym/bookshelf/internal/config"
 	"github.com/mnaufalhilmym/bookshelf/internal/model"
 	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/bookshelf/internal/testdb"
 	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
 	"github.com/mnaufalhilmym/bookshelf/internal/util"
 	"github.com/stretchr/testify/assert"
 )
 
 func newAuthorAndBookUsecase() (*usecase.AuthorUsecase, *usecase.BookUsecase) {
-	db := config.NewDatabase(":memory:", 1, 1, 100)
-	authorRepo := repository.NewAuthorRepository(db)
-	bookRepo := repository.NewBookRepository(db)
-	authorUc := usecase.NewAuthorUsecase(db, authorRepo)
-	bookUc := usecase.NewBookUsecase(db, bookRepo, authorRepo)
+	db := newDatabase()
+	authorRepo := repository.NewAuthorRepository()
+	bookRepo := repository.NewBookRepository()
+	authorUc := usecase.NewAuthorUsecase(db, authorRepo, bookRepo, repository.NewRevisionRepository())
+	bookUc := usecase.NewBookUsecase(db, bookRepo, authorRepo, repository.NewRevisionRepository())
 	return authorUc, bookUc
 }
 
 func newFailAuthorAndBookUsecase() (*usecase.AuthorUsecase, *usecase.BookUsecase) {
-	db := config.NewDatabase(":memory:", 1, 1, 100)
+	db := testdb.New()
 	authorRepo := &repository.AuthorRepository{}
 	bookRepo := &repository.BookRepository{}
-	authorUc := usecase.NewAuthorUsecase(db, authorRepo)
-	bookUc := usecase.NewBookUsecase(db, bookRepo, authorRepo)
+	authorUc := usecase.NewAuthorUsecase(db, authorRepo, bookRepo, repository.NewRevisionRepository())
+	bookUc := usecase.NewBookUsecase(db, bookRepo, authorRepo, repository.NewRevisionRepository())
 	return authorUc, bookUc
 }
 
@@ -326,6 +326,7 @@ func TestBookUsecase_Create(t *testing.T) {
 				ISBN:       params.request.ISBN,
 				AuthorID:   author.ID,
 				AuthorName: author.Name,
+				Version:    1,
 			},
 			err: nil,
 		}
@@ -420,6 +421,7 @@ func TestBookUsecase_Update(t *testing.T) {
 				ISBN:       book.ISBN,
 				AuthorID:   book.AuthorID,
 				AuthorName: book.AuthorName,
+				Version:    2,
 			},
 			err: nil,
 		}
@@ -451,6 +453,7 @@ func TestBookUsecase_Update(t *testing.T) {
 				ISBN:       *params.request.ISBN,
 				AuthorID:   book.AuthorID,
 				AuthorName: book.AuthorName,
+				Version:    2,
 			},
 			err: nil,
 		}
@@ -482,6 +485,7 @@ func TestBookUsecase_Update(t *testing.T) {
 				ISBN:       book.ISBN,
 				AuthorID:   author2.ID,
 				AuthorName: author2.Name,
+				Version:    2,
 			},
 			err: nil,
 		}
@@ -515,6 +519,7 @@ func TestBookUsecase_Update(t *testing.T) {
 				ISBN:       *params.request.ISBN,
 				AuthorID:   author2.ID,
 				AuthorName: author2.Name,
+				Version:    2,
 			},
 			err: nil,
 		}
@@ -661,3 +666,209 @@ func TestBookUsecase_Delete(t *testing.T) {
 		assert.EqualValues(t, returned.data, resp)
 	})
 }
+
+func TestBookUsecase_IfMatch(t *testing.T) {
+	db := newDatabase()
+	authorRepo := repository.NewAuthorRepository()
+	bookRepo := repository.NewBookRepository()
+	authorUc := usecase.NewAuthorUsecase(db, authorRepo, bookRepo, repository.NewRevisionRepository())
+	bookUc := usecase.NewBookUsecase(db, bookRepo, authorRepo, repository.NewRevisionRepository())
+
+	author, err := authorUc.Create(context.Background(), &model.CreateAuthorRequest{Name: "Author Name 1"})
+	assert.NoError(t, err)
+	book, err := bookUc.Create(context.Background(), &model.CreateBookRequest{
+		Title:    "Book Title 1",
+		ISBN:     "9780441172719",
+		AuthorID: author.ID,
+	})
+	assert.NoError(t, err)
+	etag := model.ETag(book)
+
+	t.Run("Positive Case 1 - update with current etag after a new rating", func(t *testing.T) {
+		assert.NoError(t, bookRepo.UpdateRating(db, book.ID, 4.5, 2))
+		rated, err := bookUc.Get(context.Background(), &model.GetBookRequest{ID: book.ID})
+		assert.NoError(t, err)
+		assert.NotEqualValues(t, etag, model.ETag(rated))
+
+		res, err := bookUc.Update(context.Background(), &model.UpdateBookRequest{
+			ID:      book.ID,
+			Title:   util.ToPointer("Book Title 1 (Revised)"),
+			IfMatch: etag,
+		})
+		assert.NoError(t, err)
+		assert.EqualValues(t, "Book Title 1 (Revised)", res.Title)
+	})
+
+	t.Run("Negative Case 1 - update with stale etag", func(t *testing.T) {
+		_, err := bookUc.Update(context.Background(), &model.UpdateBookRequest{
+			ID:      book.ID,
+			Title:   util.ToPointer("Book Title 1 (Overwritten)"),
+			IfMatch: etag,
+		})
+		assert.EqualValues(t, model.ErrorPreconditionFailed(errors.New("book has been modified")), err)
+
+		res, err := bookUc.Get(context.Background(), &model.GetBookRequest{ID: book.ID})
+		assert.NoError(t, err)
+		assert.EqualValues(t, "Book Title 1 (Revised)", res.Title)
+	})
+
+	t.Run("Negative Case 2 - delete with stale etag", func(t *testing.T) {
+		_, err := bookUc.Delete(context.Background(), &model.DeleteBookRequest{ID: book.ID, IfMatch: etag})
+		assert.EqualValues(t, model.ErrorPreconditionFailed(errors.New("book has been modified")), err)
+	})
+
+	t.Run("Negative Case 3 - saved since it was read", func(t *testing.T) {
+		first, err := bookRepo.FindByID(db, book.ID)
+		assert.NoError(t, err)
+		second, err := bookRepo.FindByID(db, book.ID)
+		assert.NoError(t, err)
+
+		first.PageCount = 412
+		assert.NoError(t, bookRepo.Update(db, first))
+		second.PageCount = 896
+		assert.ErrorIs(t, bookRepo.Update(db, second), repository.ErrStaleEntity)
+
+		res, err := bookUc.Get(context.Background(), &model.GetBookRequest{ID: book.ID})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 412, res.PageCount)
+	})
+
+	t.Run("Positive Case 2 - review between read and update", func(t *testing.T) {
+		read, err := bookRepo.FindByID(db, book.ID)
+		assert.NoError(t, err)
+
+		reader, err := usecase.NewUserUsecase(db, repository.NewUserRepository(), "jwtKey", 10*time.Second).
+			Register(context.Background(), &model.RegisterUserRequest{Username: "reader", Password: "password"})
+		assert.NoError(t, err)
+		_, err = usecase.NewReviewUsecase(db, repository.NewReviewRepository(), bookRepo).
+			Upsert(context.Background(), &model.UpsertReviewRequest{UserID: reader.ID, BookID: book.ID, Rating: 3})
+		assert.NoError(t, err)
+
+		read.PageCount = 328
+		assert.NoError(t, bookRepo.Update(db, read))
+
+		res, err := bookUc.Get(context.Background(), &model.GetBookRequest{ID: book.ID})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 328, res.PageCount)
+		assert.EqualValues(t, 3, res.AverageRating)
+		assert.EqualValues(t, 1, res.RatingCount)
+	})
+
+	t.Run("Positive Case 3 - delete with any etag", func(t *testing.T) {
+		id, err := bookUc.Delete(context.Background(), &model.DeleteBookRequest{ID: book.ID, IfMatch: "*"})
+		assert.NoError(t, err)
+		assert.EqualValues(t, book.ID, *id)
+	})
+}
+
+func TestBookUsecase_Revisions(t *testing.T) {
+	authorUc, bookUc := newAuthorAndBookUsecase()
+
+	author1, err := authorUc.Create(context.Background(), &model.CreateAuthorRequest{Name: "Author Name 1"})
+	assert.NoError(t, err)
+	author2, err := authorUc.Create(context.Background(), &model.CreateAuthorRequest{Name: "Author Name 2"})
+	assert.NoError(t, err)
+	book, err := bookUc.Create(context.Background(), &model.CreateBookRequest{
+		Title:    "Book Title 1",
+		ISBN:     "9780441172719",
+		AuthorID: author1.ID,
+	})
+	assert.NoError(t, err)
+	other, err := bookUc.Create(context.Background(), &model.CreateBookRequest{
+		Title:    "Book Title 2",
+		ISBN:     "9780451524935",
+		AuthorID: author1.ID,
+	})
+	assert.NoError(t, err)
+
+	getRevisions := func(t *testing.T, id int) ([]model.RevisionResponse, int64) {
+		request := &model.GetManyRevisionsRequest{ID: id}
+		request.Page = 1
+		request.Size = 10
+		revisions, total, err := bookUc.GetRevisions(context.Background(), request)
+		assert.NoError(t, err)
+		return revisions, total
+	}
+
+	t.Run("Positive Case 1 - no revisions before update", func(t *testing.T) {
+		_, total := getRevisions(t, book.ID)
+		assert.EqualValues(t, 0, total)
+	})
+
+	t.Run("Positive Case 2 - update records revisions", func(t *testing.T) {
+		_, err := bookUc.Update(context.Background(), &model.UpdateBookRequest{
+			ID:        book.ID,
+			Title:     util.ToPointer("Book Title 1 (Revised)"),
+			UpdatedBy: util.ToPointer(7),
+		})
+		assert.NoError(t, err)
+		_, err = bookUc.Update(context.Background(), &model.UpdateBookRequest{
+			ID:        book.ID,
+			ISBN:      util.ToPointer("9780441013593"),
+			AuthorID:  &author2.ID,
+			PageCount: util.ToPointer(412),
+		})
+		assert.NoError(t, err)
+		_, err = bookUc.Update(context.Background(), &model.UpdateBookRequest{ID: book.ID})
+		assert.NoError(t, err)
+
+		revisions, total := getRevisions(t, book.ID)
+		assert.EqualValues(t, 3, total)
+		assert.EqualValues(t, 3, revisions[0].Revision)
+		assert.EqualValues(t, 1, revisions[2].Revision)
+		assert.Nil(t, revisions[2].CreatedBy)
+		assert.EqualValues(t, util.ToPointer(7), revisions[1].CreatedBy)
+		assert.JSONEq(t, `{"title":"Book Title 1","isbn":"9780441172719","author_id":1,"page_count":0,"language":"","series":"","series_index":0}`, string(revisions[2].Data))
+	})
+
+	t.Run("Positive Case 3 - diff", func(t *testing.T) {
+		res, err := bookUc.DiffRevisions(context.Background(), &model.DiffRevisionsRequest{ID: book.ID, From: 1, To: 3})
+		assert.NoError(t, err)
+		assert.EqualValues(t, &model.RevisionDiffResponse{
+			From: 1,
+			To:   3,
+			Changes: []model.RevisionChangeResponse{
+				{Field: "author_id", From: float64(author1.ID), To: float64(author2.ID)},
+				{Field: "isbn", From: "9780441172719", To: "9780441013593"},
+				{Field: "page_count", From: float64(0), To: float64(412)},
+				{Field: "title", From: "Book Title 1", To: "Book Title 1 (Revised)"},
+			},
+		}, res)
+	})
+
+	t.Run("Negative Case 1 - revision not found", func(t *testing.T) {
+		_, err := bookUc.DiffRevisions(context.Background(), &model.DiffRevisionsRequest{ID: book.ID, From: 1, To: 4})
+		assert.EqualValues(t, model.ErrorNotFound(errors.New("revision not found")), err)
+		_, err = bookUc.RestoreRevision(context.Background(), &model.RestoreRevisionRequest{ID: other.ID, Revision: 1})
+		assert.EqualValues(t, model.ErrorNotFound(errors.New("revision not found")), err)
+	})
+
+	t.Run("Negative Case 2 - isbn is used by another book", func(t *testing.T) {
+		_, err := bookUc.Update(context.Background(), &model.UpdateBookRequest{ID: other.ID, ISBN: util.ToPointer("9780441172719")})
+		assert.NoError(t, err)
+
+		_, err = bookUc.RestoreRevision(context.Background(), &model.RestoreRevisionRequest{ID: book.ID, Revision: 1})
+		assert.EqualValues(t, model.ErrorConflict(errors.New("isbn is used by another book")), err)
+	})
+
+	t.Run("Positive Case 4 - restore", func(t *testing.T) {
+		_, err := bookUc.Update(context.Background(), &model.UpdateBookRequest{ID: other.ID, ISBN: util.ToPointer("9780451524935")})
+		assert.NoError(t, err)
+
+		res, err := bookUc.RestoreRevision(context.Background(), &model.RestoreRevisionRequest{
+			ID:         book.ID,
+			Revision:   2,
+			RestoredBy: util.ToPointer(8),
+		})
+		assert.NoError(t, err)
+		assert.EqualValues(t, "Book Title 1 (Revised)", res.Title)
+		assert.EqualValues(t, "9780441172719", res.ISBN)
+		assert.EqualValues(t, author1.ID, res.AuthorID)
+		assert.EqualValues(t, "Author Name 1", res.AuthorName)
+		assert.EqualValues(t, 0, res.PageCount)
+
+		revisions, total := getRevisions(t, book.ID)
+		assert.EqualValues(t, 4, total)
+		assert.EqualValues(t, util.ToPointer(8), revisions[0].CreatedBy)
+	})
+}
@@ -0,0 +1,316 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/gotracing"
+	"gorm.io/gorm"
+)
+
+type ChallengeUsecase struct {
+	db                        *gorm.DB
+	repository                *repository.ChallengeRepository
+	participantRepository     *repository.ChallengeParticipantRepository
+	readingProgressRepository *repository.ReadingProgressRepository
+}
+
+func NewChallengeUsecase(
+	db *gorm.DB,
+	repository *repository.ChallengeRepository,
+	participantRepository *repository.ChallengeParticipantRepository,
+	readingProgressRepository *repository.ReadingProgressRepository,
+) *ChallengeUsecase {
+	return &ChallengeUsecase{
+		db,
+		repository,
+		participantRepository,
+		readingProgressRepository,
+	}
+}
+
+func (uc *ChallengeUsecase) GetMany(ctx context.Context, request *model.GetManyChallengesRequest) ([]model.ChallengeResponse, int64, error) {
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
+	defer tx.Rollback()
+
+	var activeAt *time.Time
+	if request.Active != nil && *request.Active {
+		now := time.Now().UTC()
+		activeAt = &now
+	}
+
+	challenges, total, err := uc.repository.Search(ctx, tx, activeAt, request.Page, request.Size)
+	if err != nil {
+		return nil, 0, model.ErrorInternalServerError(errors.New("failed to get many challenges"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, 0, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return model.ToChallengesResponse(challenges), total, nil
+}
+
+func (uc *ChallengeUsecase) Get(ctx context.Context, request *model.GetChallengeRequest) (*model.ChallengeResponse, error) {
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
+	defer tx.Rollback()
+
+	challenge, err := uc.repository.FindByID(tx, request.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrorNotFound(errors.New("challenge not found"))
+		}
+		return nil, model.ErrorInternalServerError(errors.New("failed to find challenge data by id"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return model.ToChallengeResponse(challenge), nil
+}
+
+func (uc *ChallengeUsecase) Create(ctx context.Context, request *model.CreateChallengeRequest) (*model.ChallengeResponse, error) {
+	tx := begin(ctx, uc.db)
+	defer tx.Rollback()
+
+	challenge := &entity.Challenge{
+		Name:             request.Name,
+		Description:      request.Description,
+		StartAt:          request.StartAt,
+		EndAt:            request.EndAt,
+		TargetBooks:      request.TargetBooks,
+		AuthorBornBefore: request.AuthorBornBefore,
+		AuthorBornAfter:  request.AuthorBornAfter,
+	}
+
+	if err := uc.repository.Create(tx, challenge); err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to create new challenge"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return model.ToChallengeResponse(challenge), nil
+}
+
+func (uc *ChallengeUsecase) Update(ctx context.Context, request *model.UpdateChallengeRequest) (*model.ChallengeResponse, error) {
+	tx := begin(ctx, uc.db)
+	defer tx.Rollback()
+
+	challenge, err := uc.repository.FindByID(tx, request.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrorNotFound(errors.New("id not found"))
+		}
+		return nil, model.ErrorInternalServerError(errors.New("failed to find challenge data by id"))
+	}
+
+	if request.Name != nil && *request.Name != "" {
+		challenge.Name = *request.Name
+	}
+
+	if request.Description != nil {
+		challenge.Description = *request.Description
+	}
+
+	if request.StartAt != nil {
+		challenge.StartAt = *request.StartAt
+	}
+
+	if request.EndAt != nil {
+		challenge.EndAt = *request.EndAt
+	}
+
+	if request.TargetBooks != nil {
+		challenge.TargetBooks = *request.TargetBooks
+	}
+
+	if request.AuthorBornBefore != nil {
+		challenge.AuthorBornBefore = request.AuthorBornBefore
+	}
+
+	if request.AuthorBornAfter != nil {
+		challenge.AuthorBornAfter = request.AuthorBornAfter
+	}
+
+	if !challenge.EndAt.After(challenge.StartAt) {
+		return nil, model.ErrorBadRequest(errors.New("end_at must be after start_at"))
+	}
+
+	if err := uc.repository.Update(tx, challenge); err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to update challenge"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return model.ToChallengeResponse(challenge), nil
+}
+
+func (uc *ChallengeUsecase) Delete(ctx context.Context, request *model.DeleteChallengeRequest) (*int, error) {
+	tx := begin(ctx, uc.db)
+	defer tx.Rollback()
+
+	challenge, err := uc.repository.FindByID(tx, request.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrorNotFound(errors.New("id not found"))
+		}
+		return nil, model.ErrorInternalServerError(errors.New("failed to find challenge data by id"))
+	}
+
+	if err := uc.participantRepository.DeleteByChallengeID(tx, challenge.ID); err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to delete challenge participants"))
+	}
+
+	if err := uc.repository.Delete(tx, challenge); err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to delete challenge"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return &challenge.ID, nil
+}
+
+func (uc *ChallengeUsecase) Join(ctx context.Context, request *model.JoinChallengeRequest) (*model.ChallengeParticipantResponse, error) {
+	tx := begin(ctx, uc.db)
+	defer tx.Rollback()
+
+	challenge, err := uc.repository.FindByID(tx, request.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrorNotFound(errors.New("challenge not found"))
+		}
+		return nil, model.ErrorInternalServerError(errors.New("failed to find challenge data by id"))
+	}
+
+	if time.Now().After(challenge.EndAt) {
+		return nil, model.ErrorBadRequest(errors.New("challenge has ended"))
+	}
+
+	participant := &entity.ChallengeParticipant{
+		ChallengeID: challenge.ID,
+		UserID:      request.UserID,
+	}
+
+	if err := uc.participantRepository.Create(tx, participant); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, model.ErrorBadRequest(errors.New("already joined the challenge"))
+		}
+		return nil, model.ErrorInternalServerError(errors.New("failed to join challenge"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return model.ToChallengeParticipantResponse(participant), nil
+}
+
+func (uc *ChallengeUsecase) GetLeaderboard(ctx context.Context, request *model.GetChallengeLeaderboardRequest) ([]model.ChallengeLeaderboardEntryResponse, error) {
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
+	defer tx.Rollback()
+
+	challenge, err := uc.repository.FindByID(tx, request.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrorNotFound(errors.New("challenge not found"))
+		}
+		return nil, model.ErrorInternalServerError(errors.New("failed to find challenge data by id"))
+	}
+
+	participants, err := uc.participantRepository.FindAllByChallengeID(tx, challenge.ID)
+	if err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to find challenge participants"))
+	}
+
+	userIDs := make([]int, len(participants))
+	for i, participant := range participants {
+		userIDs[i] = participant.UserID
+	}
+
+	progresses, err := uc.readingProgressRepository.FindAllByUserIDs(tx, userIDs)
+	if err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to find reading progresses"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return calculateChallengeLeaderboard(challenge, participants, progresses), nil
+}
+
+func calculateChallengeLeaderboard(
+	challenge *entity.Challenge,
+	participants []entity.ChallengeParticipant,
+	progresses []entity.ReadingProgress,
+) []model.ChallengeLeaderboardEntryResponse {
+	finished := map[int]int{}
+	walkReadingHistory(progresses, func(event readingEvent) {
+		if !event.finished {
+			return
+		}
+
+		readAt := event.progress.ReadAt
+		if readAt.Before(challenge.StartAt) || readAt.After(challenge.EndAt) {
+			return
+		}
+
+		// Imported authors have no birthdate, they match neither filter.
+		birthdate := event.progress.Book.Author.Birthdate
+		if (challenge.AuthorBornBefore != nil || challenge.AuthorBornAfter != nil) && birthdate.IsZero() {
+			return
+		}
+		if challenge.AuthorBornBefore != nil && !birthdate.Before(*challenge.AuthorBornBefore) {
+			return
+		}
+		if challenge.AuthorBornAfter != nil && !birthdate.After(*challenge.AuthorBornAfter) {
+			return
+		}
+
+		finished[event.progress.UserID]++
+	})
+
+	leaderboard := make([]model.ChallengeLeaderboardEntryResponse, len(participants))
+	for i, participant := range participants {
+		leaderboard[i] = model.ChallengeLeaderboardEntryResponse{
+			UserID:        participant.UserID,
+			Username:      participant.User.Username,
+			BooksFinished: finished[participant.UserID],
+			Completed:     finished[participant.UserID] >= challenge.TargetBooks,
+		}
+	}
+
+	sort.SliceStable(leaderboard, func(i, j int) bool {
+		return leaderboard[i].BooksFinished > leaderboard[j].BooksFinished
+	})
+
+	for i := range leaderboard {
+		if i > 0 && leaderboard[i].BooksFinished == leaderboard[i-1].BooksFinished {
+			leaderboard[i].Rank = leaderboard[i-1].Rank
+		} else {
+			leaderboard[i].Rank = i + 1
+		}
+	}
+
+	return leaderboard
+}
@@ -0,0 +1,155 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChallengeUsecase_Create(t *testing.T) {
+	f := newReadingProgressFixture(t)
+
+	t.Run("Positive Case - create challenge", func(t *testing.T) {
+		request := &model.CreateChallengeRequest{
+			Name:             "Classics",
+			StartAt:          time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndAt:            time.Date(2020, 12, 31, 0, 0, 0, 0, time.UTC),
+			TargetBooks:      5,
+			AuthorBornBefore: util.ToPointer(time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)),
+		}
+
+		resp, err := f.challengeUc.Create(context.Background(), request)
+		assert.NoError(t, err)
+		assert.EqualValues(t, &model.ChallengeResponse{
+			ID:               1,
+			Name:             request.Name,
+			StartAt:          request.StartAt,
+			EndAt:            request.EndAt,
+			TargetBooks:      request.TargetBooks,
+			AuthorBornBefore: request.AuthorBornBefore,
+		}, resp)
+	})
+}
+
+func TestChallengeUsecase_Update(t *testing.T) {
+	f := newReadingProgressFixture(t)
+
+	challenge, err := f.challengeUc.Create(context.Background(), &model.CreateChallengeRequest{
+		Name:        "Classics",
+		StartAt:     time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndAt:       time.Date(2020, 12, 31, 0, 0, 0, 0, time.UTC),
+		TargetBooks: 5,
+	})
+	assert.NoError(t, err)
+
+	t.Run("Positive Case - update target", func(t *testing.T) {
+		resp, err := f.challengeUc.Update(context.Background(), &model.UpdateChallengeRequest{
+			ID:          challenge.ID,
+			TargetBooks: util.ToPointer(3),
+		})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 3, resp.TargetBooks)
+	})
+
+	t.Run("Negative Case - end before start", func(t *testing.T) {
+		resp, err := f.challengeUc.Update(context.Background(), &model.UpdateChallengeRequest{
+			ID:    challenge.ID,
+			EndAt: util.ToPointer(time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)),
+		})
+		assert.EqualValues(t, model.ErrorBadRequest(errors.New("end_at must be after start_at")), err)
+		assert.Nil(t, resp)
+	})
+}
+
+func TestChallengeUsecase_GetLeaderboard(t *testing.T) {
+	f := newReadingProgressFixture(t)
+
+	now := time.Now().UTC()
+	challenge, err := f.challengeUc.Create(context.Background(), &model.CreateChallengeRequest{
+		Name:             "Read 2 books by authors born before 1900",
+		StartAt:          now.AddDate(0, -1, 0),
+		EndAt:            now.AddDate(0, 1, 0),
+		TargetBooks:      2,
+		AuthorBornBefore: util.ToPointer(time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)),
+	})
+	assert.NoError(t, err)
+
+	user2, err := f.userUc.Register(context.Background(), &model.RegisterUserRequest{
+		Username: "reader_2",
+		Password: "password",
+	})
+	assert.NoError(t, err)
+	user3, err := f.userUc.Register(context.Background(), &model.RegisterUserRequest{
+		Username: "reader_3",
+		Password: "password",
+	})
+	assert.NoError(t, err)
+
+	for _, userID := range []int{f.user.ID, user2.ID, user3.ID} {
+		_, err := f.challengeUc.Join(context.Background(), &model.JoinChallengeRequest{
+			UserID: userID,
+			ID:     challenge.ID,
+		})
+		assert.NoError(t, err)
+	}
+
+	// book1 and book2 are written by author1 (born 1811), book3 by author2 (born 1922).
+	f.progressAs(t, f.user.ID, f.book3.ID, nil, util.ToPointer(100.0), now.AddDate(0, 0, -3))
+	f.progressAs(t, user2.ID, f.book1.ID, nil, util.ToPointer(100.0), now.AddDate(0, 0, -3))
+	f.progressAs(t, user2.ID, f.book2.ID, nil, util.ToPointer(100.0), now.AddDate(0, 0, -2))
+	f.progressAs(t, user3.ID, f.book1.ID, nil, util.ToPointer(100.0), now.AddDate(0, -2, 0))
+
+	t.Run("Positive Case - leaderboard", func(t *testing.T) {
+		resp, err := f.challengeUc.GetLeaderboard(context.Background(), &model.GetChallengeLeaderboardRequest{
+			ID: challenge.ID,
+		})
+		assert.NoError(t, err)
+		assert.EqualValues(t, []model.ChallengeLeaderboardEntryResponse{
+			{Rank: 1, UserID: user2.ID, Username: user2.Username, BooksFinished: 2, Completed: true},
+			{Rank: 2, UserID: f.user.ID, Username: f.user.Username, BooksFinished: 0},
+			{Rank: 2, UserID: user3.ID, Username: user3.Username, BooksFinished: 0},
+		}, resp)
+	})
+
+	t.Run("Positive Case - author without birthdate is left out", func(t *testing.T) {
+		// Importers create authors without a birthdate.
+		author, err := f.authorUc.Create(context.Background(), &model.CreateAuthorRequest{Name: "Imported Author"})
+		assert.NoError(t, err)
+		book, err := f.bookUc.Create(context.Background(), &model.CreateBookRequest{
+			Title:    "Imported Book",
+			ISBN:     "978-0141439518",
+			AuthorID: author.ID,
+		})
+		assert.NoError(t, err)
+		f.progressAs(t, user3.ID, book.ID, nil, util.ToPointer(100.0), now.AddDate(0, 0, -1))
+
+		resp, err := f.challengeUc.GetLeaderboard(context.Background(), &model.GetChallengeLeaderboardRequest{
+			ID: challenge.ID,
+		})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 0, resp[2].BooksFinished)
+		assert.EqualValues(t, user3.ID, resp[2].UserID)
+	})
+
+	t.Run("Negative Case 1 - join twice", func(t *testing.T) {
+		resp, err := f.challengeUc.Join(context.Background(), &model.JoinChallengeRequest{
+			UserID: f.user.ID,
+			ID:     challenge.ID,
+		})
+		assert.EqualValues(t, model.ErrorBadRequest(errors.New("already joined the challenge")), err)
+		assert.Nil(t, resp)
+	})
+
+	t.Run("Negative Case 2 - challenge not found", func(t *testing.T) {
+		resp, err := f.challengeUc.GetLeaderboard(context.Background(), &model.GetChallengeLeaderboardRequest{
+			ID: 0,
+		})
+		assert.EqualValues(t, model.ErrorNotFound(errors.New("challenge not found")), err)
+		assert.Nil(t, resp)
+	})
+}
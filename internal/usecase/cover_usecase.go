@@ -0,0 +1,296 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/bookshelf/internal/storage"
+	"github.com/mnaufalhilmym/bookshelf/internal/thumbnail"
+	"github.com/mnaufalhilmym/gotracing"
+	"gorm.io/gorm"
+)
+
+const MaxCoverSize = 5 << 20
+
+var coverSizes = []struct {
+	name  string
+	width int
+}{
+	{model.CoverSizeSmall, 150},
+	{model.CoverSizeMedium, 300},
+	{model.CoverSizeLarge, 600},
+}
+
+type CoverUsecase struct {
+	db             *gorm.DB
+	storage        storage.Storage
+	bookRepository *repository.BookRepository
+}
+
+func NewCoverUsecase(
+	db *gorm.DB,
+	storage storage.Storage,
+	bookRepository *repository.BookRepository,
+) *CoverUsecase {
+	return &CoverUsecase{
+		db,
+		storage,
+		bookRepository,
+	}
+}
+
+// Open returns the cover image of the book, or one of its thumbnails. The
+// caller must close it.
+func (uc *CoverUsecase) Open(ctx context.Context, request *model.GetBookCoverRequest) (*model.CoverImageResponse, io.ReadSeekCloser, error) {
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
+	defer tx.Rollback()
+
+	book, err := uc.bookRepository.FindByID(tx, request.BookID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, model.ErrorNotFound(errors.New("book not found"))
+		}
+		return nil, nil, model.ErrorInternalServerError(errors.New("failed to find book data by id"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	if book.CoverKey == "" {
+		return nil, nil, model.ErrorNotFound(errors.New("book has no cover"))
+	}
+
+	response := &model.CoverImageResponse{
+		ContentType: book.CoverContentType,
+		Immutable:   request.Version != "" && request.Version == model.CoverVersion(book),
+	}
+	key := book.CoverKey
+
+	// Covers stored before thumbnails were generated have no checksum, and
+	// are served in their original size.
+	if book.CoverChecksum != "" {
+		response.ETag = `"` + book.CoverChecksum + `"`
+		if request.Size != "" {
+			response.ContentType = "image/jpeg"
+			response.ETag = `"` + book.CoverChecksum + "-" + request.Size + `"`
+			key = coverThumbnailKey(book, request.Size)
+		}
+	}
+
+	content, err := openStored(ctx, uc.storage, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return response, content, nil
+}
+
+// Upload replaces the cover of the book. The previous cover is removed once
+// the new one is saved.
+func (uc *CoverUsecase) Upload(ctx context.Context, request *model.UploadBookCoverRequest) (*model.BookResponse, error) {
+	cover, err := newCoverImage(request.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := begin(ctx, uc.db)
+	defer tx.Rollback()
+
+	book, err := uc.bookRepository.FindByID(tx, request.BookID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrorNotFound(errors.New("book not found"))
+		}
+		return nil, model.ErrorInternalServerError(errors.New("failed to find book data by id"))
+	}
+
+	if book.CoverChecksum == cover.checksum {
+		return model.ToBookResponse(book), nil
+	}
+	previous := coverKeys(book)
+
+	stored, err := cover.store(ctx, uc.storage, book)
+	committed := false
+	defer func() {
+		if !committed {
+			removeStored(uc.storage, stored)
+		}
+	}()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.bookRepository.Update(tx, book); err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to update book data"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+	committed = true
+
+	removeStored(uc.storage, previous)
+
+	return model.ToBookResponse(book), nil
+}
+
+func (uc *CoverUsecase) Delete(ctx context.Context, request *model.DeleteBookCoverRequest) (*model.BookResponse, error) {
+	tx := begin(ctx, uc.db)
+	defer tx.Rollback()
+
+	book, err := uc.bookRepository.FindByID(tx, request.BookID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrorNotFound(errors.New("book not found"))
+		}
+		return nil, model.ErrorInternalServerError(errors.New("failed to find book data by id"))
+	}
+
+	if book.CoverKey == "" {
+		return nil, model.ErrorNotFound(errors.New("book has no cover"))
+	}
+	previous := coverKeys(book)
+
+	book.CoverKey = ""
+	book.CoverContentType = ""
+	book.CoverChecksum = ""
+
+	if err := uc.bookRepository.Update(tx, book); err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to update book data"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	removeStored(uc.storage, previous)
+
+	return model.ToBookResponse(book), nil
+}
+
+// coverImage is a validated cover with its thumbnails, ready to be stored.
+type coverImage struct {
+	contentType string
+	checksum    string
+	content     []byte
+	thumbnails  map[string][]byte
+}
+
+// newCoverImage checks the type and size of the image from its content and
+// generates the thumbnails.
+func newCoverImage(content []byte) (*coverImage, error) {
+	if len(content) > MaxCoverSize {
+		return nil, model.ErrorRequestEntityTooLarge(fmt.Errorf("cover must not be larger than %d MiB", MaxCoverSize>>20))
+	}
+
+	contentType, err := thumbnail.Sniff(content)
+	if err != nil {
+		return nil, model.ErrorUnsupportedMediaType(errors.New(err.Error()))
+	}
+
+	img, err := thumbnail.Decode(content)
+	if err != nil {
+		gotracing.Error("Failed to decode cover", err)
+		return nil, model.ErrorBadRequest(errors.New(err.Error()))
+	}
+
+	checksum := sha256.Sum256(content)
+	cover := &coverImage{
+		contentType: contentType,
+		checksum:    hex.EncodeToString(checksum[:]),
+		content:     content,
+		thumbnails:  map[string][]byte{},
+	}
+
+	for _, size := range coverSizes {
+		data, err := thumbnail.JPEG(img, size.width)
+		if err != nil {
+			gotracing.Error("Failed to encode cover thumbnail", err)
+			return nil, model.ErrorInternalServerError(errors.New("failed to generate cover thumbnails"))
+		}
+		cover.thumbnails[size.name] = data
+	}
+
+	return cover, nil
+}
+
+// store saves the cover and its thumbnails and sets them on the book. It
+// returns the keys stored so far, also on failure, so the caller can remove
+// them when the book is not saved.
+func (c *coverImage) store(ctx context.Context, s storage.Storage, book *entity.Book) ([]string, error) {
+	var stored []string
+
+	key := fmt.Sprintf("books/%d/covers/%s/original%s", book.ID, c.checksum, imageExtension(c.contentType))
+	if err := s.Put(ctx, key, bytes.NewReader(c.content)); err != nil {
+		gotracing.Error("Failed to store book cover", err)
+		return stored, model.ErrorInternalServerError(errors.New("failed to store cover"))
+	}
+	stored = append(stored, key)
+
+	book.CoverKey = key
+	book.CoverContentType = c.contentType
+	book.CoverChecksum = c.checksum
+
+	for _, size := range coverSizes {
+		key := coverThumbnailKey(book, size.name)
+		if err := s.Put(ctx, key, bytes.NewReader(c.thumbnails[size.name])); err != nil {
+			gotracing.Error("Failed to store book cover thumbnail", err)
+			return stored, model.ErrorInternalServerError(errors.New("failed to store cover"))
+		}
+		stored = append(stored, key)
+	}
+
+	return stored, nil
+}
+
+func coverThumbnailKey(book *entity.Book, size string) string {
+	return fmt.Sprintf("books/%d/covers/%s/%s.jpg", book.ID, book.CoverChecksum, size)
+}
+
+func coverKeys(book *entity.Book) []string {
+	if book.CoverKey == "" {
+		return nil
+	}
+
+	keys := []string{book.CoverKey}
+	if book.CoverChecksum != "" {
+		for _, size := range coverSizes {
+			keys = append(keys, coverThumbnailKey(book, size.name))
+		}
+	}
+	return keys
+}
+
+func openStored(ctx context.Context, s storage.Storage, key string) (io.ReadSeekCloser, error) {
+	content, err := s.Open(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			gotracing.Error("Stored object is missing", err)
+			return nil, model.ErrorNotFound(errors.New("file content not found"))
+		}
+		gotracing.Error("Failed to open stored object", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to open file"))
+	}
+	return content, nil
+}
+
+func removeStored(s storage.Storage, keys []string) {
+	for _, key := range keys {
+		if err := s.Delete(context.Background(), key); err != nil {
+			gotracing.Error("Failed to delete stored object", err)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package usecase_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/bookshelf/internal/storage"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+var pngImage = newImage(400, 600, png.Encode)
+
+var jpegImage = newImage(1200, 1800, func(w io.Writer, img image.Image) error {
+	return jpeg.Encode(w, img, nil)
+})
+
+func newImage(width int, height int, encode func(w io.Writer, img image.Image) error) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.RGBA{R: 200, G: 40, B: 40, A: 255}), image.Point{}, draw.Src)
+
+	buf := new(bytes.Buffer)
+	if err := encode(buf, img); err != nil {
+		panic(err)
+	}
+	return buf.Bytes()
+}
+
+// fakeS3 is an in-memory stand-in for an S3 compatible service. It checks
+// that requests are signed and that the signed payload hash matches the body.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func newFakeS3(t *testing.T) *storage.S3 {
+	fake := &fakeS3{objects: map[string][]byte{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	s3, err := storage.NewS3(server.URL, "bookshelf", "us-east-1", "access", "secret")
+	assert.NoError(t, err)
+	return s3
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=access/") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	key, ok := strings.CutPrefix(r.URL.Path, "/bookshelf/")
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		checksum := sha256.Sum256(body)
+		if r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(checksum[:]) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.objects[key] = body
+	case http.MethodGet, http.MethodHead:
+		object, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(object))
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func newCoverUsecase(t *testing.T, s storage.Storage) (*usecase.CoverUsecase, *model.BookResponse) {
+	db := newDatabase()
+	authorRepo := repository.NewAuthorRepository()
+	bookRepo := repository.NewBookRepository()
+
+	author, err := usecase.NewAuthorUsecase(db, authorRepo, bookRepo, repository.NewRevisionRepository()).Create(context.Background(), &model.CreateAuthorRequest{
+		Name:      "Author Name 1",
+		Birthdate: time.Date(2011, 11, 11, 11, 11, 11, 111, time.UTC),
+	})
+	assert.NoError(t, err)
+
+	book, err := usecase.NewBookUsecase(db, bookRepo, authorRepo, repository.NewRevisionRepository()).Create(context.Background(), &model.CreateBookRequest{
+		Title:    "Book Title 1",
+		ISBN:     "978-1451673319",
+		AuthorID: author.ID,
+	})
+	assert.NoError(t, err)
+
+	return usecase.NewCoverUsecase(db, s, bookRepo), book
+}
+
+func readCover(t *testing.T, uc *usecase.CoverUsecase, request *model.GetBookCoverRequest) (*model.CoverImageResponse, []byte) {
+	cover, content, err := uc.Open(context.Background(), request)
+	assert.NoError(t, err)
+	defer content.Close()
+
+	data, err := io.ReadAll(content)
+	assert.NoError(t, err)
+	return cover, data
+}
+
+func TestCoverUsecase_Upload(t *testing.T) {
+	storages := map[string]func(t *testing.T) storage.Storage{
+		"local": func(t *testing.T) storage.Storage { return storage.NewLocal(t.TempDir()) },
+		"s3":    func(t *testing.T) storage.Storage { return newFakeS3(t) },
+	}
+
+	for name, newStorage := range storages {
+		t.Run(name, func(t *testing.T) {
+			s := newStorage(t)
+			uc, book := newCoverUsecase(t, s)
+
+			var uploaded *model.BookResponse
+
+			t.Run("Positive Case - upload png", func(t *testing.T) {
+				res, err := uc.Upload(context.Background(), &model.UploadBookCoverRequest{
+					BookID:  book.ID,
+					Content: pngImage,
+				})
+				assert.NoError(t, err)
+
+				checksum := sha256.Sum256(pngImage)
+				version := hex.EncodeToString(checksum[:])[:16]
+				assert.EqualValues(t, &model.CoverResponse{
+					Original: "/books/1/cover?v=" + version,
+					Small:    "/books/1/cover/small?v=" + version,
+					Medium:   "/books/1/cover/medium?v=" + version,
+					Large:    "/books/1/cover/large?v=" + version,
+				}, res.Cover)
+
+				cover, content := readCover(t, uc, &model.GetBookCoverRequest{BookID: book.ID, Version: version})
+				assert.EqualValues(t, "image/png", cover.ContentType)
+				assert.True(t, cover.Immutable)
+				assert.EqualValues(t, pngImage, content)
+
+				uploaded = res
+			})
+
+			t.Run("Positive Case - thumbnails keep aspect ratio", func(t *testing.T) {
+				widths := map[string]int{model.CoverSizeSmall: 150, model.CoverSizeMedium: 300, model.CoverSizeLarge: 400}
+				for size, width := range widths {
+					cover, content := readCover(t, uc, &model.GetBookCoverRequest{BookID: book.ID, Size: size})
+					assert.EqualValues(t, "image/jpeg", cover.ContentType)
+					assert.False(t, cover.Immutable)
+					assert.Contains(t, cover.ETag, "-"+size)
+
+					config, err := jpeg.DecodeConfig(bytes.NewReader(content))
+					assert.NoError(t, err)
+					assert.EqualValues(t, width, config.Width)
+					assert.EqualValues(t, width*3/2, config.Height)
+				}
+			})
+
+			t.Run("Positive Case - replace with jpeg removes previous cover", func(t *testing.T) {
+				res, err := uc.Upload(context.Background(), &model.UploadBookCoverRequest{
+					BookID:  book.ID,
+					Content: jpegImage,
+				})
+				assert.NoError(t, err)
+				assert.NotEqualValues(t, uploaded.Cover.Original, res.Cover.Original)
+
+				cover, content := readCover(t, uc, &model.GetBookCoverRequest{BookID: book.ID, Size: model.CoverSizeLarge})
+				assert.EqualValues(t, "image/jpeg", cover.ContentType)
+				config, err := jpeg.DecodeConfig(bytes.NewReader(content))
+				assert.NoError(t, err)
+				assert.EqualValues(t, 600, config.Width)
+
+				checksum := sha256.Sum256(pngImage)
+				_, err = s.Open(context.Background(), "books/1/covers/"+hex.EncodeToString(checksum[:])+"/original.png")
+				assert.ErrorIs(t, err, storage.ErrNotFound)
+			})
+
+			t.Run("Negative Case - unsupported type", func(t *testing.T) {
+				_, err := uc.Upload(context.Background(), &model.UploadBookCoverRequest{
+					BookID:  book.ID,
+					Content: []byte("<svg xmlns=\"http://www.w3.org/2000/svg\"></svg>"),
+				})
+				assert.EqualValues(t, model.ErrorUnsupportedMediaType(errors.New("unsupported image type, expected jpeg, png or gif")), err)
+			})
+
+			t.Run("Negative Case - broken image", func(t *testing.T) {
+				_, err := uc.Upload(context.Background(), &model.UploadBookCoverRequest{
+					BookID:  book.ID,
+					Content: pngImage[:64],
+				})
+				assert.EqualValues(t, 400, err.(*model.Error).Code)
+			})
+
+			t.Run("Negative Case - too large", func(t *testing.T) {
+				_, err := uc.Upload(context.Background(), &model.UploadBookCoverRequest{
+					BookID:  book.ID,
+					Content: append(bytes.Clone(pngImage), make([]byte, usecase.MaxCoverSize)...),
+				})
+				assert.EqualValues(t, model.ErrorRequestEntityTooLarge(errors.New("cover must not be larger than 5 MiB")), err)
+			})
+
+			t.Run("Negative Case - book not found", func(t *testing.T) {
+				_, err := uc.Upload(context.Background(), &model.UploadBookCoverRequest{
+					BookID:  100,
+					Content: pngImage,
+				})
+				assert.EqualValues(t, model.ErrorNotFound(errors.New("book not found")), err)
+			})
+		})
+	}
+}
+
+func TestCoverUsecase_Delete(t *testing.T) {
+	uc, book := newCoverUsecase(t, storage.NewLocal(t.TempDir()))
+
+	_, err := uc.Upload(context.Background(), &model.UploadBookCoverRequest{
+		BookID:  book.ID,
+		Content: pngImage,
+	})
+	assert.NoError(t, err)
+
+	t.Run("Positive Case - delete cover", func(t *testing.T) {
+		res, err := uc.Delete(context.Background(), &model.DeleteBookCoverRequest{BookID: book.ID})
+		assert.NoError(t, err)
+		assert.Nil(t, res.Cover)
+
+		_, _, err = uc.Open(context.Background(), &model.GetBookCoverRequest{BookID: book.ID})
+		assert.EqualValues(t, model.ErrorNotFound(errors.New("book has no cover")), err)
+	})
+
+	t.Run("Negative Case - no cover", func(t *testing.T) {
+		_, err := uc.Delete(context.Background(), &model.DeleteBookCoverRequest{BookID: book.ID})
+		assert.EqualValues(t, model.ErrorNotFound(errors.New("book has no cover")), err)
+	})
+}
@@ -0,0 +1,274 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+	"github.com/mnaufalhilmym/bookshelf/internal/format/bibtex"
+	"github.com/mnaufalhilmym/bookshelf/internal/format/bookcsv"
+	"github.com/mnaufalhilmym/bookshelf/internal/format/marc"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/gotracing"
+	"gorm.io/gorm"
+)
+
+const exportBatchSize = 500
+
+type ExportUsecase struct {
+	db             *gorm.DB
+	bookRepository *repository.BookRepository
+}
+
+func NewExportUsecase(
+	db *gorm.DB,
+	bookRepository *repository.BookRepository,
+) *ExportUsecase {
+	return &ExportUsecase{
+		db,
+		bookRepository,
+	}
+}
+
+// ExportBooks writes the books matching the request filters to w in batches.
+// Each batch is flushed before the next one is read, so w receives the export
+// progressively.
+func (uc *ExportUsecase) ExportBooks(ctx context.Context, request *model.ExportBooksRequest, w io.Writer) error {
+	encoder, err := newBookEncoder(request.Format, w)
+	if err != nil {
+		return err
+	}
+
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
+	defer tx.Rollback()
+
+	if err := uc.bookRepository.SearchEach(
+		tx,
+		request.Title,
+		request.ISBN,
+		request.AuthorID,
+		request.AuthorName,
+		request.Sort,
+		exportBatchSize,
+		func(books []entity.Book) error {
+			for i := range books {
+				if err := encoder.encode(&books[i]); err != nil {
+					return err
+				}
+			}
+			return encoder.flush()
+		},
+	); err != nil {
+		gotracing.Error("Failed to export books", err)
+		return model.ErrorInternalServerError(errors.New("failed to export books"))
+	}
+
+	if err := encoder.close(); err != nil {
+		gotracing.Error("Failed to export books", err)
+		return model.ErrorInternalServerError(errors.New("failed to export books"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return nil
+}
+
+func (uc *ExportUsecase) ExportBook(ctx context.Context, request *model.ExportBookRequest, w io.Writer) error {
+	encoder, err := newBookEncoder(request.Format, w)
+	if err != nil {
+		return err
+	}
+
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
+	defer tx.Rollback()
+
+	book, err := uc.bookRepository.FindByID(tx, request.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.ErrorNotFound(errors.New("book not found"))
+		}
+		return model.ErrorInternalServerError(errors.New("failed to find book data by id"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	if err := encoder.encode(book); err != nil {
+		gotracing.Error("Failed to export book", err)
+		return model.ErrorInternalServerError(errors.New("failed to export book"))
+	}
+
+	if err := encoder.close(); err != nil {
+		gotracing.Error("Failed to export book", err)
+		return model.ErrorInternalServerError(errors.New("failed to export book"))
+	}
+
+	return nil
+}
+
+type bookEncoder interface {
+	encode(book *entity.Book) error
+	flush() error
+	close() error
+}
+
+func newBookEncoder(format string, w io.Writer) (bookEncoder, error) {
+	switch format {
+	case model.ExportFormatCSV:
+		return &csvBookEncoder{bookcsv.NewWriter(w), w}, nil
+	case model.ExportFormatNDJSON:
+		return &ndjsonBookEncoder{json.NewEncoder(w), w}, nil
+	case model.ExportFormatBibTeX:
+		return &bibtexBookEncoder{bibtex.NewWriter(w), w}, nil
+	case model.ExportFormatMARC:
+		return &marcBookEncoder{marc.NewWriter(w), w}, nil
+	case model.ExportFormatMARCXML:
+		return &marcXMLBookEncoder{marc.NewXMLWriter(w), w}, nil
+	default:
+		return nil, model.ErrorBadRequest(errors.New("unsupported export format"))
+	}
+}
+
+type csvBookEncoder struct {
+	writer *bookcsv.Writer
+	w      io.Writer
+}
+
+func (e *csvBookEncoder) encode(book *entity.Book) error {
+	return e.writer.Write(&bookcsv.Record{
+		ID:              book.ID,
+		Title:           book.Title,
+		ISBN:            book.ISBN,
+		AuthorID:        book.AuthorID,
+		Author:          book.Author.Name,
+		AuthorBirthdate: book.Author.Birthdate,
+		PageCount:       book.PageCount,
+		AverageRating:   book.RatingAverage,
+		RatingCount:     book.RatingCount,
+	})
+}
+
+func (e *csvBookEncoder) flush() error {
+	if err := e.writer.Flush(); err != nil {
+		return err
+	}
+	return flushWriter(e.w)
+}
+
+func (e *csvBookEncoder) close() error {
+	return e.flush()
+}
+
+type ndjsonBookEncoder struct {
+	encoder *json.Encoder
+	w       io.Writer
+}
+
+func (e *ndjsonBookEncoder) encode(book *entity.Book) error {
+	return e.encoder.Encode(model.ToExportBookResponse(book))
+}
+
+func (e *ndjsonBookEncoder) flush() error {
+	return flushWriter(e.w)
+}
+
+func (e *ndjsonBookEncoder) close() error {
+	return e.flush()
+}
+
+type bibtexBookEncoder struct {
+	writer *bibtex.Writer
+	w      io.Writer
+}
+
+func (e *bibtexBookEncoder) encode(book *entity.Book) error {
+	entry := &bibtex.Entry{
+		Type: "book",
+		Key:  bibtex.Key(book.Author.Name, book.ID),
+		Fields: []bibtex.Field{
+			{Name: "title", Value: book.Title},
+			{Name: "author", Value: book.Author.Name},
+			{Name: "isbn", Value: book.ISBN},
+		},
+	}
+	if book.PageCount > 0 {
+		entry.Fields = append(entry.Fields, bibtex.Field{Name: "pagetotal", Value: strconv.Itoa(book.PageCount)})
+	}
+	return e.writer.Write(entry)
+}
+
+func (e *bibtexBookEncoder) flush() error {
+	return flushWriter(e.w)
+}
+
+func (e *bibtexBookEncoder) close() error {
+	return e.flush()
+}
+
+type marcBookEncoder struct {
+	writer *marc.Writer
+	w      io.Writer
+}
+
+func (e *marcBookEncoder) encode(book *entity.Book) error {
+	return e.writer.Write(toMARCRecord(book))
+}
+
+func (e *marcBookEncoder) flush() error {
+	return flushWriter(e.w)
+}
+
+func (e *marcBookEncoder) close() error {
+	return e.flush()
+}
+
+type marcXMLBookEncoder struct {
+	writer *marc.XMLWriter
+	w      io.Writer
+}
+
+func (e *marcXMLBookEncoder) encode(book *entity.Book) error {
+	return e.writer.Write(toMARCRecord(book))
+}
+
+func (e *marcXMLBookEncoder) flush() error {
+	if err := e.writer.Flush(); err != nil {
+		return err
+	}
+	return flushWriter(e.w)
+}
+
+func (e *marcXMLBookEncoder) close() error {
+	if err := e.writer.Close(); err != nil {
+		return err
+	}
+	return flushWriter(e.w)
+}
+
+func toMARCRecord(book *entity.Book) *marc.Record {
+	return marc.NewRecord(&marc.Book{
+		ID:              book.ID,
+		ISBN:            book.ISBN,
+		Title:           book.Title,
+		Author:          book.Author.Name,
+		AuthorBirthdate: &book.Author.Birthdate,
+		PageCount:       book.PageCount,
+	})
+}
+
+func flushWriter(w io.Writer) error {
+	if flusher, ok := w.(interface{ Flush() }); ok {
+		flusher.Flush()
+	}
+	return nil
+}
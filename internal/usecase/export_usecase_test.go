@@ -0,0 +1,149 @@
+package usecase_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/format/bookcsv"
+	"github.com/mnaufalhilmym/bookshelf/internal/format/marc"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+	"github.com/mnaufalhilmym/bookshelf/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func newExportUsecase(t *testing.T) *usecase.ExportUsecase {
+	db := newDatabase()
+	authorRepo := repository.NewAuthorRepository()
+	bookRepo := repository.NewBookRepository()
+
+	authorUc := usecase.NewAuthorUsecase(db, authorRepo, bookRepo, repository.NewRevisionRepository())
+	bookUc := usecase.NewBookUsecase(db, bookRepo, authorRepo, repository.NewRevisionRepository())
+
+	orwell, err := authorUc.Create(context.Background(), &model.CreateAuthorRequest{
+		Name:      "George Orwell",
+		Birthdate: time.Date(1903, 6, 25, 0, 0, 0, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+	knuth, err := authorUc.Create(context.Background(), &model.CreateAuthorRequest{
+		Name:      "Donald Knuth",
+		Birthdate: time.Date(1938, 1, 10, 0, 0, 0, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+
+	for _, request := range []*model.CreateBookRequest{
+		{Title: "Nineteen Eighty-Four", ISBN: "978-0451524935", AuthorID: orwell.ID, PageCount: 328},
+		{Title: "Animal Farm", ISBN: "978-0451526342", AuthorID: orwell.ID},
+		{Title: "The TeXbook & 100% {Fun}", ISBN: "978-0201134476", AuthorID: knuth.ID, PageCount: 483},
+	} {
+		_, err := bookUc.Create(context.Background(), request)
+		assert.NoError(t, err)
+	}
+
+	return usecase.NewExportUsecase(db, bookRepo)
+}
+
+func TestExportUsecase_ExportBooks(t *testing.T) {
+	uc := newExportUsecase(t)
+
+	t.Run("Positive Case - csv can be imported again", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		err := uc.ExportBooks(context.Background(), &model.ExportBooksRequest{
+			Format: model.ExportFormatCSV,
+			Sort:   util.ToPointer("title"),
+		}, buf)
+		assert.NoError(t, err)
+
+		rows, err := bookcsv.Read(buf)
+		assert.NoError(t, err)
+		assert.Len(t, rows, 3)
+		assert.EqualValues(t, "Animal Farm", rows[0].Title)
+		assert.EqualValues(t, "George Orwell", rows[0].Author)
+		assert.EqualValues(t, time.Date(1903, 6, 25, 0, 0, 0, 0, time.UTC), *rows[0].AuthorBirthdate)
+		assert.EqualValues(t, 483, *rows[2].PageCount)
+	})
+
+	t.Run("Positive Case - ndjson honours filters", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		err := uc.ExportBooks(context.Background(), &model.ExportBooksRequest{
+			Format:     model.ExportFormatNDJSON,
+			AuthorName: util.ToPointer("orwell"),
+			Sort:       util.ToPointer("-id"),
+		}, buf)
+		assert.NoError(t, err)
+
+		var books []model.ExportBookResponse
+		scanner := bufio.NewScanner(buf)
+		for scanner.Scan() {
+			book := model.ExportBookResponse{}
+			assert.NoError(t, json.Unmarshal(scanner.Bytes(), &book))
+			books = append(books, book)
+		}
+		assert.Len(t, books, 2)
+		assert.EqualValues(t, "Animal Farm", books[0].Title)
+		assert.EqualValues(t, "George Orwell", books[0].Author.Name)
+		assert.EqualValues(t, 1, books[0].Author.ID)
+	})
+
+	t.Run("Positive Case - bibtex", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		err := uc.ExportBooks(context.Background(), &model.ExportBooksRequest{
+			Format:   model.ExportFormatBibTeX,
+			AuthorID: util.ToPointer(2),
+		}, buf)
+		assert.NoError(t, err)
+
+		expected := "@book{knuth3,\n" +
+			"  title = {The TeXbook \\& 100\\% \\{Fun\\}},\n" +
+			"  author = {Donald Knuth},\n" +
+			"  isbn = {978-0201134476},\n" +
+			"  pagetotal = {483}\n" +
+			"}\n"
+		assert.EqualValues(t, expected, buf.String())
+	})
+
+	t.Run("Positive Case - empty csv still has a header", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		err := uc.ExportBooks(context.Background(), &model.ExportBooksRequest{
+			Format: model.ExportFormatCSV,
+			Title:  util.ToPointer("missing"),
+		}, buf)
+		assert.NoError(t, err)
+		assert.EqualValues(t, "id,title,isbn,author,author_birthdate,page_count,author_id,average_rating,rating_count\n", buf.String())
+	})
+
+	t.Run("Negative Case - unsupported format", func(t *testing.T) {
+		err := uc.ExportBooks(context.Background(), &model.ExportBooksRequest{Format: "xml"}, new(bytes.Buffer))
+		assert.EqualValues(t, model.ErrorBadRequest(errors.New("unsupported export format")), err)
+	})
+}
+
+func TestExportUsecase_ExportBook(t *testing.T) {
+	uc := newExportUsecase(t)
+
+	t.Run("Positive Case - marc", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		err := uc.ExportBook(context.Background(), &model.ExportBookRequest{ID: 1, Format: model.ExportFormatMARC}, buf)
+		assert.NoError(t, err)
+
+		record, err := marc.NewReader(buf).Read()
+		assert.NoError(t, err)
+		assert.EqualValues(t, "1", record.ControlField("001"))
+		assert.EqualValues(t, "978-0451524935", record.Subfield("020", 'a'))
+		assert.EqualValues(t, "Orwell, George,", record.Subfield("100", 'a'))
+		assert.EqualValues(t, "1903-", record.Subfield("100", 'd'))
+		assert.EqualValues(t, "Nineteen Eighty-Four /", record.Subfield("245", 'a'))
+		assert.EqualValues(t, "328 pages", record.Subfield("300", 'a'))
+	})
+
+	t.Run("Negative Case - book not found", func(t *testing.T) {
+		err := uc.ExportBook(context.Background(), &model.ExportBookRequest{ID: 10, Format: model.ExportFormatMARC}, new(bytes.Buffer))
+		assert.EqualValues(t, model.ErrorNotFound(errors.New("book not found")), err)
+	})
+}
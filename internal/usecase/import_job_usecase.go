@@ -0,0 +1,348 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+	"github.com/mnaufalhilmym/bookshelf/internal/format/readinghistory"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/gotracing"
+	"gorm.io/gorm"
+)
+
+// importJobProgressInterval is how many rows are imported between two
+// progress updates of a running job.
+const importJobProgressInterval = 25
+
+type ImportJobUsecase struct {
+	db                        *gorm.DB
+	repository                *repository.ImportJobRepository
+	authorRepository          *repository.AuthorRepository
+	bookRepository            *repository.BookRepository
+	reviewRepository          *repository.ReviewRepository
+	readingProgressRepository *repository.ReadingProgressRepository
+	shelfEntryRepository      *repository.ShelfEntryRepository
+
+	jobs sync.WaitGroup
+}
+
+func NewImportJobUsecase(
+	db *gorm.DB,
+	repository *repository.ImportJobRepository,
+	authorRepository *repository.AuthorRepository,
+	bookRepository *repository.BookRepository,
+	reviewRepository *repository.ReviewRepository,
+	readingProgressRepository *repository.ReadingProgressRepository,
+	shelfEntryRepository *repository.ShelfEntryRepository,
+) *ImportJobUsecase {
+	return &ImportJobUsecase{
+		db:                        db,
+		repository:                repository,
+		authorRepository:          authorRepository,
+		bookRepository:            bookRepository,
+		reviewRepository:          reviewRepository,
+		readingProgressRepository: readingProgressRepository,
+		shelfEntryRepository:      shelfEntryRepository,
+	}
+}
+
+func (uc *ImportJobUsecase) ImportGoodreads(ctx context.Context, request *model.ImportReadingHistoryRequest) (*model.ImportJobResponse, error) {
+	return uc.start(ctx, request, model.ImportSourceGoodreads, readinghistory.ReadGoodreads)
+}
+
+func (uc *ImportJobUsecase) ImportStoryGraph(ctx context.Context, request *model.ImportReadingHistoryRequest) (*model.ImportJobResponse, error) {
+	return uc.start(ctx, request, model.ImportSourceStoryGraph, readinghistory.ReadStoryGraph)
+}
+
+func (uc *ImportJobUsecase) Get(ctx context.Context, request *model.GetImportJobRequest) (*model.ImportJobResponse, error) {
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
+	defer tx.Rollback()
+
+	job, err := uc.repository.FindByID(tx, request.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrorNotFound(errors.New("import job not found"))
+		}
+		return nil, model.ErrorInternalServerError(errors.New("failed to find import job data by id"))
+	}
+
+	if job.UserID != request.UserID {
+		return nil, model.ErrorForbidden(errors.New("import job belongs to another user"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return model.ToImportJobResponse(job), nil
+}
+
+// Wait blocks until every started import job has finished.
+func (uc *ImportJobUsecase) Wait() {
+	uc.jobs.Wait()
+}
+
+// start parses the whole file up front, so a malformed export is rejected
+// with the request instead of failing later in the job.
+func (uc *ImportJobUsecase) start(
+	ctx context.Context,
+	request *model.ImportReadingHistoryRequest,
+	source string,
+	read func(r io.Reader) ([]readinghistory.Entry, error),
+) (*model.ImportJobResponse, error) {
+	entries, err := read(bytes.NewReader(request.Content))
+	if err != nil {
+		gotracing.Error("Failed to parse reading history", err)
+		return nil, model.ErrorBadRequest(errors.New("invalid " + source + " export: " + err.Error()))
+	}
+
+	if len(entries) == 0 {
+		return nil, model.ErrorBadRequest(errors.New("invalid " + source + " export: no books found"))
+	}
+
+	tx := begin(ctx, uc.db)
+	defer tx.Rollback()
+
+	job := &entity.ImportJob{
+		UserID: request.UserID,
+		Source: source,
+		Status: entity.ImportJobStatusPending,
+		Total:  len(entries),
+	}
+	if err := uc.repository.Create(tx, job); err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to create new import job"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	response := model.ToImportJobResponse(job)
+
+	uc.jobs.Add(1)
+	go uc.run(*job, entries)
+
+	return response, nil
+}
+
+// run imports the entries outside of the request, so it does not use the
+// request context which is canceled once the response is sent.
+func (uc *ImportJobUsecase) run(job entity.ImportJob, entries []readinghistory.Entry) {
+	defer uc.jobs.Done()
+
+	db := uc.db.WithContext(context.Background())
+
+	defer func() {
+		if r := recover(); r != nil {
+			gotracing.Error("Import job panicked", errors.New("panic"))
+			uc.finish(db, &job, nil, errors.New("import failed unexpectedly"))
+		}
+	}()
+
+	result := &model.ImportReadingHistoryResponse{Total: len(entries)}
+
+	for i := range entries {
+		row := uc.importEntry(db, &job, &entries[i], result)
+		if row.Status == model.ImportStatusImported {
+			result.Imported++
+		} else {
+			result.Failed++
+		}
+		result.Rows = append(result.Rows, row)
+
+		if processed := i + 1; processed%importJobProgressInterval == 0 && processed < len(entries) {
+			if err := uc.repository.UpdateProgress(db, job.ID, processed); err == nil {
+				job.Status = entity.ImportJobStatusRunning
+				job.Processed = processed
+			}
+		}
+	}
+
+	uc.finish(db, &job, result, nil)
+}
+
+func (uc *ImportJobUsecase) finish(db *gorm.DB, job *entity.ImportJob, result *model.ImportReadingHistoryResponse, err error) {
+	finishedAt := time.Now()
+	job.FinishedAt = &finishedAt
+
+	if err != nil {
+		job.Status = entity.ImportJobStatusFailed
+		job.Error = err.Error()
+	} else {
+		resultJSON, err := json.Marshal(result)
+		if err != nil {
+			gotracing.Error("Failed to marshal import result", err)
+			job.Status = entity.ImportJobStatusFailed
+			job.Error = "failed to store import result"
+		} else {
+			job.Status = entity.ImportJobStatusCompleted
+			job.Processed = job.Total
+			job.Result = string(resultJSON)
+		}
+	}
+
+	if err := uc.repository.Update(db, job); err != nil {
+		gotracing.Error("Failed to finish import job", err)
+	}
+}
+
+// importEntry imports one book in its own transaction so a failing row does
+// not undo the rows imported before it.
+func (uc *ImportJobUsecase) importEntry(
+	db *gorm.DB,
+	job *entity.ImportJob,
+	entry *readinghistory.Entry,
+	result *model.ImportReadingHistoryResponse,
+) model.ImportReadingHistoryRowResponse {
+	row := model.ImportReadingHistoryRowResponse{
+		Line:    entry.Line,
+		Title:   entry.Title,
+		ISBN:    entry.ISBN,
+		Author:  strings.Join(entry.Authors, ", "),
+		Shelves: entry.Shelves,
+	}
+
+	if entry.Err != nil {
+		row.Status = model.ImportStatusFailed
+		row.Reason = entry.Err.Error()
+		return row
+	}
+
+	tx := begin(context.Background(), db)
+	defer tx.Rollback()
+
+	query := &bookQuery{isbn: entry.ISBN, title: entry.Title, pageCount: entry.PageCount}
+	if len(entry.Authors) > 0 {
+		query.author = entry.Authors[0]
+	}
+
+	resolver := newBookResolver(uc.authorRepository, uc.bookRepository, tx, true, job.Source+"-")
+	book, err := resolver.resolve(query)
+	if err == nil {
+		err = uc.importShelves(tx, job.UserID, book.ID, entry.Shelves)
+	}
+	if err == nil && entry.Rating > 0 {
+		row.Rating, err = uc.importRating(tx, job.UserID, book.ID, entry)
+	}
+	if err == nil {
+		row.ReadDates, err = uc.importReadDates(tx, job.UserID, book, entry.ReadDates)
+	}
+	if err == nil {
+		if err = tx.Commit().Error; err != nil {
+			gotracing.Error("Failed to commit transaction", err)
+			err = model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+		}
+	}
+
+	if err != nil {
+		row.Status = model.ImportStatusFailed
+		row.Reason = err.Error()
+		return row
+	}
+
+	result.CreatedBooks += resolver.createdBooks
+	result.CreatedAuthors += resolver.createdAuthors
+
+	row.Status = model.ImportStatusImported
+	row.BookID = &book.ID
+	return row
+}
+
+func (uc *ImportJobUsecase) importShelves(tx *gorm.DB, userID int, bookID int, shelves []string) error {
+	for _, shelf := range shelves {
+		shelf = strings.ToLower(shelf)
+
+		_, err := uc.shelfEntryRepository.FindByUserIDAndShelfAndBookID(tx, userID, shelf, bookID)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.ErrorInternalServerError(errors.New("failed to find shelf entry data"))
+		}
+
+		if err := uc.shelfEntryRepository.Create(tx, &entity.ShelfEntry{
+			UserID: userID,
+			Shelf:  shelf,
+			BookID: bookID,
+		}); err != nil {
+			return model.ErrorInternalServerError(errors.New("failed to create new shelf entry"))
+		}
+	}
+	return nil
+}
+
+// importRating stores the rating as the user's review of the book. Ratings
+// are whole stars here, so half stars from StoryGraph are rounded.
+func (uc *ImportJobUsecase) importRating(tx *gorm.DB, userID int, bookID int, entry *readinghistory.Entry) (int, error) {
+	rating := max(int(math.Round(entry.Rating)), 1)
+
+	review, err := uc.reviewRepository.FindByUserIDAndBookID(tx, userID, bookID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, model.ErrorInternalServerError(errors.New("failed to find review data"))
+	}
+
+	if review == nil {
+		review = &entity.Review{
+			UserID: userID,
+			BookID: bookID,
+			Rating: rating,
+			Text:   entry.Review,
+		}
+		if err := uc.reviewRepository.Create(tx, review); err != nil {
+			return 0, model.ErrorInternalServerError(errors.New("failed to create new review"))
+		}
+	} else {
+		review.Rating = rating
+		if entry.Review != "" {
+			review.Text = entry.Review
+		}
+		if err := uc.reviewRepository.Update(tx, review); err != nil {
+			return 0, model.ErrorInternalServerError(errors.New("failed to update review"))
+		}
+	}
+
+	if err := updateBookRating(tx, uc.reviewRepository, uc.bookRepository, bookID); err != nil {
+		return 0, err
+	}
+
+	return rating, nil
+}
+
+// importReadDates records every read as the book being finished on that day,
+// skipping the reads an earlier import already recorded.
+func (uc *ImportJobUsecase) importReadDates(tx *gorm.DB, userID int, book *entity.Book, readDates []time.Time) (int, error) {
+	imported := 0
+	for _, readAt := range readDates {
+		_, err := uc.readingProgressRepository.FindByUserIDAndBookIDAndReadAt(tx, userID, book.ID, readAt)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, model.ErrorInternalServerError(errors.New("failed to find reading progress data"))
+		}
+
+		if err := uc.readingProgressRepository.Create(tx, &entity.ReadingProgress{
+			UserID:     userID,
+			BookID:     book.ID,
+			Page:       book.PageCount,
+			Percentage: 100,
+			ReadAt:     readAt,
+		}); err != nil {
+			return 0, model.ErrorInternalServerError(errors.New("failed to create new reading progress"))
+		}
+		imported++
+	}
+	return imported, nil
+}
@@ -0,0 +1,245 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+const goodreadsExport = "Book Id,Title,Author,Author l-f,Additional Authors,ISBN,ISBN13,My Rating,Average Rating,Number of Pages,Date Read,Date Added,Bookshelves,Exclusive Shelf,My Review\n" +
+	`1,Book Title 1,Author Name 1,"1, Name Author",,"=""1451673310""","=""9781451673319""",4,4.1,200,2024/03/15,2024/01/01,favorites,read,Loved it` + "\n" +
+	`2,"Dune: Deluxe Edition",Frank Herbert,"Herbert, Frank",,"=""0441172717""","=""9780441172719""",0,4.2,612,,2024/01/02,,to-read,` + "\n" +
+	`3,Broken Row,Someone,"Someone",,"=""""","=""""",9,3.0,100,,2024/01/03,,read,` + "\n"
+
+const storyGraphExport = "Title,Authors,Contributors,ISBN/UID,Format,Read Status,Date Added,Last Date Read,Dates Read,Read Count,Moods,Pace,Star Rating,Review,Tags,Owned?\n" +
+	`Book Title 1,Author Name 1,,9781451673319,paperback,read,2024/01/01,2024/05/01,"2023/01/02-2023/01/20, 2024/04/10-2024/05/01",2,,,3.5,,classics,No` + "\n" +
+	`The Hobbit,J.R.R. Tolkien,,,hardcover,currently-reading,2024/02/01,,,0,,,,,,No` + "\n"
+
+type importJobFixture struct {
+	importJobUc *usecase.ImportJobUsecase
+	shelfUc     *usecase.ShelfUsecase
+	progressUc  *usecase.ReadingProgressUsecase
+	reviewUc    *usecase.ReviewUsecase
+	bookUc      *usecase.BookUsecase
+	user        *model.UserResponse
+	otherUser   *model.UserResponse
+}
+
+func newImportJobFixture(t *testing.T) *importJobFixture {
+	db := newDatabase()
+	userRepo := repository.NewUserRepository()
+	authorRepo := repository.NewAuthorRepository()
+	bookRepo := repository.NewBookRepository()
+	reviewRepo := repository.NewReviewRepository()
+	progressRepo := repository.NewReadingProgressRepository()
+	shelfEntryRepo := repository.NewShelfEntryRepository()
+	importJobRepo := repository.NewImportJobRepository()
+
+	userUc := usecase.NewUserUsecase(db, userRepo, "jwtKey", 10*time.Second)
+	authorUc := usecase.NewAuthorUsecase(db, authorRepo, bookRepo, repository.NewRevisionRepository())
+
+	f := &importJobFixture{
+		importJobUc: usecase.NewImportJobUsecase(
+			db,
+			importJobRepo,
+			authorRepo,
+			bookRepo,
+			reviewRepo,
+			progressRepo,
+			shelfEntryRepo,
+		),
+		shelfUc:    usecase.NewShelfUsecase(db, shelfEntryRepo),
+		progressUc: usecase.NewReadingProgressUsecase(db, progressRepo, bookRepo),
+		reviewUc:   usecase.NewReviewUsecase(db, reviewRepo, bookRepo),
+		bookUc:     usecase.NewBookUsecase(db, bookRepo, authorRepo, repository.NewRevisionRepository()),
+	}
+
+	var err error
+	f.user, err = userUc.Register(context.Background(), &model.RegisterUserRequest{
+		Username: "reader_1",
+		Password: "password",
+	})
+	assert.NoError(t, err)
+	f.otherUser, err = userUc.Register(context.Background(), &model.RegisterUserRequest{
+		Username: "reader_2",
+		Password: "password",
+	})
+	assert.NoError(t, err)
+
+	author, err := authorUc.Create(context.Background(), &model.CreateAuthorRequest{
+		Name:      "Author Name 1",
+		Birthdate: time.Date(2011, 11, 11, 11, 11, 11, 111, time.UTC),
+	})
+	assert.NoError(t, err)
+
+	_, err = f.bookUc.Create(context.Background(), &model.CreateBookRequest{
+		Title:     "Book Title 1",
+		ISBN:      "978-1451673319",
+		AuthorID:  author.ID,
+		PageCount: 200,
+	})
+	assert.NoError(t, err)
+
+	return f
+}
+
+func (f *importJobFixture) wait(t *testing.T, id int) *model.ImportJobResponse {
+	f.importJobUc.Wait()
+
+	job, err := f.importJobUc.Get(context.Background(), &model.GetImportJobRequest{UserID: f.user.ID, ID: id})
+	assert.NoError(t, err)
+	return job
+}
+
+func TestImportJobUsecase_ImportGoodreads(t *testing.T) {
+	f := newImportJobFixture(t)
+
+	t.Run("Positive Case - import shelves, ratings and read dates", func(t *testing.T) {
+		job, err := f.importJobUc.ImportGoodreads(context.Background(), &model.ImportReadingHistoryRequest{
+			UserID:  f.user.ID,
+			Content: []byte(goodreadsExport),
+		})
+		assert.NoError(t, err)
+		assert.EqualValues(t, model.ImportSourceGoodreads, job.Source)
+		assert.EqualValues(t, 3, job.Total)
+
+		job = f.wait(t, job.ID)
+		assert.EqualValues(t, entity.ImportJobStatusCompleted, job.Status)
+		assert.EqualValues(t, 3, job.Processed)
+		assert.NotNil(t, job.FinishedAt)
+
+		result := job.Result
+		assert.EqualValues(t, 2, result.Imported)
+		assert.EqualValues(t, 1, result.Failed)
+		assert.EqualValues(t, 1, result.CreatedBooks)
+		assert.EqualValues(t, 1, result.CreatedAuthors)
+
+		assert.EqualValues(t, model.ImportStatusImported, result.Rows[0].Status)
+		assert.EqualValues(t, 1, *result.Rows[0].BookID)
+		assert.EqualValues(t, 4, result.Rows[0].Rating)
+		assert.EqualValues(t, 1, result.Rows[0].ReadDates)
+		assert.EqualValues(t, "9780441172719", result.Rows[1].ISBN)
+		assert.EqualValues(t, model.ImportStatusFailed, result.Rows[2].Status)
+		assert.EqualValues(t, `invalid rating "9"`, result.Rows[2].Reason)
+
+		shelves, err := f.shelfUc.GetMany(context.Background(), &model.GetManyShelvesRequest{UserID: f.user.ID})
+		assert.NoError(t, err)
+		assert.EqualValues(t, []model.ShelfResponse{
+			{Name: "favorites", BookCount: 1},
+			{Name: "read", BookCount: 1},
+			{Name: "to-read", BookCount: 1},
+		}, shelves)
+
+		request := &model.GetShelfRequest{UserID: f.user.ID, Shelf: "to-read"}
+		request.Page = 1
+		request.Size = 10
+		entries, total, err := f.shelfUc.Get(context.Background(), request)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, total)
+		assert.EqualValues(t, "Dune: Deluxe Edition", entries[0].Book.Title)
+		assert.EqualValues(t, "9780441172719", entries[0].Book.ISBN)
+		assert.EqualValues(t, "Frank Herbert", entries[0].Book.AuthorName)
+		assert.EqualValues(t, 612, entries[0].Book.PageCount)
+
+		book, err := f.bookUc.Get(context.Background(), &model.GetBookRequest{ID: 1})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 4, book.AverageRating)
+		assert.EqualValues(t, 1, book.RatingCount)
+
+		progressRequest := &model.GetManyReadingProgressesRequest{UserID: f.user.ID, BookID: 1}
+		progressRequest.Page = 1
+		progressRequest.Size = 10
+		progresses, _, err := f.progressUc.GetMany(context.Background(), progressRequest)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, len(progresses))
+		assert.EqualValues(t, 100, progresses[0].Percentage)
+		assert.EqualValues(t, 200, progresses[0].Page)
+		assert.True(t, progresses[0].ReadAt.Equal(time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)))
+	})
+
+	t.Run("Positive Case - importing again does not duplicate", func(t *testing.T) {
+		job, err := f.importJobUc.ImportGoodreads(context.Background(), &model.ImportReadingHistoryRequest{
+			UserID:  f.user.ID,
+			Content: []byte(goodreadsExport),
+		})
+		assert.NoError(t, err)
+
+		job = f.wait(t, job.ID)
+		assert.EqualValues(t, 2, job.Result.Imported)
+		assert.EqualValues(t, 0, job.Result.CreatedBooks)
+		assert.EqualValues(t, 0, job.Result.Rows[0].ReadDates)
+
+		shelves, err := f.shelfUc.GetMany(context.Background(), &model.GetManyShelvesRequest{UserID: f.user.ID})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, shelves[1].BookCount)
+	})
+
+	t.Run("Negative Case - missing required column", func(t *testing.T) {
+		_, err := f.importJobUc.ImportGoodreads(context.Background(), &model.ImportReadingHistoryRequest{
+			UserID:  f.user.ID,
+			Content: []byte("Title,Author\nBook Title 1,Author Name 1\n"),
+		})
+		assert.EqualValues(t, model.ErrorBadRequest(errors.New("invalid goodreads export: missing Exclusive Shelf column")), err)
+	})
+
+	t.Run("Negative Case - no books", func(t *testing.T) {
+		_, err := f.importJobUc.ImportGoodreads(context.Background(), &model.ImportReadingHistoryRequest{
+			UserID:  f.user.ID,
+			Content: []byte("Title,Author,Exclusive Shelf\n"),
+		})
+		assert.EqualValues(t, model.ErrorBadRequest(errors.New("invalid goodreads export: no books found")), err)
+	})
+}
+
+func TestImportJobUsecase_ImportStoryGraph(t *testing.T) {
+	f := newImportJobFixture(t)
+
+	job, err := f.importJobUc.ImportStoryGraph(context.Background(), &model.ImportReadingHistoryRequest{
+		UserID:  f.user.ID,
+		Content: []byte(storyGraphExport),
+	})
+	assert.NoError(t, err)
+
+	job = f.wait(t, job.ID)
+	assert.EqualValues(t, entity.ImportJobStatusCompleted, job.Status)
+	assert.EqualValues(t, 2, job.Result.Imported)
+	assert.EqualValues(t, 4, job.Result.Rows[0].Rating)
+	assert.EqualValues(t, 2, job.Result.Rows[0].ReadDates)
+	assert.EqualValues(t, []string{"read", "classics"}, job.Result.Rows[0].Shelves)
+
+	request := &model.GetShelfRequest{UserID: f.user.ID, Shelf: "currently-reading"}
+	request.Page = 1
+	request.Size = 10
+	entries, _, err := f.shelfUc.Get(context.Background(), request)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "The Hobbit", entries[0].Book.Title)
+	assert.Regexp(t, "^storygraph-", entries[0].Book.ISBN)
+}
+
+func TestImportJobUsecase_Get(t *testing.T) {
+	f := newImportJobFixture(t)
+
+	job, err := f.importJobUc.ImportStoryGraph(context.Background(), &model.ImportReadingHistoryRequest{
+		UserID:  f.user.ID,
+		Content: []byte(storyGraphExport),
+	})
+	assert.NoError(t, err)
+	f.importJobUc.Wait()
+
+	t.Run("Negative Case - job of another user", func(t *testing.T) {
+		_, err := f.importJobUc.Get(context.Background(), &model.GetImportJobRequest{UserID: f.otherUser.ID, ID: job.ID})
+		assert.EqualValues(t, model.ErrorForbidden(errors.New("import job belongs to another user")), err)
+	})
+
+	t.Run("Negative Case - job not found", func(t *testing.T) {
+		_, err := f.importJobUc.Get(context.Background(), &model.GetImportJobRequest{UserID: f.user.ID, ID: 100})
+		assert.EqualValues(t, model.ErrorNotFound(errors.New("import job not found")), err)
+	})
+}
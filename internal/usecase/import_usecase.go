@@ -0,0 +1,606 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+	"github.com/mnaufalhilmym/bookshelf/internal/format/bookcsv"
+	"github.com/mnaufalhilmym/bookshelf/internal/format/calibre"
+	"github.com/mnaufalhilmym/bookshelf/internal/format/kindle"
+	"github.com/mnaufalhilmym/bookshelf/internal/format/marc"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/gotracing"
+	"gorm.io/gorm"
+)
+
+type ImportUsecase struct {
+	db                   *gorm.DB
+	authorRepository     *repository.AuthorRepository
+	bookRepository       *repository.BookRepository
+	annotationRepository *repository.AnnotationRepository
+}
+
+func NewImportUsecase(
+	db *gorm.DB,
+	authorRepository *repository.AuthorRepository,
+	bookRepository *repository.BookRepository,
+	annotationRepository *repository.AnnotationRepository,
+) *ImportUsecase {
+	return &ImportUsecase{
+		db,
+		authorRepository,
+		bookRepository,
+		annotationRepository,
+	}
+}
+
+func (uc *ImportUsecase) ImportKindle(ctx context.Context, request *model.ImportKindleRequest) (*model.ImportKindleResponse, error) {
+	clippings, err := kindle.Parse(bytes.NewReader(request.Content))
+	if err != nil {
+		gotracing.Error("Failed to parse Kindle clippings", err)
+		return nil, model.ErrorBadRequest(errors.New("invalid clippings file: " + err.Error()))
+	}
+
+	tx := begin(ctx, uc.db)
+	defer tx.Rollback()
+
+	response := &model.ImportKindleResponse{
+		DryRun: request.DryRun,
+		Total:  len(clippings),
+		Items:  make([]model.ImportKindleClippingResponse, 0, len(clippings)),
+	}
+
+	resolver := newBookResolver(uc.authorRepository, uc.bookRepository, tx, request.CreateMissing, "kindle-")
+	existingTexts := map[int]map[string]struct{}{}
+	highlights := map[string]*entity.Annotation{}
+
+	for i, clipping := range clippings {
+		item := model.ImportKindleClippingResponse{
+			Index:    i + 1,
+			Title:    clipping.Title,
+			Author:   clipping.Author,
+			Kind:     clipping.Kind,
+			Page:     clipping.Page,
+			Location: clipping.Location,
+		}
+
+		switch {
+		case clipping.Kind == kindle.KindBookmark:
+			item.Status = model.ImportStatusSkipped
+			item.Reason = "bookmarks have no text"
+		case clipping.Text == "":
+			item.Status = model.ImportStatusSkipped
+			item.Reason = "empty clipping"
+		}
+		if item.Status != "" {
+			response.Skipped++
+			response.Items = append(response.Items, item)
+			continue
+		}
+
+		book, err := resolver.resolve(&bookQuery{title: clipping.Title, author: clipping.Author})
+		if err != nil {
+			return nil, err
+		}
+		if book == nil {
+			item.Status = model.ImportStatusUnmatched
+			item.Reason = "no book matches the title and author"
+			response.Unmatched++
+			response.Items = append(response.Items, item)
+			continue
+		}
+		item.BookID = &book.ID
+
+		texts, ok := existingTexts[book.ID]
+		if !ok {
+			texts, err = uc.existingAnnotationTexts(tx, request.UserID, book.ID)
+			if err != nil {
+				return nil, err
+			}
+			existingTexts[book.ID] = texts
+		}
+
+		highlightKey := fmt.Sprintf("%d|%s", book.ID, clipping.LocationEnd())
+
+		if clipping.Kind == kindle.KindNote {
+			if highlight, ok := highlights[highlightKey]; ok && clipping.Location != "" {
+				highlight.Note = clipping.Text
+				if err := uc.annotationRepository.Update(tx, highlight); err != nil {
+					return nil, model.ErrorInternalServerError(errors.New("failed to update annotation"))
+				}
+				item.Status = model.ImportStatusAttached
+				item.Reason = "note attached to the highlight at the same location"
+				if !request.DryRun {
+					item.AnnotationID = &highlight.ID
+				}
+				response.Attached++
+				response.Items = append(response.Items, item)
+				continue
+			}
+		}
+
+		key := normalizeClippingText(clipping.Text)
+		if _, ok := texts[key]; ok {
+			item.Status = model.ImportStatusDuplicate
+			item.Reason = "the same text is already stored for this book"
+			response.Duplicates++
+			response.Items = append(response.Items, item)
+			continue
+		}
+
+		annotation := &entity.Annotation{
+			UserID:     request.UserID,
+			BookID:     book.ID,
+			Page:       clipping.Page,
+			Location:   clipping.Location,
+			Text:       clipping.Text,
+			Visibility: entity.AnnotationVisibilityPrivate,
+			CreatedAt:  clipping.AddedAt,
+			UpdatedAt:  clipping.AddedAt,
+		}
+		if err := uc.annotationRepository.Create(tx, annotation); err != nil {
+			return nil, model.ErrorInternalServerError(errors.New("failed to create new annotation"))
+		}
+
+		texts[key] = struct{}{}
+		if clipping.Kind == kindle.KindHighlight {
+			highlights[highlightKey] = annotation
+		}
+
+		item.Status = model.ImportStatusImported
+		if !request.DryRun {
+			item.AnnotationID = &annotation.ID
+		}
+		response.Imported++
+		response.Items = append(response.Items, item)
+	}
+
+	response.CreatedBooks = resolver.createdBooks
+	response.CreatedAuthors = resolver.createdAuthors
+
+	if request.DryRun {
+		return response, nil
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return response, nil
+}
+
+func (uc *ImportUsecase) existingAnnotationTexts(tx *gorm.DB, userID int, bookID int) (map[string]struct{}, error) {
+	annotations, err := uc.annotationRepository.FindAllByUserID(tx, userID, &bookID)
+	if err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to find annotations"))
+	}
+
+	texts := make(map[string]struct{}, len(annotations))
+	for _, annotation := range annotations {
+		texts[normalizeClippingText(annotation.Text)] = struct{}{}
+		if annotation.Note != "" {
+			texts[normalizeClippingText(annotation.Note)] = struct{}{}
+		}
+	}
+	return texts, nil
+}
+
+func normalizeClippingText(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
+func (uc *ImportUsecase) ImportCSV(ctx context.Context, request *model.ImportBooksRequest) (*model.ImportBooksResponse, error) {
+	records, err := bookcsv.Read(bytes.NewReader(request.Content))
+	if err != nil {
+		gotracing.Error("Failed to parse CSV file", err)
+		return nil, model.ErrorBadRequest(errors.New("invalid csv file: " + err.Error()))
+	}
+
+	rows := make([]importBookRow, len(records))
+	for i, record := range records {
+		rows[i] = importBookRow{
+			line:            record.Line,
+			title:           record.Title,
+			isbn:            record.ISBN,
+			author:          record.Author,
+			authorBirthdate: record.AuthorBirthdate,
+			pageCount:       record.PageCount,
+			err:             record.Err,
+		}
+	}
+
+	return uc.importBooks(ctx, rows, &importBooksOptions{dryRun: request.DryRun, mode: request.Mode})
+}
+
+func (uc *ImportUsecase) ImportMARC(ctx context.Context, request *model.ImportBooksRequest) (*model.ImportBooksResponse, error) {
+	var reader interface {
+		Read() (*marc.Record, error)
+	}
+	if content := bytes.TrimSpace(request.Content); len(content) > 0 && content[0] == '<' {
+		reader = marc.NewXMLReader(bytes.NewReader(request.Content))
+	} else {
+		reader = marc.NewReader(bytes.NewReader(request.Content))
+	}
+
+	var rows []importBookRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			gotracing.Error("Failed to parse MARC file", err)
+			return nil, model.ErrorBadRequest(errors.New("invalid marc file: " + err.Error()))
+		}
+
+		book := record.Book()
+		row := importBookRow{
+			record:          len(rows) + 1,
+			title:           book.Title,
+			isbn:            book.ISBN,
+			author:          book.Author,
+			authorBirthdate: book.AuthorBirthdate,
+		}
+		if book.PageCount > 0 {
+			row.pageCount = &book.PageCount
+		}
+		switch {
+		case row.title == "":
+			row.err = errors.New("title (245 $a) is required")
+		case row.isbn == "":
+			row.err = errors.New("isbn (020 $a) is required")
+		case row.author == "":
+			row.err = errors.New("author (100 $a) is required")
+		}
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, model.ErrorBadRequest(errors.New("invalid marc file: no records found"))
+	}
+
+	return uc.importBooks(ctx, rows, &importBooksOptions{dryRun: request.DryRun, mode: request.Mode})
+}
+
+func (uc *ImportUsecase) ImportCalibre(ctx context.Context, request *model.ImportCalibreRequest) (*model.ImportBooksResponse, error) {
+	books, err := calibre.Read(request.Path)
+	if err != nil {
+		gotracing.Error("Failed to read Calibre library", err)
+		return nil, model.ErrorBadRequest(errors.New("invalid calibre library: " + err.Error()))
+	}
+
+	rows := make([]importBookRow, len(books))
+	for i, book := range books {
+		row := importBookRow{
+			record: book.ID,
+			title:  book.Title,
+			isbn:   book.ISBN,
+		}
+		if len(book.Authors) > 0 {
+			row.author = book.Authors[0]
+		}
+		if len(book.Authors) > 1 {
+			row.notes = append(row.notes, "co-authors not stored: "+strings.Join(book.Authors[1:], ", "))
+		}
+		if len(book.Tags) > 0 {
+			row.notes = append(row.notes, "tags not stored: "+strings.Join(book.Tags, ", "))
+		}
+		if book.Language != "" {
+			row.language = &book.Language
+		}
+		// Calibre gives every book a series index, also those in no series.
+		if book.Series != "" {
+			row.series = &book.Series
+			row.seriesIndex = &book.SeriesIndex
+		}
+
+		switch {
+		case row.isbn == "":
+			row.skip = "book has no isbn identifier"
+		case row.title == "":
+			row.err = errors.New("title is required")
+		case row.author == "":
+			row.err = errors.New("author is required")
+		}
+		rows[i] = row
+	}
+
+	return uc.importBooks(ctx, rows, &importBooksOptions{
+		dryRun:          request.DryRun,
+		mode:            request.Mode,
+		reportConflicts: !request.Overwrite,
+	})
+}
+
+// importBookRow is a book read from an import file, located by its line in
+// a CSV file or its position in a MARC file or Calibre library.
+type importBookRow struct {
+	line            int
+	record          int
+	title           string
+	isbn            string
+	author          string
+	authorBirthdate *time.Time
+	pageCount       *int
+	language        *string
+	series          *string
+	seriesIndex     *float64
+	notes           []string
+	skip            string
+	err             error
+}
+
+type importBooksOptions struct {
+	dryRun bool
+	mode   string
+	// reportConflicts leaves existing books with different data untouched
+	// and reports the differences instead of updating them.
+	reportConflicts bool
+}
+
+// apply sets the fields of book which the row has values for.
+func (row *importBookRow) apply(book *entity.Book) {
+	if row.pageCount != nil {
+		book.PageCount = *row.pageCount
+	}
+	if row.language != nil {
+		book.Language = *row.language
+	}
+	if row.series != nil {
+		book.Series = *row.series
+	}
+	if row.seriesIndex != nil {
+		book.SeriesIndex = *row.seriesIndex
+	}
+}
+
+func (row *importBookRow) position() string {
+	if row.record > 0 {
+		return fmt.Sprintf("record %d", row.record)
+	}
+	return fmt.Sprintf("line %d", row.line)
+}
+
+func (uc *ImportUsecase) importBooks(ctx context.Context, rows []importBookRow, options *importBooksOptions) (*model.ImportBooksResponse, error) {
+	mode := options.mode
+	if mode == "" {
+		mode = model.ImportModeTransaction
+	}
+
+	tx := begin(ctx, uc.db)
+	defer tx.Rollback()
+
+	response := &model.ImportBooksResponse{
+		DryRun: options.dryRun,
+		Mode:   mode,
+		Total:  len(rows),
+		Rows:   make([]model.ImportBookRowResponse, 0, len(rows)),
+	}
+
+	authors := map[string]*entity.Author{}
+	isbns := map[string]*importBookRow{}
+
+	for i := range rows {
+		row := &rows[i]
+		item := model.ImportBookRowResponse{
+			Line:   row.line,
+			Record: row.record,
+			Title:  row.title,
+			ISBN:   row.isbn,
+			Author: row.author,
+			Notes:  row.notes,
+		}
+
+		if row.skip != "" {
+			item.Status = model.ImportStatusSkipped
+			item.Reason = row.skip
+			response.Skipped++
+			response.Rows = append(response.Rows, item)
+			continue
+		}
+
+		isbn := normalizeISBN(row.isbn)
+		if previous, ok := isbns[isbn]; ok && row.err == nil {
+			row.err = fmt.Errorf("duplicate isbn, already used on %s", previous.position())
+		}
+		if row.err != nil {
+			item.Status = model.ImportStatusFailed
+			item.Reason = row.err.Error()
+			response.Failed++
+			response.Rows = append(response.Rows, item)
+			continue
+		}
+		isbns[isbn] = row
+
+		// Every row runs in its own savepoint so a failing row leaves no
+		// partial writes behind, whichever mode is used.
+		savepoint := fmt.Sprintf("import_row_%d", i)
+		if err := tx.SavePoint(savepoint).Error; err != nil {
+			gotracing.Error("Failed to create savepoint", err)
+			return nil, model.ErrorInternalServerError(errors.New("failed to import row"))
+		}
+
+		result, err := uc.importBookRow(tx, row, authors, options.reportConflicts)
+		if err != nil {
+			if err := tx.RollbackTo(savepoint).Error; err != nil {
+				gotracing.Error("Failed to rollback to savepoint", err)
+				return nil, model.ErrorInternalServerError(errors.New("failed to import row"))
+			}
+			item.Status = model.ImportStatusFailed
+			item.Reason = err.Error()
+			response.Failed++
+			response.Rows = append(response.Rows, item)
+			continue
+		}
+
+		if result.createdAuthor {
+			authors[strings.ToLower(result.book.Author.Name)] = &result.book.Author
+			response.CreatedAuthors++
+		}
+
+		item.Status = result.status
+		item.Reason = result.reason
+		item.Conflicts = result.conflicts
+		item.BookID = &result.book.ID
+		item.AuthorID = &result.book.AuthorID
+		switch result.status {
+		case model.ImportStatusCreated:
+			response.Created++
+		case model.ImportStatusUpdated:
+			response.Updated++
+		case model.ImportStatusSkipped:
+			response.Skipped++
+		case model.ImportStatusConflict:
+			response.Conflicts++
+		}
+		response.Rows = append(response.Rows, item)
+	}
+
+	if options.dryRun || (mode == model.ImportModeTransaction && response.Failed > 0) {
+		return response, nil
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+	response.Committed = true
+
+	return response, nil
+}
+
+type importBookRowResult struct {
+	book          *entity.Book
+	status        string
+	reason        string
+	conflicts     []model.ImportConflictResponse
+	createdAuthor bool
+}
+
+func (uc *ImportUsecase) importBookRow(
+	tx *gorm.DB,
+	row *importBookRow,
+	authors map[string]*entity.Author,
+	reportConflicts bool,
+) (*importBookRowResult, error) {
+	result := &importBookRowResult{}
+
+	author, ok := authors[strings.ToLower(row.author)]
+	if !ok {
+		var err error
+		author, err = uc.authorRepository.FindByName(tx, row.author)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("failed to find author data by name")
+		}
+	}
+	if author == nil {
+		author = &entity.Author{Name: row.author}
+		if row.authorBirthdate != nil {
+			author.Birthdate = *row.authorBirthdate
+		}
+		if err := uc.authorRepository.Create(tx, author); err != nil {
+			return nil, errors.New("failed to create new author")
+		}
+		result.createdAuthor = true
+	} else {
+		authors[strings.ToLower(row.author)] = author
+	}
+
+	book, err := uc.bookRepository.FindByISBN(tx, row.isbn)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("failed to find book data by isbn")
+	}
+
+	if book == nil {
+		book = &entity.Book{
+			Title:    row.title,
+			ISBN:     row.isbn,
+			AuthorID: author.ID,
+			Author:   *author,
+		}
+		row.apply(book)
+		if err := uc.bookRepository.Create(tx, book); err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return nil, errors.New("duplicate isbn")
+			}
+			return nil, errors.New("failed to create new book")
+		}
+
+		result.book = book
+		result.status = model.ImportStatusCreated
+		return result, nil
+	}
+
+	var conflicts []model.ImportConflictResponse
+	if book.Title != row.title {
+		conflicts = append(conflicts, model.ImportConflictResponse{Field: "title", Current: book.Title, Incoming: row.title})
+	}
+	if book.AuthorID != author.ID {
+		conflicts = append(conflicts, model.ImportConflictResponse{Field: "author", Current: book.Author.Name, Incoming: author.Name})
+	}
+	if row.pageCount != nil && book.PageCount != *row.pageCount {
+		conflicts = append(conflicts, model.ImportConflictResponse{
+			Field:    "page_count",
+			Current:  strconv.Itoa(book.PageCount),
+			Incoming: strconv.Itoa(*row.pageCount),
+		})
+	}
+	if row.language != nil && book.Language != *row.language {
+		conflicts = append(conflicts, model.ImportConflictResponse{Field: "language", Current: book.Language, Incoming: *row.language})
+	}
+	if row.series != nil && book.Series != *row.series {
+		conflicts = append(conflicts, model.ImportConflictResponse{Field: "series", Current: book.Series, Incoming: *row.series})
+	}
+	if row.seriesIndex != nil && book.SeriesIndex != *row.seriesIndex {
+		conflicts = append(conflicts, model.ImportConflictResponse{
+			Field:    "series_index",
+			Current:  strconv.FormatFloat(book.SeriesIndex, 'f', -1, 64),
+			Incoming: strconv.FormatFloat(*row.seriesIndex, 'f', -1, 64),
+		})
+	}
+
+	result.book = book
+	if len(conflicts) == 0 {
+		result.status = model.ImportStatusSkipped
+		result.reason = "book is already up to date"
+		return result, nil
+	}
+
+	if reportConflicts {
+		result.status = model.ImportStatusConflict
+		result.reason = "book with the same isbn has different data"
+		result.conflicts = conflicts
+		return result, nil
+	}
+
+	changes := make([]string, len(conflicts))
+	for i, conflict := range conflicts {
+		changes[i] = conflict.Field
+	}
+
+	book.Title = row.title
+	book.AuthorID = author.ID
+	book.Author = *author
+	row.apply(book)
+
+	if err := uc.bookRepository.Update(tx, book); err != nil {
+		return nil, errors.New("failed to update book data")
+	}
+
+	result.status = model.ImportStatusUpdated
+	result.reason = "changed " + strings.Join(changes, ", ")
+	return result, nil
+}
+
+func normalizeISBN(isbn string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(isbn))
+}
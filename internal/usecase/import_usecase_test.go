@@ -0,0 +1,481 @@
+package usecase_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/config"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+const kindleClippings = "\ufeffNineteen Eighty-Four: A Novel (Orwell, George)\r\n" +
+	"- Your Highlight on page 3 | Location 118-120 | Added on Monday, 3 June 2024 21:04:11\r\n" +
+	"\r\n" +
+	"It was a bright cold day in April, and the clocks were striking thirteen.\r\n" +
+	"==========\r\n" +
+	"Nineteen Eighty-Four: A Novel (Orwell, George)\r\n" +
+	"- Your Note on page 3 | Location 120 | Added on Monday, 3 June 2024 21:05:00\r\n" +
+	"\r\n" +
+	"Famous opening line.\r\n" +
+	"==========\r\n" +
+	"Nineteen Eighty-Four: A Novel (Orwell, George)\r\n" +
+	"- Your Highlight on page 3 | Location 118-120 | Added on Monday, 3 June 2024 21:06:00\r\n" +
+	"\r\n" +
+	"It was a bright cold day in April, and the clocks were  striking thirteen.\r\n" +
+	"==========\r\n" +
+	"Nineteen Eighty-Four: A Novel (Orwell, George)\r\n" +
+	"- Your Bookmark on page 10 | Location 301 | Added on Monday, 3 June 2024 21:07:00\r\n" +
+	"\r\n" +
+	"\r\n" +
+	"==========\r\n" +
+	"Animal Farm (Orwell, George)\r\n" +
+	"- Your Highlight on page 112 | Location 1480-1481 | Added on Tuesday, 4 June 2024 08:00:00\r\n" +
+	"\r\n" +
+	"All animals are equal, but some animals are more equal than others.\r\n" +
+	"==========\r\n"
+
+type importFixture struct {
+	importUc     *usecase.ImportUsecase
+	annotationUc *usecase.AnnotationUsecase
+	authorUc     *usecase.AuthorUsecase
+	bookUc       *usecase.BookUsecase
+	user         *model.UserResponse
+	book         *model.BookResponse
+}
+
+func newImportFixture(t *testing.T) *importFixture {
+	db := newDatabase()
+	userRepo := repository.NewUserRepository()
+	authorRepo := repository.NewAuthorRepository()
+	bookRepo := repository.NewBookRepository()
+	annotationRepo := repository.NewAnnotationRepository()
+
+	userUc := usecase.NewUserUsecase(db, userRepo, "jwtKey", 10*time.Second)
+	bookUc := usecase.NewBookUsecase(db, bookRepo, authorRepo, repository.NewRevisionRepository())
+
+	f := &importFixture{
+		importUc:     usecase.NewImportUsecase(db, authorRepo, bookRepo, annotationRepo),
+		annotationUc: usecase.NewAnnotationUsecase(db, annotationRepo, bookRepo),
+		authorUc:     usecase.NewAuthorUsecase(db, authorRepo, bookRepo, repository.NewRevisionRepository()),
+		bookUc:       bookUc,
+	}
+
+	var err error
+	f.user, err = userUc.Register(context.Background(), &model.RegisterUserRequest{
+		Username: "reader_1",
+		Password: "password",
+	})
+	assert.NoError(t, err)
+
+	author, err := f.authorUc.Create(context.Background(), &model.CreateAuthorRequest{
+		Name:      "George Orwell",
+		Birthdate: time.Date(1903, 6, 25, 0, 0, 0, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+
+	f.book, err = f.bookUc.Create(context.Background(), &model.CreateBookRequest{
+		Title:    "Nineteen Eighty-Four",
+		ISBN:     "978-0451524935",
+		AuthorID: author.ID,
+	})
+	assert.NoError(t, err)
+
+	return f
+}
+
+func (f *importFixture) countAnnotations(t *testing.T) int64 {
+	request := &model.GetManyAnnotationsRequest{ViewerID: f.user.ID, UserID: &f.user.ID}
+	request.Page = 1
+	request.Size = 100
+
+	_, total, err := f.annotationUc.GetMany(context.Background(), request)
+	assert.NoError(t, err)
+	return total
+}
+
+func TestImportUsecase_ImportKindle(t *testing.T) {
+	t.Run("Positive Case - dry run previews without storing", func(t *testing.T) {
+		f := newImportFixture(t)
+
+		resp, err := f.importUc.ImportKindle(context.Background(), &model.ImportKindleRequest{
+			UserID:  f.user.ID,
+			DryRun:  true,
+			Content: []byte(kindleClippings),
+		})
+		assert.NoError(t, err)
+		assert.True(t, resp.DryRun)
+		assert.EqualValues(t, 5, resp.Total)
+		assert.EqualValues(t, 1, resp.Imported)
+		assert.EqualValues(t, 1, resp.Attached)
+		assert.EqualValues(t, 1, resp.Duplicates)
+		assert.EqualValues(t, 1, resp.Skipped)
+		assert.EqualValues(t, 1, resp.Unmatched)
+		assert.EqualValues(t, model.ImportStatusUnmatched, resp.Items[4].Status)
+		assert.Nil(t, resp.Items[0].AnnotationID)
+		assert.EqualValues(t, f.book.ID, *resp.Items[0].BookID)
+
+		assert.EqualValues(t, 0, f.countAnnotations(t))
+	})
+
+	t.Run("Positive Case - import and attach notes", func(t *testing.T) {
+		f := newImportFixture(t)
+
+		resp, err := f.importUc.ImportKindle(context.Background(), &model.ImportKindleRequest{
+			UserID:  f.user.ID,
+			Content: []byte(kindleClippings),
+		})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, resp.Imported)
+		assert.EqualValues(t, 1, resp.Attached)
+		assert.NotNil(t, resp.Items[0].AnnotationID)
+
+		annotation, err := f.annotationUc.Get(context.Background(), &model.GetAnnotationRequest{
+			ViewerID: f.user.ID,
+			ID:       *resp.Items[0].AnnotationID,
+		})
+		assert.NoError(t, err)
+		assert.EqualValues(t, "Famous opening line.", annotation.Note)
+		assert.EqualValues(t, "118-120", annotation.Location)
+		assert.EqualValues(t, 3, annotation.Page)
+		assert.EqualValues(t, "private", annotation.Visibility)
+		assert.EqualValues(t, time.Date(2024, 6, 3, 21, 4, 11, 0, time.UTC), annotation.CreatedAt.UTC())
+
+		assert.EqualValues(t, 1, f.countAnnotations(t))
+	})
+
+	t.Run("Positive Case - importing twice skips duplicates", func(t *testing.T) {
+		f := newImportFixture(t)
+
+		_, err := f.importUc.ImportKindle(context.Background(), &model.ImportKindleRequest{
+			UserID:  f.user.ID,
+			Content: []byte(kindleClippings),
+		})
+		assert.NoError(t, err)
+
+		resp, err := f.importUc.ImportKindle(context.Background(), &model.ImportKindleRequest{
+			UserID:  f.user.ID,
+			Content: []byte(kindleClippings),
+		})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 0, resp.Imported)
+		assert.EqualValues(t, 0, resp.Attached)
+		assert.EqualValues(t, 3, resp.Duplicates)
+
+		assert.EqualValues(t, 1, f.countAnnotations(t))
+	})
+
+	t.Run("Positive Case - create missing books", func(t *testing.T) {
+		f := newImportFixture(t)
+
+		resp, err := f.importUc.ImportKindle(context.Background(), &model.ImportKindleRequest{
+			UserID:        f.user.ID,
+			CreateMissing: true,
+			Content:       []byte(kindleClippings),
+		})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, resp.Imported)
+		assert.EqualValues(t, 0, resp.Unmatched)
+		assert.EqualValues(t, 1, resp.CreatedBooks)
+		assert.EqualValues(t, 0, resp.CreatedAuthors)
+		assert.NotEqualValues(t, f.book.ID, *resp.Items[4].BookID)
+
+		request := &model.GetManyAuthorsRequest{}
+		request.Page = 1
+		request.Size = 10
+
+		_, total, err := f.authorUc.GetMany(context.Background(), request)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, total)
+	})
+
+	t.Run("Negative Case - invalid file", func(t *testing.T) {
+		f := newImportFixture(t)
+
+		resp, err := f.importUc.ImportKindle(context.Background(), &model.ImportKindleRequest{
+			UserID:  f.user.ID,
+			Content: []byte("Nineteen Eighty-Four (George Orwell)\n- Something else\n\ntext\n==========\n"),
+		})
+		assert.Nil(t, resp)
+		assert.EqualValues(t, model.ErrorBadRequest(errors.New(`invalid clippings file: entry 1: unrecognized metadata line "- Something else"`)), err)
+	})
+}
+
+const booksCSV = "title,isbn,author,author_birthdate,page_count\n" +
+	"Nineteen Eighty-Four,978-0451524935,George Orwell,,328\n" +
+	"Animal Farm,978-0451526342,George Orwell,,\n" +
+	"Brave New World,978-0060850524,Aldous Huxley,1894-07-26,288\n" +
+	",978-0000000000,Nobody,,\n" +
+	"Homage to Catalonia,978-0451526342,George Orwell,,\n"
+
+func TestImportUsecase_ImportCSV(t *testing.T) {
+	t.Run("Positive Case - per row mode keeps valid rows", func(t *testing.T) {
+		f := newImportFixture(t)
+
+		resp, err := f.importUc.ImportCSV(context.Background(), &model.ImportBooksRequest{
+			Mode:    model.ImportModePerRow,
+			Content: []byte(booksCSV),
+		})
+		assert.NoError(t, err)
+		assert.True(t, resp.Committed)
+		assert.EqualValues(t, 5, resp.Total)
+		assert.EqualValues(t, 2, resp.Created)
+		assert.EqualValues(t, 1, resp.Updated)
+		assert.EqualValues(t, 2, resp.Failed)
+		assert.EqualValues(t, 1, resp.CreatedAuthors)
+
+		assert.EqualValues(t, model.ImportStatusUpdated, resp.Rows[0].Status)
+		assert.EqualValues(t, "changed page_count", resp.Rows[0].Reason)
+		assert.EqualValues(t, f.book.ID, *resp.Rows[0].BookID)
+		assert.EqualValues(t, 4, resp.Rows[2].Line)
+		assert.EqualValues(t, "title is required", resp.Rows[3].Reason)
+		assert.EqualValues(t, "duplicate isbn, already used on line 3", resp.Rows[4].Reason)
+
+		request := &model.GetManyAuthorsRequest{}
+		request.Page = 1
+		request.Size = 10
+
+		authors, total, err := f.authorUc.GetMany(context.Background(), request)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, total)
+		assert.EqualValues(t, time.Date(1894, 7, 26, 0, 0, 0, 0, time.UTC), authors[1].Birthdate.UTC())
+	})
+
+	t.Run("Positive Case - importing twice skips unchanged rows", func(t *testing.T) {
+		f := newImportFixture(t)
+
+		request := &model.ImportBooksRequest{
+			Mode:    model.ImportModePerRow,
+			Content: []byte(booksCSV),
+		}
+		_, err := f.importUc.ImportCSV(context.Background(), request)
+		assert.NoError(t, err)
+
+		resp, err := f.importUc.ImportCSV(context.Background(), request)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 0, resp.Created)
+		assert.EqualValues(t, 0, resp.Updated)
+		assert.EqualValues(t, 3, resp.Skipped)
+		assert.EqualValues(t, 0, resp.CreatedAuthors)
+	})
+
+	t.Run("Positive Case - transaction mode rolls back on failure", func(t *testing.T) {
+		f := newImportFixture(t)
+
+		resp, err := f.importUc.ImportCSV(context.Background(), &model.ImportBooksRequest{
+			Content: []byte(booksCSV),
+		})
+		assert.NoError(t, err)
+		assert.EqualValues(t, model.ImportModeTransaction, resp.Mode)
+		assert.False(t, resp.Committed)
+		assert.EqualValues(t, 2, resp.Created)
+		assert.EqualValues(t, 2, resp.Failed)
+
+		request := &model.GetManyAuthorsRequest{}
+		request.Page = 1
+		request.Size = 10
+
+		_, total, err := f.authorUc.GetMany(context.Background(), request)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, total)
+	})
+
+	t.Run("Positive Case - dry run", func(t *testing.T) {
+		f := newImportFixture(t)
+
+		resp, err := f.importUc.ImportCSV(context.Background(), &model.ImportBooksRequest{
+			DryRun:  true,
+			Content: []byte("title,isbn,author\nBrave New World,978-0060850524,Aldous Huxley\n"),
+		})
+		assert.NoError(t, err)
+		assert.True(t, resp.DryRun)
+		assert.False(t, resp.Committed)
+		assert.EqualValues(t, 1, resp.Created)
+
+		request := &model.GetManyAuthorsRequest{}
+		request.Page = 1
+		request.Size = 10
+
+		_, total, err := f.authorUc.GetMany(context.Background(), request)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, total)
+	})
+
+	t.Run("Negative Case - missing column", func(t *testing.T) {
+		f := newImportFixture(t)
+
+		resp, err := f.importUc.ImportCSV(context.Background(), &model.ImportBooksRequest{
+			Content: []byte("title,author\nBrave New World,Aldous Huxley\n"),
+		})
+		assert.Nil(t, resp)
+		assert.EqualValues(t, model.ErrorBadRequest(errors.New("invalid csv file: missing isbn column")), err)
+	})
+}
+
+func TestImportUsecase_ImportMARC(t *testing.T) {
+	for _, format := range []string{model.ExportFormatMARC, model.ExportFormatMARCXML} {
+		t.Run("Positive Case - import "+format+" export", func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			err := newExportUsecase(t).ExportBooks(context.Background(), &model.ExportBooksRequest{Format: format}, buf)
+			assert.NoError(t, err)
+
+			f := newImportFixture(t)
+
+			resp, err := f.importUc.ImportMARC(context.Background(), &model.ImportBooksRequest{Content: buf.Bytes()})
+			assert.NoError(t, err)
+			assert.True(t, resp.Committed)
+			assert.EqualValues(t, 3, resp.Total)
+			assert.EqualValues(t, 2, resp.Created)
+			assert.EqualValues(t, 1, resp.Updated)
+			assert.EqualValues(t, 1, resp.CreatedAuthors)
+
+			assert.EqualValues(t, 1, resp.Rows[0].Record)
+			assert.EqualValues(t, "Nineteen Eighty-Four", resp.Rows[0].Title)
+			assert.EqualValues(t, "changed page_count", resp.Rows[0].Reason)
+			assert.EqualValues(t, "The TeXbook & 100% {Fun}", resp.Rows[2].Title)
+			assert.EqualValues(t, "Donald Knuth", resp.Rows[2].Author)
+		})
+	}
+
+	t.Run("Positive Case - record without isbn fails", func(t *testing.T) {
+		f := newImportFixture(t)
+
+		content := `<?xml version="1.0" encoding="UTF-8"?>
+<record xmlns="http://www.loc.gov/MARC21/slim">
+  <leader>00000nam a2200000 i 4500</leader>
+  <datafield tag="100" ind1="1" ind2=" "><subfield code="a">Huxley, Aldous,</subfield><subfield code="d">1894-1963.</subfield></datafield>
+  <datafield tag="245" ind1="1" ind2="0"><subfield code="a">Brave new world :</subfield><subfield code="b">a novel /</subfield></datafield>
+</record>`
+
+		resp, err := f.importUc.ImportMARC(context.Background(), &model.ImportBooksRequest{Content: []byte(content)})
+		assert.NoError(t, err)
+		assert.False(t, resp.Committed)
+		assert.EqualValues(t, "Brave new world: a novel", resp.Rows[0].Title)
+		assert.EqualValues(t, "Aldous Huxley", resp.Rows[0].Author)
+		assert.EqualValues(t, "isbn (020 $a) is required", resp.Rows[0].Reason)
+	})
+
+	t.Run("Negative Case - invalid file", func(t *testing.T) {
+		f := newImportFixture(t)
+
+		resp, err := f.importUc.ImportMARC(context.Background(), &model.ImportBooksRequest{Content: []byte("not a marc record\x1d")})
+		assert.Nil(t, resp)
+		assert.EqualValues(t, model.ErrorBadRequest(errors.New("invalid marc file: record 1: record is too short")), err)
+	})
+}
+
+// newCalibreLibrary creates a metadata.db with the subset of the Calibre
+// schema the importer reads.
+func newCalibreLibrary(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "metadata.db")
+	db := config.NewDatabase("sqlite", path, 1, 1, 100, config.SQLiteOptions{})
+
+	for _, statement := range []string{
+		"CREATE TABLE books (id INTEGER PRIMARY KEY, title TEXT NOT NULL, sort TEXT, isbn TEXT DEFAULT '', uuid TEXT, series_index REAL NOT NULL DEFAULT 1.0)",
+		"CREATE TABLE authors (id INTEGER PRIMARY KEY, name TEXT NOT NULL, sort TEXT)",
+		"CREATE TABLE books_authors_link (id INTEGER PRIMARY KEY, book INTEGER NOT NULL, author INTEGER NOT NULL)",
+		"CREATE TABLE identifiers (id INTEGER PRIMARY KEY, book INTEGER NOT NULL, type TEXT NOT NULL DEFAULT 'isbn', val TEXT NOT NULL)",
+		"CREATE TABLE tags (id INTEGER PRIMARY KEY, name TEXT NOT NULL)",
+		"CREATE TABLE books_tags_link (id INTEGER PRIMARY KEY, book INTEGER NOT NULL, tag INTEGER NOT NULL)",
+		"CREATE TABLE series (id INTEGER PRIMARY KEY, name TEXT NOT NULL)",
+		"CREATE TABLE books_series_link (id INTEGER PRIMARY KEY, book INTEGER NOT NULL, series INTEGER NOT NULL)",
+		"CREATE TABLE languages (id INTEGER PRIMARY KEY, lang_code TEXT NOT NULL)",
+		"CREATE TABLE books_languages_link (id INTEGER PRIMARY KEY, book INTEGER NOT NULL, lang_code INTEGER NOT NULL, item_order INTEGER NOT NULL DEFAULT 0)",
+		"INSERT INTO books (id, title, uuid) VALUES (1, 'Nineteen Eighty-Four', 'a'), (2, 'Foundation', 'b'), (3, 'Good Omens', 'c'), (4, 'Untitled Draft', 'd'), (5, '1984', 'e')",
+		"INSERT INTO authors (id, name) VALUES (1, 'George Orwell'), (2, 'Isaac Asimov'), (3, 'Terry Pratchett'), (4, 'Neil Gaiman')",
+		"INSERT INTO books_authors_link (book, author) VALUES (1, 1), (2, 2), (3, 3), (3, 4), (4, 3), (5, 1)",
+		"INSERT INTO identifiers (book, type, val) VALUES (1, 'isbn', '9780451524935'), (2, 'isbn', '9780553293357'), (3, 'isbn', '9780060853983'), (3, 'goodreads', '12067'), (5, 'isbn', '9780000001984')",
+		"INSERT INTO tags (id, name) VALUES (1, 'science fiction'), (2, 'classics')",
+		"INSERT INTO books_tags_link (book, tag) VALUES (1, 2), (2, 1), (2, 2)",
+		"INSERT INTO series (id, name) VALUES (1, 'Foundation')",
+		"INSERT INTO books_series_link (book, series) VALUES (2, 1)",
+		"INSERT INTO languages (id, lang_code) VALUES (1, 'fra'), (2, 'eng')",
+		"INSERT INTO books_languages_link (book, lang_code, item_order) VALUES (2, 1, 1), (2, 2, 0), (3, 2, 0)",
+	} {
+		assert.NoError(t, db.Exec(statement).Error)
+	}
+
+	connection, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, connection.Close())
+
+	return path
+}
+
+func TestImportUsecase_ImportCalibre(t *testing.T) {
+	path := newCalibreLibrary(t)
+
+	t.Run("Positive Case - import with conflict report", func(t *testing.T) {
+		f := newImportFixture(t)
+
+		_, err := f.importUc.ImportCSV(context.Background(), &model.ImportBooksRequest{
+			Content: []byte("title,isbn,author\n1984,978-0000001984,Eric Blair\n"),
+		})
+		assert.NoError(t, err)
+
+		resp, err := f.importUc.ImportCalibre(context.Background(), &model.ImportCalibreRequest{Path: path})
+		assert.NoError(t, err)
+		assert.True(t, resp.Committed)
+		assert.EqualValues(t, 5, resp.Total)
+		assert.EqualValues(t, 2, resp.Created)
+		assert.EqualValues(t, 2, resp.Skipped)
+		assert.EqualValues(t, 1, resp.Conflicts)
+		assert.EqualValues(t, 2, resp.CreatedAuthors)
+
+		assert.EqualValues(t, model.ImportStatusSkipped, resp.Rows[0].Status)
+		assert.EqualValues(t, f.book.ID, *resp.Rows[0].BookID)
+		assert.EqualValues(t, []string{"tags not stored: classics"}, resp.Rows[0].Notes)
+		assert.EqualValues(t, []string{"tags not stored: classics, science fiction"}, resp.Rows[1].Notes)
+		assert.EqualValues(t, []string{"co-authors not stored: Neil Gaiman"}, resp.Rows[2].Notes)
+		assert.EqualValues(t, "book has no isbn identifier", resp.Rows[3].Reason)
+		assert.EqualValues(t, model.ImportStatusConflict, resp.Rows[4].Status)
+		assert.EqualValues(t, []model.ImportConflictResponse{
+			{Field: "author", Current: "Eric Blair", Incoming: "George Orwell"},
+		}, resp.Rows[4].Conflicts)
+
+		book, err := f.bookUc.Get(context.Background(), &model.GetBookRequest{ID: *resp.Rows[1].BookID})
+		assert.NoError(t, err)
+		assert.EqualValues(t, "Foundation", book.Series)
+		assert.EqualValues(t, 1, book.SeriesIndex)
+		assert.EqualValues(t, "eng", book.Language)
+		book, err = f.bookUc.Get(context.Background(), &model.GetBookRequest{ID: *resp.Rows[2].BookID})
+		assert.NoError(t, err)
+		assert.EqualValues(t, "", book.Series)
+		assert.EqualValues(t, 0, book.SeriesIndex)
+	})
+
+	t.Run("Positive Case - overwrite resolves conflicts", func(t *testing.T) {
+		f := newImportFixture(t)
+
+		_, err := f.importUc.ImportCSV(context.Background(), &model.ImportBooksRequest{
+			Content: []byte("title,isbn,author\n1984,978-0000001984,Eric Blair\n"),
+		})
+		assert.NoError(t, err)
+
+		resp, err := f.importUc.ImportCalibre(context.Background(), &model.ImportCalibreRequest{
+			Path:      path,
+			Overwrite: true,
+		})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 0, resp.Conflicts)
+		assert.EqualValues(t, 1, resp.Updated)
+		assert.EqualValues(t, "changed author", resp.Rows[4].Reason)
+	})
+
+	t.Run("Negative Case - not a calibre library", func(t *testing.T) {
+		f := newImportFixture(t)
+
+		other := filepath.Join(t.TempDir(), "other.db")
+		config.NewDatabase("sqlite", other, 1, 1, 100, config.SQLiteOptions{}).Exec("CREATE TABLE notes (id INTEGER PRIMARY KEY)")
+
+		resp, err := f.importUc.ImportCalibre(context.Background(), &model.ImportCalibreRequest{Path: other})
+		assert.Nil(t, resp)
+		assert.EqualValues(t, model.ErrorBadRequest(errors.New("invalid calibre library: not a calibre library, missing books table")), err)
+	})
+}
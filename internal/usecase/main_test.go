@@ -0,0 +1,14 @@
+package usecase_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/testdb"
+)
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	testdb.Close()
+	os.Exit(code)
+}
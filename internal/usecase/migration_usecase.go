@@ -0,0 +1,232 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+	"github.com/mnaufalhilmym/bookshelf/internal/migration"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/gotracing"
+	"gorm.io/gorm"
+)
+
+type MigrationUsecase struct {
+	db         *gorm.DB
+	repository *repository.SchemaMigrationRepository
+	migrations []migration.Migration
+}
+
+func NewMigrationUsecase(
+	db *gorm.DB,
+	repository *repository.SchemaMigrationRepository,
+	migrations []migration.Migration,
+) *MigrationUsecase {
+	return &MigrationUsecase{
+		db,
+		repository,
+		migrations,
+	}
+}
+
+func (uc *MigrationUsecase) Status(ctx context.Context) ([]model.MigrationResponse, error) {
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
+	defer tx.Rollback()
+
+	applied, err := uc.repository.FindAllApplied(tx)
+	if err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to find applied migrations"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return uc.status(applied), nil
+}
+
+// Check returns an error unless every migration is applied as it is in this
+// build, which the application requires to start.
+func (uc *MigrationUsecase) Check(ctx context.Context) error {
+	statuses, err := uc.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := checkAppliedMigrations(statuses); err != nil {
+		return err
+	}
+
+	pending := 0
+	for _, status := range statuses {
+		if status.Status == model.MigrationStatusPending {
+			pending++
+		}
+	}
+	if pending > 0 {
+		return model.ErrorConflict(fmt.Errorf("database schema has %d pending migrations, run \"migrate up\" first", pending))
+	}
+
+	return nil
+}
+
+func (uc *MigrationUsecase) Up(ctx context.Context, request *model.MigrateUpRequest) ([]model.MigrationResponse, error) {
+	target := request.Target
+	if target == 0 && len(uc.migrations) > 0 {
+		target = uc.migrations[len(uc.migrations)-1].Version
+	}
+	if uc.find(target) == nil {
+		return nil, model.ErrorBadRequest(fmt.Errorf("migration %d does not exist", target))
+	}
+
+	if err := uc.repository.CreateTable(uc.db.WithContext(ctx)); err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to create migrations table"))
+	}
+
+	statuses, err := uc.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkAppliedMigrations(statuses); err != nil {
+		return nil, err
+	}
+
+	migrated := []model.MigrationResponse{}
+	for _, status := range statuses {
+		if status.Status != model.MigrationStatusPending || status.Version > target {
+			continue
+		}
+		m := uc.find(status.Version)
+		if err := uc.apply(ctx, m, true); err != nil {
+			return migrated, err
+		}
+		response := model.ToMigrationResponse(m, nil)
+		response.Status = model.MigrationStatusApplied
+		migrated = append(migrated, response)
+	}
+
+	return migrated, nil
+}
+
+// Down reverts the latest applied migrations, as many as request.Steps.
+func (uc *MigrationUsecase) Down(ctx context.Context, request *model.MigrateDownRequest) ([]model.MigrationResponse, error) {
+	if request.Steps < 1 {
+		return nil, model.ErrorBadRequest(errors.New("steps must be at least 1"))
+	}
+
+	statuses, err := uc.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkAppliedMigrations(statuses); err != nil {
+		return nil, err
+	}
+
+	reverted := []model.MigrationResponse{}
+	for i := len(statuses) - 1; i >= 0 && len(reverted) < request.Steps; i-- {
+		if statuses[i].Status != model.MigrationStatusApplied {
+			continue
+		}
+		m := uc.find(statuses[i].Version)
+		if err := uc.apply(ctx, m, false); err != nil {
+			return reverted, err
+		}
+		reverted = append(reverted, model.ToMigrationResponse(m, nil))
+	}
+
+	return reverted, nil
+}
+
+func (uc *MigrationUsecase) apply(ctx context.Context, m *migration.Migration, up bool) error {
+	restoreForeignKeys, err := uc.repository.DisableForeignKeys(uc.db.WithContext(ctx))
+	if err != nil {
+		return model.ErrorInternalServerError(errors.New("failed to disable foreign keys"))
+	}
+	defer restoreForeignKeys()
+
+	tx := begin(ctx, uc.db)
+	defer tx.Rollback()
+
+	steps := m.Down
+	if up {
+		steps = m.Up
+	}
+	for _, step := range steps {
+		if err := step.Apply(tx); err != nil {
+			gotracing.Error("Failed to apply migration", err)
+			return model.ErrorInternalServerError(fmt.Errorf("failed to apply migration %d %s: %w", m.Version, m.Name, err))
+		}
+	}
+
+	record := &entity.SchemaMigration{Version: m.Version}
+	if up {
+		record.Name = m.Name
+		record.Checksum = m.Checksum()
+		record.AppliedAt = time.Now()
+		if err := uc.repository.Create(tx, record); err != nil {
+			return model.ErrorInternalServerError(errors.New("failed to record migration"))
+		}
+	} else {
+		if err := uc.repository.Delete(tx, record); err != nil {
+			return model.ErrorInternalServerError(errors.New("failed to delete migration record"))
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return nil
+}
+
+func (uc *MigrationUsecase) find(version int) *migration.Migration {
+	for i := range uc.migrations {
+		if uc.migrations[i].Version == version {
+			return &uc.migrations[i]
+		}
+	}
+	return nil
+}
+
+func (uc *MigrationUsecase) status(applied []entity.SchemaMigration) []model.MigrationResponse {
+	byVersion := make(map[int]*entity.SchemaMigration, len(applied))
+	for i := range applied {
+		byVersion[applied[i].Version] = &applied[i]
+	}
+
+	statuses := make([]model.MigrationResponse, 0, len(uc.migrations))
+	for i := range uc.migrations {
+		m := &uc.migrations[i]
+		statuses = append(statuses, model.ToMigrationResponse(m, byVersion[m.Version]))
+		delete(byVersion, m.Version)
+	}
+	for _, record := range byVersion {
+		statuses = append(statuses, model.ToUnknownMigrationResponse(record))
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+	return statuses
+}
+
+// checkAppliedMigrations refuses to work with a database that was migrated
+// by a migration that has been edited since, or by a newer build.
+func checkAppliedMigrations(statuses []model.MigrationResponse) error {
+	for _, status := range statuses {
+		switch status.Status {
+		case model.MigrationStatusModified:
+			return model.ErrorConflict(fmt.Errorf("migration %d %s was edited after it was applied", status.Version, status.Name))
+		case model.MigrationStatusUnknown:
+			return model.ErrorConflict(fmt.Errorf("migration %d %s was applied by a newer build", status.Version, status.Name))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/migration"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/bookshelf/internal/testdb"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func newDatabase() *gorm.DB {
+	db := testdb.New()
+	uc := usecase.NewMigrationUsecase(db, repository.NewSchemaMigrationRepository(), migration.All())
+	if _, err := uc.Up(context.Background(), &model.MigrateUpRequest{}); err != nil {
+		panic(err)
+	}
+	return db
+}
+
+type migrationTestNote struct {
+	ID   int    `gorm:"column:id;primaryKey"`
+	Text string `gorm:"column:text"`
+}
+
+func (*migrationTestNote) TableName() string {
+	return "notes"
+}
+
+type migrationTestNoteV2 struct {
+	ID     int    `gorm:"column:id;primaryKey"`
+	Text   string `gorm:"column:text"`
+	Pinned bool   `gorm:"column:pinned;not null;default:false"`
+}
+
+func (*migrationTestNoteV2) TableName() string {
+	return "notes"
+}
+
+func newTestMigrations() []migration.Migration {
+	return []migration.Migration{
+		{
+			Version: 1,
+			Name:    "create_notes",
+			Up:      []migration.Step{migration.AutoMigrate(&migrationTestNote{})},
+			Down:    []migration.Step{migration.DropTable(&migrationTestNote{})},
+		},
+		{
+			Version: 2,
+			Name:    "add_notes_pinned",
+			Up:      []migration.Step{migration.AddColumn(&migrationTestNoteV2{}, "Pinned")},
+			Down:    []migration.Step{migration.DropColumn(&migrationTestNoteV2{}, "Pinned")},
+		},
+	}
+}
+
+func migrationStatuses(responses []model.MigrationResponse) []string {
+	statuses := make([]string, len(responses))
+	for i, response := range responses {
+		statuses[i] = response.Status
+	}
+	return statuses
+}
+
+func TestMigrationUsecase_UpDown(t *testing.T) {
+	ctx := context.Background()
+	db := testdb.New()
+	repo := repository.NewSchemaMigrationRepository()
+	uc := usecase.NewMigrationUsecase(db, repo, newTestMigrations())
+
+	t.Run("Negative Case - unmigrated database", func(t *testing.T) {
+		statuses, err := uc.Status(ctx)
+		assert.NoError(t, err)
+		assert.EqualValues(t, []string{model.MigrationStatusPending, model.MigrationStatusPending}, migrationStatuses(statuses))
+
+		err = uc.Check(ctx)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "2 pending migrations")
+	})
+
+	t.Run("Positive Case - up to version", func(t *testing.T) {
+		migrated, err := uc.Up(ctx, &model.MigrateUpRequest{Target: 1})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, len(migrated))
+		assert.True(t, db.Migrator().HasTable("notes"))
+		assert.False(t, db.Migrator().HasColumn(&migrationTestNoteV2{}, "pinned"))
+	})
+
+	t.Run("Positive Case - up to latest", func(t *testing.T) {
+		migrated, err := uc.Up(ctx, &model.MigrateUpRequest{})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, len(migrated))
+		assert.EqualValues(t, 2, migrated[0].Version)
+		assert.True(t, db.Migrator().HasColumn(&migrationTestNoteV2{}, "pinned"))
+		assert.NoError(t, uc.Check(ctx))
+
+		migrated, err = uc.Up(ctx, &model.MigrateUpRequest{})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 0, len(migrated))
+	})
+
+	t.Run("Positive Case - down", func(t *testing.T) {
+		reverted, err := uc.Down(ctx, &model.MigrateDownRequest{Steps: 1})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, len(reverted))
+		assert.EqualValues(t, 2, reverted[0].Version)
+		assert.False(t, db.Migrator().HasColumn(&migrationTestNoteV2{}, "pinned"))
+
+		statuses, err := uc.Status(ctx)
+		assert.NoError(t, err)
+		assert.EqualValues(t, []string{model.MigrationStatusApplied, model.MigrationStatusPending}, migrationStatuses(statuses))
+	})
+
+	t.Run("Negative Case - unknown version", func(t *testing.T) {
+		_, err := uc.Up(ctx, &model.MigrateUpRequest{Target: 3})
+		assert.Error(t, err)
+
+		_, err = uc.Down(ctx, &model.MigrateDownRequest{Steps: 0})
+		assert.Error(t, err)
+	})
+
+	t.Run("Positive Case - down all", func(t *testing.T) {
+		reverted, err := uc.Down(ctx, &model.MigrateDownRequest{Steps: 5})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, len(reverted))
+		assert.False(t, db.Migrator().HasTable("notes"))
+	})
+}
+
+func TestMigrationUsecase_Checksum(t *testing.T) {
+	ctx := context.Background()
+	db := testdb.New()
+	repo := repository.NewSchemaMigrationRepository()
+
+	_, err := usecase.NewMigrationUsecase(db, repo, newTestMigrations()).Up(ctx, &model.MigrateUpRequest{})
+	assert.NoError(t, err)
+
+	t.Run("Negative Case - edited migration", func(t *testing.T) {
+		migrations := newTestMigrations()
+		migrations[0].Up = []migration.Step{migration.AutoMigrate(&migrationTestNoteV2{})}
+		uc := usecase.NewMigrationUsecase(db, repo, migrations)
+
+		statuses, err := uc.Status(ctx)
+		assert.NoError(t, err)
+		assert.EqualValues(t, []string{model.MigrationStatusModified, model.MigrationStatusApplied}, migrationStatuses(statuses))
+
+		err = uc.Check(ctx)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "migration 1 create_notes was edited after it was applied")
+
+		_, err = uc.Up(ctx, &model.MigrateUpRequest{})
+		assert.Error(t, err)
+		_, err = uc.Down(ctx, &model.MigrateDownRequest{Steps: 1})
+		assert.Error(t, err)
+	})
+
+	t.Run("Negative Case - migrated by newer build", func(t *testing.T) {
+		uc := usecase.NewMigrationUsecase(db, repo, newTestMigrations()[:1])
+
+		statuses, err := uc.Status(ctx)
+		assert.NoError(t, err)
+		assert.EqualValues(t, []string{model.MigrationStatusApplied, model.MigrationStatusUnknown}, migrationStatuses(statuses))
+
+		err = uc.Check(ctx)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "migration 2 add_notes_pinned was applied by a newer build")
+	})
+}
+
+func TestMigrationUsecase_InitialSchema(t *testing.T) {
+	ctx := context.Background()
+	db := newDatabase()
+	uc := usecase.NewMigrationUsecase(db, repository.NewSchemaMigrationRepository(), migration.All())
+
+	assert.NoError(t, uc.Check(ctx))
+
+	reverted, err := uc.Down(ctx, &model.MigrateDownRequest{Steps: len(migration.All())})
+	assert.NoError(t, err)
+	assert.EqualValues(t, len(migration.All()), len(reverted))
+	assert.False(t, db.Migrator().HasTable("books"))
+	assert.Error(t, uc.Check(ctx))
+
+	_, err = uc.Up(ctx, &model.MigrateUpRequest{})
+	assert.NoError(t, err)
+	assert.True(t, db.Migrator().HasTable("books"))
+}
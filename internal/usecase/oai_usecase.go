@@ -0,0 +1,568 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+	"github.com/mnaufalhilmym/bookshelf/internal/format/dublincore"
+	"github.com/mnaufalhilmym/bookshelf/internal/format/marc"
+	"github.com/mnaufalhilmym/bookshelf/internal/format/oaipmh"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/gotracing"
+	"gorm.io/gorm"
+)
+
+const (
+	oaiPageSize   = 50
+	oaiPrefixDC   = "oai_dc"
+	oaiPrefixMARC = "marc21"
+	oaiSetAuthor  = "author"
+)
+
+var oaiMetadataFormats = []oaipmh.MetadataFormat{
+	{Prefix: oaiPrefixDC, Schema: dublincore.OAISchema, Namespace: dublincore.OAINamespace},
+	{Prefix: oaiPrefixMARC, Schema: marc.XMLSchema, Namespace: marc.XMLNamespace},
+}
+
+// oaiArguments lists the arguments each verb accepts besides the verb.
+var oaiArguments = map[string][]string{
+	oaipmh.VerbIdentify:            {},
+	oaipmh.VerbListMetadataFormats: {"identifier"},
+	oaipmh.VerbListSets:            {"resumptionToken"},
+	oaipmh.VerbListIdentifiers:     {"metadataPrefix", "from", "until", "set", "resumptionToken"},
+	oaipmh.VerbListRecords:         {"metadataPrefix", "from", "until", "set", "resumptionToken"},
+	oaipmh.VerbGetRecord:           {"identifier", "metadataPrefix"},
+}
+
+// OAIUsecase is an OAI-PMH data provider for the books of the catalog. Books
+// are harvested by their modification time and grouped in a set per author.
+// Deleted books are kept as deleted records.
+type OAIUsecase struct {
+	db               *gorm.DB
+	bookRepository   *repository.BookRepository
+	authorRepository *repository.AuthorRepository
+	repositoryName   string
+	adminEmail       string
+	// namespace is the domain name in the identifiers of the records. The
+	// host of the request is used when it is empty.
+	namespace string
+}
+
+func NewOAIUsecase(
+	db *gorm.DB,
+	bookRepository *repository.BookRepository,
+	authorRepository *repository.AuthorRepository,
+	repositoryName string,
+	adminEmail string,
+	namespace string,
+) *OAIUsecase {
+	return &OAIUsecase{
+		db,
+		bookRepository,
+		authorRepository,
+		repositoryName,
+		adminEmail,
+		namespace,
+	}
+}
+
+// oaiList holds the arguments of a list request, which are carried over to
+// the following pages in the resumption token.
+type oaiList struct {
+	verb           string
+	metadataPrefix string
+	set            string
+	from           string
+	until          string
+	afterID        int
+	cursor         int64
+}
+
+func (uc *OAIUsecase) Handle(ctx context.Context, request *model.OAIRequest) (*model.OAIResponse, error) {
+	args := request.Arguments
+	response := &oaipmh.Response{
+		Date:    time.Now(),
+		BaseURL: request.BaseURL,
+		Request: oaipmh.Request{
+			Verb:            args.Get("verb"),
+			Identifier:      args.Get("identifier"),
+			MetadataPrefix:  args.Get("metadataPrefix"),
+			From:            args.Get("from"),
+			Until:           args.Get("until"),
+			Set:             args.Get("set"),
+			ResumptionToken: args.Get("resumptionToken"),
+		},
+	}
+
+	if code, message := checkOAIArguments(args); code != "" {
+		response.Errors = append(response.Errors, oaipmh.Error{Code: code, Message: message})
+	} else if err := uc.handle(ctx, request, response); err != nil {
+		return nil, err
+	}
+
+	var content bytes.Buffer
+	if err := oaipmh.Write(&content, response); err != nil {
+		gotracing.Error("Failed to encode OAI-PMH response", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to encode response"))
+	}
+
+	return &model.OAIResponse{ContentType: oaipmh.ContentType, Content: content.Bytes()}, nil
+}
+
+func (uc *OAIUsecase) handle(ctx context.Context, request *model.OAIRequest, response *oaipmh.Response) error {
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
+	defer tx.Rollback()
+
+	var err error
+	switch response.Request.Verb {
+	case oaipmh.VerbIdentify:
+		err = uc.identify(tx, response)
+	case oaipmh.VerbListMetadataFormats:
+		err = uc.listMetadataFormats(tx, request, response)
+	case oaipmh.VerbListSets:
+		err = uc.listSets(tx, response)
+	case oaipmh.VerbListIdentifiers, oaipmh.VerbListRecords:
+		err = uc.listRecords(tx, request, response)
+	case oaipmh.VerbGetRecord:
+		err = uc.getRecord(tx, request, response)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return nil
+}
+
+func (uc *OAIUsecase) identify(tx *gorm.DB, response *oaipmh.Response) error {
+	earliest, err := uc.bookRepository.FindEarliestModification(tx)
+	if err != nil {
+		return model.ErrorInternalServerError(errors.New("failed to find earliest modification"))
+	}
+	if earliest.IsZero() {
+		earliest = time.Unix(0, 0)
+	}
+
+	response.Identify = &oaipmh.Identify{
+		RepositoryName:    uc.repositoryName,
+		AdminEmails:       []string{uc.adminEmail},
+		EarliestDatestamp: earliest,
+		DeletedRecord:     oaipmh.DeletedRecordPersistent,
+		Granularity:       oaipmh.GranularitySecond,
+	}
+	return nil
+}
+
+func (uc *OAIUsecase) listMetadataFormats(tx *gorm.DB, request *model.OAIRequest, response *oaipmh.Response) error {
+	if identifier := response.Request.Identifier; identifier != "" {
+		header, _, err := uc.findRecord(tx, request, identifier)
+		if err != nil {
+			return err
+		}
+		if header == nil {
+			return oaiError(response, oaipmh.ErrIDDoesNotExist, "identifier does not exist")
+		}
+	}
+
+	response.MetadataFormats = oaiMetadataFormats
+	return nil
+}
+
+func (uc *OAIUsecase) listSets(tx *gorm.DB, response *oaipmh.Response) error {
+	list := &oaiList{verb: oaipmh.VerbListSets}
+	if token := response.Request.ResumptionToken; token != "" {
+		var ok bool
+		if list, ok = decodeOAIList(token, oaipmh.VerbListSets); !ok {
+			return oaiError(response, oaipmh.ErrBadResumptionToken, "resumption token is invalid")
+		}
+	}
+
+	authors, err := uc.authorRepository.FindAllAfterID(tx, list.afterID, oaiPageSize)
+	if err != nil {
+		return model.ErrorInternalServerError(errors.New("failed to get authors"))
+	}
+
+	total, err := uc.authorRepository.Count(tx)
+	if err != nil {
+		return model.ErrorInternalServerError(errors.New("failed to count authors"))
+	}
+
+	// The set of all authors is listed first, so it is not counted by the
+	// cursor, which counts authors.
+	if list.cursor == 0 {
+		response.Sets = append(response.Sets, oaipmh.Set{Spec: oaiSetAuthor, Name: "Authors"})
+	}
+	for _, author := range authors {
+		response.Sets = append(response.Sets, oaipmh.Set{
+			Spec: fmt.Sprintf("%s:%d", oaiSetAuthor, author.ID),
+			Name: author.Name,
+		})
+	}
+
+	lastID := 0
+	if len(authors) > 0 {
+		lastID = authors[len(authors)-1].ID
+	}
+	response.ResumptionToken = nextOAIResumptionToken(list, len(authors), lastID, total)
+	return nil
+}
+
+func (uc *OAIUsecase) listRecords(tx *gorm.DB, request *model.OAIRequest, response *oaipmh.Response) error {
+	list := &oaiList{
+		verb:           response.Request.Verb,
+		metadataPrefix: response.Request.MetadataPrefix,
+		set:            response.Request.Set,
+		from:           response.Request.From,
+		until:          response.Request.Until,
+	}
+	if token := response.Request.ResumptionToken; token != "" {
+		var ok bool
+		if list, ok = decodeOAIList(token, response.Request.Verb); !ok {
+			return oaiError(response, oaipmh.ErrBadResumptionToken, "resumption token is invalid")
+		}
+	}
+
+	if list.metadataPrefix == "" {
+		return oaiError(response, oaipmh.ErrBadArgument, "metadataPrefix is required")
+	}
+	if !isOAIMetadataPrefix(list.metadataPrefix) {
+		return oaiError(response, oaipmh.ErrCannotDisseminateFormat, "metadata format is not supported")
+	}
+
+	from, until, message := parseOAIRange(list.from, list.until)
+	if message != "" {
+		return oaiError(response, oaipmh.ErrBadArgument, message)
+	}
+
+	authorID, ok := parseOAISet(list.set)
+	if !ok {
+		return oaiError(response, oaipmh.ErrNoRecordsMatch, "set does not exist")
+	}
+
+	books, err := uc.bookRepository.FindAllModified(tx, authorID, from, until, list.afterID, oaiPageSize)
+	if err != nil {
+		return model.ErrorInternalServerError(errors.New("failed to get books"))
+	}
+
+	deletedBooks, err := uc.bookRepository.FindAllDeleted(tx, authorID, from, until, list.afterID, oaiPageSize)
+	if err != nil {
+		return model.ErrorInternalServerError(errors.New("failed to get deleted books"))
+	}
+
+	total, err := uc.bookRepository.CountModified(tx, authorID, from, until)
+	if err != nil {
+		return model.ErrorInternalServerError(errors.New("failed to count books"))
+	}
+
+	totalDeleted, err := uc.bookRepository.CountDeleted(tx, authorID, from, until)
+	if err != nil {
+		return model.ErrorInternalServerError(errors.New("failed to count deleted books"))
+	}
+
+	// Books and deleted books are merged by ID, which the resumption token
+	// continues after.
+	var records []oaipmh.Record
+	for len(records) < oaiPageSize && (len(books) > 0 || len(deletedBooks) > 0) {
+		if len(deletedBooks) == 0 || (len(books) > 0 && books[0].ID < deletedBooks[0].ID) {
+			records = append(records, uc.toRecord(request, &books[0], list.metadataPrefix))
+			books = books[1:]
+		} else {
+			records = append(records, uc.toDeletedRecord(request, &deletedBooks[0]))
+			deletedBooks = deletedBooks[1:]
+		}
+	}
+
+	if len(records) == 0 {
+		return oaiError(response, oaipmh.ErrNoRecordsMatch, "no records match the request")
+	}
+
+	if list.verb == oaipmh.VerbListIdentifiers {
+		for _, record := range records {
+			response.Headers = append(response.Headers, record.Header)
+		}
+	} else {
+		response.Records = records
+	}
+
+	lastID, _ := uc.parseIdentifier(request, records[len(records)-1].Identifier)
+	response.ResumptionToken = nextOAIResumptionToken(list, len(records), lastID, total+totalDeleted)
+	return nil
+}
+
+func (uc *OAIUsecase) getRecord(tx *gorm.DB, request *model.OAIRequest, response *oaipmh.Response) error {
+	if response.Request.Identifier == "" || response.Request.MetadataPrefix == "" {
+		return oaiError(response, oaipmh.ErrBadArgument, "identifier and metadataPrefix are required")
+	}
+
+	if !isOAIMetadataPrefix(response.Request.MetadataPrefix) {
+		return oaiError(response, oaipmh.ErrCannotDisseminateFormat, "metadata format is not supported")
+	}
+
+	header, book, err := uc.findRecord(tx, request, response.Request.Identifier)
+	if err != nil {
+		return err
+	}
+	if header == nil {
+		return oaiError(response, oaipmh.ErrIDDoesNotExist, "identifier does not exist")
+	}
+
+	if book != nil {
+		response.Records = []oaipmh.Record{uc.toRecord(request, book, response.Request.MetadataPrefix)}
+	} else {
+		response.Records = []oaipmh.Record{{Header: *header}}
+	}
+	return nil
+}
+
+// findRecord returns the header of the record with the identifier, and the
+// book unless the record is deleted. The header is nil when there is no such
+// record.
+func (uc *OAIUsecase) findRecord(tx *gorm.DB, request *model.OAIRequest, identifier string) (*oaipmh.Header, *entity.Book, error) {
+	id, ok := uc.parseIdentifier(request, identifier)
+	if !ok {
+		return nil, nil, nil
+	}
+
+	book, err := uc.bookRepository.FindByID(tx, id)
+	if err == nil {
+		record := uc.toRecord(request, book, "")
+		return &record.Header, book, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, model.ErrorInternalServerError(errors.New("failed to find book data by id"))
+	}
+
+	deleted, err := uc.bookRepository.FindDeletedByID(tx, id)
+	if err == nil {
+		record := uc.toDeletedRecord(request, deleted)
+		return &record.Header, nil, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, model.ErrorInternalServerError(errors.New("failed to find deleted book data by id"))
+	}
+
+	return nil, nil, nil
+}
+
+func (uc *OAIUsecase) toRecord(request *model.OAIRequest, book *entity.Book, metadataPrefix string) oaipmh.Record {
+	record := oaipmh.Record{
+		Header: oaipmh.Header{
+			Identifier: uc.identifier(request, book.ID),
+			Datestamp:  book.UpdatedAt,
+			Sets:       []string{fmt.Sprintf("%s:%d", oaiSetAuthor, book.AuthorID)},
+		},
+	}
+
+	switch metadataPrefix {
+	case oaiPrefixDC:
+		record.Metadata = model.ToBookDublinCore(model.ToBookResponse(book), "")
+	case oaiPrefixMARC:
+		record.Metadata = toMARCRecord(book)
+	}
+
+	return record
+}
+
+func (uc *OAIUsecase) toDeletedRecord(request *model.OAIRequest, book *entity.DeletedBook) oaipmh.Record {
+	return oaipmh.Record{
+		Header: oaipmh.Header{
+			Identifier: uc.identifier(request, book.ID),
+			Datestamp:  book.DeletedAt,
+			Sets:       []string{fmt.Sprintf("%s:%d", oaiSetAuthor, book.AuthorID)},
+			Deleted:    true,
+		},
+	}
+}
+
+func (uc *OAIUsecase) identifier(request *model.OAIRequest, id int) string {
+	return fmt.Sprintf("%s%d", uc.identifierPrefix(request), id)
+}
+
+func (uc *OAIUsecase) parseIdentifier(request *model.OAIRequest, identifier string) (int, bool) {
+	value, ok := strings.CutPrefix(identifier, uc.identifierPrefix(request))
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.Atoi(value)
+	if err != nil || id <= 0 || strconv.Itoa(id) != value {
+		return 0, false
+	}
+	return id, true
+}
+
+func (uc *OAIUsecase) identifierPrefix(request *model.OAIRequest) string {
+	namespace := uc.namespace
+	if namespace == "" {
+		if u, err := url.Parse(request.BaseURL); err == nil {
+			namespace = u.Hostname()
+		}
+	}
+	return "oai:" + namespace + ":book/"
+}
+
+// checkOAIArguments returns the error code and message of a request with a
+// bad verb or arguments, or an empty code.
+func checkOAIArguments(args url.Values) (string, string) {
+	verb := args.Get("verb")
+	if verb == "" {
+		return oaipmh.ErrBadVerb, "verb is missing"
+	}
+
+	allowed, ok := oaiArguments[verb]
+	if !ok {
+		return oaipmh.ErrBadVerb, "verb is not legal"
+	}
+
+	for key, values := range args {
+		if len(values) > 1 {
+			return oaipmh.ErrBadArgument, fmt.Sprintf("argument %s is repeated", key)
+		}
+		if key != "verb" && !slices.Contains(allowed, key) {
+			return oaipmh.ErrBadArgument, fmt.Sprintf("argument %s is not legal", key)
+		}
+	}
+
+	if args.Has("resumptionToken") && len(args) > 2 {
+		return oaipmh.ErrBadArgument, "resumptionToken is an exclusive argument"
+	}
+
+	return "", ""
+}
+
+func isOAIMetadataPrefix(prefix string) bool {
+	return prefix == oaiPrefixDC || prefix == oaiPrefixMARC
+}
+
+// parseOAIRange returns the bounds of the modification time. Until is made
+// exclusive by adding the granularity it has. A message is returned when the
+// arguments are bad.
+func parseOAIRange(fromValue string, untilValue string) (*time.Time, *time.Time, string) {
+	var from, until *time.Time
+	var fromSeconds, untilSeconds bool
+
+	if fromValue != "" {
+		t, seconds, err := oaipmh.ParseDatestamp(fromValue)
+		if err != nil {
+			return nil, nil, "from is not a valid datestamp"
+		}
+		from, fromSeconds = &t, seconds
+	}
+
+	if untilValue != "" {
+		t, seconds, err := oaipmh.ParseDatestamp(untilValue)
+		if err != nil {
+			return nil, nil, "until is not a valid datestamp"
+		}
+		if seconds {
+			t = t.Add(time.Second)
+		} else {
+			t = t.AddDate(0, 0, 1)
+		}
+		until, untilSeconds = &t, seconds
+	}
+
+	if from != nil && until != nil {
+		if fromSeconds != untilSeconds {
+			return nil, nil, "from and until have different granularities"
+		}
+		if !from.Before(*until) {
+			return nil, nil, "from is after until"
+		}
+	}
+
+	return from, until, ""
+}
+
+// parseOAISet returns the author of an author set, or nil for the set of all
+// authors or no set. ok is false when the set does not exist.
+func parseOAISet(set string) (*int, bool) {
+	if set == "" || set == oaiSetAuthor {
+		return nil, true
+	}
+
+	value, ok := strings.CutPrefix(set, oaiSetAuthor+":")
+	if !ok {
+		return nil, false
+	}
+	id, err := strconv.Atoi(value)
+	if err != nil || id <= 0 {
+		return nil, false
+	}
+	return &id, true
+}
+
+// nextOAIResumptionToken returns the resumption token of a page with count
+// items ending at lastID, or nil when the list fits on one page.
+func nextOAIResumptionToken(list *oaiList, count int, lastID int, total int64) *oaipmh.ResumptionToken {
+	done := list.cursor + int64(count)
+	if list.cursor == 0 && done >= total {
+		return nil
+	}
+
+	token := &oaipmh.ResumptionToken{CompleteListSize: total, Cursor: list.cursor}
+	if done < total && count > 0 {
+		next := *list
+		next.afterID = lastID
+		next.cursor = done
+		token.Token = next.encode()
+	}
+	return token
+}
+
+func (l *oaiList) encode() string {
+	values := url.Values{}
+	values.Set("verb", l.verb)
+	values.Set("metadataPrefix", l.metadataPrefix)
+	values.Set("set", l.set)
+	values.Set("from", l.from)
+	values.Set("until", l.until)
+	values.Set("after", strconv.Itoa(l.afterID))
+	values.Set("cursor", strconv.FormatInt(l.cursor, 10))
+	return base64.RawURLEncoding.EncodeToString([]byte(values.Encode()))
+}
+
+func decodeOAIList(token string, verb string) (*oaiList, bool) {
+	decoded, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, false
+	}
+	values, err := url.ParseQuery(string(decoded))
+	if err != nil || values.Get("verb") != verb {
+		return nil, false
+	}
+
+	list := &oaiList{
+		verb:           verb,
+		metadataPrefix: values.Get("metadataPrefix"),
+		set:            values.Get("set"),
+		from:           values.Get("from"),
+		until:          values.Get("until"),
+	}
+	if list.afterID, err = strconv.Atoi(values.Get("after")); err != nil || list.afterID <= 0 {
+		return nil, false
+	}
+	if list.cursor, err = strconv.ParseInt(values.Get("cursor"), 10, 64); err != nil || list.cursor <= 0 {
+		return nil, false
+	}
+	return list, true
+}
+
+func oaiError(response *oaipmh.Response, code string, message string) error {
+	response.Errors = append(response.Errors, oaipmh.Error{Code: code, Message: message})
+	return nil
+}
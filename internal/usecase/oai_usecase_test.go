@@ -0,0 +1,287 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type oaiTestResponse struct {
+	Request struct {
+		Verb           string `xml:"verb,attr"`
+		MetadataPrefix string `xml:"metadataPrefix,attr"`
+		BaseURL        string `xml:",chardata"`
+	} `xml:"request"`
+	Errors []struct {
+		Code string `xml:"code,attr"`
+	} `xml:"error"`
+	Identify struct {
+		RepositoryName    string `xml:"repositoryName"`
+		AdminEmail        string `xml:"adminEmail"`
+		EarliestDatestamp string `xml:"earliestDatestamp"`
+		DeletedRecord     string `xml:"deletedRecord"`
+		Granularity       string `xml:"granularity"`
+	} `xml:"Identify"`
+	MetadataFormats []string        `xml:"ListMetadataFormats>metadataFormat>metadataPrefix"`
+	SetSpecs        []string        `xml:"ListSets>set>setSpec"`
+	SetNames        []string        `xml:"ListSets>set>setName"`
+	Headers         []oaiTestHeader `xml:"ListIdentifiers>header"`
+	Records         []oaiTestRecord `xml:"ListRecords>record"`
+	Record          []oaiTestRecord `xml:"GetRecord>record"`
+	Token           *oaiTestToken   `xml:"ListRecords>resumptionToken"`
+}
+
+type oaiTestHeader struct {
+	Status     string   `xml:"status,attr"`
+	Identifier string   `xml:"identifier"`
+	Datestamp  string   `xml:"datestamp"`
+	Sets       []string `xml:"setSpec"`
+}
+
+type oaiTestRecord struct {
+	Header   oaiTestHeader `xml:"header"`
+	Metadata *struct {
+		DC *struct {
+			Titles      []string `xml:"title"`
+			Creators    []string `xml:"creator"`
+			Identifiers []string `xml:"identifier"`
+			Relations   []string `xml:"relation"`
+		} `xml:"http://www.openarchives.org/OAI/2.0/oai_dc/ dc"`
+		MARC *struct {
+			ControlFields []string `xml:"controlfield"`
+		} `xml:"http://www.loc.gov/MARC21/slim record"`
+	} `xml:"metadata"`
+}
+
+type oaiTestToken struct {
+	CompleteListSize int64  `xml:"completeListSize,attr"`
+	Cursor           int64  `xml:"cursor,attr"`
+	Token            string `xml:",chardata"`
+}
+
+func newOAIUsecase(t *testing.T) (*usecase.OAIUsecase, *gorm.DB, *repository.BookRepository) {
+	db := newDatabase()
+	authorRepo := repository.NewAuthorRepository()
+	bookRepo := repository.NewBookRepository()
+
+	herbert := &entity.Author{Name: "Frank Herbert"}
+	orwell := &entity.Author{Name: "George Orwell"}
+	assert.NoError(t, authorRepo.Create(db, herbert))
+	assert.NoError(t, authorRepo.Create(db, orwell))
+
+	for _, book := range []*entity.Book{
+		{Title: "Dune", ISBN: "978-0441172719", AuthorID: herbert.ID, Series: "Dune"},
+		{Title: "Dune Messiah", ISBN: "978-0593098233", AuthorID: herbert.ID, Series: "Dune"},
+		{Title: "Nineteen Eighty-Four", ISBN: "kindle-0123456789abcdef", AuthorID: orwell.ID},
+	} {
+		assert.NoError(t, bookRepo.Create(db, book))
+	}
+
+	uc := usecase.NewOAIUsecase(db, bookRepo, authorRepo, "Bookshelf", "admin@example.com", "books.example.com")
+	return uc, db, bookRepo
+}
+
+func harvest(t *testing.T, uc *usecase.OAIUsecase, args url.Values) *oaiTestResponse {
+	res, err := uc.Handle(context.Background(), &model.OAIRequest{
+		BaseURL:   "https://books.example.com/oai",
+		Arguments: args,
+	})
+	assert.NoError(t, err)
+	assert.EqualValues(t, "text/xml; charset=utf-8", res.ContentType)
+
+	response := new(oaiTestResponse)
+	assert.NoError(t, xml.Unmarshal(res.Content, response))
+	return response
+}
+
+func oaiErrorCodes(response *oaiTestResponse) []string {
+	var codes []string
+	for _, err := range response.Errors {
+		codes = append(codes, err.Code)
+	}
+	return codes
+}
+
+func TestOAIUsecase_Protocol(t *testing.T) {
+	uc, db, _ := newOAIUsecase(t)
+
+	earliest := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.NoError(t, db.Model(&entity.Book{}).Where("id = ?", 2).UpdateColumn("updated_at", earliest.Local()).Error)
+
+	t.Run("Positive Case - identify", func(t *testing.T) {
+		response := harvest(t, uc, url.Values{"verb": {"Identify"}})
+		assert.Nil(t, response.Errors)
+		assert.EqualValues(t, "Identify", response.Request.Verb)
+		assert.EqualValues(t, "https://books.example.com/oai", response.Request.BaseURL)
+		assert.EqualValues(t, "Bookshelf", response.Identify.RepositoryName)
+		assert.EqualValues(t, "admin@example.com", response.Identify.AdminEmail)
+		assert.EqualValues(t, "2020-01-02T03:04:05Z", response.Identify.EarliestDatestamp)
+		assert.EqualValues(t, "persistent", response.Identify.DeletedRecord)
+		assert.EqualValues(t, "YYYY-MM-DDThh:mm:ssZ", response.Identify.Granularity)
+	})
+
+	t.Run("Positive Case - list metadata formats", func(t *testing.T) {
+		response := harvest(t, uc, url.Values{"verb": {"ListMetadataFormats"}, "identifier": {"oai:books.example.com:book/1"}})
+		assert.EqualValues(t, []string{"oai_dc", "marc21"}, response.MetadataFormats)
+	})
+
+	t.Run("Positive Case - list sets", func(t *testing.T) {
+		response := harvest(t, uc, url.Values{"verb": {"ListSets"}})
+		assert.EqualValues(t, []string{"author", "author:1", "author:2"}, response.SetSpecs)
+		assert.EqualValues(t, []string{"Authors", "Frank Herbert", "George Orwell"}, response.SetNames)
+	})
+
+	for name, test := range map[string]struct {
+		args url.Values
+		code string
+	}{
+		"missing verb":            {url.Values{}, "badVerb"},
+		"illegal verb":            {url.Values{"verb": {"Harvest"}}, "badVerb"},
+		"repeated argument":       {url.Values{"verb": {"ListRecords"}, "metadataPrefix": {"oai_dc", "marc21"}}, "badArgument"},
+		"illegal argument":        {url.Values{"verb": {"Identify"}, "metadataPrefix": {"oai_dc"}}, "badArgument"},
+		"missing metadataPrefix":  {url.Values{"verb": {"ListIdentifiers"}}, "badArgument"},
+		"exclusive token":         {url.Values{"verb": {"ListRecords"}, "metadataPrefix": {"oai_dc"}, "resumptionToken": {"x"}}, "badArgument"},
+		"invalid from":            {url.Values{"verb": {"ListRecords"}, "metadataPrefix": {"oai_dc"}, "from": {"yesterday"}}, "badArgument"},
+		"mixed granularities":     {url.Values{"verb": {"ListRecords"}, "metadataPrefix": {"oai_dc"}, "from": {"2020-01-01"}, "until": {"2020-01-02T00:00:00Z"}}, "badArgument"},
+		"from after until":        {url.Values{"verb": {"ListRecords"}, "metadataPrefix": {"oai_dc"}, "from": {"2020-01-02"}, "until": {"2020-01-01"}}, "badArgument"},
+		"unknown format":          {url.Values{"verb": {"ListRecords"}, "metadataPrefix": {"mods"}}, "cannotDisseminateFormat"},
+		"unknown set":             {url.Values{"verb": {"ListRecords"}, "metadataPrefix": {"oai_dc"}, "set": {"language:en"}}, "noRecordsMatch"},
+		"empty range":             {url.Values{"verb": {"ListRecords"}, "metadataPrefix": {"oai_dc"}, "until": {"2019-12-31"}}, "noRecordsMatch"},
+		"invalid token":           {url.Values{"verb": {"ListRecords"}, "resumptionToken": {"x"}}, "badResumptionToken"},
+		"unknown identifier":      {url.Values{"verb": {"GetRecord"}, "metadataPrefix": {"oai_dc"}, "identifier": {"oai:books.example.com:book/100"}}, "idDoesNotExist"},
+		"foreign identifier":      {url.Values{"verb": {"GetRecord"}, "metadataPrefix": {"oai_dc"}, "identifier": {"oai:example.org:book/1"}}, "idDoesNotExist"},
+		"missing identifier":      {url.Values{"verb": {"GetRecord"}, "metadataPrefix": {"oai_dc"}}, "badArgument"},
+		"formats of unknown item": {url.Values{"verb": {"ListMetadataFormats"}, "identifier": {"oai:books.example.com:book/100"}}, "idDoesNotExist"},
+	} {
+		t.Run("Negative Case - "+name, func(t *testing.T) {
+			response := harvest(t, uc, test.args)
+			assert.EqualValues(t, []string{test.code}, oaiErrorCodes(response))
+			if test.code == "badVerb" || test.code == "badArgument" {
+				assert.EqualValues(t, "", response.Request.Verb)
+			}
+		})
+	}
+}
+
+func TestOAIUsecase_ListRecords(t *testing.T) {
+	uc, db, bookRepo := newOAIUsecase(t)
+
+	assert.NoError(t, db.Model(&entity.Book{}).Where("id = ?", 1).UpdateColumn("updated_at", time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC).Local()).Error)
+	assert.NoError(t, db.Model(&entity.Book{}).Where("id = ?", 2).UpdateColumn("updated_at", time.Date(2020, 1, 2, 12, 0, 0, 0, time.UTC).Local()).Error)
+
+	t.Run("Positive Case - dublin core records", func(t *testing.T) {
+		response := harvest(t, uc, url.Values{"verb": {"ListRecords"}, "metadataPrefix": {"oai_dc"}})
+		assert.Nil(t, response.Errors)
+		assert.Nil(t, response.Token)
+		assert.EqualValues(t, 3, len(response.Records))
+
+		dune := response.Records[0]
+		assert.EqualValues(t, "oai:books.example.com:book/1", dune.Header.Identifier)
+		assert.EqualValues(t, "2020-01-01T12:00:00Z", dune.Header.Datestamp)
+		assert.EqualValues(t, []string{"author:1"}, dune.Header.Sets)
+		assert.EqualValues(t, []string{"Dune"}, dune.Metadata.DC.Titles)
+		assert.EqualValues(t, []string{"Frank Herbert"}, dune.Metadata.DC.Creators)
+		assert.EqualValues(t, []string{"urn:isbn:9780441172719"}, dune.Metadata.DC.Identifiers)
+		assert.EqualValues(t, []string{"Dune"}, dune.Metadata.DC.Relations)
+
+		assert.Nil(t, response.Records[2].Metadata.DC.Identifiers)
+	})
+
+	t.Run("Positive Case - marcxml record", func(t *testing.T) {
+		response := harvest(t, uc, url.Values{
+			"verb":           {"GetRecord"},
+			"metadataPrefix": {"marc21"},
+			"identifier":     {"oai:books.example.com:book/2"},
+		})
+		assert.Nil(t, response.Errors)
+		assert.EqualValues(t, 1, len(response.Record))
+		assert.EqualValues(t, []string{"2"}, response.Record[0].Metadata.MARC.ControlFields)
+	})
+
+	t.Run("Positive Case - modified in range", func(t *testing.T) {
+		response := harvest(t, uc, url.Values{
+			"verb":           {"ListIdentifiers"},
+			"metadataPrefix": {"oai_dc"},
+			"from":           {"2020-01-01T12:00:01Z"},
+			"until":          {"2020-01-02T12:00:00Z"},
+		})
+		assert.Nil(t, response.Errors)
+		assert.EqualValues(t, 1, len(response.Headers))
+		assert.EqualValues(t, "oai:books.example.com:book/2", response.Headers[0].Identifier)
+	})
+
+	t.Run("Positive Case - author set", func(t *testing.T) {
+		response := harvest(t, uc, url.Values{"verb": {"ListIdentifiers"}, "metadataPrefix": {"oai_dc"}, "set": {"author:2"}})
+		assert.EqualValues(t, 1, len(response.Headers))
+		assert.EqualValues(t, "oai:books.example.com:book/3", response.Headers[0].Identifier)
+	})
+
+	t.Run("Positive Case - deleted record", func(t *testing.T) {
+		book, err := bookRepo.FindByID(db, 1)
+		assert.NoError(t, err)
+		assert.NoError(t, bookRepo.Trash(db, book, nil))
+
+		response := harvest(t, uc, url.Values{"verb": {"ListRecords"}, "metadataPrefix": {"oai_dc"}, "from": {"2021-01-01"}})
+		assert.EqualValues(t, 2, len(response.Records))
+		assert.EqualValues(t, "oai:books.example.com:book/1", response.Records[0].Header.Identifier)
+		assert.EqualValues(t, "deleted", response.Records[0].Header.Status)
+		assert.EqualValues(t, []string{"author:1"}, response.Records[0].Header.Sets)
+		assert.Nil(t, response.Records[0].Metadata)
+		assert.EqualValues(t, "oai:books.example.com:book/3", response.Records[1].Header.Identifier)
+
+		response = harvest(t, uc, url.Values{
+			"verb":           {"GetRecord"},
+			"metadataPrefix": {"oai_dc"},
+			"identifier":     {"oai:books.example.com:book/1"},
+		})
+		assert.Nil(t, response.Errors)
+		assert.EqualValues(t, "deleted", response.Record[0].Header.Status)
+	})
+}
+
+func TestOAIUsecase_ResumptionToken(t *testing.T) {
+	uc, db, bookRepo := newOAIUsecase(t)
+
+	for i := 4; i <= 60; i++ {
+		assert.NoError(t, bookRepo.Create(db, &entity.Book{
+			Title:    fmt.Sprintf("Book Title %d", i),
+			ISBN:     fmt.Sprintf("isbn-%d", i),
+			AuthorID: 1,
+		}))
+	}
+	book, err := bookRepo.FindByID(db, 30)
+	assert.NoError(t, err)
+	assert.NoError(t, bookRepo.Trash(db, book, nil))
+
+	first := harvest(t, uc, url.Values{"verb": {"ListRecords"}, "metadataPrefix": {"marc21"}})
+	assert.Nil(t, first.Errors)
+	assert.EqualValues(t, 50, len(first.Records))
+	assert.EqualValues(t, "deleted", first.Records[29].Header.Status)
+	assert.EqualValues(t, 60, first.Token.CompleteListSize)
+	assert.EqualValues(t, 0, first.Token.Cursor)
+	assert.NotEmpty(t, first.Token.Token)
+
+	last := harvest(t, uc, url.Values{"verb": {"ListRecords"}, "resumptionToken": {first.Token.Token}})
+	assert.Nil(t, last.Errors)
+	assert.EqualValues(t, 10, len(last.Records))
+	assert.EqualValues(t, "oai:books.example.com:book/51", last.Records[0].Header.Identifier)
+	assert.NotNil(t, last.Records[0].Metadata.MARC)
+	assert.EqualValues(t, 60, last.Token.CompleteListSize)
+	assert.EqualValues(t, 50, last.Token.Cursor)
+	assert.Empty(t, last.Token.Token)
+
+	t.Run("Negative Case - token of another verb", func(t *testing.T) {
+		response := harvest(t, uc, url.Values{"verb": {"ListIdentifiers"}, "resumptionToken": {first.Token.Token}})
+		assert.EqualValues(t, []string{"badResumptionToken"}, oaiErrorCodes(response))
+	})
+}
@@ -0,0 +1,429 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+	"github.com/mnaufalhilmym/bookshelf/internal/format/opds"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/bookshelf/internal/util"
+	"github.com/mnaufalhilmym/gotracing"
+	"gorm.io/gorm"
+)
+
+const (
+	opdsPageSize = 25
+	opdsTitle    = "Bookshelf"
+)
+
+// OPDSUsecase serves the catalog as OPDS feeds. OPDS 1.2 feeds live below
+// /opds and OPDS 2.0 feeds below /opds/v2. Covers and files are linked below
+// /opds too, where e-reader apps can fetch them with the same credentials.
+type OPDSUsecase struct {
+	db                 *gorm.DB
+	bookRepository     *repository.BookRepository
+	authorRepository   *repository.AuthorRepository
+	bookFileRepository *repository.BookFileRepository
+}
+
+func NewOPDSUsecase(
+	db *gorm.DB,
+	bookRepository *repository.BookRepository,
+	authorRepository *repository.AuthorRepository,
+	bookFileRepository *repository.BookFileRepository,
+) *OPDSUsecase {
+	return &OPDSUsecase{
+		db,
+		bookRepository,
+		authorRepository,
+		bookFileRepository,
+	}
+}
+
+func (uc *OPDSUsecase) Root(ctx context.Context, request *model.OPDSFeedRequest) (*model.OPDSResponse, error) {
+	root := opdsRoot(request.Version)
+
+	feed := newOPDSFeed(request.Version, opds.KindNavigation, "root", opdsTitle, root)
+	feed.Navigation = []opds.Navigation{
+		{ID: "urn:bookshelf:opds:newest", Title: "Newest", Href: root + "/newest", Kind: opds.KindAcquisition},
+		{ID: "urn:bookshelf:opds:authors", Title: "By Author", Href: root + "/authors", Kind: opds.KindNavigation},
+		{ID: "urn:bookshelf:opds:series", Title: "By Series", Href: root + "/series", Kind: opds.KindNavigation},
+	}
+
+	return encodeOPDSFeed(request.Version, feed)
+}
+
+func (uc *OPDSUsecase) Newest(ctx context.Context, request *model.OPDSFeedRequest) (*model.OPDSResponse, error) {
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
+	defer tx.Rollback()
+
+	page := max(request.Page, 1)
+	books, total, err := uc.bookRepository.Search(ctx, tx, nil, nil, nil, nil, util.ToPointer("-id"), page, opdsPageSize)
+	if err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to get books"))
+	}
+
+	feed := newOPDSFeed(request.Version, opds.KindAcquisition, "newest", "Newest", opdsRoot(request.Version)+"/newest")
+	feed.Up = feed.Start
+	paginateOPDSFeed(feed, page, total)
+
+	if feed.Publications, err = uc.publications(tx, request.Version, books); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return encodeOPDSFeed(request.Version, feed)
+}
+
+func (uc *OPDSUsecase) Authors(ctx context.Context, request *model.OPDSFeedRequest) (*model.OPDSResponse, error) {
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
+	defer tx.Rollback()
+
+	page := max(request.Page, 1)
+	authors, total, err := uc.authorRepository.Search(ctx, tx, nil, nil, nil, page, opdsPageSize)
+	if err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to get authors"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	root := opdsRoot(request.Version)
+
+	feed := newOPDSFeed(request.Version, opds.KindNavigation, "authors", "By Author", root+"/authors")
+	feed.Up = feed.Start
+	paginateOPDSFeed(feed, page, total)
+
+	for _, author := range authors {
+		feed.Navigation = append(feed.Navigation, opds.Navigation{
+			ID:    fmt.Sprintf("urn:bookshelf:opds:author:%d", author.ID),
+			Title: author.Name,
+			Href:  fmt.Sprintf("%s/authors/%d", root, author.ID),
+			Kind:  opds.KindAcquisition,
+		})
+	}
+
+	return encodeOPDSFeed(request.Version, feed)
+}
+
+func (uc *OPDSUsecase) Author(ctx context.Context, request *model.OPDSAuthorFeedRequest) (*model.OPDSResponse, error) {
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
+	defer tx.Rollback()
+
+	author, err := uc.authorRepository.FindByID(tx, request.AuthorID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrorNotFound(errors.New("author not found"))
+		}
+		return nil, model.ErrorInternalServerError(errors.New("failed to find author data by id"))
+	}
+
+	page := max(request.Page, 1)
+	books, total, err := uc.bookRepository.Search(ctx, tx, nil, nil, &author.ID, nil, util.ToPointer("title"), page, opdsPageSize)
+	if err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to get books"))
+	}
+
+	root := opdsRoot(request.Version)
+
+	feed := newOPDSFeed(
+		request.Version,
+		opds.KindAcquisition,
+		fmt.Sprintf("author:%d", author.ID),
+		author.Name,
+		fmt.Sprintf("%s/authors/%d", root, author.ID),
+	)
+	feed.Up = root + "/authors"
+	paginateOPDSFeed(feed, page, total)
+
+	if feed.Publications, err = uc.publications(tx, request.Version, books); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return encodeOPDSFeed(request.Version, feed)
+}
+
+func (uc *OPDSUsecase) Series(ctx context.Context, request *model.OPDSFeedRequest) (*model.OPDSResponse, error) {
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
+	defer tx.Rollback()
+
+	counts, err := uc.bookRepository.CountBySeries(tx)
+	if err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to get series"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	root := opdsRoot(request.Version)
+
+	feed := newOPDSFeed(request.Version, opds.KindNavigation, "series", "By Series", root+"/series")
+	feed.Up = feed.Start
+
+	for _, name := range names {
+		feed.Navigation = append(feed.Navigation, opds.Navigation{
+			ID:    "urn:bookshelf:opds:series:" + url.PathEscape(name),
+			Title: name,
+			Href:  root + "/series/books?name=" + url.QueryEscape(name),
+			Kind:  opds.KindAcquisition,
+			Count: counts[name],
+		})
+	}
+
+	return encodeOPDSFeed(request.Version, feed)
+}
+
+func (uc *OPDSUsecase) SeriesBooks(ctx context.Context, request *model.OPDSSeriesFeedRequest) (*model.OPDSResponse, error) {
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
+	defer tx.Rollback()
+
+	books, err := uc.bookRepository.FindAllBySeries(tx, request.Series)
+	if err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to get books"))
+	}
+	if len(books) == 0 {
+		return nil, model.ErrorNotFound(errors.New("series not found"))
+	}
+
+	root := opdsRoot(request.Version)
+
+	feed := newOPDSFeed(
+		request.Version,
+		opds.KindAcquisition,
+		"series:"+url.PathEscape(request.Series),
+		request.Series,
+		root+"/series/books?name="+url.QueryEscape(request.Series),
+	)
+	feed.Up = root + "/series"
+
+	if feed.Publications, err = uc.publications(tx, request.Version, books); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return encodeOPDSFeed(request.Version, feed)
+}
+
+// Search matches the query against titles, series, ISBNs and author names.
+func (uc *OPDSUsecase) Search(ctx context.Context, request *model.OPDSSearchFeedRequest) (*model.OPDSResponse, error) {
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
+	defer tx.Rollback()
+
+	page := max(request.Page, 1)
+	books, total, err := uc.bookRepository.SearchText(ctx, tx, request.Query, page, opdsPageSize)
+	if err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to search books"))
+	}
+
+	feed := newOPDSFeed(
+		request.Version,
+		opds.KindAcquisition,
+		"search",
+		fmt.Sprintf("Search results for %q", request.Query),
+		opdsRoot(request.Version)+"/search?q="+url.QueryEscape(request.Query),
+	)
+	feed.Up = feed.Start
+	paginateOPDSFeed(feed, page, total)
+
+	if feed.Publications, err = uc.publications(tx, request.Version, books); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return encodeOPDSFeed(request.Version, feed)
+}
+
+func (uc *OPDSUsecase) OpenSearch(ctx context.Context, request *model.OPDSOpenSearchRequest) (*model.OPDSResponse, error) {
+	buf := new(bytes.Buffer)
+	template := strings.TrimRight(request.BaseURL, "/") + opdsRoot(opds.Version1) + "/search?q={searchTerms}"
+	if err := opds.WriteOpenSearch(buf, opdsTitle, template); err != nil {
+		gotracing.Error("Failed to encode OpenSearch description", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to encode opensearch description"))
+	}
+
+	return &model.OPDSResponse{
+		ContentType: opds.OpenSearchContentType(),
+		Content:     buf.Bytes(),
+	}, nil
+}
+
+// publications describes the books with links to their covers and files.
+func (uc *OPDSUsecase) publications(tx *gorm.DB, version string, books []entity.Book) ([]opds.Publication, error) {
+	if len(books) == 0 {
+		return nil, nil
+	}
+
+	bookIDs := make([]int, len(books))
+	for i, book := range books {
+		bookIDs[i] = book.ID
+	}
+
+	files, err := uc.bookFileRepository.FindAllByBookIDs(tx, bookIDs)
+	if err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to get book files"))
+	}
+
+	filesByBook := map[int][]entity.BookFile{}
+	for _, file := range files {
+		filesByBook[file.BookID] = append(filesByBook[file.BookID], file)
+	}
+
+	publications := make([]opds.Publication, len(books))
+	for i, book := range books {
+		publication := opds.Publication{
+			ID:          fmt.Sprintf("urn:bookshelf:book:%d", book.ID),
+			Title:       book.Title,
+			Language:    book.Language,
+			Series:      book.Series,
+			SeriesIndex: book.SeriesIndex,
+			Authors: []opds.Author{{
+				Name: book.Author.Name,
+				Href: fmt.Sprintf("%s/authors/%d", opdsRoot(version), book.AuthorID),
+			}},
+		}
+
+		// Books created by imports carry a placeholder instead of an ISBN.
+		if util.IsISBN(book.ISBN) {
+			publication.ISBN = util.CompactISBN(book.ISBN)
+		}
+
+		if cover := model.ToCoverResponse(&book); cover != nil {
+			publication.Image = opdsRoot(opds.Version1) + cover.Original
+			publication.ImageType = book.CoverContentType
+			publication.Thumbnail = opdsRoot(opds.Version1) + cover.Medium
+			publication.ThumbnailType = "image/jpeg"
+			if book.CoverChecksum == "" {
+				publication.ThumbnailType = book.CoverContentType
+			}
+		}
+
+		for _, file := range filesByBook[book.ID] {
+			publication.Acquisitions = append(publication.Acquisitions, opds.Acquisition{
+				Href:   fmt.Sprintf("%s/books/%d/files/%d", opdsRoot(opds.Version1), book.ID, file.ID),
+				Type:   file.ContentType,
+				Title:  file.Filename,
+				Length: file.Size,
+			})
+			if file.CreatedAt.After(publication.Updated) {
+				publication.Updated = file.CreatedAt
+			}
+		}
+
+		publications[i] = publication
+	}
+
+	return publications, nil
+}
+
+func newOPDSFeed(version string, kind string, id string, title string, self string) *opds.Feed {
+	root := opdsRoot(version)
+	feed := &opds.Feed{
+		Kind:    kind,
+		ID:      "urn:bookshelf:opds:" + id,
+		Title:   title,
+		Updated: time.Now().UTC().Truncate(time.Second),
+		Self:    self,
+		Start:   root,
+	}
+
+	if version == opds.Version2 {
+		feed.Search = root + "/search{?q}"
+	} else {
+		feed.Search = root + "/opensearch.xml"
+	}
+
+	return feed
+}
+
+func paginateOPDSFeed(feed *opds.Feed, page int, total int64) {
+	feed.Page = page
+	feed.ItemsPerPage = opdsPageSize
+	feed.TotalResults = total
+
+	if page > 1 {
+		feed.Self = opdsPageLink(feed.Self, page)
+		feed.Previous = opdsPageLink(feed.Self, page-1)
+	}
+	if int64(page*opdsPageSize) < total {
+		feed.Next = opdsPageLink(feed.Self, page+1)
+	}
+}
+
+// encodeOPDSFeed writes the feed in the version requested and stamps the
+// publications that have no files with the feed time.
+func encodeOPDSFeed(version string, feed *opds.Feed) (*model.OPDSResponse, error) {
+	for i := range feed.Publications {
+		if feed.Publications[i].Updated.IsZero() {
+			feed.Publications[i].Updated = feed.Updated
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	if err := opds.Write(buf, version, feed); err != nil {
+		gotracing.Error("Failed to encode OPDS feed", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to encode feed"))
+	}
+
+	return &model.OPDSResponse{
+		ContentType: opds.ContentType(version, feed.Kind),
+		Content:     buf.Bytes(),
+	}, nil
+}
+
+func opdsRoot(version string) string {
+	if version == opds.Version2 {
+		return "/opds/v2"
+	}
+	return "/opds"
+}
+
+func opdsPageLink(href string, page int) string {
+	u, err := url.Parse(href)
+	if err != nil {
+		return href
+	}
+	query := u.Query()
+	if page > 1 {
+		query.Set("page", fmt.Sprint(page))
+	} else {
+		query.Del("page")
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
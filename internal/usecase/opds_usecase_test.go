@@ -0,0 +1,323 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+type atomTestFeed struct {
+	ID           string          `xml:"id"`
+	Title        string          `xml:"title"`
+	TotalResults int64           `xml:"totalResults"`
+	Links        []atomTestLink  `xml:"link"`
+	Entries      []atomTestEntry `xml:"entry"`
+}
+
+type atomTestEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Author     string         `xml:"author>name"`
+	Identifier string         `xml:"identifier"`
+	Language   string         `xml:"language"`
+	Content    string         `xml:"content"`
+	Links      []atomTestLink `xml:"link"`
+}
+
+type atomTestLink struct {
+	Rel    string `xml:"rel,attr"`
+	Href   string `xml:"href,attr"`
+	Type   string `xml:"type,attr"`
+	Length int64  `xml:"length,attr"`
+}
+
+func (f *atomTestFeed) link(rel string) string {
+	for _, link := range f.Links {
+		if link.Rel == rel {
+			return link.Href
+		}
+	}
+	return ""
+}
+
+type jsonTestFeed struct {
+	Metadata struct {
+		Title         string `json:"title"`
+		NumberOfItems int64  `json:"numberOfItems"`
+	} `json:"metadata"`
+	Links []struct {
+		Rel       string `json:"rel"`
+		Href      string `json:"href"`
+		Templated bool   `json:"templated"`
+	} `json:"links"`
+	Navigation []struct {
+		Href       string `json:"href"`
+		Title      string `json:"title"`
+		Properties struct {
+			NumberOfItems int64 `json:"numberOfItems"`
+		} `json:"properties"`
+	} `json:"navigation"`
+	Publications []struct {
+		Metadata struct {
+			Title     string `json:"title"`
+			BelongsTo struct {
+				Series []struct {
+					Name     string  `json:"name"`
+					Position float64 `json:"position"`
+				} `json:"series"`
+			} `json:"belongsTo"`
+		} `json:"metadata"`
+		Links []struct {
+			Rel  string `json:"rel"`
+			Href string `json:"href"`
+			Type string `json:"type"`
+		} `json:"links"`
+		Images []struct {
+			Href string `json:"href"`
+		} `json:"images"`
+	} `json:"publications"`
+}
+
+func newOPDSUsecase(t *testing.T) *usecase.OPDSUsecase {
+	db := newDatabase()
+	authorRepo := repository.NewAuthorRepository()
+	bookRepo := repository.NewBookRepository()
+	bookFileRepo := repository.NewBookFileRepository()
+
+	herbert := &entity.Author{Name: "Frank Herbert", Birthdate: time.Date(1920, 10, 8, 0, 0, 0, 0, time.UTC)}
+	orwell := &entity.Author{Name: "George Orwell", Birthdate: time.Date(1903, 6, 25, 0, 0, 0, 0, time.UTC)}
+	assert.NoError(t, authorRepo.Create(db, herbert))
+	assert.NoError(t, authorRepo.Create(db, orwell))
+
+	books := []*entity.Book{
+		{Title: "Dune", ISBN: "978-0441172719", AuthorID: herbert.ID, Language: "en", Series: "Dune", SeriesIndex: 1},
+		{Title: "Children of Dune", ISBN: "978-0593098240", AuthorID: herbert.ID, Series: "Dune", SeriesIndex: 3},
+		{Title: "Dune Messiah", ISBN: "978-0593098233", AuthorID: herbert.ID, Series: "Dune", SeriesIndex: 2},
+		{Title: "Nineteen Eighty-Four", ISBN: "kindle-0123456789abcdef", AuthorID: orwell.ID},
+	}
+	for _, book := range books {
+		assert.NoError(t, bookRepo.Create(db, book))
+	}
+
+	books[0].CoverKey = "books/1/covers/abc/original.png"
+	books[0].CoverContentType = "image/png"
+	books[0].CoverChecksum = "0123456789abcdef0123"
+	assert.NoError(t, db.Omit("Author").Save(books[0]).Error)
+
+	assert.NoError(t, bookFileRepo.Create(db, &entity.BookFile{
+		BookID:      books[0].ID,
+		Format:      "epub",
+		Filename:    "dune.epub",
+		ContentType: "application/epub+zip",
+		Size:        1234,
+		Checksum:    "abc",
+		StorageKey:  "books/1/files/abc.epub",
+	}))
+
+	return usecase.NewOPDSUsecase(db, bookRepo, authorRepo, bookFileRepo)
+}
+
+func decodeAtom(t *testing.T, res *model.OPDSResponse) *atomTestFeed {
+	feed := new(atomTestFeed)
+	assert.NoError(t, xml.Unmarshal(res.Content, feed))
+	return feed
+}
+
+func decodeJSONFeed(t *testing.T, res *model.OPDSResponse) *jsonTestFeed {
+	feed := new(jsonTestFeed)
+	assert.NoError(t, json.Unmarshal(res.Content, feed))
+	return feed
+}
+
+func TestOPDSUsecase_Root(t *testing.T) {
+	uc := newOPDSUsecase(t)
+
+	t.Run("Positive Case - opds 1.2 navigation", func(t *testing.T) {
+		res, err := uc.Root(context.Background(), &model.OPDSFeedRequest{Version: model.OPDSVersion1})
+		assert.NoError(t, err)
+		assert.EqualValues(t, "application/atom+xml;profile=opds-catalog;kind=navigation", res.ContentType)
+
+		feed := decodeAtom(t, res)
+		assert.EqualValues(t, "/opds", feed.link("self"))
+		assert.EqualValues(t, "/opds/opensearch.xml", feed.link("search"))
+		assert.EqualValues(t, 3, len(feed.Entries))
+		assert.EqualValues(t, "/opds/newest", feed.Entries[0].Links[0].Href)
+		assert.EqualValues(t, "application/atom+xml;profile=opds-catalog;kind=acquisition", feed.Entries[0].Links[0].Type)
+		assert.EqualValues(t, "/opds/authors", feed.Entries[1].Links[0].Href)
+		assert.EqualValues(t, "/opds/series", feed.Entries[2].Links[0].Href)
+	})
+
+	t.Run("Positive Case - opds 2.0 navigation", func(t *testing.T) {
+		res, err := uc.Root(context.Background(), &model.OPDSFeedRequest{Version: model.OPDSVersion2})
+		assert.NoError(t, err)
+		assert.EqualValues(t, "application/opds+json", res.ContentType)
+
+		feed := decodeJSONFeed(t, res)
+		assert.EqualValues(t, 3, len(feed.Navigation))
+		assert.EqualValues(t, "/opds/v2/newest", feed.Navigation[0].Href)
+		assert.Nil(t, feed.Publications)
+		assert.Contains(t, res.Content, byte('{'))
+		assert.True(t, strings.Contains(string(res.Content), `"href":"/opds/v2/search{?q}","type":"application/opds+json","templated":true`))
+	})
+}
+
+func TestOPDSUsecase_Newest(t *testing.T) {
+	uc := newOPDSUsecase(t)
+
+	res, err := uc.Newest(context.Background(), &model.OPDSFeedRequest{Version: model.OPDSVersion1})
+	assert.NoError(t, err)
+	assert.EqualValues(t, "application/atom+xml;profile=opds-catalog;kind=acquisition", res.ContentType)
+
+	feed := decodeAtom(t, res)
+	assert.EqualValues(t, 4, feed.TotalResults)
+	assert.EqualValues(t, "", feed.link("next"))
+	assert.EqualValues(t, 4, len(feed.Entries))
+
+	orwell := feed.Entries[0]
+	assert.EqualValues(t, "Nineteen Eighty-Four", orwell.Title)
+	assert.EqualValues(t, "", orwell.Identifier)
+
+	dune := feed.Entries[3]
+	assert.EqualValues(t, "urn:bookshelf:book:1", dune.ID)
+	assert.EqualValues(t, "Frank Herbert", dune.Author)
+	assert.EqualValues(t, "urn:isbn:9780441172719", dune.Identifier)
+	assert.EqualValues(t, "en", dune.Language)
+	assert.EqualValues(t, "Dune #1", dune.Content)
+	assert.EqualValues(t, []atomTestLink{
+		{Rel: "http://opds-spec.org/image", Href: "/opds/books/1/cover?v=0123456789abcdef", Type: "image/png"},
+		{Rel: "http://opds-spec.org/image/thumbnail", Href: "/opds/books/1/cover/medium?v=0123456789abcdef", Type: "image/jpeg"},
+		{Rel: "http://opds-spec.org/acquisition", Href: "/opds/books/1/files/1", Type: "application/epub+zip", Length: 1234},
+	}, dune.Links)
+}
+
+func TestOPDSUsecase_Author(t *testing.T) {
+	uc := newOPDSUsecase(t)
+
+	t.Run("Positive Case - books of author by title", func(t *testing.T) {
+		res, err := uc.Author(context.Background(), &model.OPDSAuthorFeedRequest{Version: model.OPDSVersion1, AuthorID: 1})
+		assert.NoError(t, err)
+
+		feed := decodeAtom(t, res)
+		assert.EqualValues(t, "Frank Herbert", feed.Title)
+		assert.EqualValues(t, "/opds/authors", feed.link("up"))
+		assert.EqualValues(t, 3, len(feed.Entries))
+		assert.EqualValues(t, "Children of Dune", feed.Entries[0].Title)
+	})
+
+	t.Run("Negative Case - author not found", func(t *testing.T) {
+		_, err := uc.Author(context.Background(), &model.OPDSAuthorFeedRequest{Version: model.OPDSVersion1, AuthorID: 100})
+		assert.EqualValues(t, model.ErrorNotFound(errors.New("author not found")), err)
+	})
+}
+
+func TestOPDSUsecase_Series(t *testing.T) {
+	uc := newOPDSUsecase(t)
+
+	t.Run("Positive Case - series navigation", func(t *testing.T) {
+		res, err := uc.Series(context.Background(), &model.OPDSFeedRequest{Version: model.OPDSVersion2})
+		assert.NoError(t, err)
+
+		feed := decodeJSONFeed(t, res)
+		assert.EqualValues(t, 1, len(feed.Navigation))
+		assert.EqualValues(t, "Dune", feed.Navigation[0].Title)
+		assert.EqualValues(t, "/opds/v2/series/books?name=Dune", feed.Navigation[0].Href)
+		assert.EqualValues(t, 3, feed.Navigation[0].Properties.NumberOfItems)
+	})
+
+	t.Run("Positive Case - books in series order", func(t *testing.T) {
+		res, err := uc.SeriesBooks(context.Background(), &model.OPDSSeriesFeedRequest{Version: model.OPDSVersion2, Series: "Dune"})
+		assert.NoError(t, err)
+
+		feed := decodeJSONFeed(t, res)
+		assert.EqualValues(t, 3, len(feed.Publications))
+		for i, title := range []string{"Dune", "Dune Messiah", "Children of Dune"} {
+			assert.EqualValues(t, title, feed.Publications[i].Metadata.Title)
+			assert.EqualValues(t, i+1, feed.Publications[i].Metadata.BelongsTo.Series[0].Position)
+		}
+		assert.EqualValues(t, "http://opds-spec.org/acquisition", feed.Publications[0].Links[0].Rel)
+		assert.EqualValues(t, "/opds/books/1/files/1", feed.Publications[0].Links[0].Href)
+		assert.EqualValues(t, 2, len(feed.Publications[0].Images))
+		assert.EqualValues(t, 0, len(feed.Publications[1].Links))
+	})
+
+	t.Run("Negative Case - series not found", func(t *testing.T) {
+		_, err := uc.SeriesBooks(context.Background(), &model.OPDSSeriesFeedRequest{Version: model.OPDSVersion2, Series: "Foundation"})
+		assert.EqualValues(t, model.ErrorNotFound(errors.New("series not found")), err)
+	})
+}
+
+func TestOPDSUsecase_Search(t *testing.T) {
+	uc := newOPDSUsecase(t)
+
+	t.Run("Positive Case - match author name", func(t *testing.T) {
+		res, err := uc.Search(context.Background(), &model.OPDSSearchFeedRequest{Version: model.OPDSVersion1, Query: "orwell"})
+		assert.NoError(t, err)
+
+		feed := decodeAtom(t, res)
+		assert.EqualValues(t, 1, len(feed.Entries))
+		assert.EqualValues(t, "Nineteen Eighty-Four", feed.Entries[0].Title)
+		assert.EqualValues(t, "/opds/search?q=orwell", feed.link("self"))
+	})
+
+	t.Run("Positive Case - empty opds 2.0 result keeps publications", func(t *testing.T) {
+		res, err := uc.Search(context.Background(), &model.OPDSSearchFeedRequest{Version: model.OPDSVersion2, Query: "tolkien"})
+		assert.NoError(t, err)
+		assert.True(t, strings.Contains(string(res.Content), `"publications":[]`))
+	})
+
+	t.Run("Positive Case - open search description", func(t *testing.T) {
+		res, err := uc.OpenSearch(context.Background(), &model.OPDSOpenSearchRequest{BaseURL: "https://books.example.com"})
+		assert.NoError(t, err)
+		assert.EqualValues(t, "application/opensearchdescription+xml", res.ContentType)
+		assert.True(t, strings.Contains(string(res.Content), `template="https://books.example.com/opds/search?q={searchTerms}"`))
+	})
+}
+
+func TestOPDSUsecase_Pagination(t *testing.T) {
+	db := newDatabase()
+	authorRepo := repository.NewAuthorRepository()
+	bookRepo := repository.NewBookRepository()
+	uc := usecase.NewOPDSUsecase(db, bookRepo, authorRepo, repository.NewBookFileRepository())
+
+	author := &entity.Author{Name: "Author Name 1"}
+	assert.NoError(t, authorRepo.Create(db, author))
+	for i := 1; i <= 30; i++ {
+		assert.NoError(t, bookRepo.Create(db, &entity.Book{
+			Title:    fmt.Sprintf("Book Title %d", i),
+			ISBN:     fmt.Sprintf("isbn-%d", i),
+			AuthorID: author.ID,
+		}))
+	}
+
+	t.Run("Positive Case - first page", func(t *testing.T) {
+		res, err := uc.Newest(context.Background(), &model.OPDSFeedRequest{Version: model.OPDSVersion1})
+		assert.NoError(t, err)
+
+		feed := decodeAtom(t, res)
+		assert.EqualValues(t, 25, len(feed.Entries))
+		assert.EqualValues(t, "/opds/newest?page=2", feed.link("next"))
+		assert.EqualValues(t, "", feed.link("previous"))
+	})
+
+	t.Run("Positive Case - last page", func(t *testing.T) {
+		res, err := uc.Newest(context.Background(), &model.OPDSFeedRequest{Version: model.OPDSVersion1, Page: 2})
+		assert.NoError(t, err)
+
+		feed := decodeAtom(t, res)
+		assert.EqualValues(t, 5, len(feed.Entries))
+		assert.EqualValues(t, "/opds/newest?page=2", feed.link("self"))
+		assert.EqualValues(t, "/opds/newest", feed.link("previous"))
+		assert.EqualValues(t, "", feed.link("next"))
+	})
+}
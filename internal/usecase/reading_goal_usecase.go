@@ -0,0 +1,213 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/gotracing"
+	"gorm.io/gorm"
+)
+
+type ReadingGoalUsecase struct {
+	db                        *gorm.DB
+	repository                *repository.ReadingGoalRepository
+	readingProgressRepository *repository.ReadingProgressRepository
+}
+
+func NewReadingGoalUsecase(
+	db *gorm.DB,
+	repository *repository.ReadingGoalRepository,
+	readingProgressRepository *repository.ReadingProgressRepository,
+) *ReadingGoalUsecase {
+	return &ReadingGoalUsecase{
+		db,
+		repository,
+		readingProgressRepository,
+	}
+}
+
+func (uc *ReadingGoalUsecase) GetMany(ctx context.Context, request *model.GetManyReadingGoalsRequest) ([]model.ReadingGoalResponse, error) {
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
+	defer tx.Rollback()
+
+	goals, err := uc.repository.FindAllByUserID(tx, request.UserID)
+	if err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to find reading goals"))
+	}
+
+	progresses, err := uc.readingProgressRepository.FindAllByUserIDs(tx, []int{request.UserID})
+	if err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to find reading progresses"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	now := time.Now().UTC()
+	response := make([]model.ReadingGoalResponse, len(goals))
+	for i, goal := range goals {
+		response[i] = *calculateReadingGoal(&goal, progresses, now)
+	}
+
+	return response, nil
+}
+
+func (uc *ReadingGoalUsecase) Get(ctx context.Context, request *model.GetReadingGoalRequest) (*model.ReadingGoalResponse, error) {
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
+	defer tx.Rollback()
+
+	goal, err := uc.repository.FindByUserIDAndYear(tx, request.UserID, request.Year)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrorNotFound(errors.New("reading goal not found"))
+		}
+		return nil, model.ErrorInternalServerError(errors.New("failed to find reading goal data"))
+	}
+
+	progresses, err := uc.readingProgressRepository.FindAllByUserIDs(tx, []int{request.UserID})
+	if err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to find reading progresses"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return calculateReadingGoal(goal, progresses, time.Now().UTC()), nil
+}
+
+func (uc *ReadingGoalUsecase) Upsert(ctx context.Context, request *model.UpsertReadingGoalRequest) (*model.ReadingGoalResponse, error) {
+	tx := begin(ctx, uc.db)
+	defer tx.Rollback()
+
+	goal, err := uc.repository.FindByUserIDAndYear(tx, request.UserID, request.Year)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, model.ErrorInternalServerError(errors.New("failed to find reading goal data"))
+	}
+
+	if goal == nil {
+		goal = &entity.ReadingGoal{
+			UserID: request.UserID,
+			Year:   request.Year,
+			Type:   request.Type,
+			Target: request.Target,
+		}
+		if err := uc.repository.Create(tx, goal); err != nil {
+			return nil, model.ErrorInternalServerError(errors.New("failed to create new reading goal"))
+		}
+	} else {
+		goal.Type = request.Type
+		goal.Target = request.Target
+		if err := uc.repository.Update(tx, goal); err != nil {
+			return nil, model.ErrorInternalServerError(errors.New("failed to update reading goal"))
+		}
+	}
+
+	progresses, err := uc.readingProgressRepository.FindAllByUserIDs(tx, []int{request.UserID})
+	if err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to find reading progresses"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return calculateReadingGoal(goal, progresses, time.Now().UTC()), nil
+}
+
+func (uc *ReadingGoalUsecase) Delete(ctx context.Context, request *model.DeleteReadingGoalRequest) (*int, error) {
+	tx := begin(ctx, uc.db)
+	defer tx.Rollback()
+
+	goal, err := uc.repository.FindByUserIDAndYear(tx, request.UserID, request.Year)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrorNotFound(errors.New("reading goal not found"))
+		}
+		return nil, model.ErrorInternalServerError(errors.New("failed to find reading goal data"))
+	}
+
+	if err := uc.repository.Delete(tx, goal); err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to delete reading goal"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return &goal.Year, nil
+}
+
+func calculateReadingGoal(goal *entity.ReadingGoal, progresses []entity.ReadingProgress, now time.Time) *model.ReadingGoalResponse {
+	yearStart := time.Date(goal.Year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	yearEnd := yearStart.AddDate(1, 0, 0)
+
+	progress := 0
+	walkReadingHistory(progresses, func(event readingEvent) {
+		readAt := event.progress.ReadAt.UTC()
+		if readAt.Before(yearStart) || !readAt.Before(yearEnd) {
+			return
+		}
+
+		switch goal.Type {
+		case entity.ReadingGoalTypeBooks:
+			if event.finished {
+				progress++
+			}
+		case entity.ReadingGoalTypePages:
+			progress += event.pagesRead
+		}
+	})
+
+	elapsed := 0.0
+	switch {
+	case !now.Before(yearEnd):
+		elapsed = 1
+	case now.After(yearStart):
+		elapsed = now.Sub(yearStart).Seconds() / yearEnd.Sub(yearStart).Seconds()
+	}
+
+	expected := int(math.Floor(float64(goal.Target) * elapsed))
+	difference := progress - expected
+
+	response := &model.ReadingGoalResponse{
+		Year:       goal.Year,
+		Type:       goal.Type,
+		Target:     goal.Target,
+		Progress:   progress,
+		Expected:   expected,
+		Difference: difference,
+		Completed:  progress >= goal.Target,
+	}
+
+	unit := strings.TrimSuffix(goal.Type, "s")
+	if abs := max(difference, -difference); abs != 1 {
+		unit = goal.Type
+	}
+
+	switch {
+	case response.Completed:
+		response.Pace = "goal completed"
+	case difference > 0:
+		response.Pace = fmt.Sprintf("%d %s ahead of schedule", difference, unit)
+	case difference < 0:
+		response.Pace = fmt.Sprintf("%d %s behind schedule", -difference, unit)
+	default:
+		response.Pace = "on track"
+	}
+
+	return response
+}
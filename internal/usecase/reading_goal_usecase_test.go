@@ -0,0 +1,127 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadingGoalUsecase_Upsert(t *testing.T) {
+	f := newReadingProgressFixture(t)
+
+	t.Run("Positive Case 1 - past year goal completed", func(t *testing.T) {
+		f.progress(t, f.book2.ID, nil, util.ToPointer(100.0), time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC))
+
+		resp, err := f.goalUc.Upsert(context.Background(), &model.UpsertReadingGoalRequest{
+			UserID: f.user.ID,
+			Year:   2020,
+			Type:   "books",
+			Target: 1,
+		})
+		assert.NoError(t, err)
+		assert.EqualValues(t, &model.ReadingGoalResponse{
+			Year:       2020,
+			Type:       "books",
+			Target:     1,
+			Progress:   1,
+			Expected:   1,
+			Difference: 0,
+			Pace:       "goal completed",
+			Completed:  true,
+		}, resp)
+	})
+
+	t.Run("Positive Case 2 - past year goal behind schedule", func(t *testing.T) {
+		resp, err := f.goalUc.Upsert(context.Background(), &model.UpsertReadingGoalRequest{
+			UserID: f.user.ID,
+			Year:   2020,
+			Type:   "books",
+			Target: 4,
+		})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, resp.Progress)
+		assert.EqualValues(t, -3, resp.Difference)
+		assert.EqualValues(t, "3 books behind schedule", resp.Pace)
+	})
+
+	t.Run("Positive Case 3 - pages goal", func(t *testing.T) {
+		f.progress(t, f.book1.ID, util.ToPointer(120), nil, time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC))
+
+		resp, err := f.goalUc.Upsert(context.Background(), &model.UpsertReadingGoalRequest{
+			UserID: f.user.ID,
+			Year:   2021,
+			Type:   "pages",
+			Target: 119,
+		})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 120, resp.Progress)
+		assert.EqualValues(t, "goal completed", resp.Pace)
+	})
+
+	t.Run("Positive Case 4 - future year goal", func(t *testing.T) {
+		resp, err := f.goalUc.Upsert(context.Background(), &model.UpsertReadingGoalRequest{
+			UserID: f.user.ID,
+			Year:   time.Now().Year() + 1,
+			Type:   "books",
+			Target: 12,
+		})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 0, resp.Expected)
+		assert.EqualValues(t, "on track", resp.Pace)
+	})
+}
+
+func TestReadingGoalUsecase_GetMany(t *testing.T) {
+	f := newReadingProgressFixture(t)
+
+	for _, year := range []int{2020, 2021} {
+		_, err := f.goalUc.Upsert(context.Background(), &model.UpsertReadingGoalRequest{
+			UserID: f.user.ID,
+			Year:   year,
+			Type:   "books",
+			Target: 10,
+		})
+		assert.NoError(t, err)
+	}
+
+	resp, err := f.goalUc.GetMany(context.Background(), &model.GetManyReadingGoalsRequest{UserID: f.user.ID})
+	assert.NoError(t, err)
+	assert.Len(t, resp, 2)
+	assert.EqualValues(t, 2021, resp[0].Year)
+	assert.EqualValues(t, 2020, resp[1].Year)
+}
+
+func TestReadingGoalUsecase_Delete(t *testing.T) {
+	f := newReadingProgressFixture(t)
+
+	_, err := f.goalUc.Upsert(context.Background(), &model.UpsertReadingGoalRequest{
+		UserID: f.user.ID,
+		Year:   2020,
+		Type:   "pages",
+		Target: 1000,
+	})
+	assert.NoError(t, err)
+
+	t.Run("Positive Case - delete goal", func(t *testing.T) {
+		resp, err := f.goalUc.Delete(context.Background(), &model.DeleteReadingGoalRequest{
+			UserID: f.user.ID,
+			Year:   2020,
+		})
+		assert.NoError(t, err)
+		assert.EqualValues(t, util.ToPointer(2020), resp)
+	})
+
+	t.Run("Negative Case - goal not found", func(t *testing.T) {
+		resp, err := f.goalUc.Get(context.Background(), &model.GetReadingGoalRequest{
+			UserID: f.user.ID,
+			Year:   2020,
+		})
+		assert.EqualValues(t, model.ErrorNotFound(errors.New("reading goal not found")), err)
+		assert.Nil(t, resp)
+	})
+}
@@ -0,0 +1,52 @@
+package usecase
+
+import "github.com/mnaufalhilmym/bookshelf/internal/entity"
+
+type readingEvent struct {
+	progress  *entity.ReadingProgress
+	pagesRead int
+	finished  bool
+}
+
+// walkReadingHistory expects progresses ordered by read time. A book counts as
+// finished each time its progress crosses 100%, so re-reads are counted again,
+// and moving backwards in a book is treated as a reset, not as pages read.
+func walkReadingHistory(progresses []entity.ReadingProgress, fn func(event readingEvent)) {
+	type bookKey struct {
+		userID int
+		bookID int
+	}
+	type bookState struct {
+		page     int
+		finished bool
+	}
+
+	books := map[bookKey]*bookState{}
+
+	for i := range progresses {
+		progress := &progresses[i]
+
+		key := bookKey{progress.UserID, progress.BookID}
+		state, ok := books[key]
+		if !ok {
+			state = &bookState{}
+			books[key] = state
+		}
+
+		event := readingEvent{progress: progress}
+
+		if delta := progress.Page - state.page; delta > 0 {
+			event.pagesRead = delta
+		}
+		state.page = progress.Page
+
+		if progress.Percentage >= 100 {
+			event.finished = !state.finished
+			state.finished = true
+		} else {
+			state.finished = false
+		}
+
+		fn(event)
+	}
+}
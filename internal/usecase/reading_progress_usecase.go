@@ -0,0 +1,237 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/gotracing"
+	"gorm.io/gorm"
+)
+
+const favoriteAuthorsLimit = 5
+
+type ReadingProgressUsecase struct {
+	db             *gorm.DB
+	repository     *repository.ReadingProgressRepository
+	bookRepository *repository.BookRepository
+}
+
+func NewReadingProgressUsecase(
+	db *gorm.DB,
+	repository *repository.ReadingProgressRepository,
+	bookRepository *repository.BookRepository,
+) *ReadingProgressUsecase {
+	return &ReadingProgressUsecase{
+		db,
+		repository,
+		bookRepository,
+	}
+}
+
+func (uc *ReadingProgressUsecase) GetMany(ctx context.Context, request *model.GetManyReadingProgressesRequest) ([]model.ReadingProgressResponse, int64, error) {
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
+	defer tx.Rollback()
+
+	progresses, total, err := uc.repository.Search(ctx, tx, request.UserID, request.BookID, request.Page, request.Size)
+	if err != nil {
+		return nil, 0, model.ErrorInternalServerError(errors.New("failed to get many reading progresses"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, 0, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return model.ToReadingProgressesResponse(progresses), total, nil
+}
+
+func (uc *ReadingProgressUsecase) Create(ctx context.Context, request *model.CreateReadingProgressRequest) (*model.ReadingProgressResponse, error) {
+	tx := begin(ctx, uc.db)
+	defer tx.Rollback()
+
+	book, err := uc.bookRepository.FindByID(tx, request.BookID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrorNotFound(errors.New("book not found"))
+		}
+		return nil, model.ErrorInternalServerError(errors.New("failed to find book data by id"))
+	}
+
+	progress := &entity.ReadingProgress{
+		UserID: request.UserID,
+		BookID: book.ID,
+		ReadAt: time.Now().UTC(),
+	}
+
+	if request.ReadAt != nil {
+		progress.ReadAt = request.ReadAt.UTC()
+	}
+
+	switch {
+	case request.Page != nil:
+		if book.PageCount > 0 && *request.Page > book.PageCount {
+			return nil, model.ErrorBadRequest(errors.New("page exceeds book page count"))
+		}
+		progress.Page = *request.Page
+		if request.Percentage != nil {
+			progress.Percentage = *request.Percentage
+		} else if book.PageCount > 0 {
+			progress.Percentage = float64(*request.Page) * 100 / float64(book.PageCount)
+		}
+	case request.Percentage != nil:
+		progress.Percentage = *request.Percentage
+		progress.Page = int(math.Round(*request.Percentage * float64(book.PageCount) / 100))
+	default:
+		return nil, model.ErrorBadRequest(errors.New("page or percentage is required"))
+	}
+
+	if err := uc.repository.Create(tx, progress); err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to create new reading progress"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return model.ToReadingProgressResponse(progress), nil
+}
+
+func (uc *ReadingProgressUsecase) GetStats(ctx context.Context, request *model.GetReadingStatsRequest) (*model.ReadingStatsResponse, error) {
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
+	defer tx.Rollback()
+
+	progresses, err := uc.repository.FindAllByUserIDs(tx, []int{request.UserID})
+	if err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to find reading progresses"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return calculateReadingStats(progresses, time.Now().UTC()), nil
+}
+
+func calculateReadingStats(progresses []entity.ReadingProgress, now time.Time) *model.ReadingStatsResponse {
+	stats := &model.ReadingStatsResponse{
+		BooksFinishedByMonth: []model.PeriodCountResponse{},
+		BooksFinishedByYear:  []model.PeriodCountResponse{},
+		FavoriteAuthors:      []model.FavoriteAuthorResponse{},
+	}
+
+	authors := map[int]*model.FavoriteAuthorResponse{}
+	byMonth := map[string]int64{}
+	byYear := map[string]int64{}
+	activeDays := map[time.Time]struct{}{}
+
+	walkReadingHistory(progresses, func(event readingEvent) {
+		progress := event.progress
+
+		author, ok := authors[progress.Book.AuthorID]
+		if !ok {
+			author = &model.FavoriteAuthorResponse{
+				AuthorID:   progress.Book.AuthorID,
+				AuthorName: progress.Book.Author.Name,
+			}
+			authors[progress.Book.AuthorID] = author
+		}
+
+		stats.PagesRead += int64(event.pagesRead)
+		author.PagesRead += int64(event.pagesRead)
+
+		readAt := progress.ReadAt.UTC()
+		if event.finished {
+			stats.BooksFinished++
+			author.BooksFinished++
+			byMonth[readAt.Format("2006-01")]++
+			byYear[readAt.Format("2006")]++
+		}
+
+		activeDays[truncateToDay(readAt)] = struct{}{}
+	})
+
+	stats.BooksFinishedByMonth = toPeriodCounts(byMonth)
+	stats.BooksFinishedByYear = toPeriodCounts(byYear)
+
+	if len(activeDays) > 0 {
+		stats.AveragePagesPerDay = float64(stats.PagesRead) / float64(len(activeDays))
+	}
+
+	for _, author := range authors {
+		if author.BooksFinished > 0 || author.PagesRead > 0 {
+			stats.FavoriteAuthors = append(stats.FavoriteAuthors, *author)
+		}
+	}
+	sort.Slice(stats.FavoriteAuthors, func(i, j int) bool {
+		a, b := stats.FavoriteAuthors[i], stats.FavoriteAuthors[j]
+		if a.BooksFinished != b.BooksFinished {
+			return a.BooksFinished > b.BooksFinished
+		}
+		if a.PagesRead != b.PagesRead {
+			return a.PagesRead > b.PagesRead
+		}
+		return a.AuthorID < b.AuthorID
+	})
+	if len(stats.FavoriteAuthors) > favoriteAuthorsLimit {
+		stats.FavoriteAuthors = stats.FavoriteAuthors[:favoriteAuthorsLimit]
+	}
+
+	stats.CurrentStreakDays, stats.LongestStreakDays = calculateStreaks(activeDays, truncateToDay(now))
+
+	return stats
+}
+
+func calculateStreaks(activeDays map[time.Time]struct{}, today time.Time) (current int, longest int) {
+	days := make([]time.Time, 0, len(activeDays))
+	for day := range activeDays {
+		days = append(days, day)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+
+	run := 0
+	for i, day := range days {
+		if i > 0 && days[i-1].AddDate(0, 0, 1).Equal(day) {
+			run++
+		} else {
+			run = 1
+		}
+		longest = max(longest, run)
+	}
+
+	// The current streak is still alive if the user has read today or yesterday.
+	day := today
+	if _, ok := activeDays[day]; !ok {
+		day = day.AddDate(0, 0, -1)
+	}
+	for {
+		if _, ok := activeDays[day]; !ok {
+			break
+		}
+		current++
+		day = day.AddDate(0, 0, -1)
+	}
+
+	return current, longest
+}
+
+func truncateToDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func toPeriodCounts(counts map[string]int64) []model.PeriodCountResponse {
+	response := make([]model.PeriodCountResponse, 0, len(counts))
+	for period, count := range counts {
+		response = append(response, model.PeriodCountResponse{Period: period, Count: count})
+	}
+	sort.Slice(response, func(i, j int) bool { return response[i].Period < response[j].Period })
+	return response
+}
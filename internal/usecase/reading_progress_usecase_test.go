@@ -0,0 +1,218 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+	"github.com/mnaufalhilmym/bookshelf/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+type readingProgressFixture struct {
+	userUc      *usecase.UserUsecase
+	progressUc  *usecase.ReadingProgressUsecase
+	goalUc      *usecase.ReadingGoalUsecase
+	challengeUc *usecase.ChallengeUsecase
+	authorUc    *usecase.AuthorUsecase
+	bookUc      *usecase.BookUsecase
+	user        *model.UserResponse
+	author1     *model.AuthorResponse
+	author2     *model.AuthorResponse
+	book1       *model.BookResponse
+	book2       *model.BookResponse
+	book3       *model.BookResponse
+}
+
+func newReadingProgressFixture(t *testing.T) *readingProgressFixture {
+	db := newDatabase()
+	userRepo := repository.NewUserRepository()
+	authorRepo := repository.NewAuthorRepository()
+	bookRepo := repository.NewBookRepository()
+	progressRepo := repository.NewReadingProgressRepository()
+	goalRepo := repository.NewReadingGoalRepository()
+	challengeRepo := repository.NewChallengeRepository()
+	participantRepo := repository.NewChallengeParticipantRepository()
+
+	authorUc := usecase.NewAuthorUsecase(db, authorRepo, bookRepo, repository.NewRevisionRepository())
+	bookUc := usecase.NewBookUsecase(db, bookRepo, authorRepo, repository.NewRevisionRepository())
+
+	f := &readingProgressFixture{
+		userUc:      usecase.NewUserUsecase(db, userRepo, "jwtKey", 10*time.Second),
+		progressUc:  usecase.NewReadingProgressUsecase(db, progressRepo, bookRepo),
+		goalUc:      usecase.NewReadingGoalUsecase(db, goalRepo, progressRepo),
+		challengeUc: usecase.NewChallengeUsecase(db, challengeRepo, participantRepo, progressRepo),
+		authorUc:    authorUc,
+		bookUc:      bookUc,
+	}
+
+	var err error
+	f.user, err = f.userUc.Register(context.Background(), &model.RegisterUserRequest{
+		Username: "reader",
+		Password: "password",
+	})
+	assert.NoError(t, err)
+
+	f.author1, err = authorUc.Create(context.Background(), &model.CreateAuthorRequest{
+		Name:      "Author Name 1",
+		Birthdate: time.Date(1811, 1, 11, 0, 0, 0, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+	f.author2, err = authorUc.Create(context.Background(), &model.CreateAuthorRequest{
+		Name:      "Author Name 2",
+		Birthdate: time.Date(1922, 2, 22, 0, 0, 0, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+
+	f.book1, err = bookUc.Create(context.Background(), &model.CreateBookRequest{
+		Title:     "Book Title 1",
+		ISBN:      "978-0451524935",
+		AuthorID:  f.author1.ID,
+		PageCount: 200,
+	})
+	assert.NoError(t, err)
+	f.book2, err = bookUc.Create(context.Background(), &model.CreateBookRequest{
+		Title:     "Book Title 2",
+		ISBN:      "978-0743273565",
+		AuthorID:  f.author1.ID,
+		PageCount: 100,
+	})
+	assert.NoError(t, err)
+	f.book3, err = bookUc.Create(context.Background(), &model.CreateBookRequest{
+		Title:     "Book Title 3",
+		ISBN:      "979-0061120084",
+		AuthorID:  f.author2.ID,
+		PageCount: 300,
+	})
+	assert.NoError(t, err)
+
+	return f
+}
+
+func (f *readingProgressFixture) progress(t *testing.T, bookID int, page *int, percentage *float64, readAt time.Time) {
+	f.progressAs(t, f.user.ID, bookID, page, percentage, readAt)
+}
+
+func (f *readingProgressFixture) progressAs(t *testing.T, userID int, bookID int, page *int, percentage *float64, readAt time.Time) {
+	_, err := f.progressUc.Create(context.Background(), &model.CreateReadingProgressRequest{
+		UserID:     userID,
+		BookID:     bookID,
+		Page:       page,
+		Percentage: percentage,
+		ReadAt:     &readAt,
+	})
+	assert.NoError(t, err)
+}
+
+func TestReadingProgressUsecase_Create(t *testing.T) {
+	f := newReadingProgressFixture(t)
+
+	t.Run("Positive Case 1 - progress by page", func(t *testing.T) {
+		resp, err := f.progressUc.Create(context.Background(), &model.CreateReadingProgressRequest{
+			UserID: f.user.ID,
+			BookID: f.book1.ID,
+			Page:   util.ToPointer(50),
+		})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 50, resp.Page)
+		assert.EqualValues(t, 25, resp.Percentage)
+	})
+
+	t.Run("Positive Case 2 - progress by percentage", func(t *testing.T) {
+		resp, err := f.progressUc.Create(context.Background(), &model.CreateReadingProgressRequest{
+			UserID:     f.user.ID,
+			BookID:     f.book2.ID,
+			Percentage: util.ToPointer(40.0),
+		})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 40, resp.Page)
+		assert.EqualValues(t, 40, resp.Percentage)
+	})
+
+	t.Run("Negative Case 1 - page exceeds page count", func(t *testing.T) {
+		resp, err := f.progressUc.Create(context.Background(), &model.CreateReadingProgressRequest{
+			UserID: f.user.ID,
+			BookID: f.book2.ID,
+			Page:   util.ToPointer(101),
+		})
+		assert.EqualValues(t, model.ErrorBadRequest(errors.New("page exceeds book page count")), err)
+		assert.Nil(t, resp)
+	})
+
+	t.Run("Negative Case 2 - book not found", func(t *testing.T) {
+		resp, err := f.progressUc.Create(context.Background(), &model.CreateReadingProgressRequest{
+			UserID: f.user.ID,
+			BookID: 0,
+			Page:   util.ToPointer(1),
+		})
+		assert.EqualValues(t, model.ErrorNotFound(errors.New("book not found")), err)
+		assert.Nil(t, resp)
+	})
+}
+
+func TestReadingProgressUsecase_GetMany(t *testing.T) {
+	f := newReadingProgressFixture(t)
+
+	now := time.Now().UTC()
+	f.progress(t, f.book1.ID, util.ToPointer(10), nil, now.Add(-2*time.Hour))
+	f.progress(t, f.book1.ID, util.ToPointer(20), nil, now.Add(-time.Hour))
+	f.progress(t, f.book2.ID, util.ToPointer(30), nil, now)
+
+	request := &model.GetManyReadingProgressesRequest{UserID: f.user.ID, BookID: f.book1.ID}
+	request.Page = 1
+	request.Size = 10
+
+	resp, total, err := f.progressUc.GetMany(context.Background(), request)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, total)
+	assert.EqualValues(t, 20, resp[0].Page)
+	assert.EqualValues(t, 10, resp[1].Page)
+}
+
+func TestReadingProgressUsecase_GetStats(t *testing.T) {
+	t.Run("Positive Case 1 - no reading history", func(t *testing.T) {
+		f := newReadingProgressFixture(t)
+
+		resp, err := f.progressUc.GetStats(context.Background(), &model.GetReadingStatsRequest{UserID: f.user.ID})
+		assert.NoError(t, err)
+		assert.EqualValues(t, &model.ReadingStatsResponse{
+			BooksFinishedByMonth: []model.PeriodCountResponse{},
+			BooksFinishedByYear:  []model.PeriodCountResponse{},
+			FavoriteAuthors:      []model.FavoriteAuthorResponse{},
+		}, resp)
+	})
+
+	t.Run("Positive Case 2 - reading history", func(t *testing.T) {
+		f := newReadingProgressFixture(t)
+
+		today := time.Now().UTC().Truncate(24 * time.Hour).Add(12 * time.Hour)
+		f.progress(t, f.book3.ID, util.ToPointer(150), nil, today.AddDate(0, 0, -10))
+		f.progress(t, f.book1.ID, util.ToPointer(100), nil, today.AddDate(0, 0, -2))
+		f.progress(t, f.book1.ID, util.ToPointer(200), nil, today.AddDate(0, 0, -1))
+		f.progress(t, f.book2.ID, nil, util.ToPointer(100.0), today)
+
+		resp, err := f.progressUc.GetStats(context.Background(), &model.GetReadingStatsRequest{UserID: f.user.ID})
+		assert.NoError(t, err)
+
+		assert.EqualValues(t, 2, resp.BooksFinished)
+		assert.EqualValues(t, 450, resp.PagesRead)
+		assert.EqualValues(t, 112.5, resp.AveragePagesPerDay)
+		assert.EqualValues(t, 3, resp.CurrentStreakDays)
+		assert.EqualValues(t, 3, resp.LongestStreakDays)
+
+		var finishedByYear int64
+		for _, period := range resp.BooksFinishedByYear {
+			finishedByYear += period.Count
+		}
+		assert.EqualValues(t, 2, finishedByYear)
+
+		assert.EqualValues(t, []model.FavoriteAuthorResponse{
+			{AuthorID: f.author1.ID, AuthorName: f.author1.Name, BooksFinished: 2, PagesRead: 300},
+			{AuthorID: f.author2.ID, AuthorName: f.author2.Name, BooksFinished: 0, PagesRead: 150},
+		}, resp.FavoriteAuthors)
+	})
+}
@@ -0,0 +1,209 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/gotracing"
+	"gorm.io/gorm"
+)
+
+type ReviewUsecase struct {
+	db             *gorm.DB
+	repository     *repository.ReviewRepository
+	bookRepository *repository.BookRepository
+}
+
+func NewReviewUsecase(
+	db *gorm.DB,
+	repository *repository.ReviewRepository,
+	bookRepository *repository.BookRepository,
+) *ReviewUsecase {
+	return &ReviewUsecase{
+		db,
+		repository,
+		bookRepository,
+	}
+}
+
+func (uc *ReviewUsecase) GetMany(ctx context.Context, request *model.GetManyReviewsRequest) ([]model.ReviewResponse, int64, error) {
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
+	defer tx.Rollback()
+
+	if _, err := uc.bookRepository.FindByID(tx, request.BookID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, 0, model.ErrorNotFound(errors.New("book not found"))
+		}
+		return nil, 0, model.ErrorInternalServerError(errors.New("failed to find book data by id"))
+	}
+
+	reviews, total, err := uc.repository.Search(ctx, tx, request.BookID, request.Page, request.Size)
+	if err != nil {
+		return nil, 0, model.ErrorInternalServerError(errors.New("failed to get many reviews"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, 0, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return model.ToReviewsResponse(reviews), total, nil
+}
+
+func (uc *ReviewUsecase) Upsert(ctx context.Context, request *model.UpsertReviewRequest) (*model.ReviewResponse, error) {
+	tx := begin(ctx, uc.db)
+	defer tx.Rollback()
+
+	if _, err := uc.bookRepository.FindByID(tx, request.BookID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrorNotFound(errors.New("book not found"))
+		}
+		return nil, model.ErrorInternalServerError(errors.New("failed to find book data by id"))
+	}
+
+	review, err := uc.repository.FindByUserIDAndBookID(tx, request.UserID, request.BookID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, model.ErrorInternalServerError(errors.New("failed to find review data"))
+	}
+
+	if review == nil {
+		review = &entity.Review{
+			UserID: request.UserID,
+			BookID: request.BookID,
+			Rating: request.Rating,
+			Text:   request.Text,
+		}
+		if err := uc.repository.Create(tx, review); err != nil {
+			return nil, model.ErrorInternalServerError(errors.New("failed to create new review"))
+		}
+	} else {
+		review.Rating = request.Rating
+		review.Text = request.Text
+		if err := uc.repository.Update(tx, review); err != nil {
+			return nil, model.ErrorInternalServerError(errors.New("failed to update review"))
+		}
+	}
+
+	if err := updateBookRating(tx, uc.repository, uc.bookRepository, request.BookID); err != nil {
+		return nil, err
+	}
+
+	review, err = uc.repository.FindByID(tx, review.ID)
+	if err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to find review data by id"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return model.ToReviewResponse(review), nil
+}
+
+func (uc *ReviewUsecase) DeleteOwn(ctx context.Context, request *model.DeleteOwnReviewRequest) (*int, error) {
+	tx := begin(ctx, uc.db)
+	defer tx.Rollback()
+
+	review, err := uc.repository.FindByUserIDAndBookID(tx, request.UserID, request.BookID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrorNotFound(errors.New("review not found"))
+		}
+		return nil, model.ErrorInternalServerError(errors.New("failed to find review data"))
+	}
+
+	if err := uc.repository.Delete(tx, review); err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to delete review"))
+	}
+
+	if err := updateBookRating(tx, uc.repository, uc.bookRepository, request.BookID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return &review.ID, nil
+}
+
+func (uc *ReviewUsecase) Delete(ctx context.Context, request *model.DeleteReviewRequest) (*int, error) {
+	tx := begin(ctx, uc.db)
+	defer tx.Rollback()
+
+	review, err := uc.repository.FindByID(tx, request.ReviewID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrorNotFound(errors.New("id not found"))
+		}
+		return nil, model.ErrorInternalServerError(errors.New("failed to find review data by id"))
+	}
+
+	if review.BookID != request.BookID {
+		return nil, model.ErrorNotFound(errors.New("id not found"))
+	}
+
+	if err := uc.repository.Delete(tx, review); err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to delete review"))
+	}
+
+	if err := updateBookRating(tx, uc.repository, uc.bookRepository, review.BookID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return &review.ID, nil
+}
+
+// RecalculateRatings aggregates the rating of every book from its reviews
+// again, repairing ratings that went out of sync with the reviews.
+func (uc *ReviewUsecase) RecalculateRatings(ctx context.Context) (*model.RecalculateRatingsResponse, error) {
+	tx := begin(ctx, uc.db)
+	defer tx.Rollback()
+
+	bookIDs, err := uc.bookRepository.FindAllIDs(tx)
+	if err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to find books"))
+	}
+
+	for _, bookID := range bookIDs {
+		if err := updateBookRating(tx, uc.repository, uc.bookRepository, bookID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return &model.RecalculateRatingsResponse{Books: len(bookIDs)}, nil
+}
+
+func updateBookRating(
+	tx *gorm.DB,
+	reviewRepository *repository.ReviewRepository,
+	bookRepository *repository.BookRepository,
+	bookID int,
+) error {
+	average, count, err := reviewRepository.AggregateRating(tx, bookID)
+	if err != nil {
+		return model.ErrorInternalServerError(errors.New("failed to aggregate book rating"))
+	}
+
+	if err := bookRepository.UpdateRating(tx, bookID, average, count); err != nil {
+		return model.ErrorInternalServerError(errors.New("failed to update book rating"))
+	}
+
+	return nil
+}
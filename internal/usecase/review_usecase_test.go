@@ -0,0 +1,272 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+	"github.com/mnaufalhilmym/bookshelf/internal/util"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type reviewFixture struct {
+	db       *gorm.DB
+	userUc   *usecase.UserUsecase
+	bookUc   *usecase.BookUsecase
+	reviewUc *usecase.ReviewUsecase
+	user1    *model.UserResponse
+	user2    *model.UserResponse
+	book     *model.BookResponse
+}
+
+func newReviewFixture(t *testing.T) *reviewFixture {
+	db := newDatabase()
+	userRepo := repository.NewUserRepository()
+	authorRepo := repository.NewAuthorRepository()
+	bookRepo := repository.NewBookRepository()
+	reviewRepo := repository.NewReviewRepository()
+
+	f := &reviewFixture{
+		db:       db,
+		userUc:   usecase.NewUserUsecase(db, userRepo, "jwtKey", 10*time.Second),
+		bookUc:   usecase.NewBookUsecase(db, bookRepo, authorRepo, repository.NewRevisionRepository()),
+		reviewUc: usecase.NewReviewUsecase(db, reviewRepo, bookRepo),
+	}
+	authorUc := usecase.NewAuthorUsecase(db, authorRepo, bookRepo, repository.NewRevisionRepository())
+
+	var err error
+	f.user1, err = f.userUc.Register(context.Background(), &model.RegisterUserRequest{
+		Username: "reader_1",
+		Password: "password",
+	})
+	assert.NoError(t, err)
+	f.user2, err = f.userUc.Register(context.Background(), &model.RegisterUserRequest{
+		Username: "reader_2",
+		Password: "password",
+	})
+	assert.NoError(t, err)
+
+	author, err := authorUc.Create(context.Background(), &model.CreateAuthorRequest{
+		Name:      "Author Name 1",
+		Birthdate: time.Date(2011, 1, 11, 1, 11, 11, 1111, time.UTC),
+	})
+	assert.NoError(t, err)
+
+	f.book, err = f.bookUc.Create(context.Background(), &model.CreateBookRequest{
+		Title:    "Book Title 1",
+		ISBN:     "978-0451524935",
+		AuthorID: author.ID,
+	})
+	assert.NoError(t, err)
+
+	return f
+}
+
+func TestReviewUsecase_Upsert(t *testing.T) {
+	f := newReviewFixture(t)
+
+	t.Run("Positive Case 1 - create review", func(t *testing.T) {
+		resp, err := f.reviewUc.Upsert(context.Background(), &model.UpsertReviewRequest{
+			UserID: f.user1.ID,
+			BookID: f.book.ID,
+			Rating: 4,
+			Text:   "Good read",
+		})
+		assert.NoError(t, err)
+		assert.EqualValues(t, f.user1.Username, resp.Username)
+		assert.EqualValues(t, 4, resp.Rating)
+		assert.EqualValues(t, "Good read", resp.Text)
+
+		book, err := f.bookUc.Get(context.Background(), &model.GetBookRequest{ID: f.book.ID})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 4, book.AverageRating)
+		assert.EqualValues(t, 1, book.RatingCount)
+	})
+
+	t.Run("Positive Case 2 - edit existing review", func(t *testing.T) {
+		first, err := f.reviewUc.Upsert(context.Background(), &model.UpsertReviewRequest{
+			UserID: f.user2.ID,
+			BookID: f.book.ID,
+			Rating: 1,
+		})
+		assert.NoError(t, err)
+
+		resp, err := f.reviewUc.Upsert(context.Background(), &model.UpsertReviewRequest{
+			UserID: f.user2.ID,
+			BookID: f.book.ID,
+			Rating: 5,
+			Text:   "Changed my mind",
+		})
+		assert.NoError(t, err)
+		assert.EqualValues(t, first.ID, resp.ID)
+		assert.EqualValues(t, 5, resp.Rating)
+
+		book, err := f.bookUc.Get(context.Background(), &model.GetBookRequest{ID: f.book.ID})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 4.5, book.AverageRating)
+		assert.EqualValues(t, 2, book.RatingCount)
+	})
+
+	t.Run("Negative Case - book not found", func(t *testing.T) {
+		resp, err := f.reviewUc.Upsert(context.Background(), &model.UpsertReviewRequest{
+			UserID: f.user1.ID,
+			BookID: 0,
+			Rating: 3,
+		})
+		assert.EqualValues(t, model.ErrorNotFound(errors.New("book not found")), err)
+		assert.Nil(t, resp)
+	})
+}
+
+func TestReviewUsecase_RecalculateRatings(t *testing.T) {
+	f := newReviewFixture(t)
+
+	_, err := f.reviewUc.Upsert(context.Background(), &model.UpsertReviewRequest{
+		UserID: f.user1.ID,
+		BookID: f.book.ID,
+		Rating: 3,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, f.db.Exec("UPDATE books SET rating_average = 0, rating_count = 0").Error)
+
+	resp, err := f.reviewUc.RecalculateRatings(context.Background())
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, resp.Books)
+
+	book, err := f.bookUc.Get(context.Background(), &model.GetBookRequest{ID: f.book.ID})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, book.AverageRating)
+	assert.EqualValues(t, 1, book.RatingCount)
+}
+
+func TestReviewUsecase_GetMany(t *testing.T) {
+	f := newReviewFixture(t)
+
+	_, err := f.reviewUc.Upsert(context.Background(), &model.UpsertReviewRequest{
+		UserID: f.user1.ID,
+		BookID: f.book.ID,
+		Rating: 3,
+	})
+	assert.NoError(t, err)
+	_, err = f.reviewUc.Upsert(context.Background(), &model.UpsertReviewRequest{
+		UserID: f.user2.ID,
+		BookID: f.book.ID,
+		Rating: 5,
+	})
+	assert.NoError(t, err)
+
+	t.Run("Positive Case - paginated reviews", func(t *testing.T) {
+		request := &model.GetManyReviewsRequest{BookID: f.book.ID}
+		request.Page = 1
+		request.Size = 1
+
+		resp, total, err := f.reviewUc.GetMany(context.Background(), request)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, total)
+		assert.Len(t, resp, 1)
+	})
+
+	t.Run("Negative Case - book not found", func(t *testing.T) {
+		request := &model.GetManyReviewsRequest{BookID: 0}
+		request.Page = 1
+		request.Size = 10
+
+		resp, total, err := f.reviewUc.GetMany(context.Background(), request)
+		assert.EqualValues(t, model.ErrorNotFound(errors.New("book not found")), err)
+		assert.Nil(t, resp)
+		assert.EqualValues(t, 0, total)
+	})
+}
+
+func TestReviewUsecase_Delete(t *testing.T) {
+	f := newReviewFixture(t)
+
+	review1, err := f.reviewUc.Upsert(context.Background(), &model.UpsertReviewRequest{
+		UserID: f.user1.ID,
+		BookID: f.book.ID,
+		Rating: 2,
+	})
+	assert.NoError(t, err)
+	review2, err := f.reviewUc.Upsert(context.Background(), &model.UpsertReviewRequest{
+		UserID: f.user2.ID,
+		BookID: f.book.ID,
+		Rating: 4,
+	})
+	assert.NoError(t, err)
+
+	t.Run("Positive Case 1 - delete own review", func(t *testing.T) {
+		resp, err := f.reviewUc.DeleteOwn(context.Background(), &model.DeleteOwnReviewRequest{
+			UserID: f.user1.ID,
+			BookID: f.book.ID,
+		})
+		assert.NoError(t, err)
+		assert.EqualValues(t, &review1.ID, resp)
+
+		book, err := f.bookUc.Get(context.Background(), &model.GetBookRequest{ID: f.book.ID})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 4, book.AverageRating)
+		assert.EqualValues(t, 1, book.RatingCount)
+	})
+
+	t.Run("Positive Case 2 - moderator removes review", func(t *testing.T) {
+		resp, err := f.reviewUc.Delete(context.Background(), &model.DeleteReviewRequest{
+			BookID:   f.book.ID,
+			ReviewID: review2.ID,
+		})
+		assert.NoError(t, err)
+		assert.EqualValues(t, &review2.ID, resp)
+
+		book, err := f.bookUc.Get(context.Background(), &model.GetBookRequest{ID: f.book.ID})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 0, book.AverageRating)
+		assert.EqualValues(t, 0, book.RatingCount)
+	})
+
+	t.Run("Negative Case - review not found", func(t *testing.T) {
+		resp, err := f.reviewUc.DeleteOwn(context.Background(), &model.DeleteOwnReviewRequest{
+			UserID: f.user1.ID,
+			BookID: f.book.ID,
+		})
+		assert.EqualValues(t, model.ErrorNotFound(errors.New("review not found")), err)
+		assert.Nil(t, resp)
+	})
+}
+
+func TestBookUsecase_GetManySortByRating(t *testing.T) {
+	f := newReviewFixture(t)
+
+	book2, err := f.bookUc.Create(context.Background(), &model.CreateBookRequest{
+		Title:    "Book Title 2",
+		ISBN:     "978-0743273565",
+		AuthorID: f.book.AuthorID,
+	})
+	assert.NoError(t, err)
+
+	_, err = f.reviewUc.Upsert(context.Background(), &model.UpsertReviewRequest{
+		UserID: f.user1.ID,
+		BookID: f.book.ID,
+		Rating: 2,
+	})
+	assert.NoError(t, err)
+	_, err = f.reviewUc.Upsert(context.Background(), &model.UpsertReviewRequest{
+		UserID: f.user1.ID,
+		BookID: book2.ID,
+		Rating: 5,
+	})
+	assert.NoError(t, err)
+
+	request := &model.GetManyBooksRequest{Sort: util.ToPointer("-rating")}
+	request.Page = 1
+	request.Size = 10
+
+	resp, total, err := f.bookUc.GetMany(context.Background(), request)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, total)
+	assert.EqualValues(t, book2.ID, resp[0].ID)
+	assert.EqualValues(t, f.book.ID, resp[1].ID)
+}
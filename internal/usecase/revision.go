@@ -0,0 +1,221 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"gorm.io/gorm"
+)
+
+// bookSnapshot holds the fields of a book which its revisions keep. Ratings
+// and covers are left out, they do not change by editing the book.
+type bookSnapshot struct {
+	Title       string  `json:"title"`
+	ISBN        string  `json:"isbn"`
+	AuthorID    int     `json:"author_id"`
+	PageCount   int     `json:"page_count"`
+	Language    string  `json:"language"`
+	Series      string  `json:"series"`
+	SeriesIndex float64 `json:"series_index"`
+}
+
+func newBookSnapshot(book *entity.Book) *bookSnapshot {
+	return &bookSnapshot{
+		Title:       book.Title,
+		ISBN:        book.ISBN,
+		AuthorID:    book.AuthorID,
+		PageCount:   book.PageCount,
+		Language:    book.Language,
+		Series:      book.Series,
+		SeriesIndex: book.SeriesIndex,
+	}
+}
+
+func (s *bookSnapshot) apply(book *entity.Book) {
+	book.Title = s.Title
+	book.ISBN = s.ISBN
+	book.AuthorID = s.AuthorID
+	book.PageCount = s.PageCount
+	book.Language = s.Language
+	book.Series = s.Series
+	book.SeriesIndex = s.SeriesIndex
+}
+
+type authorSnapshot struct {
+	Name      string    `json:"name"`
+	Birthdate time.Time `json:"birthdate"`
+}
+
+func newAuthorSnapshot(author *entity.Author) *authorSnapshot {
+	return &authorSnapshot{
+		Name:      author.Name,
+		Birthdate: author.Birthdate.UTC(),
+	}
+}
+
+func (s *authorSnapshot) apply(author *entity.Author) {
+	author.Name = s.Name
+	author.Birthdate = s.Birthdate
+}
+
+// recordRevision saves the record as it is after a change, as its next
+// revision. A record without revisions, created before revisions were kept or
+// never changed since, first gets the snapshot from before the change as its
+// first revision. Changes which leave the record as its latest revision are
+// not recorded.
+func recordRevision(
+	tx *gorm.DB,
+	revisionRepository *repository.RevisionRepository,
+	entityType string,
+	entityID int,
+	before any,
+	after any,
+	createdBy *int,
+) error {
+	latest, err := revisionRepository.FindLatest(tx, entityType, entityID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.ErrorInternalServerError(errors.New("failed to find latest revision"))
+	}
+
+	if latest == nil {
+		data, err := json.Marshal(before)
+		if err != nil {
+			return model.ErrorInternalServerError(errors.New("failed to encode revision"))
+		}
+		latest = &entity.Revision{
+			EntityType: entityType,
+			EntityID:   entityID,
+			Revision:   1,
+			Data:       string(data),
+		}
+		if err := revisionRepository.Create(tx, latest); err != nil {
+			return model.ErrorInternalServerError(errors.New("failed to record revision"))
+		}
+	}
+
+	data, err := json.Marshal(after)
+	if err != nil {
+		return model.ErrorInternalServerError(errors.New("failed to encode revision"))
+	}
+	if latest.Data == string(data) {
+		return nil
+	}
+
+	if err := revisionRepository.Create(tx, &entity.Revision{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Revision:   latest.Revision + 1,
+		Data:       string(data),
+		CreatedBy:  createdBy,
+	}); err != nil {
+		return model.ErrorInternalServerError(errors.New("failed to record revision"))
+	}
+	return nil
+}
+
+func getRevisions(
+	ctx context.Context,
+	tx *gorm.DB,
+	revisionRepository *repository.RevisionRepository,
+	entityType string,
+	request *model.GetManyRevisionsRequest,
+) ([]model.RevisionResponse, int64, error) {
+	revisions, total, err := revisionRepository.Search(ctx, tx, entityType, request.ID, request.Page, request.Size)
+	if err != nil {
+		return nil, 0, model.ErrorInternalServerError(errors.New("failed to get many revisions"))
+	}
+	return model.ToRevisionsResponse(revisions), total, nil
+}
+
+func findRevision(
+	tx *gorm.DB,
+	revisionRepository *repository.RevisionRepository,
+	entityType string,
+	entityID int,
+	number int,
+) (*entity.Revision, error) {
+	revision, err := revisionRepository.FindByRevision(tx, entityType, entityID, number)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrorNotFound(errors.New("revision not found"))
+		}
+		return nil, model.ErrorInternalServerError(errors.New("failed to find revision"))
+	}
+	return revision, nil
+}
+
+func diffRevisions(
+	tx *gorm.DB,
+	revisionRepository *repository.RevisionRepository,
+	entityType string,
+	request *model.DiffRevisionsRequest,
+) (*model.RevisionDiffResponse, error) {
+	var to *entity.Revision
+	var err error
+	if request.To == 0 {
+		to, err = revisionRepository.FindLatest(tx, entityType, request.ID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, model.ErrorNotFound(errors.New("revision not found"))
+			}
+			return nil, model.ErrorInternalServerError(errors.New("failed to find latest revision"))
+		}
+	} else {
+		to, err = findRevision(tx, revisionRepository, entityType, request.ID, request.To)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	fromRevision := request.From
+	if fromRevision == 0 {
+		fromRevision = to.Revision - 1
+	}
+	from, err := findRevision(tx, revisionRepository, entityType, request.ID, fromRevision)
+	if err != nil {
+		return nil, err
+	}
+
+	var fromData, toData map[string]any
+	if err := json.Unmarshal([]byte(from.Data), &fromData); err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to decode revision"))
+	}
+	if err := json.Unmarshal([]byte(to.Data), &toData); err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to decode revision"))
+	}
+
+	fields := make([]string, 0, len(fromData)+len(toData))
+	for field := range fromData {
+		fields = append(fields, field)
+	}
+	for field := range toData {
+		if _, ok := fromData[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	changes := []model.RevisionChangeResponse{}
+	for _, field := range fields {
+		if !reflect.DeepEqual(fromData[field], toData[field]) {
+			changes = append(changes, model.RevisionChangeResponse{
+				Field: field,
+				From:  fromData[field],
+				To:    toData[field],
+			})
+		}
+	}
+
+	return &model.RevisionDiffResponse{
+		From:    from.Revision,
+		To:      to.Revision,
+		Changes: changes,
+	}, nil
+}
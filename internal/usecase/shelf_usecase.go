@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/gotracing"
+	"gorm.io/gorm"
+)
+
+type ShelfUsecase struct {
+	db         *gorm.DB
+	repository *repository.ShelfEntryRepository
+}
+
+func NewShelfUsecase(db *gorm.DB, repository *repository.ShelfEntryRepository) *ShelfUsecase {
+	return &ShelfUsecase{
+		db,
+		repository,
+	}
+}
+
+func (uc *ShelfUsecase) GetMany(ctx context.Context, request *model.GetManyShelvesRequest) ([]model.ShelfResponse, error) {
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
+	defer tx.Rollback()
+
+	counts, err := uc.repository.CountByShelf(tx, request.UserID)
+	if err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to count shelf entries"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	response := make([]model.ShelfResponse, 0, len(counts))
+	for name, count := range counts {
+		response = append(response, model.ShelfResponse{Name: name, BookCount: count})
+	}
+	slices.SortFunc(response, func(a, b model.ShelfResponse) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return response, nil
+}
+
+func (uc *ShelfUsecase) Get(ctx context.Context, request *model.GetShelfRequest) ([]model.ShelfEntryResponse, int64, error) {
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
+	defer tx.Rollback()
+
+	entries, total, err := uc.repository.Search(
+		ctx,
+		tx,
+		request.UserID,
+		strings.ToLower(request.Shelf),
+		request.Page,
+		request.Size,
+	)
+	if err != nil {
+		return nil, 0, model.ErrorInternalServerError(errors.New("failed to get shelf entries"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, 0, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return model.ToShelfEntriesResponse(entries), total, nil
+}
@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/gorm"
+)
+
+const (
+	beginAttempts = 5
+	beginBackoff  = 50 * time.Millisecond
+)
+
+// begin starts a transaction, and starts it again while SQLite reports the
+// database busy, when another process holds the write lock past the busy
+// timeout. Writing transactions on SQLite take the write lock as they begin,
+// so a transaction which has begun is not turned away busy later on.
+func begin(ctx context.Context, db *gorm.DB, opts ...*sql.TxOptions) *gorm.DB {
+	tx := db.WithContext(ctx).Begin(opts...)
+	for attempt := 1; attempt < beginAttempts && isBusy(tx.Error); attempt++ {
+		select {
+		case <-ctx.Done():
+			return tx
+		case <-time.After(time.Duration(attempt) * beginBackoff):
+		}
+		tx = db.WithContext(ctx).Begin(opts...)
+	}
+	return tx
+}
+
+func isBusy(err error) bool {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+}
@@ -0,0 +1,258 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/bookshelf/internal/storage"
+	"github.com/mnaufalhilmym/gotracing"
+	"gorm.io/gorm"
+)
+
+type TrashUsecase struct {
+	db                 *gorm.DB
+	storage            storage.Storage
+	repository         *repository.TrashRepository
+	userRepository     *repository.UserRepository
+	authorRepository   *repository.AuthorRepository
+	bookRepository     *repository.BookRepository
+	bookFileRepository *repository.BookFileRepository
+	retention          time.Duration
+}
+
+// NewTrashUsecase purges the users, authors and books which have been in the
+// trash for longer than retention, and the files and covers of the books
+// from storage.
+func NewTrashUsecase(
+	db *gorm.DB,
+	storage storage.Storage,
+	repository *repository.TrashRepository,
+	userRepository *repository.UserRepository,
+	authorRepository *repository.AuthorRepository,
+	bookRepository *repository.BookRepository,
+	bookFileRepository *repository.BookFileRepository,
+	retention time.Duration,
+) *TrashUsecase {
+	return &TrashUsecase{
+		db:                 db,
+		storage:            storage,
+		repository:         repository,
+		userRepository:     userRepository,
+		authorRepository:   authorRepository,
+		bookRepository:     bookRepository,
+		bookFileRepository: bookFileRepository,
+		retention:          retention,
+	}
+}
+
+func (uc *TrashUsecase) GetMany(ctx context.Context, request *model.GetManyTrashRequest) ([]model.TrashItemResponse, int64, error) {
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
+	defer tx.Rollback()
+
+	items, total, err := uc.repository.Search(ctx, tx, request.Type, request.Page, request.Size)
+	if err != nil {
+		return nil, 0, model.ErrorInternalServerError(errors.New("failed to get many trash items"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, 0, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return model.ToTrashItemsResponse(items), total, nil
+}
+
+// Restore takes an item out of the trash. A book cannot be restored while its
+// author is in the trash, or while another book has its ISBN.
+func (uc *TrashUsecase) Restore(ctx context.Context, request *model.RestoreTrashRequest) (*int, error) {
+	tx := begin(ctx, uc.db)
+	defer tx.Rollback()
+
+	var err error
+	switch request.Type {
+	case entity.TrashItemTypeUser:
+		err = uc.restoreUser(tx, request.ID)
+	case entity.TrashItemTypeAuthor:
+		err = uc.restoreAuthor(tx, request.ID)
+	case entity.TrashItemTypeBook:
+		err = uc.restoreBook(tx, request.ID)
+	default:
+		err = model.ErrorBadRequest(errors.New("type must be user, author or book"))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return &request.ID, nil
+}
+
+func (uc *TrashUsecase) restoreUser(tx *gorm.DB, id int) error {
+	user, err := uc.userRepository.FindTrashedByID(tx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.ErrorNotFound(errors.New("user not found in trash"))
+		}
+		return model.ErrorInternalServerError(errors.New("failed to find user data by id"))
+	}
+
+	if err := uc.userRepository.Restore(tx, user); err != nil {
+		return model.ErrorInternalServerError(errors.New("failed to restore user"))
+	}
+	return nil
+}
+
+func (uc *TrashUsecase) restoreAuthor(tx *gorm.DB, id int) error {
+	author, err := uc.authorRepository.FindTrashedByID(tx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.ErrorNotFound(errors.New("author not found in trash"))
+		}
+		return model.ErrorInternalServerError(errors.New("failed to find author data by id"))
+	}
+
+	if err := uc.authorRepository.Restore(tx, author); err != nil {
+		return model.ErrorInternalServerError(errors.New("failed to restore author"))
+	}
+	return nil
+}
+
+func (uc *TrashUsecase) restoreBook(tx *gorm.DB, id int) error {
+	book, err := uc.bookRepository.FindTrashedByID(tx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.ErrorNotFound(errors.New("book not found in trash"))
+		}
+		return model.ErrorInternalServerError(errors.New("failed to find book data by id"))
+	}
+
+	if _, err := uc.authorRepository.FindByID(tx, book.AuthorID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.ErrorConflict(errors.New("author of the book is in the trash"))
+		}
+		return model.ErrorInternalServerError(errors.New("failed to find author data by id"))
+	}
+
+	if _, err := uc.bookRepository.FindByISBN(tx, book.ISBN); err == nil {
+		return model.ErrorConflict(errors.New("isbn is used by another book"))
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.ErrorInternalServerError(errors.New("failed to find book data by isbn"))
+	}
+
+	if err := uc.bookRepository.Restore(tx, book); err != nil {
+		return model.ErrorInternalServerError(errors.New("failed to restore book"))
+	}
+	return nil
+}
+
+// Purge deletes the items which have been in the trash for longer than the
+// retention period, and returns how many were deleted. Books go first, so
+// that the authors whose books are purged can be purged too. Items still
+// referenced by other records stay in the trash.
+func (uc *TrashUsecase) Purge(ctx context.Context) (int, error) {
+	before := time.Now().Add(-uc.retention)
+
+	books, err := purgeTrashed(ctx, uc.db, uc.storage, uc.bookRepository, before, uc.storedBookKeys)
+	if err != nil {
+		return books, err
+	}
+	authors, err := purgeTrashed(ctx, uc.db, uc.storage, uc.authorRepository, before, nil)
+	if err != nil {
+		return books + authors, err
+	}
+	users, err := purgeTrashed(ctx, uc.db, uc.storage, uc.userRepository, before, nil)
+	return books + authors + users, err
+}
+
+// storedBookKeys returns the keys of the cover and the files of the book.
+func (uc *TrashUsecase) storedBookKeys(tx *gorm.DB, book *entity.Book) ([]string, error) {
+	files, err := uc.bookFileRepository.FindAllByBookID(tx, book.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := coverKeys(book)
+	for _, file := range files {
+		keys = append(keys, file.StorageKey)
+	}
+	return keys, nil
+}
+
+// Schedule purges the trash every interval until ctx is done.
+func (uc *TrashUsecase) Schedule(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := uc.Purge(ctx); err != nil {
+				gotracing.Error("Failed to purge trash", err)
+			}
+		}
+	}
+}
+
+type trashRepository[T any] interface {
+	FindAllTrashedBefore(db *gorm.DB, before time.Time) ([]T, error)
+	Purge(db *gorm.DB, entity *T) error
+}
+
+// purgeTrashed deletes each row in a transaction of its own, so that a row
+// which is still referenced does not keep the others in the trash. The
+// objects storedKeys returns for a row are removed from storage once its
+// deletion is committed.
+func purgeTrashed[T any](
+	ctx context.Context,
+	db *gorm.DB,
+	s storage.Storage,
+	repository trashRepository[T],
+	before time.Time,
+	storedKeys func(tx *gorm.DB, entity *T) ([]string, error),
+) (int, error) {
+	entities, err := repository.FindAllTrashedBefore(db.WithContext(ctx), before)
+	if err != nil {
+		return 0, model.ErrorInternalServerError(errors.New("failed to find trashed data"))
+	}
+
+	purged := 0
+	for i := range entities {
+		tx := begin(ctx, db)
+
+		var keys []string
+		if storedKeys != nil {
+			if keys, err = storedKeys(tx, &entities[i]); err != nil {
+				tx.Rollback()
+				return purged, model.ErrorInternalServerError(errors.New("failed to find stored files"))
+			}
+		}
+
+		if err := repository.Purge(tx, &entities[i]); err != nil {
+			tx.Rollback()
+			if errors.Is(err, gorm.ErrForeignKeyViolated) {
+				gotracing.Warnf("Kept %T in trash, it is still referenced", entities[i])
+				continue
+			}
+			return purged, model.ErrorInternalServerError(errors.New("failed to purge trashed data"))
+		}
+		if err := tx.Commit().Error; err != nil {
+			gotracing.Error("Failed to commit transaction", err)
+			return purged, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+		}
+		purged++
+
+		removeStored(s, keys)
+	}
+	return purged, nil
+}
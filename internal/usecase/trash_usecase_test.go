@@ -0,0 +1,333 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
+	"github.com/mnaufalhilmym/bookshelf/internal/model"
+	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/bookshelf/internal/storage"
+	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
+	"github.com/mnaufalhilmym/bookshelf/internal/util"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type trashTestUsecases struct {
+	storageDir string
+	trash      *usecase.TrashUsecase
+	user       *usecase.UserUsecase
+	author     *usecase.AuthorUsecase
+	book       *usecase.BookUsecase
+	review     *usecase.ReviewUsecase
+	progress   *usecase.ReadingProgressUsecase
+	annotation *usecase.AnnotationUsecase
+	bookFile   *usecase.BookFileUsecase
+}
+
+func newTrashUsecases(t *testing.T, db *gorm.DB, retention time.Duration) *trashTestUsecases {
+	storageDir := t.TempDir()
+	fileStorage := storage.NewLocal(storageDir)
+	userRepo := repository.NewUserRepository()
+	authorRepo := repository.NewAuthorRepository()
+	bookRepo := repository.NewBookRepository()
+	bookFileRepo := repository.NewBookFileRepository()
+	return &trashTestUsecases{
+		storageDir: storageDir,
+		trash:      usecase.NewTrashUsecase(db, fileStorage, repository.NewTrashRepository(), userRepo, authorRepo, bookRepo, bookFileRepo, retention),
+		user:       usecase.NewUserUsecase(db, userRepo, "jwtKey", 10*time.Second),
+		author:     usecase.NewAuthorUsecase(db, authorRepo, bookRepo, repository.NewRevisionRepository()),
+		book:       usecase.NewBookUsecase(db, bookRepo, authorRepo, repository.NewRevisionRepository()),
+		review:     usecase.NewReviewUsecase(db, repository.NewReviewRepository(), bookRepo),
+		progress:   usecase.NewReadingProgressUsecase(db, repository.NewReadingProgressRepository(), bookRepo),
+		annotation: usecase.NewAnnotationUsecase(db, repository.NewAnnotationRepository(), bookRepo),
+		bookFile:   usecase.NewBookFileUsecase(db, fileStorage, bookFileRepo, bookRepo, authorRepo),
+	}
+}
+
+func TestTrashUsecase_Restore(t *testing.T) {
+	uc := newTrashUsecases(t, newDatabase(), time.Hour)
+
+	author, err := uc.author.Create(context.Background(), &model.CreateAuthorRequest{Name: "Author Name 1"})
+	assert.NoError(t, err)
+	book, err := uc.book.Create(context.Background(), &model.CreateBookRequest{
+		Title:    "Book Title 1",
+		ISBN:     "9780441172719",
+		AuthorID: author.ID,
+	})
+	assert.NoError(t, err)
+	admin, err := uc.user.Register(context.Background(), &model.RegisterUserRequest{Username: "admin", Password: "password"})
+	assert.NoError(t, err)
+
+	_, err = uc.book.Delete(context.Background(), &model.DeleteBookRequest{ID: book.ID, DeletedBy: &admin.ID})
+	assert.NoError(t, err)
+
+	t.Run("Positive Case 1 - deleted book is left out", func(t *testing.T) {
+		_, err := uc.book.Get(context.Background(), &model.GetBookRequest{ID: book.ID})
+		assert.EqualValues(t, model.ErrorNotFound(errors.New("book not found")), err)
+
+		request := &model.GetManyTrashRequest{}
+		request.Page = 1
+		request.Size = 10
+		items, total, err := uc.trash.GetMany(context.Background(), request)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, total)
+		assert.EqualValues(t, "book", items[0].Type)
+		assert.EqualValues(t, book.ID, items[0].ID)
+		assert.EqualValues(t, "Book Title 1", items[0].Name)
+		assert.EqualValues(t, &admin.ID, items[0].DeletedBy)
+	})
+
+	var replacement *model.BookResponse
+	t.Run("Positive Case 2 - isbn of deleted book is free", func(t *testing.T) {
+		replacement, err = uc.book.Create(context.Background(), &model.CreateBookRequest{
+			Title:    "Book Title 2",
+			ISBN:     "9780441172719",
+			AuthorID: author.ID,
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Negative Case 1 - isbn is used by another book", func(t *testing.T) {
+		_, err := uc.trash.Restore(context.Background(), &model.RestoreTrashRequest{Type: "book", ID: book.ID})
+		assert.EqualValues(t, model.ErrorConflict(errors.New("isbn is used by another book")), err)
+	})
+
+	t.Run("Negative Case 2 - not in trash", func(t *testing.T) {
+		_, err := uc.trash.Restore(context.Background(), &model.RestoreTrashRequest{Type: "book", ID: replacement.ID})
+		assert.EqualValues(t, model.ErrorNotFound(errors.New("book not found in trash")), err)
+	})
+
+	t.Run("Negative Case 3 - author is in trash", func(t *testing.T) {
+		_, err := uc.author.Delete(context.Background(), &model.DeleteAuthorRequest{ID: author.ID, Cascade: true})
+		assert.NoError(t, err)
+
+		_, err = uc.trash.Restore(context.Background(), &model.RestoreTrashRequest{Type: "book", ID: book.ID})
+		assert.EqualValues(t, model.ErrorConflict(errors.New("author of the book is in the trash")), err)
+	})
+
+	t.Run("Positive Case 3 - restore author and book", func(t *testing.T) {
+		_, err := uc.trash.Restore(context.Background(), &model.RestoreTrashRequest{Type: "author", ID: author.ID})
+		assert.NoError(t, err)
+		id, err := uc.trash.Restore(context.Background(), &model.RestoreTrashRequest{Type: "book", ID: book.ID})
+		assert.NoError(t, err)
+		assert.EqualValues(t, book.ID, *id)
+
+		res, err := uc.book.Get(context.Background(), &model.GetBookRequest{ID: book.ID})
+		assert.NoError(t, err)
+		assert.EqualValues(t, "Book Title 1", res.Title)
+
+		request := &model.GetManyTrashRequest{Type: util.ToPointer("book")}
+		request.Page = 1
+		request.Size = 10
+		items, total, err := uc.trash.GetMany(context.Background(), request)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, total)
+		assert.EqualValues(t, replacement.ID, items[0].ID)
+		assert.Nil(t, items[0].DeletedBy)
+	})
+
+	t.Run("Positive Case 4 - restore user", func(t *testing.T) {
+		_, err := uc.user.Delete(context.Background(), &model.DeleteUserRequest{Username: "admin"})
+		assert.NoError(t, err)
+		_, err = uc.user.GetByUsername(context.Background(), "admin")
+		assert.Error(t, err)
+
+		_, err = uc.trash.Restore(context.Background(), &model.RestoreTrashRequest{Type: "user", ID: admin.ID})
+		assert.NoError(t, err)
+		_, err = uc.user.GetByUsername(context.Background(), "admin")
+		assert.NoError(t, err)
+	})
+}
+
+func TestTrashUsecase_Purge(t *testing.T) {
+	db := newDatabase()
+	uc := newTrashUsecases(t, db, 0)
+
+	author, err := uc.author.Create(context.Background(), &model.CreateAuthorRequest{Name: "Author Name 1"})
+	assert.NoError(t, err)
+	book1, err := uc.book.Create(context.Background(), &model.CreateBookRequest{
+		Title:    "Book Title 1",
+		ISBN:     "9780441172719",
+		AuthorID: author.ID,
+	})
+	assert.NoError(t, err)
+	_, err = uc.book.Create(context.Background(), &model.CreateBookRequest{
+		Title:    "Book Title 2",
+		ISBN:     "9780451524935",
+		AuthorID: author.ID,
+	})
+	assert.NoError(t, err)
+	otherAuthor, err := uc.author.Create(context.Background(), &model.CreateAuthorRequest{Name: "Author Name 2"})
+	assert.NoError(t, err)
+	kept, err := uc.book.Create(context.Background(), &model.CreateBookRequest{
+		Title:    "Book Title 3",
+		ISBN:     "9780060850524",
+		AuthorID: otherAuthor.ID,
+	})
+	assert.NoError(t, err)
+	reader, err := uc.user.Register(context.Background(), &model.RegisterUserRequest{Username: "reader", Password: "password"})
+	assert.NoError(t, err)
+	_, err = uc.review.Upsert(context.Background(), &model.UpsertReviewRequest{UserID: reader.ID, BookID: book1.ID, Rating: 5})
+	assert.NoError(t, err)
+	_, err = uc.review.Upsert(context.Background(), &model.UpsertReviewRequest{UserID: reader.ID, BookID: kept.ID, Rating: 4})
+	assert.NoError(t, err)
+
+	countTrash := func(t *testing.T) int64 {
+		request := &model.GetManyTrashRequest{}
+		request.Page = 1
+		request.Size = 10
+		_, total, err := uc.trash.GetMany(context.Background(), request)
+		assert.NoError(t, err)
+		return total
+	}
+
+	t.Run("Positive Case 1 - nothing to purge", func(t *testing.T) {
+		purged, err := uc.trash.Purge(context.Background())
+		assert.NoError(t, err)
+		assert.EqualValues(t, 0, purged)
+	})
+
+	t.Run("Positive Case 2 - purge books and their author", func(t *testing.T) {
+		_, err := uc.author.Delete(context.Background(), &model.DeleteAuthorRequest{ID: author.ID, Cascade: true})
+		assert.NoError(t, err)
+
+		purged, err := uc.trash.Purge(context.Background())
+		assert.NoError(t, err)
+		assert.EqualValues(t, 3, purged)
+		assert.EqualValues(t, 0, countTrash(t))
+
+		_, err = repository.NewBookRepository().FindTrashedByID(db, book1.ID)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("Positive Case 3 - referenced user stays in trash", func(t *testing.T) {
+		_, err := uc.user.Delete(context.Background(), &model.DeleteUserRequest{Username: "reader"})
+		assert.NoError(t, err)
+
+		purged, err := uc.trash.Purge(context.Background())
+		assert.NoError(t, err)
+		assert.EqualValues(t, 0, purged)
+		assert.EqualValues(t, 1, countTrash(t))
+	})
+
+	t.Run("Positive Case 4 - retention period", func(t *testing.T) {
+		_, err := uc.user.Register(context.Background(), &model.RegisterUserRequest{Username: "idle", Password: "password"})
+		assert.NoError(t, err)
+		_, err = uc.user.Delete(context.Background(), &model.DeleteUserRequest{Username: "idle"})
+		assert.NoError(t, err)
+
+		purged, err := newTrashUsecases(t, db, time.Hour).trash.Purge(context.Background())
+		assert.NoError(t, err)
+		assert.EqualValues(t, 0, purged)
+
+		purged, err = uc.trash.Purge(context.Background())
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, purged)
+		assert.EqualValues(t, 1, countTrash(t))
+	})
+}
+
+func TestTrashUsecase_PurgeBook(t *testing.T) {
+	db := newDatabase()
+	uc := newTrashUsecases(t, db, 0)
+
+	author, err := uc.author.Create(context.Background(), &model.CreateAuthorRequest{Name: "Author Name 1"})
+	assert.NoError(t, err)
+	book, err := uc.book.Create(context.Background(), &model.CreateBookRequest{
+		Title:    "Book Title 1",
+		ISBN:     "9780441172719",
+		AuthorID: author.ID,
+	})
+	assert.NoError(t, err)
+	reader, err := uc.user.Register(context.Background(), &model.RegisterUserRequest{Username: "reader", Password: "password"})
+	assert.NoError(t, err)
+
+	_, err = uc.progress.Create(context.Background(), &model.CreateReadingProgressRequest{UserID: reader.ID, BookID: book.ID, Page: util.ToPointer(10)})
+	assert.NoError(t, err)
+	_, err = uc.annotation.Create(context.Background(), &model.CreateAnnotationRequest{UserID: reader.ID, BookID: book.ID, Page: 10, Text: "Fear is the mind-killer."})
+	assert.NoError(t, err)
+	_, err = uc.review.Upsert(context.Background(), &model.UpsertReviewRequest{UserID: reader.ID, BookID: book.ID, Rating: 5})
+	assert.NoError(t, err)
+	_, err = uc.bookFile.Upload(context.Background(), &model.UploadBookFileRequest{
+		BookID:   book.ID,
+		Filename: "dune.epub",
+		Content:  newEPUB(t, epubPackage, pngImage),
+	})
+	assert.NoError(t, err)
+
+	countStored := func(t *testing.T) int {
+		count := 0
+		err := filepath.WalkDir(uc.storageDir, func(path string, entry fs.DirEntry, err error) error {
+			if err == nil && !entry.IsDir() {
+				count++
+			}
+			return err
+		})
+		assert.NoError(t, err)
+		return count
+	}
+	// The file, the cover and its three thumbnails.
+	assert.EqualValues(t, 5, countStored(t))
+
+	t.Run("Positive Case - purge book with progress, annotation, review and file", func(t *testing.T) {
+		_, err := uc.book.Delete(context.Background(), &model.DeleteBookRequest{ID: book.ID})
+		assert.NoError(t, err)
+
+		purged, err := uc.trash.Purge(context.Background())
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, purged)
+
+		_, err = repository.NewBookRepository().FindTrashedByID(db, book.ID)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		for _, table := range []string{"reading_progresses", "annotations", "shelf_entries", "reviews", "book_files", "revisions"} {
+			var count int64
+			assert.NoError(t, db.Table(table).Count(&count).Error)
+			assert.EqualValues(t, 0, count, table)
+		}
+		assert.EqualValues(t, 0, countStored(t))
+	})
+}
+
+func TestTrashUsecase_PurgeUser(t *testing.T) {
+	db := newDatabase()
+	uc := newTrashUsecases(t, db, 0)
+
+	author, err := uc.author.Create(context.Background(), &model.CreateAuthorRequest{Name: "Author Name 1"})
+	assert.NoError(t, err)
+	book, err := uc.book.Create(context.Background(), &model.CreateBookRequest{
+		Title:    "Book Title 1",
+		ISBN:     "9780441172719",
+		AuthorID: author.ID,
+	})
+	assert.NoError(t, err)
+	reader, err := uc.user.Register(context.Background(), &model.RegisterUserRequest{Username: "reader", Password: "password"})
+	assert.NoError(t, err)
+
+	_, err = uc.progress.Create(context.Background(), &model.CreateReadingProgressRequest{UserID: reader.ID, BookID: book.ID, Page: util.ToPointer(10)})
+	assert.NoError(t, err)
+	assert.NoError(t, db.Create(&entity.ReadingGoal{UserID: reader.ID, Year: 2024, Type: "books", Target: 12}).Error)
+	assert.NoError(t, db.Create(&entity.ImportJob{UserID: reader.ID, Source: "goodreads", Total: 1}).Error)
+
+	t.Run("Positive Case - purge user with progress, shelf entry, goal and import job", func(t *testing.T) {
+		_, err := uc.user.Delete(context.Background(), &model.DeleteUserRequest{Username: "reader"})
+		assert.NoError(t, err)
+
+		purged, err := uc.trash.Purge(context.Background())
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, purged)
+
+		_, err = repository.NewUserRepository().FindTrashedByID(db, reader.ID)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		for _, table := range []string{"reading_progresses", "shelf_entries", "reading_goals", "import_jobs"} {
+			var count int64
+			assert.NoError(t, db.Table(table).Where("user_id = ?", reader.ID).Count(&count).Error)
+			assert.EqualValues(t, 0, count, table)
+		}
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"sort"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -37,18 +38,17 @@ func NewUserUsecase(
 }
 
 func (uc *UserUsecase) Register(ctx context.Context, request *model.RegisterUserRequest) (*model.UserResponse, error) {
-	tx := uc.db.WithContext(ctx).Begin()
+	tx := begin(ctx, uc.db)
 	defer tx.Rollback()
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+	hashedPassword, err := hashPassword(request.Password)
 	if err != nil {
-		gotracing.Error("Failed to generate hashed password", err)
-		return nil, model.ErrorInternalServerError(errors.New("failed to generate hashed password"))
+		return nil, err
 	}
 
 	user := &entity.User{
 		Username: request.Username,
-		Password: string(hashedPassword),
+		Password: hashedPassword,
 	}
 
 	if err := uc.repository.Create(tx, user); err != nil {
@@ -67,7 +67,7 @@ func (uc *UserUsecase) Register(ctx context.Context, request *model.RegisterUser
 }
 
 func (uc *UserUsecase) Login(ctx context.Context, request *model.LoginRequest) (*model.LoginResponse, error) {
-	tx := uc.db.WithContext(ctx).Begin(&sql.TxOptions{ReadOnly: true})
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
 	defer tx.Rollback()
 
 	user, err := uc.repository.FindByUsername(tx, request.Username)
@@ -106,15 +106,143 @@ func (uc *UserUsecase) Login(ctx context.Context, request *model.LoginRequest) (
 }
 
 func (uc *UserUsecase) GetByUsername(ctx context.Context, username string) (*model.UserResponse, error) {
-	tx := uc.db.WithContext(ctx).Begin(&sql.TxOptions{ReadOnly: true})
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
 	defer tx.Rollback()
 
+	user, err := uc.repository.FindByUsername(tx, username)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrorNotFound(errors.New("username not found"))
+		}
+		return nil, model.ErrorInternalServerError(errors.New("failed to find user data by username"))
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		gotracing.Error("Failed to commit transaction", err)
 		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
 	}
 
+	return model.ToUserResponse(user), nil
+}
+
+// Authenticate checks the credentials of clients that send them with every
+// request, such as HTTP Basic authentication, instead of logging in.
+func (uc *UserUsecase) Authenticate(ctx context.Context, username string, password string) (*model.UserResponse, error) {
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
+	defer tx.Rollback()
+
 	user, err := uc.repository.FindByUsername(tx, username)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrorUnauthorized(errors.New("invalid username or password"))
+		}
+		return nil, model.ErrorInternalServerError(errors.New("failed to find user data by username"))
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return nil, model.ErrorUnauthorized(errors.New("invalid username or password"))
+		}
+		return nil, model.ErrorInternalServerError(errors.New("failed to compare hash and password"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return model.ToUserResponse(user), nil
+}
+
+func (uc *UserUsecase) GetMany(ctx context.Context) ([]model.UserResponse, error) {
+	tx := begin(ctx, uc.db, &sql.TxOptions{ReadOnly: true})
+	defer tx.Rollback()
+
+	users, err := uc.repository.FindAll(tx)
+	if err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to get many users"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].ID < users[j].ID
+	})
+
+	responses := make([]model.UserResponse, len(users))
+	for i := range users {
+		responses[i] = *model.ToUserResponse(&users[i])
+	}
+	return responses, nil
+}
+
+// Create registers a user with any role, for administrators of the server.
+func (uc *UserUsecase) Create(ctx context.Context, request *model.CreateUserRequest) (*model.UserResponse, error) {
+	if !isUserRole(request.Role) {
+		return nil, model.ErrorBadRequest(errors.New("role must be user, moderator or admin"))
+	}
+
+	tx := begin(ctx, uc.db)
+	defer tx.Rollback()
+
+	hashedPassword, err := hashPassword(request.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &entity.User{
+		Username: request.Username,
+		Password: hashedPassword,
+		Role:     request.Role,
+	}
+
+	if err := uc.repository.Create(tx, user); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, model.ErrorBadRequest(errors.New("duplicate username"))
+		}
+		return nil, model.ErrorInternalServerError(errors.New("failed to create new user"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return model.ToUserResponse(user), nil
+}
+
+func (uc *UserUsecase) SetRole(ctx context.Context, request *model.SetUserRoleRequest) (*model.UserResponse, error) {
+	if !isUserRole(request.Role) {
+		return nil, model.ErrorBadRequest(errors.New("role must be user, moderator or admin"))
+	}
+
+	return uc.update(ctx, request.Username, func(user *entity.User) error {
+		user.Role = request.Role
+		return nil
+	})
+}
+
+func (uc *UserUsecase) ResetPassword(ctx context.Context, request *model.ResetUserPasswordRequest) (*model.UserResponse, error) {
+	return uc.update(ctx, request.Username, func(user *entity.User) error {
+		hashedPassword, err := hashPassword(request.Password)
+		if err != nil {
+			return err
+		}
+		user.Password = hashedPassword
+		return nil
+	})
+}
+
+// Delete moves a user to the trash, the username stays taken until the user
+// is purged.
+func (uc *UserUsecase) Delete(ctx context.Context, request *model.DeleteUserRequest) (*model.UserResponse, error) {
+	tx := begin(ctx, uc.db)
+	defer tx.Rollback()
+
+	user, err := uc.repository.FindByUsername(tx, request.Username)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, model.ErrorNotFound(errors.New("username not found"))
@@ -122,5 +250,55 @@ func (uc *UserUsecase) GetByUsername(ctx context.Context, username string) (*mod
 		return nil, model.ErrorInternalServerError(errors.New("failed to find user data by username"))
 	}
 
+	if err := uc.repository.Trash(tx, user, nil); err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to delete user"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
 	return model.ToUserResponse(user), nil
 }
+
+func (uc *UserUsecase) update(ctx context.Context, username string, change func(user *entity.User) error) (*model.UserResponse, error) {
+	tx := begin(ctx, uc.db)
+	defer tx.Rollback()
+
+	user, err := uc.repository.FindByUsername(tx, username)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrorNotFound(errors.New("username not found"))
+		}
+		return nil, model.ErrorInternalServerError(errors.New("failed to find user data by username"))
+	}
+
+	if err := change(user); err != nil {
+		return nil, err
+	}
+
+	if err := uc.repository.Update(tx, user); err != nil {
+		return nil, model.ErrorInternalServerError(errors.New("failed to update user"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		gotracing.Error("Failed to commit transaction", err)
+		return nil, model.ErrorInternalServerError(errors.New("failed to commit transaction"))
+	}
+
+	return model.ToUserResponse(user), nil
+}
+
+func hashPassword(password string) (string, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		gotracing.Error("Failed to generate hashed password", err)
+		return "", model.ErrorInternalServerError(errors.New("failed to generate hashed password"))
+	}
+	return string(hashedPassword), nil
+}
+
+func isUserRole(role string) bool {
+	return role == entity.UserRoleUser || role == entity.UserRoleModerator || role == entity.UserRoleAdmin
+}
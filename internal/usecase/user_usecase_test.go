@@ -6,21 +6,22 @@ import (
 	"testing"
 	"time"
 
-	"github.com/mnaufalhilmym/bookshelf/internal/config"
+	"github.com/mnaufalhilmym/bookshelf/internal/entity"
 	"github.com/mnaufalhilmym/bookshelf/internal/model"
 	"github.com/mnaufalhilmym/bookshelf/internal/repository"
+	"github.com/mnaufalhilmym/bookshelf/internal/testdb"
 	"github.com/mnaufalhilmym/bookshelf/internal/usecase"
 	"github.com/stretchr/testify/assert"
 )
 
 func newUserUsecase() *usecase.UserUsecase {
-	db := config.NewDatabase(":memory:", 1, 1, 100)
-	repo := repository.NewUserRepository(db)
+	db := newDatabase()
+	repo := repository.NewUserRepository()
 	return usecase.NewUserUsecase(db, repo, "jwtKey", 10*time.Second)
 }
 
 func newFailUserUsecase() *usecase.UserUsecase {
-	db := config.NewDatabase(":memory:", 1, 1, 100)
+	db := testdb.New()
 	repo := &repository.UserRepository{}
 	return usecase.NewUserUsecase(db, repo, "jwtKey", 10*time.Second)
 }
@@ -198,3 +199,95 @@ func TestUserUsecase_Login(t *testing.T) {
 		assert.EqualValues(t, returned.resp, resp)
 	})
 }
+
+func TestUserUsecase_Authenticate(t *testing.T) {
+	userUc := newUserUsecase()
+
+	user, err := userUc.Register(context.Background(), &model.RegisterUserRequest{
+		Username: "reader",
+		Password: "password",
+	})
+	assert.NoError(t, err)
+
+	t.Run("Positive Case - valid credentials", func(t *testing.T) {
+		res, err := userUc.Authenticate(context.Background(), "reader", "password")
+		assert.NoError(t, err)
+		assert.EqualValues(t, user, res)
+	})
+
+	t.Run("Negative Case - wrong password", func(t *testing.T) {
+		_, err := userUc.Authenticate(context.Background(), "reader", "wrong")
+		assert.EqualValues(t, model.ErrorUnauthorized(errors.New("invalid username or password")), err)
+	})
+
+	t.Run("Negative Case - unknown user", func(t *testing.T) {
+		_, err := userUc.Authenticate(context.Background(), "nobody", "password")
+		assert.EqualValues(t, model.ErrorUnauthorized(errors.New("invalid username or password")), err)
+	})
+}
+
+func TestUserUsecase_Admin(t *testing.T) {
+	userUc := newUserUsecase()
+	ctx := context.Background()
+
+	t.Run("Positive Case - create admin", func(t *testing.T) {
+		res, err := userUc.Create(ctx, &model.CreateUserRequest{
+			Username: "admin",
+			Password: "password",
+			Role:     entity.UserRoleAdmin,
+		})
+		assert.NoError(t, err)
+		assert.EqualValues(t, entity.UserRoleAdmin, res.Role)
+	})
+
+	t.Run("Negative Case - create with unknown role", func(t *testing.T) {
+		_, err := userUc.Create(ctx, &model.CreateUserRequest{
+			Username: "root",
+			Password: "password",
+			Role:     "root",
+		})
+		assert.EqualValues(t, model.ErrorBadRequest(errors.New("role must be user, moderator or admin")), err)
+	})
+
+	t.Run("Negative Case - create duplicate", func(t *testing.T) {
+		_, err := userUc.Create(ctx, &model.CreateUserRequest{
+			Username: "admin",
+			Password: "password",
+			Role:     entity.UserRoleUser,
+		})
+		assert.EqualValues(t, model.ErrorBadRequest(errors.New("duplicate username")), err)
+	})
+
+	t.Run("Positive Case - set role", func(t *testing.T) {
+		_, err := userUc.Register(ctx, &model.RegisterUserRequest{Username: "reader", Password: "password"})
+		assert.NoError(t, err)
+
+		res, err := userUc.SetRole(ctx, &model.SetUserRoleRequest{Username: "reader", Role: entity.UserRoleModerator})
+		assert.NoError(t, err)
+		assert.EqualValues(t, entity.UserRoleModerator, res.Role)
+	})
+
+	t.Run("Negative Case - set role of unknown user", func(t *testing.T) {
+		_, err := userUc.SetRole(ctx, &model.SetUserRoleRequest{Username: "nobody", Role: entity.UserRoleAdmin})
+		assert.EqualValues(t, model.ErrorNotFound(errors.New("username not found")), err)
+	})
+
+	t.Run("Positive Case - reset password", func(t *testing.T) {
+		_, err := userUc.ResetPassword(ctx, &model.ResetUserPasswordRequest{Username: "reader", Password: "new password"})
+		assert.NoError(t, err)
+
+		_, err = userUc.Authenticate(ctx, "reader", "password")
+		assert.Error(t, err)
+		res, err := userUc.Authenticate(ctx, "reader", "new password")
+		assert.NoError(t, err)
+		assert.EqualValues(t, entity.UserRoleModerator, res.Role)
+	})
+
+	t.Run("Positive Case - list", func(t *testing.T) {
+		res, err := userUc.GetMany(ctx)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, len(res))
+		assert.EqualValues(t, "admin", res[0].Username)
+		assert.EqualValues(t, "reader", res[1].Username)
+	})
+}
@@ -0,0 +1,24 @@
+package util
+
+import "strings"
+
+// IsISBN reports whether the value has the digits of an ISBN-10 or ISBN-13,
+// which tells real ISBNs apart from the placeholders imports store.
+func IsISBN(isbn string) bool {
+	digits := 0
+	for _, c := range isbn {
+		switch {
+		case c >= '0' && c <= '9', c == 'X' || c == 'x':
+			digits++
+		case c == '-' || c == ' ':
+		default:
+			return false
+		}
+	}
+	return digits == 10 || digits == 13
+}
+
+// CompactISBN removes the hyphens and spaces of an ISBN.
+func CompactISBN(isbn string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(isbn, "-", ""), " ", "")
+}